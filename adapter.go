@@ -3,7 +3,9 @@ package keyvalue
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"reflect"
+	"sync"
 
 	"github.com/mailstepcz/types"
 )
@@ -44,22 +46,45 @@ type FactoryOption struct {
 func (o *FactoryOption) adapterOption() {}
 
 // ConvertorOption is an option specifying custom type conversions.
+// It's safe to register conversions with [RegisterConv] while the option is being used by concurrent copies.
+// Funcs mustn't be modified directly once the option is shared.
 type ConvertorOption struct {
 	Funcs map[TypePair]func(interface{}) (interface{}, error)
+	mtx   sync.RWMutex
 }
 
 func (o *ConvertorOption) adapterOption() {}
 
+// NamingOption is an option specifying the names under which structure fields are enumerated and set,
+// e.g. [AvroFields] for matching structures with Avro records.
+type NamingOption struct {
+	Naming NamingStrategy
+}
+
+func (o *NamingOption) adapterOption() {}
+
+// funcs returns a snapshot of the registered conversions.
+func (o *ConvertorOption) funcs() map[TypePair]func(interface{}) (interface{}, error) {
+	o.mtx.RLock()
+	defer o.mtx.RUnlock()
+	return o.Funcs
+}
+
 // RegisterConv registers a custom conversion function.
+// The conversions are copied on write so that snapshots held by running copies are never mutated.
 func RegisterConv[T, U any](o *ConvertorOption, f func(T) (U, error)) {
 	t1 := reflect.TypeOf((*T)(nil)).Elem()
 	t2 := reflect.TypeOf((*U)(nil)).Elem()
-	if o.Funcs == nil {
-		o.Funcs = make(map[TypePair]func(interface{}) (interface{}, error))
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	funcs := maps.Clone(o.Funcs)
+	if funcs == nil {
+		funcs = make(map[TypePair]func(interface{}) (interface{}, error))
 	}
-	o.Funcs[TypePair{t1, t2}] = func(x interface{}) (interface{}, error) {
+	funcs[TypePair{t1, t2}] = func(x interface{}) (interface{}, error) {
 		return f(x.(T))
 	}
+	o.Funcs = funcs
 }
 
 // TypePair is a pair of types.
@@ -74,7 +99,11 @@ func (v InterfaceValue) Interface() interface{} {
 }
 
 // NewAdapter creates a new adapter.
+// Objects which are adapters already are returned as they are.
 func NewAdapter(obj interface{}, opts ...Option) (Adapter, error) {
+	if a, ok := obj.(Adapter); ok {
+		return a, nil
+	}
 	t := reflect.TypeOf(obj)
 	if t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Struct {
 		if t == types.StructpbPtr {
@@ -89,11 +118,24 @@ func NewAdapter(obj interface{}, opts ...Option) (Adapter, error) {
 }
 
 // Copy copies the contents of the source object to the destination object.
+//
+// Deprecated: Use [TypedCopy].
 func Copy(dst, src interface{}) error {
 	return NewCopy(dst, src)
 }
 
-// CopyV1 copies the contents of the source object to the destination object.
+// MustCopy copies the contents of the source object to the destination object. It panics on error.
+//
+// Deprecated: Use [MustTypedCopy].
+func MustCopy(dst, src interface{}) {
+	if err := Copy(dst, src); err != nil {
+		panic(err)
+	}
+}
+
+// CopyV1 copies the contents of the source object to the destination object using adapters.
+//
+// Deprecated: Use [TypedCopy] with [WithEngine] and [EngineAdapter].
 func CopyV1(dst, src interface{}, opts ...Option) error {
 	var convertor *ConvertorOption
 	for _, opt := range opts {
@@ -103,7 +145,7 @@ func CopyV1(dst, src interface{}, opts ...Option) error {
 		}
 	}
 	if convertor != nil {
-		if conv, ok := convertor.Funcs[TypePair{reflect.TypeOf(src), reflect.TypeOf(dst)}]; ok {
+		if conv, ok := convertor.funcs()[TypePair{reflect.TypeOf(src), reflect.TypeOf(dst)}]; ok {
 			r, err := conv(src)
 			if err != nil {
 				return err
@@ -3,7 +3,9 @@ package keyvalue
 import (
 	"database/sql"
 	"fmt"
+	"mime/multipart"
 	"reflect"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,6 +18,9 @@ type StructAdapter struct {
 	value     reflect.Value
 	factory   *FactoryOption
 	convertor *ConvertorOption
+	naming    NamingStrategy
+	fields    map[string]reflect.StructField
+	opts      []Option
 }
 
 // NewStructAdapter creates a new adapter for a structure..
@@ -31,6 +36,7 @@ func NewStructAdapter(obj interface{}, opts ...Option) (*StructAdapter, error) {
 	var (
 		fo *FactoryOption
 		co *ConvertorOption
+		ns NamingStrategy
 	)
 	for _, opt := range opts {
 		switch opt := opt.(type) {
@@ -38,24 +44,39 @@ func NewStructAdapter(obj interface{}, opts ...Option) (*StructAdapter, error) {
 			fo = opt
 		case *ConvertorOption:
 			co = opt
+		case *NamingOption:
+			ns = opt.Naming
 		}
 	}
+	var fields map[string]reflect.StructField
+	if ns != nil {
+		fields = fieldsByName(v.Type(), ns)
+	}
 	return &StructAdapter{
 		value:     v,
 		factory:   fo,
 		convertor: co,
+		naming:    ns,
+		fields:    fields,
+		opts:      opts,
 	}, nil
 }
 
 // EnumFields enumerates all the public fields of the underlying value.
 func (a *StructAdapter) EnumFields(fn func(string, Value) error) error {
-	for i := 0; i < a.value.NumField(); i++ {
-		f := a.value.Type().Field(i)
-		if f.PkgPath != "" || f.Tag.Get("kv") == "-" {
+	for _, fd := range describeType(a.value.Type()).fields {
+		f := fd.StructField
+		if len(f.Index) > 1 || f.PkgPath != "" || fd.skipped {
 			continue
 		}
-		v := a.value.Field(i)
-		if err := fn(f.Name, InterfaceValue{value: v.Interface()}); err != nil {
+		name := f.Name
+		if a.naming != nil {
+			if name = a.naming(f); name == "" {
+				continue
+			}
+		}
+		v := a.value.Field(f.Index[0])
+		if err := fn(name, InterfaceValue{value: v.Interface()}); err != nil {
 			return err
 		}
 	}
@@ -64,7 +85,20 @@ func (a *StructAdapter) EnumFields(fn func(string, Value) error) error {
 
 // Set sets the value of a field.
 func (a *StructAdapter) Set(name string, value interface{}) error {
-	sf, ok := a.value.Type().FieldByName(name)
+	var (
+		sf reflect.StructField
+		ok bool
+	)
+	if a.naming != nil {
+		sf, ok = a.fields[name]
+		if !ok {
+			if key, found := describeType(a.value.Type()).canonical[name]; found {
+				sf, ok = a.fields[key]
+			}
+		}
+	} else {
+		sf, ok = a.value.Type().FieldByName(name)
+	}
 	if !ok {
 		return fmt.Errorf("field '%s': %w", name, ErrNoSuchField)
 	}
@@ -73,13 +107,33 @@ func (a *StructAdapter) Set(name string, value interface{}) error {
 	}
 	f := a.value.FieldByIndex(sf.Index)
 	v := reflect.ValueOf(value)
+	if name := tagConverterName(sf); name != "" {
+		conv, ok := LookupNamedConv(name, f.Type(), v.Type())
+		if !ok {
+			return fmt.Errorf("field '%s', converter '%s': %w", sf.Name, name, ErrNotRegistered)
+		}
+		d, err := conv.Func(value)
+		if err != nil {
+			return err
+		}
+		dv := reflect.ValueOf(d)
+		if !dv.IsValid() {
+			f.SetZero()
+			return nil
+		}
+		f.Set(dv)
+		return nil
+	}
+	if src, ok := value.(Adapter); ok && (f.Kind() == reflect.Struct || f.Kind() == reflect.Pointer && f.Type().Elem().Kind() == reflect.Struct) {
+		return a.setNested(f, src)
+	}
 	var builder func() interface{}
 	if a.factory != nil {
-		builder = a.factory.Builders[name]
+		builder = a.factory.Builders[sf.Name]
 	}
 	var customFuncs map[TypePair]func(interface{}) (interface{}, error)
 	if a.convertor != nil {
-		customFuncs = a.convertor.Funcs
+		customFuncs = a.convertor.funcs()
 	}
 	d, err := destValue(f.Type(), v, builder, customFuncs)
 	if err != nil {
@@ -89,12 +143,40 @@ func (a *StructAdapter) Set(name string, value interface{}) error {
 	return nil
 }
 
+// setNested copies the fields enumerated by the adapter into the structure field with the options of the adapter.
+// Nil structure pointers are allocated.
+func (a *StructAdapter) setNested(f reflect.Value, src Adapter) error {
+	ptr := f
+	if f.Kind() == reflect.Pointer {
+		if f.IsNil() {
+			f.Set(reflect.New(f.Type().Elem()))
+		}
+	} else {
+		ptr = f.Addr()
+	}
+	dst, err := NewStructAdapter(ptr.Interface(), a.opts...)
+	if err != nil {
+		return err
+	}
+	return src.EnumFields(func(name string, value Value) error {
+		return dst.Set(name, value.Interface())
+	})
+}
+
 func destValue(t reflect.Type, v reflect.Value, builder func() interface{}, customFuncs map[TypePair]func(interface{}) (interface{}, error)) (interface{}, error) {
 	switch {
 	case v.Type().AssignableTo(t):
 		return v.Interface(), nil
 	case v.Type().ConvertibleTo(t):
 		return v.Convert(t).Interface(), nil
+	case v.Type() == fileHeaderType && isHeaderBytes(t):
+		b, err := readFileHeader(v.Interface().(*multipart.FileHeader))
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(b).Convert(t).Interface(), nil
+	case v.Type() == fileHeaderType && t.Kind() == reflect.Interface && multipartFileType.Implements(t):
+		return v.Interface().(*multipart.FileHeader).Open()
 	case t.Kind() == reflect.Interface:
 		if builder == nil {
 			return nil, ErrNoBuilder
@@ -193,6 +275,24 @@ func destValue(t reflect.Type, v reflect.Value, builder func() interface{}, cust
 			return nil, err
 		}
 		return timestamppb.New(t), nil
+	case t == durationType && v.Kind() == reflect.String:
+		return time.ParseDuration(v.String())
+	case isNumber(t) && v.Kind() == reflect.String:
+		return parseNumber(t, v.String())
+	case t.Kind() == reflect.Bool && v.Kind() == reflect.String:
+		b, err := strconv.ParseBool(v.String())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(b).Convert(t).Interface(), nil
+	case t.Kind() == reflect.Slice && (v.Kind() == reflect.String || v.Type() == fileHeaderType):
+		d, err := destValue(t.Elem(), v, nil, customFuncs)
+		if err != nil {
+			return nil, err
+		}
+		r := reflect.MakeSlice(t, 1, 1)
+		r.Index(0).Set(reflect.ValueOf(d))
+		return r.Interface(), nil
 	case t.Kind() == reflect.Slice && v.Kind() == reflect.Slice:
 		r := reflect.MakeSlice(t, v.Len(), v.Len())
 		for i := 0; i < v.Len(); i++ {
@@ -211,6 +311,32 @@ func destValue(t reflect.Type, v reflect.Value, builder func() interface{}, cust
 	}
 }
 
+// parseNumber parses the string as a number of the type.
+func parseNumber(t reflect.Type, s string) (interface{}, error) {
+	r := reflect.New(t).Elem()
+	switch {
+	case isFloat(t):
+		x, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return nil, err
+		}
+		r.SetFloat(x)
+	case r.CanInt():
+		x, err := strconv.ParseInt(s, 10, t.Bits())
+		if err != nil {
+			return nil, err
+		}
+		r.SetInt(x)
+	default:
+		x, err := strconv.ParseUint(s, 10, t.Bits())
+		if err != nil {
+			return nil, err
+		}
+		r.SetUint(x)
+	}
+	return r.Interface(), nil
+}
+
 var (
 	_ Adapter = (*StructAdapter)(nil)
 )
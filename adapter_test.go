@@ -2,7 +2,9 @@ package keyvalue
 
 import (
 	"encoding/json"
+	"reflect"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -255,6 +257,39 @@ func TestCustomConvInnerCopy(t *testing.T) {
 	req.Equal("1234", dst.X.Y)
 }
 
+func TestConcurrentRegisterConv(t *testing.T) {
+	req := require.New(t)
+
+	conv := new(ConvertorOption)
+	RegisterConv(conv, func(x *custSrc) (*custDst, error) {
+		return &custDst{Y: strconv.Itoa(x.X)}, nil
+	})
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	dsts := make([]custDst, 10)
+	for i := range errs {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			RegisterConv(conv, func(x int) (string, error) {
+				return strconv.Itoa(x), nil
+			})
+		}()
+		go func() {
+			defer wg.Done()
+			errs[i] = CopyV1(&dsts[i], &custSrc{X: 1234}, conv)
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		req.NoError(err)
+		req.Equal("1234", dsts[i].Y)
+	}
+
+	_, ok := lookupConvertor(reflect.TypeFor[string](), reflect.TypeFor[uuid.UUID]())
+	req.True(ok)
+}
+
 type s3 struct {
 	IDs []string
 }
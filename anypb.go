@@ -0,0 +1,84 @@
+package keyvalue
+
+import (
+	"reflect"
+	"sync"
+	"unsafe"
+
+	"github.com/mailstepcz/serr"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+var (
+	anyPtrType       = reflect.TypeFor[*anypb.Any]()
+	protoMessageType = reflect.TypeFor[proto.Message]()
+
+	anyMessageTypes   = make(map[protoreflect.FullName]reflect.Type)
+	anyMessageTypesMu sync.RWMutex
+)
+
+// RegisterAnyMessage allows messages of the type of the message to be unpacked from [anypb.Any] fields.
+// Fields holding messages of types which aren't registered fail to copy with [ErrNotRegistered]
+// so that the copy never instantiates arbitrary messages named by the sender.
+func RegisterAnyMessage(m proto.Message) {
+	anyMessageTypesMu.Lock()
+	defer anyMessageTypesMu.Unlock()
+	anyMessageTypes[m.ProtoReflect().Descriptor().FullName()] = reflect.TypeOf(m)
+}
+
+// lookupAnyMessage returns the type of the registered message with the full name.
+func lookupAnyMessage(name protoreflect.FullName) (reflect.Type, bool) {
+	anyMessageTypesMu.RLock()
+	defer anyMessageTypesMu.RUnlock()
+	t, ok := anyMessageTypes[name]
+	return t, ok
+}
+
+// anyUnpackConv returns a converter unpacking [anypb.Any] values. The message is unpacked into a new message
+// of the registered type matching the type URL, which is then converted into the destination like by [TypedCopy].
+func anyUnpackConv(dstType reflect.Type, opts *CopierOptions) func(unsafe.Pointer, unsafe.Pointer) error {
+	onNil := nilHandler(dstType, opts)
+	return func(dst, src unsafe.Pointer) error {
+		a := *(**anypb.Any)(src)
+		if a == nil {
+			return onNil(dst)
+		}
+		mt, ok := lookupAnyMessage(a.MessageName())
+		if !ok {
+			return serr.Wrap("", ErrNotRegistered, serr.String("typeURL", a.GetTypeUrl()))
+		}
+		m := reflect.New(mt.Elem())
+		if err := a.UnmarshalTo(m.Interface().(proto.Message)); err != nil {
+			return err
+		}
+		if mt == dstType {
+			*(*unsafe.Pointer)(dst) = m.UnsafePointer()
+			return nil
+		}
+		conv, err := valConvWithOptions(dstType, mt, opts)
+		if err != nil {
+			return err
+		}
+		p := m.UnsafePointer()
+		return conv(dst, unsafe.Pointer(&p))
+	}
+}
+
+// anyPackConv returns a converter packing messages into [anypb.Any] values.
+func anyPackConv(srcType reflect.Type, opts *CopierOptions) func(unsafe.Pointer, unsafe.Pointer) error {
+	onNil := nilHandler(anyPtrType, opts)
+	return func(dst, src unsafe.Pointer) error {
+		v := reflect.NewAt(srcType, src).Elem()
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return onNil(dst)
+		}
+		a, err := anypb.New(v.Interface().(proto.Message))
+		if err != nil {
+			return err
+		}
+		*(**anypb.Any)(dst) = a
+		return nil
+	}
+}
@@ -0,0 +1,76 @@
+package keyvalue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type anyEvent struct {
+	Payload *anypb.Any
+}
+
+type stringValueEvent struct {
+	Payload *wrapperspb.StringValue
+}
+
+type stringEvent struct {
+	Payload string
+}
+
+type timeEvent struct {
+	Payload time.Time
+}
+
+func TestAnyUnpacking(t *testing.T) {
+	req := require.New(t)
+
+	RegisterAnyMessage(&wrapperspb.StringValue{})
+	RegisterAnyMessage(&timestamppb.Timestamp{})
+
+	a, err := anypb.New(wrapperspb.String("hello"))
+	req.NoError(err)
+
+	var sve stringValueEvent
+	req.NoError(NewerCopy(&sve, &anyEvent{Payload: a}))
+	req.Equal("hello", sve.Payload.GetValue())
+
+	var se stringEvent
+	req.NoError(NewerCopy(&se, &anyEvent{Payload: a}))
+	req.Equal("hello", se.Payload)
+
+	tm := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	a, err = anypb.New(timestamppb.New(tm))
+	req.NoError(err)
+	var te timeEvent
+	req.NoError(NewerCopy(&te, &anyEvent{Payload: a}))
+	req.True(tm.Equal(te.Payload))
+
+	sve = stringValueEvent{}
+	req.NoError(NewerCopy(&sve, &anyEvent{}))
+	req.Nil(sve.Payload)
+
+	a, err = anypb.New(durationpb.New(time.Second))
+	req.NoError(err)
+	req.ErrorIs(NewerCopy(&sve, &anyEvent{Payload: a}), ErrNotRegistered)
+}
+
+func TestAnyPacking(t *testing.T) {
+	req := require.New(t)
+
+	var ae anyEvent
+	req.NoError(NewerCopy(&ae, &stringValueEvent{Payload: wrapperspb.String("hello")}))
+	req.Equal("type.googleapis.com/google.protobuf.StringValue", ae.Payload.GetTypeUrl())
+	var sv wrapperspb.StringValue
+	req.NoError(ae.Payload.UnmarshalTo(&sv))
+	req.Equal("hello", sv.GetValue())
+
+	ae = anyEvent{}
+	req.NoError(NewerCopy(&ae, &stringValueEvent{}))
+	req.Nil(ae.Payload)
+}
@@ -0,0 +1,123 @@
+package keyvalue
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// ChangeRecorder receives the changes of destination fields made by a copier, see [CopierOptions.Recorder].
+type ChangeRecorder interface {
+	RecordChange(FieldDiff)
+}
+
+// ChangeSet is a [ChangeRecorder] collecting the changes. It's safe for concurrent use.
+type ChangeSet struct {
+	mu      sync.Mutex
+	changes []FieldDiff
+}
+
+// RecordChange adds the change to the change set.
+func (c *ChangeSet) RecordChange(d FieldDiff) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.changes = append(c.changes, d)
+}
+
+// Changes returns the recorded changes in the order in which they were made.
+func (c *ChangeSet) Changes() []FieldDiff {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]FieldDiff(nil), c.changes...)
+}
+
+// Reset removes the recorded changes.
+func (c *ChangeSet) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.changes = nil
+}
+
+// contextRecorder stands for the recorder passed on to the copier in the context by [recordingEntry]
+// so that the copiers compiled for options with recorders are shared by all the recorders.
+type contextRecorder struct{}
+
+// RecordChange does nothing since the changes are reported to the recorder in the context.
+func (contextRecorder) RecordChange(FieldDiff) {}
+
+// recorderKey is the context key of the recorder of the changes made by the copier.
+type recorderKey struct{}
+
+// recordingEntry returns the copiers reporting the changes to the recorder of the options.
+// The copiers are those compiled for options taking the recorder from the context, which are cached,
+// so that recorders created for every copy don't make the cache grow.
+func recordingEntry(dstType, srcType reflect.Type, opts *CopierOptions) (*cacheEntry, error) {
+	o := *opts
+	o.Recorder = contextRecorder{}
+	e, err := copierEntry(dstType, srcType, &o)
+	if err != nil {
+		return nil, err
+	}
+	rec := opts.Recorder
+	ctxCopier := func(ctx context.Context, dst, src unsafe.Pointer) error {
+		return e.ctxCopier(context.WithValue(ctx, recorderKey{}, rec), dst, src)
+	}
+	return &cacheEntry{
+		copier:    withoutContext(ctxCopier),
+		ctxCopier: ctxCopier,
+	}, nil
+}
+
+// fieldRecorder returns the recorder of the changes made by the copier.
+func fieldRecorder(opts *CopierOptions, top bool) ChangeRecorder {
+	if !top || opts == nil {
+		return nil
+	}
+	return opts.Recorder
+}
+
+// recordingConv returns a converter reporting the changes the converter makes to the destination field to the recorder.
+// The previous value is deeply copied before the conversion so that changes made in place are reported too.
+func recordingConv(path []string, t reflect.Type, conv func(context.Context, unsafe.Pointer, unsafe.Pointer) error, rec ChangeRecorder) (func(context.Context, unsafe.Pointer, unsafe.Pointer) error, error) {
+	snapshot, err := valConvWithOptions(t, t, deepCopyOptions)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, dst, src unsafe.Pointer) error {
+		rec := rec
+		if _, ok := rec.(contextRecorder); ok {
+			if rec, ok = ctx.Value(recorderKey{}).(ChangeRecorder); !ok {
+				return conv(ctx, dst, src)
+			}
+		}
+		before := reflect.New(t)
+		if err := snapshot(before.UnsafePointer(), dst); err != nil {
+			return err
+		}
+		err := conv(ctx, dst, src)
+		if _, skipped := err.(*SkippedElementsError); err != nil && !skipped {
+			return err
+		}
+		for _, d := range diffValues(nil, path, before.Elem(), reflect.NewAt(t, dst).Elem()) {
+			rec.RecordChange(d)
+		}
+		return err
+	}, nil
+}
+
+// contextConv turns a converter into a context-aware converter ignoring the context.
+func contextConv(conv func(unsafe.Pointer, unsafe.Pointer) error) func(context.Context, unsafe.Pointer, unsafe.Pointer) error {
+	return func(_ context.Context, dst, src unsafe.Pointer) error {
+		return conv(dst, src)
+	}
+}
+
+// fieldPath returns the names of the fields on the way to the nested field with the index.
+func fieldPath(t reflect.Type, index []int) []string {
+	path := make([]string, len(index))
+	for i := range index {
+		path[i] = t.FieldByIndex(index[:i+1]).Name
+	}
+	return path
+}
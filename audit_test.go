@@ -0,0 +1,68 @@
+package keyvalue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type auditAddress struct {
+	Street string
+	City   string
+}
+
+type auditCustomerDTO struct {
+	ID      string
+	Name    string
+	Created time.Time
+	Address *auditAddress
+	Tags    []string
+}
+
+type auditCustomer struct {
+	ID      uuid.UUID
+	Name    string
+	Created time.Time
+	Address *auditAddress
+	Tags    []string
+	Source  string
+}
+
+func TestCopyRecorder(t *testing.T) {
+	req := require.New(t)
+
+	u := uuid.New()
+	created := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	addr := &auditAddress{Street: "Main", City: "Prague"}
+	dst := auditCustomer{
+		ID:      u,
+		Name:    "John",
+		Created: created,
+		Address: addr,
+		Tags:    []string{"a"},
+	}
+	var cs ChangeSet
+	req.NoError(TypedCopy(&dst, &auditCustomerDTO{
+		ID:      u.String(),
+		Name:    "Jane",
+		Created: created.In(time.FixedZone("CEST", 2*60*60)),
+		Address: &auditAddress{Street: "Main", City: "Brno"},
+		Tags:    []string{"a", "b"},
+	}, WithOmitNotFound(), WithRecorder(&cs), func(o *CopierOptions) {
+		o.Constants = map[string]interface{}{"Source": "import"}
+	}))
+
+	changes := cs.Changes()
+	req.Len(changes, 4)
+	req.Equal(FieldDiff{FieldPath: []string{"Name"}, Before: "John", After: "Jane"}, changes[0])
+	req.Equal(FieldDiff{FieldPath: []string{"Address", "City"}, Before: "Prague", After: "Brno"}, changes[1])
+	req.Equal("Tags", changes[2].Path())
+	req.Equal([]string{"a"}, changes[2].Before)
+	req.Equal([]string{"a", "b"}, changes[2].After)
+	req.Equal(FieldDiff{FieldPath: []string{"Source"}, Before: "", After: "import"}, changes[3])
+
+	cs.Reset()
+	req.Empty(cs.Changes())
+}
@@ -0,0 +1,209 @@
+package keyvalue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mailstepcz/serr"
+	"github.com/shopspring/decimal"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var (
+	// ErrInvalidAvroSchema signifies that an Avro schema isn't the schema of a record.
+	ErrInvalidAvroSchema = errors.New("invalid Avro schema")
+)
+
+// Avro logical types converted by the adapter.
+const (
+	avroDecimal         = "decimal"
+	avroTimestampMillis = "timestamp-millis"
+	avroTimestampMicros = "timestamp-micros"
+	avroUUID            = "uuid"
+)
+
+// AvroFields is a naming strategy matching fields with the fields of Avro records.
+// The field name is taken from the `avro` tag like in hamba/avro and defaults to the field name.
+// Fields tagged with `avro:"-"` aren't copied.
+func AvroFields(f reflect.StructField) string {
+	switch tag := f.Tag.Get("avro"); tag {
+	case "-":
+		return ""
+	case "":
+		return f.Name
+	default:
+		return tag
+	}
+}
+
+// AvroRecordAdapter is a key-value adapter for Avro generic records, i.e. the maps hamba/avro and goavro
+// decode records into and encode records from. Values of the fields with the logical types decimal, timestamp-millis,
+// timestamp-micros and uuid are converted into [decimal.Decimal], [time.Time] and [uuid.UUID] respectively
+// and back. Values of union fields wrapped in single-entry maps by goavro are unwrapped while null values are skipped.
+type AvroRecordAdapter struct {
+	record map[string]interface{}
+	fields []avroField
+}
+
+// avroField is a field of an Avro record schema.
+type avroField struct {
+	name        string
+	logicalType string
+	scale       int32
+	union       bool
+}
+
+// avroType is an Avro type given by an object.
+type avroType struct {
+	Type        json.RawMessage `json:"type"`
+	LogicalType string          `json:"logicalType"`
+	Scale       int32           `json:"scale"`
+}
+
+// NewAvroRecordAdapter creates a new adapter for an Avro generic record with the schema in its JSON form.
+func NewAvroRecordAdapter(record map[string]interface{}, schema string) (*AvroRecordAdapter, error) {
+	var s struct {
+		Type   string `json:"type"`
+		Fields []struct {
+			Name string          `json:"name"`
+			Type json.RawMessage `json:"type"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(schema), &s); err != nil {
+		return nil, serr.Wrap("", ErrInvalidAvroSchema, serr.Error("error", err))
+	}
+	if s.Type != "record" {
+		return nil, serr.Wrap("", ErrInvalidAvroSchema, serr.String("type", s.Type))
+	}
+	fields := make([]avroField, len(s.Fields))
+	for i, f := range s.Fields {
+		fields[i] = parseAvroField(f.Name, f.Type)
+	}
+	return &AvroRecordAdapter{
+		record: record,
+		fields: fields,
+	}, nil
+}
+
+// parseAvroField describes the field of the type. The logical type of a union is that of its first non-null branch.
+func parseAvroField(name string, typ json.RawMessage) avroField {
+	f := avroField{name: name}
+	var branches []json.RawMessage
+	if json.Unmarshal(typ, &branches) == nil {
+		f.union = true
+	} else {
+		branches = []json.RawMessage{typ}
+	}
+	for _, b := range branches {
+		var t avroType
+		if json.Unmarshal(b, &t) == nil && t.LogicalType != "" {
+			f.logicalType = t.LogicalType
+			f.scale = t.Scale
+			break
+		}
+	}
+	return f
+}
+
+// EnumFields enumerates all the fields of the underlying record which are present and not null.
+func (a *AvroRecordAdapter) EnumFields(fn func(string, Value) error) error {
+	for _, f := range a.fields {
+		v := a.record[f.name]
+		if m, ok := v.(map[string]interface{}); ok && f.union && len(m) == 1 {
+			for _, branch := range m {
+				v = branch
+			}
+		}
+		if v == nil {
+			continue
+		}
+		v, err := f.decode(v)
+		if err != nil {
+			return fmt.Errorf("field '%s': %w", f.name, err)
+		}
+		if err := fn(f.name, InterfaceValue{value: v}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Set sets the value of a field.
+func (a *AvroRecordAdapter) Set(name string, value interface{}) error {
+	for _, f := range a.fields {
+		if f.name == name {
+			a.record[name] = f.encode(value)
+			return nil
+		}
+	}
+	return fmt.Errorf("field '%s': %w", name, ErrNoSuchField)
+}
+
+// decode converts the value of the field according to its logical type.
+func (f *avroField) decode(v interface{}) (interface{}, error) {
+	switch f.logicalType {
+	case avroDecimal:
+		switch v := v.(type) {
+		case *big.Rat:
+			return decimal.NewFromBigRat(v, f.scale), nil
+		case []byte:
+			return decimal.NewFromBigInt(twosComplement(v), -f.scale), nil
+		}
+	case avroTimestampMillis:
+		switch v := v.(type) {
+		case int64:
+			return time.UnixMilli(v).UTC(), nil
+		case int:
+			return time.UnixMilli(int64(v)).UTC(), nil
+		}
+	case avroTimestampMicros:
+		switch v := v.(type) {
+		case int64:
+			return time.UnixMicro(v).UTC(), nil
+		case int:
+			return time.UnixMicro(int64(v)).UTC(), nil
+		}
+	case avroUUID:
+		if s, ok := v.(string); ok {
+			return uuid.Parse(s)
+		}
+	}
+	return v, nil
+}
+
+// encode converts the value into the representation of the logical type of the field.
+func (f *avroField) encode(v interface{}) interface{} {
+	switch f.logicalType {
+	case avroDecimal:
+		if d, ok := v.(decimal.Decimal); ok {
+			return d.Rat()
+		}
+	case avroTimestampMillis, avroTimestampMicros:
+		if ts, ok := v.(*timestamppb.Timestamp); ok {
+			return ts.AsTime()
+		}
+	case avroUUID:
+		if u, ok := v.(uuid.UUID); ok {
+			return u.String()
+		}
+	}
+	return v
+}
+
+// twosComplement decodes a big-endian two's complement integer.
+func twosComplement(b []byte) *big.Int {
+	i := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		i.Sub(i, new(big.Int).Lsh(big.NewInt(1), uint(len(b))*8))
+	}
+	return i
+}
+
+var (
+	_ Adapter = (*AvroRecordAdapter)(nil)
+)
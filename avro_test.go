@@ -0,0 +1,104 @@
+package keyvalue
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+const orderSchema = `{
+	"type": "record",
+	"name": "Order",
+	"fields": [
+		{"name": "id", "type": {"type": "string", "logicalType": "uuid"}},
+		{"name": "amount", "type": {"type": "bytes", "logicalType": "decimal", "precision": 10, "scale": 2}},
+		{"name": "created_at", "type": ["null", {"type": "long", "logicalType": "timestamp-millis"}]},
+		{"name": "note", "type": ["null", "string"]},
+		{"name": "count", "type": "int"}
+	]
+}`
+
+type avroOrder struct {
+	ID        uuid.UUID       `avro:"id"`
+	Amount    decimal.Decimal `avro:"amount"`
+	CreatedAt time.Time       `avro:"created_at"`
+	Note      string          `avro:"note"`
+	Count     int             `avro:"count"`
+	Internal  string          `avro:"-"`
+}
+
+func TestAvroRecordToStruct(t *testing.T) {
+	req := require.New(t)
+
+	u := uuid.New()
+	tm := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	record := map[string]interface{}{
+		"id":         u.String(),
+		"amount":     []byte{0xfe, 0xd4},
+		"created_at": map[string]interface{}{"long.timestamp-millis": tm.UnixMilli()},
+		"note":       nil,
+		"count":      int32(3),
+	}
+	a, err := NewAvroRecordAdapter(record, orderSchema)
+	req.NoError(err)
+
+	order := avroOrder{Note: "kept"}
+	req.NoError(CopyV1(&order, a, &NamingOption{Naming: AvroFields}))
+	req.Equal(u, order.ID)
+	req.Equal("-3", order.Amount.String())
+	req.Equal(tm, order.CreatedAt)
+	req.Equal("kept", order.Note)
+	req.Equal(3, order.Count)
+}
+
+func TestAvroRecordFromStruct(t *testing.T) {
+	req := require.New(t)
+
+	u := uuid.New()
+	tm := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	record := make(map[string]interface{})
+	a, err := NewAvroRecordAdapter(record, orderSchema)
+	req.NoError(err)
+
+	order := avroOrder{
+		ID:        u,
+		Amount:    decimal.RequireFromString("123.45"),
+		CreatedAt: tm,
+		Note:      "note",
+		Count:     3,
+		Internal:  "internal",
+	}
+	req.NoError(CopyV1(a, &order, &NamingOption{Naming: AvroFields}))
+	req.Equal(map[string]interface{}{
+		"id":         u.String(),
+		"amount":     big.NewRat(12345, 100),
+		"created_at": tm,
+		"note":       "note",
+		"count":      3,
+	}, record)
+
+	var roundTrip avroOrder
+	req.NoError(CopyV1(&roundTrip, a, &NamingOption{Naming: AvroFields}))
+	req.Equal(order.ID, roundTrip.ID)
+	req.True(order.Amount.Equal(roundTrip.Amount))
+	req.Equal(order.CreatedAt, roundTrip.CreatedAt)
+	req.Equal(order.Note, roundTrip.Note)
+}
+
+func TestAvroRecordAdapterErrors(t *testing.T) {
+	req := require.New(t)
+
+	_, err := NewAvroRecordAdapter(nil, `{"type": "enum", "symbols": ["A"]}`)
+	req.ErrorIs(err, ErrInvalidAvroSchema)
+
+	a, err := NewAvroRecordAdapter(map[string]interface{}{"id": "nope"}, orderSchema)
+	req.NoError(err)
+	req.ErrorIs(a.Set("unknown", 1), ErrNoSuchField)
+
+	var order avroOrder
+	req.Error(CopyV1(&order, a, &NamingOption{Naming: AvroFields}))
+}
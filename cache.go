@@ -0,0 +1,138 @@
+package keyvalue
+
+import (
+	"context"
+	"sync/atomic"
+	"unsafe"
+)
+
+var (
+	cacheLimit int
+	cacheTick  atomic.Int64
+)
+
+type cacheEntry struct {
+	copier    func(unsafe.Pointer, unsafe.Pointer) error
+	ctxCopier func(context.Context, unsafe.Pointer, unsafe.Pointer) error
+	used      atomic.Int64
+}
+
+// SetCacheLimit bounds the number of cached copiers and, separately, the number of cached value converters.
+// When the limit is reached, the least recently used copier or converter is evicted.
+// A non-positive limit makes the caches unbounded, which is the default.
+func SetCacheLimit(n int) {
+	cacheMtx.Lock()
+	cacheLimit = n
+	for cacheLimit > 0 && len(copiers) > cacheLimit {
+		evictLRU(copiers)
+	}
+	cacheMtx.Unlock()
+	valConvMtx.Lock()
+	for n > 0 && len(valConvs) > n {
+		evictLRU(valConvs)
+	}
+	valConvMtx.Unlock()
+	internMtx.Lock()
+	for n > 0 && len(internedOptions) > n {
+		evictInterned()
+	}
+	internMtx.Unlock()
+}
+
+// ResetCache removes all the cached copiers and value converters so that they're compiled anew when next used,
+// e.g. once converters have been registered anew between tests. Copiers obtained before the reset keep working.
+// Registered converters, conversion providers and messages aren't affected.
+func ResetCache() {
+	cacheMtx.Lock()
+	copiers = make(map[copierTypePair]*cacheEntry)
+	cacheMtx.Unlock()
+	valConvMtx.Lock()
+	valConvs = make(map[copierTypePair]*cacheEntry)
+	valConvMtx.Unlock()
+	internMtx.Lock()
+	internedOptions = make(map[string]*internedEntry)
+	canonicalOpts = make(map[*CopierOptions]string)
+	internMtx.Unlock()
+}
+
+// CacheSize returns the number of cached copiers.
+func CacheSize() int {
+	cacheMtx.RLock()
+	defer cacheMtx.RUnlock()
+	return len(copiers)
+}
+
+func cachedCopier(key copierTypePair) (func(unsafe.Pointer, unsafe.Pointer) error, bool) {
+	e, ok := cachedEntry(key)
+	if !ok {
+		return nil, false
+	}
+	return e.copier, true
+}
+
+func cachedEntry(key copierTypePair) (*cacheEntry, bool) {
+	cacheMtx.RLock()
+	e, ok := copiers[key]
+	cacheMtx.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	e.used.Store(cacheTick.Add(1))
+	return e, true
+}
+
+func storeEntry(key copierTypePair, e *cacheEntry) {
+	e.used.Store(cacheTick.Add(1))
+	cacheMtx.Lock()
+	defer cacheMtx.Unlock()
+	if _, ok := copiers[key]; !ok {
+		for cacheLimit > 0 && len(copiers) >= cacheLimit {
+			evictLRU(copiers)
+		}
+	}
+	copiers[key] = e
+}
+
+func cachedValConv(key copierTypePair) (func(unsafe.Pointer, unsafe.Pointer) error, bool) {
+	valConvMtx.RLock()
+	e, ok := valConvs[key]
+	valConvMtx.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	e.used.Store(cacheTick.Add(1))
+	return e.copier, true
+}
+
+func storeValConv(key copierTypePair, conv func(unsafe.Pointer, unsafe.Pointer) error) {
+	e := &cacheEntry{copier: conv}
+	e.used.Store(cacheTick.Add(1))
+	cacheMtx.RLock()
+	limit := cacheLimit
+	cacheMtx.RUnlock()
+	valConvMtx.Lock()
+	defer valConvMtx.Unlock()
+	if _, ok := valConvs[key]; !ok {
+		for limit > 0 && len(valConvs) >= limit {
+			evictLRU(valConvs)
+		}
+	}
+	valConvs[key] = e
+}
+
+// evictLRU removes the least recently used entry from a cache. The cache must be locked.
+func evictLRU(cache map[copierTypePair]*cacheEntry) {
+	var (
+		lruKey  copierTypePair
+		lruUsed int64
+		found   bool
+	)
+	for k, e := range cache {
+		if u := e.used.Load(); !found || u < lruUsed {
+			lruKey, lruUsed, found = k, u, true
+		}
+	}
+	if found {
+		delete(cache, lruKey)
+	}
+}
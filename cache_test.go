@@ -0,0 +1,217 @@
+package keyvalue
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopierNoCache(t *testing.T) {
+	req := require.New(t)
+
+	ResetCache()
+
+	_, err := CopierForPairWithOptions(reflect.TypeFor[copierDst2](), reflect.TypeFor[copierDst2](), &CopierOptions{NoCache: true})
+	req.NoError(err)
+	req.Zero(CacheSize())
+}
+
+func TestCopierCacheLimit(t *testing.T) {
+	req := require.New(t)
+
+	ResetCache()
+	SetCacheLimit(2)
+	defer SetCacheLimit(0)
+
+	type s1 struct{ N int }
+	type s2 struct{ N int }
+	type s3 struct{ N int }
+
+	_, err := CopierForPair(reflect.TypeFor[s1](), reflect.TypeFor[s1]())
+	req.NoError(err)
+	_, err = CopierForPair(reflect.TypeFor[s2](), reflect.TypeFor[s2]())
+	req.NoError(err)
+	_, err = CopierForPair(reflect.TypeFor[s1](), reflect.TypeFor[s1]())
+	req.NoError(err)
+	_, err = CopierForPair(reflect.TypeFor[s3](), reflect.TypeFor[s3]())
+	req.NoError(err)
+
+	req.Equal(2, CacheSize())
+	req.Contains(copiers, copierTypePair{dst: reflect.TypeFor[s1](), src: reflect.TypeFor[s1]()})
+	req.Contains(copiers, copierTypePair{dst: reflect.TypeFor[s3](), src: reflect.TypeFor[s3]()})
+}
+
+func TestValConvCacheLimit(t *testing.T) {
+	req := require.New(t)
+
+	ResetCache()
+	SetCacheLimit(2)
+	defer SetCacheLimit(0)
+
+	type n1 int
+	type n2 int
+	type n3 int
+
+	_, err := valConv(reflect.TypeFor[n1](), reflect.TypeFor[int]())
+	req.NoError(err)
+	_, err = valConv(reflect.TypeFor[n2](), reflect.TypeFor[int]())
+	req.NoError(err)
+	_, err = valConv(reflect.TypeFor[n3](), reflect.TypeFor[int]())
+	req.NoError(err)
+
+	valConvMtx.RLock()
+	defer valConvMtx.RUnlock()
+	req.Len(valConvs, 2)
+	req.Contains(valConvs, copierTypePair{dst: reflect.TypeFor[n3](), src: reflect.TypeFor[int]()})
+}
+
+func TestOptionsCachedByContents(t *testing.T) {
+	type dst struct {
+		N int64
+		S string
+	}
+
+	req := require.New(t)
+
+	ResetCache()
+	SetCacheLimit(10)
+	defer SetCacheLimit(0)
+
+	for i := 0; i < 100; i++ {
+		_, err := CopierForPairWithOptions(reflect.TypeFor[dst](), reflect.TypeFor[copierDst2](), &CopierOptions{FieldsToOmit: []string{"S"}})
+		req.NoError(err)
+	}
+	req.Equal(1, CacheSize())
+
+	for i := 0; i < 100; i++ {
+		_, err := CopierForPairWithOptions(reflect.TypeFor[dst](), reflect.TypeFor[copierDst2](), &CopierOptions{MinorUnitExponent: int32(i)})
+		req.NoError(err)
+	}
+	req.Equal(10, CacheSize())
+	valConvMtx.RLock()
+	req.LessOrEqual(len(valConvs), 10)
+	valConvMtx.RUnlock()
+
+	ResetCache()
+	for i := 0; i < 2; i++ {
+		_, err := CopierForPairWithOptions(reflect.TypeFor[dst](), reflect.TypeFor[copierDst2](), &CopierOptions{Recorder: &ChangeSet{}})
+		req.NoError(err)
+	}
+	req.Equal(1, CacheSize())
+}
+
+func TestRecordedCopiesCached(t *testing.T) {
+	type entity struct {
+		N int64
+		S string
+	}
+
+	req := require.New(t)
+
+	ResetCache()
+	var size int
+	for i := 0; i < 100; i++ {
+		var changes ChangeSet
+		var d entity
+		req.NoError(TypedCopy(&d, &entity{N: int64(i), S: "x"}, WithRecorder(&changes)))
+		req.Len(changes.Changes(), 1+min(i, 1))
+		if i == 0 {
+			size = CacheSize()
+		}
+	}
+	req.Equal(size, CacheSize())
+
+	double := WithTypeConverter(func(s string) (string, error) { return s + s, nil })
+	for i := 0; i < 10; i++ {
+		var d entity
+		req.NoError(TypedCopy(&d, &entity{S: "x"}, double))
+		req.Equal("xx", d.S)
+	}
+	req.Equal(size+1, CacheSize())
+}
+
+func TestOptionsCachedByLocation(t *testing.T) {
+	req := require.New(t)
+
+	type event struct {
+		At time.Time
+	}
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, offset := range []int{3600, 7200} {
+		var dst event
+		req.NoError(TypedCopy(&dst, &event{At: at}, WithTimeLocation(time.FixedZone("", offset))))
+		_, got := dst.At.Zone()
+		req.Equal(offset, got)
+	}
+
+	var dst event
+	req.NoError(TypedCopy(&dst, &event{At: at}, WithTimeLocation(time.FixedZone("CET", 3600))))
+	req.NoError(TypedCopy(&dst, &event{At: at}, WithTimeLocation(time.FixedZone("CET", 7200))))
+	_, offset := dst.At.Zone()
+	req.Equal(7200, offset)
+
+	ResetCache()
+	for i := 0; i < 10; i++ {
+		req.NoError(TypedCopy(&dst, &event{At: at}, WithTimeLocation(time.FixedZone("CET", 3600))))
+	}
+	req.Equal(1, CacheSize())
+}
+
+func TestResetCache(t *testing.T) {
+	req := require.New(t)
+
+	_, err := CopierForPair(reflect.TypeFor[copierDst2](), reflect.TypeFor[copierDst2]())
+	req.NoError(err)
+	req.NotZero(CacheSize())
+
+	ResetCache()
+	req.Zero(CacheSize())
+}
+
+func TestCopierConcurrentUse(t *testing.T) {
+	type item struct {
+		ID   int
+		Tags []string
+	}
+	type itemDTO struct {
+		ID   int64
+		Tags []string
+	}
+
+	req := require.New(t)
+
+	ResetCache()
+	var wg sync.WaitGroup
+	errs := make([]error, 16)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			copier, err := CopierForPair(reflect.TypeFor[itemDTO](), reflect.TypeFor[item]())
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			for j := 0; j < 100; j++ {
+				var dst itemDTO
+				if err := copier(unsafe.Pointer(&dst), unsafe.Pointer(&item{ID: j, Tags: []string{"a"}})); err != nil {
+					errs[i] = err
+					return
+				}
+				if dst.ID != int64(j) {
+					errs[i] = ErrValidation
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		req.NoError(err)
+	}
+	req.Equal(1, CacheSize())
+}
@@ -0,0 +1,108 @@
+package keyvalue
+
+import (
+	"errors"
+
+	"github.com/mailstepcz/serr"
+)
+
+var (
+	// ErrInvalidChunkSize signifies that a chunked copier was requested with a chunk size which isn't positive.
+	ErrInvalidChunkSize = errors.New("invalid chunk size")
+)
+
+// Chunker converts objects read from a streaming source in chunks of bounded size.
+// The chunk passed to the sink is reused for the next chunk, so the sink mustn't retain it once it returns.
+type Chunker[D, S any] struct {
+	copier    func(*D, *S) error
+	chunkSize int
+}
+
+// ChunkedCopier creates a copier converting objects read from a streaming source, such as the rows of a large query,
+// in chunks of the given size so that the memory used doesn't depend on the number of objects.
+func ChunkedCopier[D, S any](chunkSize int) (*Chunker[D, S], error) {
+	if chunkSize <= 0 {
+		return nil, serr.Wrap("", ErrInvalidChunkSize, serr.Int("chunkSize", chunkSize))
+	}
+	c, err := TypedCopierForPair[D, S]()
+	if err != nil {
+		return nil, err
+	}
+	return &Chunker[D, S]{
+		copier:    c,
+		chunkSize: chunkSize,
+	}, nil
+}
+
+// CopySeq converts the objects yielded by the iterator, which is compatible with iter.Seq[*S], and passes the chunks of converted
+// objects to the sink. The last chunk may be shorter. Copying stops at the first error returned by the copier or the sink.
+func (c *Chunker[D, S]) CopySeq(seq func(yield func(*S) bool), sink func([]D) error) error {
+	var err error
+	ch := c.newChunk()
+	seq(func(src *S) bool {
+		err = ch.add(src, sink)
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	return ch.flush(sink)
+}
+
+// CopyFunc converts the objects returned by next until it reports there are no more objects, and passes the chunks
+// of converted objects to the sink. The last chunk may be shorter. Copying stops at the first error returned by next,
+// the copier or the sink.
+func (c *Chunker[D, S]) CopyFunc(next func() (*S, bool, error), sink func([]D) error) error {
+	ch := c.newChunk()
+	for {
+		src, ok, err := next()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ch.flush(sink)
+		}
+		if err := ch.add(src, sink); err != nil {
+			return err
+		}
+	}
+}
+
+// chunk is the state of a single chunked copy.
+type chunk[D, S any] struct {
+	c     *Chunker[D, S]
+	buf   []D
+	index int
+}
+
+func (c *Chunker[D, S]) newChunk() *chunk[D, S] {
+	return &chunk[D, S]{
+		c:   c,
+		buf: make([]D, 0, c.chunkSize),
+	}
+}
+
+// add converts the object into the chunk and passes the chunk to the sink once it's full.
+func (ch *chunk[D, S]) add(src *S, sink func([]D) error) error {
+	var zero D
+	ch.buf = append(ch.buf, zero)
+	if err := ch.c.copier(&ch.buf[len(ch.buf)-1], src); err != nil {
+		return serr.Wrap("", err, serr.Int("index", ch.index))
+	}
+	ch.index++
+	if len(ch.buf) == ch.c.chunkSize {
+		return ch.flush(sink)
+	}
+	return nil
+}
+
+// flush passes the non-empty chunk to the sink and empties it.
+func (ch *chunk[D, S]) flush(sink func([]D) error) error {
+	if len(ch.buf) == 0 {
+		return nil
+	}
+	err := sink(ch.buf)
+	clear(ch.buf)
+	ch.buf = ch.buf[:0]
+	return err
+}
@@ -0,0 +1,82 @@
+package keyvalue
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type chunkRow struct {
+	ID   int
+	Name string
+}
+
+type chunkDTO struct {
+	ID   int64
+	Name string
+}
+
+func TestChunkedCopier(t *testing.T) {
+	req := require.New(t)
+
+	c, err := ChunkedCopier[chunkDTO, chunkRow](2)
+	req.NoError(err)
+
+	rows := func(n int) func(yield func(*chunkRow) bool) {
+		return func(yield func(*chunkRow) bool) {
+			for i := 0; i < n; i++ {
+				if !yield(&chunkRow{ID: i, Name: strconv.Itoa(i)}) {
+					return
+				}
+			}
+		}
+	}
+
+	var chunks [][]chunkDTO
+	collect := func(ch []chunkDTO) error {
+		chunks = append(chunks, append([]chunkDTO(nil), ch...))
+		return nil
+	}
+	req.NoError(c.CopySeq(rows(5), collect))
+	req.Equal([][]chunkDTO{
+		{{ID: 0, Name: "0"}, {ID: 1, Name: "1"}},
+		{{ID: 2, Name: "2"}, {ID: 3, Name: "3"}},
+		{{ID: 4, Name: "4"}},
+	}, chunks)
+
+	chunks = nil
+	req.NoError(c.CopySeq(rows(0), collect))
+	req.Empty(chunks)
+
+	chunks = nil
+	i := 0
+	next := func() (*chunkRow, bool, error) {
+		if i == 4 {
+			return nil, false, nil
+		}
+		i++
+		return &chunkRow{ID: i}, true, nil
+	}
+	req.NoError(c.CopyFunc(next, collect))
+	req.Equal([][]chunkDTO{{{ID: 1}, {ID: 2}}, {{ID: 3}, {ID: 4}}}, chunks)
+
+	errSink := errors.New("sink")
+	calls := 0
+	err = c.CopySeq(rows(10), func([]chunkDTO) error {
+		calls++
+		return errSink
+	})
+	req.ErrorIs(err, errSink)
+	req.Equal(1, calls)
+
+	errNext := errors.New("next")
+	err = c.CopyFunc(func() (*chunkRow, bool, error) {
+		return nil, false, errNext
+	}, collect)
+	req.ErrorIs(err, errNext)
+
+	_, err = ChunkedCopier[chunkDTO, chunkRow](0)
+	req.ErrorIs(err, ErrInvalidChunkSize)
+}
@@ -0,0 +1,220 @@
+package keyvalue
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"unsafe"
+
+	"github.com/mailstepcz/serr"
+)
+
+var (
+	// ErrCyclicValue is returned if the cloned value references itself.
+	ErrCyclicValue = errors.New("cyclic value")
+)
+
+// deepCopyOptions are the options used by [Clone].
+var deepCopyOptions = &CopierOptions{DeepCopy: true}
+
+// Clone creates a deep copy of the object. Pointers, slices, maps and arrays are copied recursively,
+// while interfaces, channels, functions, unexported structure fields and fields tagged with `kv:"-"` are copied shallowly.
+// Values which reference themselves through the recursively copied values can't be cloned and [ErrCyclicValue] is returned.
+func Clone[T any](src *T) (*T, error) {
+	if src == nil {
+		return nil, nil
+	}
+	t := reflect.TypeFor[T]()
+	if hasPointers(t) {
+		if err := checkAcyclic(reflect.ValueOf(src).Elem(), make(map[visitKey]bool)); err != nil {
+			return nil, err
+		}
+	}
+	conv, err := valConvWithOptions(t, t, deepCopyOptions)
+	if err != nil {
+		return nil, err
+	}
+	var dst T
+	if err := conv(unsafe.Pointer(&dst), unsafe.Pointer(src)); err != nil {
+		return nil, err
+	}
+	return &dst, nil
+}
+
+// deepConv returns a converter creating deep copies of values of the type.
+func deepConv(t reflect.Type, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	cp := typedCopier(t)
+	switch t.Kind() {
+	case reflect.Pointer:
+		elType := t.Elem()
+		elConv := lazyConv(elType, opts)
+		return func(dst, src unsafe.Pointer) error {
+			p := *(*unsafe.Pointer)(src)
+			if p == nil {
+				*(*unsafe.Pointer)(dst) = nil
+				return nil
+			}
+			newPtr := reflect.New(elType).UnsafePointer()
+			if err := elConv(newPtr, p); err != nil {
+				return err
+			}
+			*(*unsafe.Pointer)(dst) = newPtr
+			return nil
+		}, nil
+
+	case reflect.Slice:
+		elType := t.Elem()
+		elSize := elType.Size()
+		elConv := lazyConv(elType, opts)
+		return func(dst, src unsafe.Pointer) error {
+			srcSlice := reflect.NewAt(t, src).Elem()
+			if srcSlice.IsNil() {
+				reflect.NewAt(t, dst).Elem().SetZero()
+				return nil
+			}
+			len := srcSlice.Len()
+			dstSlice := reflect.MakeSlice(t, len, len)
+			srcPtr := srcSlice.UnsafePointer()
+			dstPtr := dstSlice.UnsafePointer()
+			for i := uintptr(0); i < uintptr(len); i++ {
+				if err := elConv(unsafe.Add(dstPtr, i*elSize), unsafe.Add(srcPtr, i*elSize)); err != nil {
+					return err
+				}
+			}
+			reflect.NewAt(t, dst).Elem().Set(dstSlice)
+			return nil
+		}, nil
+
+	case reflect.Array:
+		elType := t.Elem()
+		elSize := elType.Size()
+		elConv := lazyConv(elType, opts)
+		return func(dst, src unsafe.Pointer) error {
+			for i := uintptr(0); i < uintptr(t.Len()); i++ {
+				if err := elConv(unsafe.Add(dst, i*elSize), unsafe.Add(src, i*elSize)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}, nil
+
+	case reflect.Map:
+		elType := t.Elem()
+		elConv := lazyConv(elType, opts)
+		return func(dst, src unsafe.Pointer) error {
+			srcMap := reflect.NewAt(t, src).Elem()
+			if srcMap.IsNil() {
+				reflect.NewAt(t, dst).Elem().SetZero()
+				return nil
+			}
+			dstMap := reflect.MakeMapWithSize(t, srcMap.Len())
+			srcEl := reflect.New(elType).Elem()
+			iter := srcMap.MapRange()
+			for iter.Next() {
+				srcEl.Set(iter.Value())
+				dstEl := reflect.New(elType)
+				if err := elConv(dstEl.UnsafePointer(), srcEl.Addr().UnsafePointer()); err != nil {
+					return err
+				}
+				dstMap.SetMapIndex(iter.Key(), dstEl.Elem())
+			}
+			reflect.NewAt(t, dst).Elem().Set(dstMap)
+			return nil
+		}, nil
+
+	case reflect.Struct:
+		copier, err := nestedCopier(t, t, opts)
+		if err != nil {
+			return nil, err
+		}
+		return func(dst, src unsafe.Pointer) error {
+			cp(dst, src)
+			return copier(dst, src)
+		}, nil
+	}
+	return func(dst, src unsafe.Pointer) error {
+		cp(dst, src)
+		return nil
+	}, nil
+}
+
+// lazyConv returns a converter for values of the type which is created on first use
+// so that recursive types don't make the creation of converters loop.
+func lazyConv(t reflect.Type, opts *CopierOptions) func(unsafe.Pointer, unsafe.Pointer) error {
+	var (
+		once sync.Once
+		conv func(unsafe.Pointer, unsafe.Pointer) error
+		err  error
+	)
+	return func(dst, src unsafe.Pointer) error {
+		once.Do(func() {
+			conv, err = valConvWithOptions(t, t, opts)
+		})
+		if err != nil {
+			return err
+		}
+		return conv(dst, src)
+	}
+}
+
+// visitKey identifies a value referenced by a pointer, slice or map.
+type visitKey struct {
+	ptr unsafe.Pointer
+	typ reflect.Type
+}
+
+// checkAcyclic checks that the value doesn't reference itself through the values copied recursively by [Clone].
+// The map holds the values on the path to the checked value.
+func checkAcyclic(v reflect.Value, path map[visitKey]bool) error {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map:
+		if v.IsNil() || !hasPointers(v.Type().Elem()) {
+			return nil
+		}
+		key := visitKey{ptr: v.UnsafePointer(), typ: v.Type()}
+		if path[key] {
+			return serr.Wrap("", ErrCyclicValue, serr.String("type", v.Type().String()))
+		}
+		path[key] = true
+		defer delete(path, key)
+		switch v.Kind() {
+		case reflect.Pointer:
+			return checkAcyclic(v.Elem(), path)
+		case reflect.Slice:
+			for i := 0; i < v.Len(); i++ {
+				if err := checkAcyclic(v.Index(i), path); err != nil {
+					return err
+				}
+			}
+		case reflect.Map:
+			iter := v.MapRange()
+			for iter.Next() {
+				if err := checkAcyclic(iter.Value(), path); err != nil {
+					return err
+				}
+			}
+		}
+
+	case reflect.Array:
+		if !hasPointers(v.Type().Elem()) {
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := checkAcyclic(v.Index(i), path); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			sf := v.Type().Field(i)
+			if !sf.IsExported() || sf.Tag.Get("kv") == "-" || !hasPointers(sf.Type) {
+				continue
+			}
+			if err := checkAcyclic(v.Field(i), path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
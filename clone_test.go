@@ -0,0 +1,119 @@
+package keyvalue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mailstepcz/pointer"
+	"github.com/stretchr/testify/require"
+)
+
+type cloneNode struct {
+	Name     string
+	Value    *int
+	Tags     []string
+	Attrs    map[string][]int
+	Children []*cloneNode
+	Parent   *cloneNode `kv:"-"`
+	Created  time.Time
+	Grid     [2][]int
+	secret   *string
+}
+
+func TestClone(t *testing.T) {
+	req := require.New(t)
+
+	child := &cloneNode{Name: "child", Value: pointer.To(2)}
+	src := &cloneNode{
+		Name:     "root",
+		Value:    pointer.To(1),
+		Tags:     []string{"a", "b"},
+		Attrs:    map[string][]int{"x": {1, 2}},
+		Children: []*cloneNode{child},
+		Created:  time.Now(),
+		Grid:     [2][]int{{1}, {2}},
+		secret:   pointer.To("secret"),
+	}
+	child.Parent = src
+
+	dst, err := Clone(src)
+	req.NoError(err)
+	req.Equal(src.Name, dst.Name)
+	req.Equal(*src.Value, *dst.Value)
+	req.NotSame(src.Value, dst.Value)
+	req.Equal(src.Tags, dst.Tags)
+	req.Equal(src.Attrs, dst.Attrs)
+	req.Equal(src.Created, dst.Created)
+	req.Equal(src.Grid, dst.Grid)
+	req.Same(src.secret, dst.secret)
+	req.Len(dst.Children, 1)
+	req.NotSame(child, dst.Children[0])
+	req.Equal("child", dst.Children[0].Name)
+
+	dst.Tags[0] = "z"
+	dst.Attrs["x"][0] = 9
+	*dst.Children[0].Value = 3
+	dst.Grid[0][0] = 5
+	req.Equal("a", src.Tags[0])
+	req.Equal(1, src.Attrs["x"][0])
+	req.Equal(2, *child.Value)
+	req.Equal(1, src.Grid[0][0])
+
+	var nilNode *cloneNode
+	dst, err = Clone(nilNode)
+	req.NoError(err)
+	req.Nil(dst)
+
+	m, err := Clone(&map[string]*int{"a": pointer.To(1)})
+	req.NoError(err)
+	req.Equal(1, *(*m)["a"])
+}
+
+func TestCloneRenamedFields(t *testing.T) {
+	type customer struct {
+		CustomerID string `copy:"ClientID"`
+		ClientID   string
+	}
+	type order struct {
+		Customer  customer
+		Ptr       *customer
+		Customers []customer
+	}
+
+	req := require.New(t)
+
+	c := customer{CustomerID: "a", ClientID: "b"}
+	dst, err := Clone(&c)
+	req.NoError(err)
+	req.Equal(c, *dst)
+
+	o := &order{Customer: c, Ptr: &c, Customers: []customer{c}}
+	cloned, err := Clone(o)
+	req.NoError(err)
+	req.Equal(o, cloned)
+	req.NotSame(o.Ptr, cloned.Ptr)
+
+	diffs, err := Diff(o, o)
+	req.NoError(err)
+	req.Empty(diffs)
+}
+
+func TestCloneCyclic(t *testing.T) {
+	req := require.New(t)
+
+	node := &cloneNode{Name: "node"}
+	node.Children = []*cloneNode{node}
+	_, err := Clone(node)
+	req.ErrorIs(err, ErrCyclicValue)
+
+	shared := &cloneNode{Name: "shared"}
+	dst, err := Clone(&cloneNode{Children: []*cloneNode{shared, shared}})
+	req.NoError(err)
+	req.Len(dst.Children, 2)
+	req.Equal("shared", dst.Children[1].Name)
+
+	m := map[string]any{}
+	m["self"] = m
+	_, err = Clone(&m)
+	req.NoError(err)
+}
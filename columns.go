@@ -0,0 +1,133 @@
+package keyvalue
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"unsafe"
+
+	"github.com/mailstepcz/serr"
+)
+
+var (
+	// ErrBadColumnBuilder signifies that a column builder lacks the methods Append and AppendNull.
+	ErrBadColumnBuilder = errors.New("bad column builder")
+)
+
+// ParquetColumns is a naming strategy matching fields with columns by their parquet names.
+// The column name is taken from the `parquet:"name,..."` tag and defaults to the field name.
+// Fields tagged with `parquet:"-"` aren't copied.
+func ParquetColumns(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("parquet"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return f.Name
+	default:
+		return name
+	}
+}
+
+// ColumnWriter appends structures to column builders, such as the field builders of Arrow's record builders,
+// so that slices of structures can be exported column by column without code for each column.
+// Structures can be copied into parquet row structures with [SliceCopierForPair] instead.
+type ColumnWriter[S any] struct {
+	columns []columnAppender
+}
+
+// columnAppender appends the values of a structure field to a column builder.
+type columnAppender struct {
+	field      reflect.StructField
+	valueType  reflect.Type
+	conv       func(unsafe.Pointer, unsafe.Pointer) error
+	appendFn   reflect.Value
+	appendNull reflect.Value
+}
+
+// NewColumnWriter creates a writer appending structures to the builders of the named columns, e.g. those of an Arrow schema.
+// A builder has to have the methods Append(T) and AppendNull(). Structure fields are matched with the columns by the names
+// given by the naming strategy, or by their names if it's nil, and converted into the types of the builders like by [TypedCopy].
+// Nil pointers, invalid nullable values and empty optional values are appended as nulls.
+func NewColumnWriter[S any](names []string, builders []interface{}, naming NamingStrategy) (*ColumnWriter[S], error) {
+	if len(names) != len(builders) {
+		return nil, serr.Wrap("", ErrBadColumnBuilder, serr.Int("names", len(names)), serr.Int("builders", len(builders)))
+	}
+	if naming == nil {
+		naming = func(f reflect.StructField) string {
+			return f.Name
+		}
+	}
+	t := reflect.TypeFor[S]()
+	if t.Kind() != reflect.Struct {
+		return nil, serr.Wrap("", ErrTypeNotStruct, serr.String("srcType", t.String()))
+	}
+	fields := fieldsByName(t, naming)
+	columns := make([]columnAppender, len(names))
+	for i, name := range names {
+		f, ok := fields[name]
+		if ok {
+			_, ok = fieldOffset(t, f.Index)
+		}
+		if !ok {
+			return nil, serr.Wrap("", ErrFieldNotFound, serr.String("column", name), serr.String("srcType", t.String()))
+		}
+		b := reflect.ValueOf(builders[i])
+		appendFn, appendNull := b.MethodByName("Append"), b.MethodByName("AppendNull")
+		if !appendFn.IsValid() || !appendNull.IsValid() || appendFn.Type().NumIn() != 1 || appendNull.Type().NumIn() != 0 {
+			return nil, serr.Wrap("", ErrBadColumnBuilder, serr.String("column", name), serr.String("builderType", b.Type().String()))
+		}
+		valueType := appendFn.Type().In(0)
+		conv, err := valConv(reflect.PointerTo(valueType), f.Type)
+		if err != nil {
+			return nil, compileFieldError(err, f.Name, valueType, f.Type)
+		}
+		columns[i] = columnAppender{
+			field:      f,
+			valueType:  valueType,
+			conv:       conv,
+			appendFn:   appendFn,
+			appendNull: appendNull,
+		}
+	}
+	return &ColumnWriter[S]{
+		columns: columns,
+	}, nil
+}
+
+// Append appends the structure to the column builders. Nothing is appended if any of the fields can't be converted
+// so that the columns keep the same length.
+func (w *ColumnWriter[S]) Append(src *S) error {
+	values := make([]reflect.Value, len(w.columns))
+	for i, c := range w.columns {
+		p := reflect.New(reflect.PointerTo(c.valueType))
+		srcField := reflect.ValueOf(src).Elem().FieldByIndex(c.field.Index)
+		if err := c.conv(p.UnsafePointer(), srcField.Addr().UnsafePointer()); err != nil {
+			return fieldError(err, c.field.Name, c.valueType, c.field.Type)
+		}
+		values[i] = p.Elem()
+	}
+	for i, c := range w.columns {
+		if values[i].IsNil() {
+			c.appendNull.Call(nil)
+			continue
+		}
+		c.appendFn.Call([]reflect.Value{values[i].Elem()})
+	}
+	return nil
+}
+
+// AppendSlice appends the structures to the column builders. Nil structures are skipped.
+// Appending stops at the first structure which can't be converted.
+func (w *ColumnWriter[S]) AppendSlice(src []*S) error {
+	t := reflect.TypeFor[S]()
+	for i, x := range src {
+		if x == nil {
+			continue
+		}
+		if err := w.Append(x); err != nil {
+			return elementError(err, i, nil, t)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package keyvalue
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mailstepcz/maybe"
+	"github.com/stretchr/testify/require"
+)
+
+type columnBuilder[T any] struct {
+	values []T
+	valid  []bool
+}
+
+func (b *columnBuilder[T]) Append(v T) {
+	b.values = append(b.values, v)
+	b.valid = append(b.valid, true)
+}
+
+func (b *columnBuilder[T]) AppendNull() {
+	var zero T
+	b.values = append(b.values, zero)
+	b.valid = append(b.valid, false)
+}
+
+type exportedOrder struct {
+	ID       uuid.UUID           `parquet:"id"`
+	Quantity int32               `parquet:"quantity,optional"`
+	Note     *string             `parquet:"note"`
+	Label    maybe.Maybe[string] `parquet:"label"`
+	Secret   string              `parquet:"-"`
+}
+
+func TestColumnWriter(t *testing.T) {
+	req := require.New(t)
+
+	var (
+		ids        columnBuilder[string]
+		quantities columnBuilder[int64]
+		notes      columnBuilder[string]
+		labels     columnBuilder[string]
+	)
+	w, err := NewColumnWriter[exportedOrder](
+		[]string{"id", "quantity", "note", "label"},
+		[]interface{}{&ids, &quantities, &notes, &labels},
+		ParquetColumns,
+	)
+	req.NoError(err)
+
+	id1, id2 := uuid.New(), uuid.New()
+	note := "fragile"
+	req.NoError(w.AppendSlice([]*exportedOrder{
+		{ID: id1, Quantity: 3, Note: &note, Label: maybe.Unit("red")},
+		nil,
+		{ID: id2, Quantity: 5},
+	}))
+	req.Equal([]string{id1.String(), id2.String()}, ids.values)
+	req.Equal([]int64{3, 5}, quantities.values)
+	req.Equal([]bool{true, false}, notes.valid)
+	req.Equal("fragile", notes.values[0])
+	req.Equal([]bool{true, false}, labels.valid)
+	req.Equal("red", labels.values[0])
+}
+
+func TestColumnWriterErrors(t *testing.T) {
+	req := require.New(t)
+
+	var ids columnBuilder[string]
+	_, err := NewColumnWriter[exportedOrder]([]string{"secret"}, []interface{}{&ids}, ParquetColumns)
+	req.ErrorIs(err, ErrFieldNotFound)
+
+	_, err = NewColumnWriter[exportedOrder]([]string{"id"}, []interface{}{ids}, ParquetColumns)
+	req.ErrorIs(err, ErrBadColumnBuilder)
+
+	_, err = NewColumnWriter[exportedOrder]([]string{"id", "note"}, []interface{}{&ids}, ParquetColumns)
+	req.ErrorIs(err, ErrBadColumnBuilder)
+
+	w, err := NewColumnWriter[exportedOrder]([]string{"ID"}, []interface{}{&ids}, nil)
+	req.NoError(err)
+	req.NoError(w.Append(&exportedOrder{}))
+	req.Equal([]string{uuid.Nil.String()}, ids.values)
+}
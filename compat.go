@@ -0,0 +1,63 @@
+package keyvalue
+
+import (
+	"reflect"
+	"slices"
+)
+
+// FieldCompatibility describes whether a source field can be copied to the destination type.
+type FieldCompatibility struct {
+	SrcField string
+	// Omitted is set for fields excluded from copying by a tag or by the options.
+	Omitted bool
+	// Err is the error the copier creation fails with because of the field.
+	Err error
+}
+
+// Compatible checks whether a copier can be created for the pair of types with the options without copying any values.
+func Compatible[D, S any](opts *CopierOptions) error {
+	_, err := compileCopier(reflect.TypeFor[D](), reflect.TypeFor[S](), opts, true, maskOf(opts))
+	return err
+}
+
+// FieldCompatibilities checks each exported source field separately and reports which of them can be copied
+// to the destination type with the options. The copiers are not cached. Field masks, validation and instrumentation are ignored.
+func FieldCompatibilities[D, S any](opts *CopierOptions) ([]FieldCompatibility, error) {
+	dstType, srcType := reflect.TypeFor[D](), reflect.TypeFor[S]()
+	if dstType.Kind() != reflect.Struct || srcType.Kind() != reflect.Struct {
+		return nil, ErrTypeNotStruct
+	}
+	var fieldOpts CopierOptions
+	if opts != nil {
+		fieldOpts = *opts
+	}
+	fieldOpts.FieldMask = nil
+	fieldOpts.Validate = nil
+	fieldOpts.Instrument = nil
+	fieldOpts.NoCache = true
+	var result []FieldCompatibility
+	for _, srcField := range reflect.VisibleFields(srcType) {
+		if srcField.PkgPath != "" || srcField.Anonymous {
+			continue
+		}
+		c := FieldCompatibility{SrcField: srcField.Name}
+		if srcField.Tag.Get("kv") == "-" || slices.Contains(fieldOpts.FieldsToOmit, srcField.Name) ||
+			fieldOpts.FieldsToCopy != nil && !slices.Contains(fieldOpts.FieldsToCopy, srcField.Name) {
+			c.Omitted = true
+		} else {
+			o := fieldOpts
+			o.FieldsToCopy = []string{srcField.Name}
+			_, c.Err = compileCopier(dstType, srcType, &o, true, nil)
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+// maskOf returns the parsed field mask of the options.
+func maskOf(opts *CopierOptions) maskTree {
+	if opts == nil {
+		return nil
+	}
+	return parseFieldMask(opts.FieldMask)
+}
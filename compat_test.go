@@ -0,0 +1,40 @@
+package keyvalue
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type compatDst struct {
+	ID    string
+	Name  string
+	Count int64
+}
+
+type compatSrc struct {
+	ID       uuid.UUID
+	Name     string
+	Count    chan int
+	Secret   string `kv:"-"`
+	Password string
+}
+
+func TestCompatible(t *testing.T) {
+	req := require.New(t)
+
+	req.NoError(Compatible[compatDst, compatSrc](&CopierOptions{FieldsToOmit: []string{"Count", "Password"}}))
+	req.ErrorIs(Compatible[compatDst, compatSrc](&CopierOptions{FieldsToOmit: []string{"Count"}}), ErrFieldNotFound)
+	req.ErrorIs(Compatible[compatDst, int](nil), ErrTypeNotStruct)
+
+	fields, err := FieldCompatibilities[compatDst, compatSrc](&CopierOptions{FieldsToOmit: []string{"Name"}})
+	req.NoError(err)
+	req.Len(fields, 5)
+	req.Equal(FieldCompatibility{SrcField: "ID"}, fields[0])
+	req.Equal(FieldCompatibility{SrcField: "Name", Omitted: true}, fields[1])
+	req.Equal("Count", fields[2].SrcField)
+	req.Error(fields[2].Err)
+	req.Equal(FieldCompatibility{SrcField: "Secret", Omitted: true}, fields[3])
+	req.ErrorIs(fields[4].Err, ErrFieldNotFound)
+}
@@ -0,0 +1,76 @@
+package keyvalue
+
+import (
+	"reflect"
+	"unsafe"
+
+	"github.com/mailstepcz/serr"
+)
+
+// destinationField returns the exported destination field and its offset. Fields promoted through embedded pointers aren't supported.
+func destinationField(dstType reflect.Type, name string) (reflect.StructField, uintptr, error) {
+	f, ok := dstType.FieldByName(name)
+	if !ok || f.PkgPath != "" {
+		return f, 0, serr.Wrap("", ErrFieldNotFound, serr.String("dstField", name), serr.String("dstType", dstType.Name()))
+	}
+	offset, ok := fieldOffset(dstType, f.Index)
+	if !ok {
+		return f, 0, serr.Wrap("", ErrFieldNotFound, serr.String("dstField", name), serr.String("dstType", dstType.Name()))
+	}
+	return f, offset, nil
+}
+
+// assignValue converts a dynamically typed value to the destination type like the copier converts field values.
+func assignValue(dstType reflect.Type, dst unsafe.Pointer, x interface{}, opts *CopierOptions) error {
+	v := reflect.New(reflect.TypeOf(x))
+	v.Elem().Set(reflect.ValueOf(x))
+	conv, err := valConvWithOptions(dstType, v.Type().Elem(), opts)
+	if err != nil {
+		return err
+	}
+	return conv(dst, v.UnsafePointer())
+}
+
+// computedConv returns a converter computing the value of a destination field from the whole source structure.
+// Nil values leave the destination field untouched.
+func computedConv(dstType, srcType reflect.Type, f func(interface{}) (interface{}, error), opts *CopierOptions) func(unsafe.Pointer, unsafe.Pointer) error {
+	return func(dst, src unsafe.Pointer) error {
+		x, err := f(reflect.NewAt(srcType, src).Interface())
+		if err != nil {
+			return err
+		}
+		if x == nil {
+			return nil
+		}
+		return assignValue(dstType, dst, x, opts)
+	}
+}
+
+// constantConv returns a converter writing the constant into a destination field.
+// The constant is converted to the field type once when the converter is created and nil is written as the zero value.
+// Constants referencing pointers, slices or maps are copied deeply like by [Clone] so that copies don't share them.
+func constantConv(dstType reflect.Type, x interface{}, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	v := reflect.New(dstType)
+	if x != nil {
+		if err := assignValue(dstType, v.UnsafePointer(), x, opts); err != nil {
+			return nil, err
+		}
+	}
+	if hasPointers(dstType) && dstType.Kind() != reflect.String {
+		if err := checkAcyclic(v.Elem(), make(map[visitKey]bool)); err != nil {
+			return nil, err
+		}
+		conv, err := valConvWithOptions(dstType, dstType, deepCopyOptions)
+		if err != nil {
+			return nil, err
+		}
+		return func(dst, _ unsafe.Pointer) error {
+			return conv(dst, v.UnsafePointer())
+		}, nil
+	}
+	cp := typedCopier(dstType)
+	return func(dst, _ unsafe.Pointer) error {
+		cp(dst, v.UnsafePointer())
+		return nil
+	}, nil
+}
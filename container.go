@@ -0,0 +1,84 @@
+package keyvalue
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"github.com/mailstepcz/serr"
+)
+
+var (
+	containerRecipes   = make(map[string]ContainerRecipe)
+	containerRecipesMu sync.RWMutex
+)
+
+// ElemConv converts a value held by the source container into the corresponding value of the destination container
+// like the copier converts field values, typically a value of a type argument. The values may be unexported fields
+// but the destination has to be addressable.
+type ElemConv func(dst, src reflect.Value) error
+
+// ContainerRecipe copies a generic container into another instantiation of the same generic type.
+// It receives an addressable destination container, the source container and the function converting their contents.
+type ContainerRecipe func(dst, src reflect.Value, conv ElemConv) error
+
+// RegisterContainer registers the recipe copying between distinct instantiations of the generic type of C.
+// C may be any instantiation of the type, e.g. Page[any] for a generic Page[T], and the recipe is used for all of them.
+func RegisterContainer[C any](recipe ContainerRecipe) {
+	name, ok := genericOrigin(reflect.TypeFor[C]())
+	if !ok {
+		panic("keyvalue: " + reflect.TypeFor[C]().String() + " isn't an instantiation of a generic type")
+	}
+	containerRecipesMu.Lock()
+	defer containerRecipesMu.Unlock()
+	containerRecipes[name] = recipe
+}
+
+// genericOrigin returns the qualified name of the generic type the type is an instantiation of.
+func genericOrigin(t reflect.Type) (string, bool) {
+	name, _, ok := strings.Cut(t.Name(), "[")
+	if !ok {
+		return "", false
+	}
+	return t.PkgPath() + "." + name, true
+}
+
+// containerRecipe returns the recipe copying between the instantiations of a registered generic container.
+func containerRecipe(dstType, srcType reflect.Type) (ContainerRecipe, bool) {
+	dstOrigin, ok := genericOrigin(dstType)
+	if !ok {
+		return nil, false
+	}
+	if srcOrigin, ok := genericOrigin(srcType); !ok || srcOrigin != dstOrigin {
+		return nil, false
+	}
+	containerRecipesMu.RLock()
+	defer containerRecipesMu.RUnlock()
+	recipe, ok := containerRecipes[dstOrigin]
+	return recipe, ok
+}
+
+// hasContainerRecipe checks whether the types are instantiations of a registered generic container.
+func hasContainerRecipe(dstType, srcType reflect.Type) bool {
+	_, ok := containerRecipe(dstType, srcType)
+	return ok
+}
+
+// containerConv returns a converter between instantiations of a generic container copied by the recipe.
+// The contents of the containers are converted with converters compiled once they're first used.
+func containerConv(dstType, srcType reflect.Type, recipe ContainerRecipe, opts *CopierOptions) func(unsafe.Pointer, unsafe.Pointer) error {
+	elem := func(dst, src reflect.Value) error {
+		if !dst.CanAddr() || !src.CanAddr() {
+			return serr.Wrap("", ErrUnsupportedTypePair, serr.String("dstType", dst.Type().String()), serr.String("srcType", src.Type().String()))
+		}
+		conv, err := valConvWithOptions(dst.Type(), src.Type(), opts)
+		if err != nil {
+			return err
+		}
+		return conv(dst.Addr().UnsafePointer(), src.Addr().UnsafePointer())
+	}
+	return func(dst, src unsafe.Pointer) error {
+		return recipe(reflect.NewAt(dstType, dst).Elem(), reflect.NewAt(srcType, src).Elem(), elem)
+	}
+}
@@ -0,0 +1,78 @@
+package keyvalue
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type result[T any] struct {
+	value T
+	err   error
+}
+
+type page[T any] struct {
+	Items  []T
+	cursor string
+}
+
+type pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+type containerSrc struct {
+	Result result[string]
+	Page   *page[string]
+	Pair   pair[string, int]
+}
+
+type containerDst struct {
+	Result result[uuid.UUID]
+	Page   *page[uuid.UUID]
+	Pair   pair[uuid.UUID, int64]
+}
+
+func TestContainerRecipes(t *testing.T) {
+	req := require.New(t)
+
+	RegisterContainer[result[any]](func(dst, src reflect.Value, conv ElemConv) error {
+		if err := conv(dst.Field(0), src.Field(0)); err != nil {
+			return err
+		}
+		return conv(dst.Field(1), src.Field(1))
+	})
+	RegisterContainer[page[any]](func(dst, src reflect.Value, conv ElemConv) error {
+		if err := conv(dst.FieldByName("Items"), src.FieldByName("Items")); err != nil {
+			return err
+		}
+		return conv(dst.FieldByName("cursor"), src.FieldByName("cursor"))
+	})
+	RegisterContainer[pair[any, any]](func(dst, src reflect.Value, conv ElemConv) error {
+		if err := conv(dst.Field(0), src.Field(0)); err != nil {
+			return err
+		}
+		return conv(dst.Field(1), src.Field(1))
+	})
+
+	u := uuid.New()
+	errFailed := errors.New("failed")
+	var dst containerDst
+	req.NoError(TypedCopy(&dst, &containerSrc{
+		Result: result[string]{value: u.String(), err: errFailed},
+		Page:   &page[string]{Items: []string{u.String()}, cursor: "next"},
+		Pair:   pair[string, int]{First: u.String(), Second: 2},
+	}))
+	req.Equal(result[uuid.UUID]{value: u, err: errFailed}, dst.Result)
+	req.Equal(&page[uuid.UUID]{Items: []uuid.UUID{u}, cursor: "next"}, dst.Page)
+	req.Equal(pair[uuid.UUID, int64]{First: u, Second: 2}, dst.Pair)
+
+	req.Error(TypedCopy(&dst, &containerSrc{Result: result[string]{value: "bad"}}))
+
+	req.Panics(func() {
+		RegisterContainer[containerSrc](nil)
+	})
+}
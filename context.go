@@ -0,0 +1,223 @@
+package keyvalue
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"unsafe"
+
+	"github.com/mailstepcz/serr"
+	"github.com/mailstepcz/types"
+)
+
+// contextValConv returns a context-aware converter for a pair of types whose values hold nested structures
+// with context-aware converters, either directly or through pointers and slices, so that the context passed on
+// to the copier reaches them. It returns nil for the pairs which are converted without a context.
+func contextValConv(dstType, srcType reflect.Type, opts *CopierOptions) (func(context.Context, unsafe.Pointer, unsafe.Pointer) error, error) {
+	if !usesContext(dstType, srcType, opts, make(map[[2]reflect.Type]bool)) {
+		return nil, nil
+	}
+	onNil := nilHandler(dstType, opts)
+	switch {
+	case srcType.Kind() == reflect.Pointer && dstType.Kind() == reflect.Pointer:
+		dstElType := dstType.Elem()
+		elConv, err := contextValConv(dstElType, srcType.Elem(), opts)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, dst, src unsafe.Pointer) error {
+			if p := *(*unsafe.Pointer)(src); p != nil {
+				newPtr := reflect.New(dstElType).UnsafePointer()
+				err := elConv(ctx, newPtr, p)
+				if _, skipped := err.(*SkippedElementsError); err != nil && !skipped {
+					return err
+				}
+				*(*unsafe.Pointer)(dst) = newPtr
+				return err
+			}
+			return onNil(dst)
+		}, nil
+
+	case srcType.Kind() == reflect.Slice && dstType.Kind() == reflect.Slice:
+		return contextSliceConv(dstType, srcType, opts, false)
+
+	case dstType.Kind() == reflect.Pointer:
+		conv, err := contextValConv(dstType.Elem(), srcType, opts)
+		if err != nil {
+			return nil, err
+		}
+		skipZero := opts != nil && opts.NilPolicy == NilSkipZero
+		return func(ctx context.Context, dst, src unsafe.Pointer) error {
+			v := reflect.New(dstType.Elem())
+			err := conv(ctx, v.UnsafePointer(), src)
+			if _, skipped := err.(*SkippedElementsError); err != nil && !skipped {
+				if skipZero && reflect.NewAt(srcType, src).Elem().IsZero() {
+					return nil
+				}
+				return err
+			}
+			*(*unsafe.Pointer)(dst) = v.UnsafePointer()
+			return err
+		}, nil
+
+	case srcType.Kind() == reflect.Pointer:
+		conv, err := contextValConv(dstType, srcType.Elem(), opts)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, dst, src unsafe.Pointer) error {
+			if x := *(*unsafe.Pointer)(src); x != nil {
+				return conv(ctx, dst, x)
+			}
+			return onNil(dst)
+		}, nil
+	}
+	return nestedContextCopier(dstType, srcType, opts)
+}
+
+// contextSliceConv returns a context-aware converter of slices like [contextValConv] does.
+// Elements which can't be converted are skipped like by [skippingSliceConv] if skip is set.
+func contextSliceConv(dstType, srcType reflect.Type, opts *CopierOptions, skip bool) (func(context.Context, unsafe.Pointer, unsafe.Pointer) error, error) {
+	if dstType.Kind() != reflect.Slice || srcType.Kind() != reflect.Slice {
+		return nil, serr.Wrap("", ErrUnsupportedTypePair, serr.String("srcType", srcType.String()), serr.String("dstType", dstType.String()))
+	}
+	dstElType, srcElType := dstType.Elem(), srcType.Elem()
+	elConv, err := contextValConv(dstElType, srcElType, opts)
+	if err != nil {
+		return nil, err
+	}
+	onNil := nilHandler(dstType, opts)
+	reuse := opts != nil && opts.ReuseSlices && !skip
+	dstElSize := dstElType.Size()
+	srcElSize := srcElType.Size()
+	return func(ctx context.Context, dst, src unsafe.Pointer) error {
+		srcSlice := reflect.NewAt(srcType, src).Elem()
+		if srcSlice.IsNil() {
+			return onNil(dst)
+		}
+		n := srcSlice.Len()
+		dstSlice := destSlice(dstType, dst, n, reuse)
+		srcPtr := srcSlice.UnsafePointer()
+		dstPtr := dstSlice.UnsafePointer()
+		var skipped *SkippedElementsError
+		kept := 0
+		for i := 0; i < n; i++ {
+			el := unsafe.Add(dstPtr, uintptr(kept)*dstElSize)
+			err := elConv(ctx, el, unsafe.Add(srcPtr, uintptr(i)*srcElSize))
+			if skip {
+				if skipElement(&skipped, err, i, dstElType, srcElType) {
+					dstSlice.Index(kept).SetZero()
+					continue
+				}
+			} else if err != nil {
+				if se, ok := err.(*SkippedElementsError); ok {
+					// the element is kept and its skipped elements are reported
+					skipped = addSkipped(skipped, se, strconv.Itoa(i))
+				} else {
+					return elementError(err, i, dstElType, srcElType)
+				}
+			}
+			kept++
+		}
+		reflect.NewAt(dstType, dst).Elem().Set(dstSlice.Slice(0, kept))
+		if skipped != nil {
+			return skipped
+		}
+		return nil
+	}, nil
+}
+
+// usesContext reports whether values of the pair of types hold nested structures converted by [contextValConv],
+// i.e. pairs of structures declared by a manifest or with fields selecting context-aware named converters.
+// Pairs which are converted otherwise, e.g. by type converters or containers, don't use the context.
+func usesContext(dstType, srcType reflect.Type, opts *CopierOptions, seen map[[2]reflect.Type]bool) bool {
+	pair := [2]reflect.Type{dstType, srcType}
+	if dstType == srcType || seen[pair] || !convertedStructurally(dstType, srcType, opts) {
+		return false
+	}
+	seen[pair] = true
+	switch {
+	case srcType.Kind() == reflect.Pointer && dstType.Kind() == reflect.Pointer:
+		return usesContext(dstType.Elem(), srcType.Elem(), opts, seen)
+	case srcType.Kind() == reflect.Slice && dstType.Kind() == reflect.Slice:
+		return usesContext(dstType.Elem(), srcType.Elem(), opts, seen)
+	case dstType.Kind() == reflect.Pointer && srcType.Kind() == reflect.Struct:
+		return usesContext(dstType.Elem(), srcType, opts, seen)
+	case srcType.Kind() == reflect.Pointer && dstType.Kind() == reflect.Struct:
+		return usesContext(dstType, srcType.Elem(), opts, seen)
+	case dstType.Kind() != reflect.Struct || srcType.Kind() != reflect.Struct:
+		return false
+	}
+	if opts != nil {
+		if _, ok := opts.pairOptions[pair]; ok {
+			return true
+		}
+	}
+	dstRenamed := describeType(dstType).renamed
+	for _, sf := range describeType(srcType).fields {
+		srcField := sf.StructField
+		if srcField.PkgPath != "" || sf.skipped {
+			continue
+		}
+		dstField, ok := dstRenamed[srcField.Name]
+		if !ok {
+			dstField, ok = dstType.FieldByName(srcField.Name)
+		}
+		if !ok || dstField.PkgPath != "" {
+			continue
+		}
+		if isContextConverter(tagConverterName(dstField)) || isContextConverter(tagConverterName(srcField)) ||
+			usesContext(dstField.Type, srcField.Type, opts, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// convertedStructurally checks whether values of the pair of types are converted by following their structure,
+// i.e. pointers, slices and structures copied field by field, rather than by a conversion specific to the types
+// such as type converters, containers or maybe values.
+func convertedStructurally(dstType, srcType reflect.Type, opts *CopierOptions) bool {
+	if opts != nil {
+		if _, ok := opts.TypeConverters[TypePair{srcType, dstType}]; ok {
+			return false
+		}
+	}
+	if _, ok := providedConv(dstType, srcType); ok {
+		return false
+	}
+	if hasContainerRecipe(dstType, srcType) || srcType.Implements(types.Copiable) {
+		return false
+	}
+	if dstType.Kind() != reflect.Struct || srcType.Kind() != reflect.Struct {
+		return true
+	}
+	return !reflect.PointerTo(dstType).Implements(types.Maybe) && !reflect.PointerTo(srcType).Implements(types.Maybe) &&
+		!reflect.PointerTo(srcType).Implements(types.Required) && !isRangeStruct(dstType) && !isRangeStruct(srcType)
+}
+
+// isContextConverter checks whether a context-aware converter is registered under the name.
+func isContextConverter(name string) bool {
+	if name == "" {
+		return false
+	}
+	namedConvertersMu.RLock()
+	defer namedConvertersMu.RUnlock()
+	for k, c := range namedConverters {
+		if k.name == name && c.ContextFunc != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// nestedContextCopier returns a context-aware copier for a pair of structs nested in a copied value like [nestedCopier].
+func nestedContextCopier(dstType, srcType reflect.Type, opts *CopierOptions) (func(context.Context, unsafe.Pointer, unsafe.Pointer) error, error) {
+	if opts == nil {
+		return ContextCopierForPairWithOptions(dstType, srcType, nil)
+	}
+	if po, ok := opts.pairOptions[[2]reflect.Type{dstType, srcType}]; ok {
+		return compileContextCopier(dstType, srcType, po, true, nil)
+	}
+	return compileContextCopier(dstType, srcType, opts, false, nil)
+}
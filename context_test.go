@@ -0,0 +1,188 @@
+package keyvalue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type ctxTenantKey struct{}
+
+type ctxAmount struct {
+	Cents int64
+}
+
+type ctxAmountDTO struct {
+	Cents string `copy:",conv=tenantCents"`
+}
+
+type ctxOrder struct {
+	Total ctxAmount
+	Fee   int64
+}
+
+type ctxOrderDTO struct {
+	Total ctxAmountDTO
+	Fee   string
+}
+
+func init() {
+	RegisterNamedContextConverter("tenantCents", func(ctx context.Context, x int64) (string, error) {
+		tenant, _ := ctx.Value(ctxTenantKey{}).(string)
+		return fmt.Sprintf("%s:%d", tenant, x), nil
+	})
+}
+
+func TestCopyContext(t *testing.T) {
+	req := require.New(t)
+	ctx := context.WithValue(context.Background(), ctxTenantKey{}, "acme")
+
+	conv := Converter{
+		DstType: reflect.TypeFor[string](),
+		SrcType: reflect.TypeFor[int64](),
+		ContextFunc: func(ctx context.Context, x interface{}) (interface{}, error) {
+			return fmt.Sprintf("%v %d", ctx.Value(ctxTenantKey{}), x.(int64)), nil
+		},
+	}
+	var validated string
+	var dst ctxOrderDTO
+	req.NoError(CopyContext(ctx, &dst, &ctxOrder{Total: ctxAmount{Cents: 1234}, Fee: 5},
+		WithConverter("Fee", conv),
+		func(o *CopierOptions) {
+			o.ValidateContext = func(ctx context.Context, _ interface{}) error {
+				validated = ctx.Value(ctxTenantKey{}).(string)
+				return nil
+			}
+		},
+	))
+	req.Equal("acme 5", dst.Fee)
+	req.Equal("acme:1234", dst.Total.Cents)
+	req.Equal("acme", validated)
+
+	dst = ctxOrderDTO{}
+	req.NoError(TypedCopy(&dst, &ctxOrder{Fee: 5}, WithConverter("Fee", conv)))
+	req.Equal("<nil> 5", dst.Fee)
+
+	req.ErrorIs(CopyContext(ctx, (*ctxOrderDTO)(nil), &ctxOrder{}), ErrNilPointer)
+	req.ErrorIs(CopyContext(ctx, &dst, (*ctxOrder)(nil)), ErrNilPointer)
+	req.ErrorIs(TypedCopy((*ctxOrderDTO)(nil), &ctxOrder{}), ErrNilPointer)
+	req.Panics(func() { MustTypedCopy((*ctxOrderDTO)(nil), &ctxOrder{}) })
+}
+
+func TestCopyContextNested(t *testing.T) {
+	type line struct {
+		Total ctxAmount
+	}
+	type lineDTO struct {
+		Total ctxAmountDTO
+	}
+	type source struct {
+		Amounts []ctxAmount
+		Ptr     *ctxAmount
+		Value   *ctxAmount
+		Lines   []*line
+	}
+	type target struct {
+		Amounts []ctxAmountDTO
+		Ptr     *ctxAmountDTO
+		Value   ctxAmountDTO
+		Lines   []lineDTO
+	}
+
+	req := require.New(t)
+	ctx := context.WithValue(context.Background(), ctxTenantKey{}, "acme")
+
+	var dst target
+	req.NoError(CopyContext(ctx, &dst, &source{
+		Amounts: []ctxAmount{{Cents: 1}, {Cents: 2}},
+		Ptr:     &ctxAmount{Cents: 3},
+		Value:   &ctxAmount{Cents: 4},
+		Lines:   []*line{{Total: ctxAmount{Cents: 5}}},
+	}))
+	req.Equal(target{
+		Amounts: []ctxAmountDTO{{Cents: "acme:1"}, {Cents: "acme:2"}},
+		Ptr:     &ctxAmountDTO{Cents: "acme:3"},
+		Value:   ctxAmountDTO{Cents: "acme:4"},
+		Lines:   []lineDTO{{Total: ctxAmountDTO{Cents: "acme:5"}}},
+	}, dst)
+
+	dst = target{}
+	req.NoError(CopyContext(ctx, &dst, &source{}))
+	req.Equal(target{}, dst)
+
+	dst = target{}
+	req.NoError(TypedCopy(&dst, &source{Amounts: []ctxAmount{{Cents: 1}}}))
+	req.Equal([]ctxAmountDTO{{Cents: ":1"}}, dst.Amounts)
+}
+
+func TestCopyContextSkipInvalid(t *testing.T) {
+	type line struct {
+		Cents int64
+		SKU   string
+	}
+	type lineDTO struct {
+		Cents string `copy:",conv=tenantCents"`
+		SKU   uuid.UUID
+	}
+	type source struct {
+		Lines []line
+	}
+	type target struct {
+		Lines []lineDTO `copy:",skipinvalid"`
+	}
+
+	req := require.New(t)
+	ctx := context.WithValue(context.Background(), ctxTenantKey{}, "acme")
+	u := uuid.New()
+
+	var dst target
+	err := CopyContext(ctx, &dst, &source{Lines: []line{{Cents: 1, SKU: "bad"}, {Cents: 2, SKU: u.String()}}})
+	var se *SkippedElementsError
+	req.ErrorAs(err, &se)
+	req.Len(se.Elements, 1)
+	req.Equal([]lineDTO{{Cents: "acme:2", SKU: u}}, dst.Lines)
+}
+
+func TestContextCopierForPair(t *testing.T) {
+	type source struct {
+		Cents int64 `copy:",conv=tenantCents"`
+	}
+	type target struct {
+		Cents string
+	}
+
+	req := require.New(t)
+	ctx := context.WithValue(context.Background(), ctxTenantKey{}, "acme")
+
+	copier, err := ContextCopierForPair(reflect.TypeFor[target](), reflect.TypeFor[source]())
+	req.NoError(err)
+	var dst target
+	req.NoError(copier(ctx, unsafe.Pointer(&dst), unsafe.Pointer(&source{Cents: 10})))
+	req.Equal("acme:10", dst.Cents)
+
+	plain, err := CopierForPair(reflect.TypeFor[target](), reflect.TypeFor[source]())
+	req.NoError(err)
+	req.NoError(plain(unsafe.Pointer(&dst), unsafe.Pointer(&source{Cents: 10})))
+	req.Equal(":10", dst.Cents)
+
+	errBoom := errors.New("boom")
+	copier, err = ContextCopierForPair(reflect.TypeFor[target](), reflect.TypeFor[source](), WithConverter("Cents", Converter{
+		DstType: reflect.TypeFor[string](),
+		SrcType: reflect.TypeFor[int64](),
+		ContextFunc: func(context.Context, interface{}) (interface{}, error) {
+			return nil, errBoom
+		},
+	}))
+	req.NoError(err)
+	err = copier(ctx, unsafe.Pointer(&dst), unsafe.Pointer(&source{}))
+	req.ErrorIs(err, errBoom)
+	var ce *CopyError
+	req.ErrorAs(err, &ce)
+	req.Equal("Cents", ce.Path())
+}
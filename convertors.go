@@ -2,6 +2,7 @@ package keyvalue
 
 import (
 	"reflect"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,7 +10,8 @@ import (
 )
 
 var (
-	convertors = make(map[typePair]func(interface{}) (interface{}, error))
+	convertors    = make(map[typePair]func(interface{}) (interface{}, error))
+	convertorsMtx sync.RWMutex
 )
 
 type typePair struct {
@@ -17,7 +19,10 @@ type typePair struct {
 	st reflect.Type
 }
 
+// registerConvertor registers a conversion function. It's safe for concurrent use with [lookupConvertor].
 func registerConvertor[D, S any](f func(S) (D, error)) {
+	convertorsMtx.Lock()
+	defer convertorsMtx.Unlock()
 	convertors[typePair{
 		dt: reflect.TypeOf((*D)(nil)).Elem(),
 		st: reflect.TypeOf((*S)(nil)).Elem(),
@@ -26,6 +31,14 @@ func registerConvertor[D, S any](f func(S) (D, error)) {
 	}
 }
 
+// lookupConvertor returns the conversion function registered for the pair of types.
+func lookupConvertor(dt, st reflect.Type) (func(interface{}) (interface{}, error), bool) {
+	convertorsMtx.RLock()
+	defer convertorsMtx.RUnlock()
+	f, ok := convertors[typePair{dt: dt, st: st}]
+	return f, ok
+}
+
 func init() {
 	registerConvertor(func(src uuid.UUID) (string, error) {
 		return src.String(), nil
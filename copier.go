@@ -1,11 +1,21 @@
-// Package keyvalue provides the [Copy] function that copies data between structures.
+// Package keyvalue provides the [TypedCopy] function that copies data between structures.
 // The functionality includes implicit and custom conversions.
+//
+// Copiers are compiled once per pair of types and options and cached. Compiled copiers are immutable
+// so they're safe for concurrent use, provided the values copied concurrently don't overlap and the custom
+// converters, validators and hooks in the options are safe for concurrent use themselves.
+// [ResetCache] discards the cached copiers, e.g. between tests.
 package keyvalue
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"math"
 	"reflect"
 	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 	"unsafe"
@@ -22,8 +32,10 @@ import (
 	"github.com/mailstepcz/validate"
 	"github.com/oklog/ulid/v2"
 	"github.com/rickb777/date/v2"
+	"github.com/rickb777/date/v2/timespan"
 	"github.com/shopspring/decimal"
 	"golang.org/x/text/language"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -32,13 +44,28 @@ var (
 	ErrTypeNotStruct = errors.New("type not struct")
 	// ErrFieldNotFound signifies that the required field was not found.
 	ErrFieldNotFound = errors.New("field not found")
+	// ErrAmbiguousField signifies that a field name is promoted from several embedded structures.
+	ErrAmbiguousField = errors.New("ambiguous field")
 	// ErrUnsupportedTypePair signifies incompatible type pair.
 	ErrUnsupportedTypePair = errors.New("unsupported pair")
+	// ErrNilSource signifies a nil source value rejected by the [NilError] policy.
+	ErrNilSource = errors.New("nil source value")
+	// ErrPrecisionLoss signifies a numeric conversion which would lose precision.
+	ErrPrecisionLoss = errors.New("precision loss")
+	// ErrValidation signifies that the destination object failed the validation run by [CopierOptions.Validate].
+	ErrValidation = errors.New("validation failed")
+	// ErrPanic signifies a panic recovered while copying a field.
+	ErrPanic = errors.New("panic while copying")
 	// ErrPointerNotSupportedInDestinationSlice signifies that a pointer in the slice would clash with the GC.
+	//
+	// Deprecated: values containing pointers are copied with write barriers in place, hence this error is never returned.
 	ErrPointerNotSupportedInDestinationSlice = errors.New("dangerous pointer in slice")
 
-	copiers  = make(map[copierTypePair]func(unsafe.Pointer, unsafe.Pointer) error)
+	copiers  = make(map[copierTypePair]*cacheEntry)
 	cacheMtx sync.RWMutex
+
+	valConvs   = make(map[copierTypePair]*cacheEntry)
+	valConvMtx sync.RWMutex
 )
 
 var (
@@ -50,41 +77,330 @@ type CopierOptions struct {
 	OmitNotFound bool
 	FieldsToCopy []string
 	FieldsToOmit []string
+	// Instrument is invoked after each copy performed by the copier.
+	Instrument func(CopyStats)
+	// Tracer traces each copy performed by the copier, e.g. with OpenTelemetry spans.
+	Tracer CopyTracer
+	// NoCache prevents the copier from being stored in the global cache.
+	// It's meant for ephemeral types such as those created by [reflect.StructOf] and for options holding functions,
+	// e.g. computed fields and conditions, which are created anew for every copy since copiers are cached by their identity.
+	NoCache bool
+	// NilPolicy determines how nil source values are handled.
+	NilPolicy NilPolicy
+	// EnumPolicy determines how invalid closed enum values are handled.
+	EnumPolicy EnumPolicy
+	// OnEnumFallback is invoked whenever an invalid closed enum value is replaced with the default value of the enum
+	// according to the EnumPolicy, e.g. to log a warning.
+	OnEnumFallback func(EnumFallback)
+	// RecoverPanics converts panics in field copiers into errors wrapping [ErrPanic].
+	RecoverPanics bool
+	// StrictNumeric makes numeric conversions fail with [ErrPrecisionLoss] instead of silently rounding or truncating.
+	StrictNumeric bool
+	// Validate is invoked with a pointer to the destination object after each successful copy performed by the copier.
+	// Its error is returned as a [CopyError] wrapping both [ErrValidation] and the error itself.
+	// The Struct method of go-playground/validator's Validate can be used directly.
+	Validate func(interface{}) error
+	// ValidateContext is invoked like Validate with the context passed on to the copier, see [CopyContext].
+	// It takes precedence over Validate.
+	ValidateContext func(context.Context, interface{}) error
+	// Recorder receives the changes the copier makes to the destination fields, including computed and constant fields,
+	// along with their previous and new values. Fields written with their current values aren't reported.
+	// The recorder is passed on to the cached copier at run time so it can be created for every copy.
+	Recorder ChangeRecorder
+	// Naming determines how source fields are matched with destination fields. Fields are matched by their names by default.
+	Naming NamingStrategy
+	// FieldMask restricts the copy to the destination fields selected by the mask's paths as described by AIP-161.
+	// Path segments are matched against protobuf field names or, for plain structures, snake-cased field names.
+	// Paths which don't match any copied field make the copier creation fail with [ErrFieldNotFound].
+	FieldMask *fieldmaskpb.FieldMask
+	// FieldRenames maps source field names to the names of the destination fields they are copied to.
+	// The names may be dot-separated paths of fields nested in the destination, e.g. "Address.City".
+	// They take precedence over the `copy` tags renaming fields, e.g. `copy:"CustomerID"`, see [CopierForPair],
+	// so fields of structures which can't be tagged can be mapped too. Renames of missing source fields make
+	// the copier creation fail with [ErrFieldNotFound] unless OmitNotFound is set.
+	FieldRenames map[string]string
+	// NoAutoAlloc makes fields nested in the destination through nil pointers be skipped.
+	// By default the nested structures are allocated when the fields are copied.
+	NoAutoAlloc bool
+	// EmbeddedPointers makes fields promoted through embedded pointers in the destination be copied
+	// like other nested fields, allocating nil embedded pointers unless NoAutoAlloc is set.
+	// By default such fields aren't found in the destination.
+	EmbeddedPointers bool
+	// Converters maps source field names to custom converters used for copying the fields.
+	Converters map[string]Converter
+	// TypeConverters are custom conversions used for all the values of their pairs of source and destination types,
+	// including the fields of nested structures and the elements of slices, like [ConvertorOption.Funcs]
+	// for [CopyV1]. They take precedence over the conversion providers and the built-in conversions.
+	TypeConverters map[TypePair]func(interface{}) (interface{}, error)
+	// NamedConverters maps source field names to the names of converters registered with [RegisterNamedConverter]
+	// or [RegisterNamedConv] which are used for copying the fields. It takes precedence over the `copy` tag.
+	NamedConverters map[string]string
+	// Slices maps source field names to the options post-processing the destination slices they are copied to.
+	// It takes precedence over the `copy` tag.
+	Slices map[string]SliceOptions
+	// DeepCopy makes values of the same type be copied recursively instead of sharing pointers, slices and maps.
+	DeepCopy bool
+	// ReuseSlices makes converted slices be written into the existing destination slices if their capacity suffices
+	// instead of allocating new ones. The destination slices mustn't be shared with other values.
+	ReuseSlices bool
+	// ShareSlices makes slices with identical element types share the backing arrays of the source slices instead of
+	// having their elements copied, even with DeepCopy. Writes to the elements of either slice are visible through
+	// the other one so it's meant for read-only copies of large values. Slices post-processed by [SliceOptions]
+	// are still copied.
+	ShareSlices bool
+	// NormalizeMaps makes structures copied into dynamic maps be stored as values which serialize in the usual way.
+	// Values which marshal themselves as text, such as UUIDs, ULIDs, times and decimals, are converted into strings,
+	// honouring TimeLayout and DecimalFormat, and nested structures, slices and maps become nested dynamic maps and
+	// slices of normalized values.
+	NormalizeMaps bool
+	// Limits bounds the source values copied by the copier. Sources exceeding them fail to copy with [ErrLimitExceeded].
+	Limits CopyLimits
+	// TimeLocation makes copied times be converted into the location, e.g. [time.UTC],
+	// including times converted from and to protobuf timestamps and strings.
+	TimeLocation *time.Location
+	// StripMonotonic removes the monotonic clock readings from copied times so that they compare equal with ==
+	// to the same instants round-tripped through protobuf timestamps.
+	StripMonotonic bool
+	// TimePrecision truncates copied times, including times converted from and to protobuf timestamps and strings,
+	// to the precision, e.g. [time.Millisecond]. Truncated times have no monotonic clock readings.
+	TimePrecision time.Duration
+	// DateLocation is the location in which times and protobuf timestamps are truncated when copied to dates.
+	// Protobuf timestamps are truncated in UTC and times in their own locations by default.
+	DateLocation *time.Location
+	// StringTransforms maps source field names to transforms applied to the strings copied from the fields.
+	// Transforms can also be listed in the `transform` tag of destination fields, e.g. `transform:"trim,lower"`.
+	StringTransforms map[string][]StringTransform
+	// LanguageCanon canonicalizes language tags converted from and to strings, e.g. [language.All].
+	LanguageCanon language.CanonType
+	// SupportedLanguages restricts language tags converted from and to strings to the best matching supported language.
+	// The first supported language is used when there's no match, including strings with well-formed but unknown subtags.
+	SupportedLanguages []language.Tag
+	// DecimalRounding rounds decimals converted from floating-point numbers and decimals converted to them.
+	DecimalRounding *DecimalRounding
+	// DecimalFormat formats decimals converted to strings with a fixed number of digits after the decimal point.
+	// Decimals are formatted by their String method by default.
+	DecimalFormat *DecimalFormat
+	// DecimalFormats maps source field names to the formats of the decimals copied from the fields to strings.
+	// They take precedence over the `copy` tag of the destination fields, e.g. `copy:",scale=2"`.
+	DecimalFormats map[string]DecimalFormat
+	// MinorUnitExponent enables conversions between decimals and int64 amounts in minor units, e.g. 2 for cents.
+	// Decimals which aren't whole amounts of minor units are rounded by DecimalRounding if set and rejected with [ErrPrecisionLoss] otherwise.
+	MinorUnitExponent int32
+	// TimeLayout is the layout of times and protobuf timestamps converted from and to strings. It defaults to [time.RFC3339Nano].
+	TimeLayout string
+	// TimeStrings enables conversions of times and protobuf timestamps from and to strings with TimeLayout
+	// and conversions of dates from and to ISO 8601 strings.
+	TimeStrings bool
+	// Splitters maps source field names to splitters copying the fields into several destination fields.
+	Splitters map[string]Splitter
+	// Conditions maps source field names to predicates deciding whether the fields are copied.
+	// The predicates receive a pointer to the source structure.
+	Conditions map[string]func(interface{}) bool
+	// Computed maps destination field names to functions computing the fields from a pointer to the source structure.
+	// The computed values are converted to the field types and nil values leave the fields untouched.
+	Computed map[string]func(interface{}) (interface{}, error)
+	// Constants maps destination field names to values written into the fields on every copy.
+	// The values are converted to the field types and the pointers, slices and maps they reference are copied into every copy.
+	Constants map[string]interface{}
+	// Unions maps destination field names to the tagged unions in the source structure the fields are copied from.
+	// The fields have to be oneof fields or fields of sum types registered with [RegisterSumVariant].
+	Unions map[string]TaggedUnion
+	// Engine is the engine [TypedCopy] copies objects with. Copiers created for pairs of types ignore it.
+	Engine Engine
+	// AdapterOptions are the options of the adapters used by [EngineAdapter].
+	AdapterOptions []Option
+
+	// pairOptions are the options of the pairs of nested structures declared by a [Manifest].
+	pairOptions map[[2]reflect.Type]*CopierOptions
+}
+
+// Converter is a custom conversion of field values between a pair of types.
+type Converter struct {
+	DstType reflect.Type
+	SrcType reflect.Type
+	Func    func(interface{}) (interface{}, error)
+	// ContextFunc is used instead of Func if set. It receives the context passed on to the copier,
+	// see [CopyContext], or the background context when the field is copied by a copier without a context.
+	ContextFunc func(context.Context, interface{}) (interface{}, error)
+}
+
+// NilPolicy is a policy for handling nil source values such as nil pointers, nil slices,
+// invalid timestamps and empty maybe values.
+type NilPolicy int
+
+// nil policies
+const (
+	// NilSkip leaves the destination untouched.
+	NilSkip NilPolicy = iota
+	// NilZero sets the destination to its zero value.
+	NilZero
+	// NilError makes the copy fail with [ErrNilSource].
+	NilError
+	// NilSkipZero leaves the destination untouched like NilSkip and also treats zero source values which fail
+	// to be converted into pointers as nil, e.g. empty strings copied to *uuid.UUID fields leave them nil.
+	NilSkipZero
+)
+
+// CopyStats holds statistics about a single copy passed on to [CopierOptions.Instrument].
+type CopyStats struct {
+	DstType  reflect.Type
+	SrcType  reflect.Type
+	Fields   int
+	Duration time.Duration
+	// Allocs is an estimate of heap allocations performed by the copy.
+	Allocs int
+	// Elements is the number of source elements copied by slice copiers.
+	Elements int
+	Err      error
 }
 
 type copierTypePair struct {
 	dst, src reflect.Type
+	opts     *CopierOptions
 }
 
 // CopierForPair creates a copier for a pair of structs.
+// Source fields are copied to the destination fields of the same names. A field of either structure can be matched
+// with a differently named field of the other one by naming it in its `copy` tag, e.g. `copy:"CustomerID"`,
+// and destination fields renamed this way aren't matched by their own names.
 func CopierForPair(dstType, srcType reflect.Type) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
 	return CopierForPairWithOptions(dstType, srcType, nil)
 }
 
+// CopierForPairWith creates a copier like [CopierForPair] configured by functional options.
+// Copiers are cached by the contents of the options, so calls with equal options share the copier.
+func CopierForPairWith(dstType, srcType reflect.Type, opts ...CopierOption) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	if len(opts) == 0 {
+		return CopierForPairWithOptions(dstType, srcType, nil)
+	}
+	return CopierForPairWithOptions(dstType, srcType, NewCopierOptions(opts...))
+}
+
 // CopierForPairWithOptions creates a copier for a pair of structs with custom options.
 func CopierForPairWithOptions(dstType, srcType reflect.Type, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	e, err := copierEntry(dstType, srcType, opts)
+	if err != nil {
+		return nil, err
+	}
+	return e.copier, nil
+}
+
+// ContextCopierForPair creates a copier for a pair of structs which passes the context on to context-aware
+// converters and hooks such as [Converter.ContextFunc]. Options are handled like by [CopierForPairWith].
+func ContextCopierForPair(dstType, srcType reflect.Type, opts ...CopierOption) (func(context.Context, unsafe.Pointer, unsafe.Pointer) error, error) {
+	if len(opts) == 0 {
+		return ContextCopierForPairWithOptions(dstType, srcType, nil)
+	}
+	return ContextCopierForPairWithOptions(dstType, srcType, NewCopierOptions(opts...))
+}
+
+// ContextCopierForPairWithOptions creates a copier like [ContextCopierForPair] with custom options.
+// It shares the cache with [CopierForPairWithOptions].
+func ContextCopierForPairWithOptions(dstType, srcType reflect.Type, opts *CopierOptions) (func(context.Context, unsafe.Pointer, unsafe.Pointer) error, error) {
+	e, err := copierEntry(dstType, srcType, opts)
+	if err != nil {
+		return nil, err
+	}
+	return e.ctxCopier, nil
+}
+
+// copierEntry returns the cached copiers for a pair of structs, compiling them if they aren't cached yet.
+func copierEntry(dstType, srcType reflect.Type, opts *CopierOptions) (*cacheEntry, error) {
+	if opts != nil && opts.Recorder != nil && opts.Recorder != (contextRecorder{}) {
+		return recordingEntry(dstType, srcType, opts)
+	}
+	opts = canonicalOptions(opts)
 	key := copierTypePair{
-		dst: dstType,
-		src: srcType,
+		dst:  dstType,
+		src:  srcType,
+		opts: opts,
+	}
+	if e, ok := cachedEntry(key); ok {
+		return e, nil
+	}
+	var mask maskTree
+	if opts != nil {
+		mask = parseFieldMask(opts.FieldMask)
 	}
-	cacheMtx.RLock()
-	copier, ok := copiers[key]
-	cacheMtx.RUnlock()
-	if ok {
-		return copier, nil
+	ctxCopier, err := compileContextCopier(dstType, srcType, opts, true, mask)
+	if err != nil {
+		return nil, err
+	}
+	e := &cacheEntry{
+		copier:    withoutContext(ctxCopier),
+		ctxCopier: ctxCopier,
+	}
+	if opts == nil || !opts.NoCache {
+		storeEntry(key, e)
+	}
+	return e, nil
+}
+
+// withoutContext returns a copier running the context-aware copier with the background context.
+func withoutContext(copier func(context.Context, unsafe.Pointer, unsafe.Pointer) error) func(unsafe.Pointer, unsafe.Pointer) error {
+	ctx := context.Background()
+	return func(dst, src unsafe.Pointer) error {
+		return copier(ctx, dst, src)
+	}
+}
+
+// compileCopier creates a copier for a pair of structs like [compileContextCopier] which runs with the background context.
+func compileCopier(dstType, srcType reflect.Type, opts *CopierOptions, top bool, mask maskTree) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	copier, err := compileContextCopier(dstType, srcType, opts, top, mask)
+	if err != nil {
+		return nil, err
 	}
+	return withoutContext(copier), nil
+}
+
+// compileContextCopier creates a context-aware copier for a pair of structs.
+// Field selection and instrumentation only apply to top-level copiers; nested copiers merely inherit the conversion options.
+// A non-nil mask restricts the copied fields to those it selects.
+func compileContextCopier(dstType, srcType reflect.Type, opts *CopierOptions, top bool, mask maskTree) (func(context.Context, unsafe.Pointer, unsafe.Pointer) error, error) {
 	if dstType.Kind() != reflect.Struct || srcType.Kind() != reflect.Struct {
 		return nil, ErrTypeNotStruct
 	}
-	fieldCopiers := make([]func(unsafe.Pointer, unsafe.Pointer) error, 0, srcType.NumField())
-	for _, srcField := range reflect.VisibleFields(srcType) {
+	prog := &program{
+		instrs: make([]instr, 0, srcType.NumField()),
+	}
+	allocs := 0
+	if err := checkAmbiguity(dstType, srcType, opts, top); err != nil {
+		return nil, err
+	}
+	if err := checkRenames(dstType, srcType, opts, top); err != nil {
+		return nil, err
+	}
+	dstAmbiguous := describeType(dstType).ambiguous
+	// values of the same type are copied field by field without the renames given by the `copy` tags
+	// which are meant for pairs of different types
+	tagRenames := dstType != srcType
+	dstRenamed := describeType(dstType).renamed
+	if !tagRenames {
+		dstRenamed = nil
+	}
+	var matched []string
+	consumed := append(oneofFields(dstType, srcType), unionFields(opts, top)...)
+	var dstFields map[string]reflect.StructField
+	if opts != nil && opts.Naming != nil {
+		dstFields = fieldsByName(dstType, opts.Naming)
+	}
+	var (
+		closeCond      func()
+		copiedEmbedded [][]int
+	)
+	for _, sf := range describeType(srcType).fields {
+		srcField := sf.StructField
+		if closeCond != nil {
+			closeCond()
+			closeCond = nil
+		}
 		if srcField.PkgPath != "" {
 			continue
 		}
-		if srcField.Tag.Get("kv") == "-" {
+		if sf.skipped {
 			continue
 		}
-		if opts != nil {
+		if top && opts != nil {
 			if slices.Index(opts.FieldsToOmit, srcField.Name) != -1 {
 				continue
 			}
@@ -92,47 +408,586 @@ func CopierForPairWithOptions(dstType, srcType reflect.Type, opts *CopierOptions
 				continue
 			}
 		}
-		dstField, ok := dstType.FieldByName(srcField.Name)
+		if slices.Contains(consumed, srcField.Name) {
+			if isOneof(srcField) {
+				conv, err := oneofToFields(dstType, srcType, srcField, opts)
+				if err != nil {
+					return nil, compileFieldError(err, srcField.Name, dstType, srcField.Type)
+				}
+				prog.addCustomField(srcField.Name, dstType, srcField.Type, 0, 0, conv, opts)
+			}
+			continue
+		}
+		srcOffset := sf.offset
+		var srcHops []pointerHop
+		if sf.viaPointer {
+			if slices.ContainsFunc(copiedEmbedded, func(idx []int) bool { return isPrefix(idx, srcField.Index) }) {
+				// the field is copied along with the embedded pointer
+				continue
+			}
+			srcHops, srcOffset, _ = fieldHops(srcType, srcField.Index)
+		}
+		if cond, ok := fieldCondition(srcField.Name, opts, top); ok {
+			// the instructions added until the next iteration are guarded by the condition
+			closeCond = prog.addCond(srcField.Name, func(src unsafe.Pointer) bool {
+				return cond(reflect.NewAt(srcType, src).Interface())
+			})
+		}
+		if splitter, ok := fieldSplitter(srcField.Name, opts, top); ok {
+			conv, err := splitterConv(dstType, srcField.Type, splitter, opts)
+			if err != nil {
+				return nil, compileFieldError(err, srcField.Name, dstType, srcField.Type)
+			}
+			if len(srcHops) > 0 {
+				conv = promotedFieldConv(srcHops, srcOffset, conv, nilHandler(dstType, nil))
+				srcOffset = 0
+			}
+			prog.addCustomField(srcField.Name, dstType, srcField.Type, 0, srcOffset, conv, opts)
+			continue
+		}
+		var (
+			dstField reflect.StructField
+			ok       bool
+		)
+		if rename, renamed := renamedField(srcField, opts, top, tagRenames); renamed {
+			dstField, ok = fieldByPath(dstType, rename)
+		} else if f, renamed := dstRenamed[srcField.Name]; renamed {
+			dstField, ok = f, true
+		} else if dstFields != nil {
+			name := opts.Naming(srcField)
+			if name == "" {
+				continue
+			}
+			// of the destination fields with the same name the one named like the source field is preferred
+			if f, exact := dstType.FieldByName(srcField.Name); exact && f.IsExported() && opts.Naming(f) == name {
+				dstField, ok = f, true
+			} else {
+				dstField, ok = dstFields[name]
+			}
+		} else {
+			if paths, ok := dstAmbiguous[srcField.Name]; ok {
+				return nil, ambiguityError(dstType, srcType, srcField.Name, paths)
+			}
+			dstField, ok = dstType.FieldByName(srcField.Name)
+			if ok && tagRenames && copyTagName(dstField) != "" {
+				// the destination field is copied from the source field named by its tag
+				ok = false
+			}
+		}
+		var (
+			dstHops   []pointerHop
+			dstOffset uintptr
+		)
+		if ok {
+			dstHops, dstOffset, ok = fieldHops(dstType, dstField.Index)
+		}
+		if ok && len(dstHops) > 0 && (opts == nil || !opts.EmbeddedPointers) && viaEmbeddedPointer(dstType, dstField.Index) {
+			ok = false
+		}
+		var subMask maskTree
+		if mask != nil {
+			name := maskName(srcField)
+			if ok {
+				name = maskName(dstField)
+			}
+			sub, selected := mask[name]
+			if !selected {
+				continue
+			}
+			subMask = sub
+			matched = append(matched, name)
+		}
 		if !ok {
 			if opts != nil && opts.OmitNotFound {
 				continue
 			}
-			return nil, serr.Wrap("", ErrFieldNotFound, serr.String("srcField", srcField.Name), serr.String("srcType", srcType.Name()))
+			return nil, &CopyError{
+				DstType:   dstType,
+				SrcType:   srcType,
+				FieldPath: []string{srcField.Name},
+				Err:       serr.Wrap("", ErrFieldNotFound, serr.String("srcField", srcField.Name), serr.String("srcType", srcType.Name())),
+			}
+		}
+		custom, hasCustom, err := fieldConverter(dstField, srcField, opts, top)
+		if err != nil {
+			return nil, compileFieldError(err, srcField.Name, dstField.Type, srcField.Type)
+		}
+		var (
+			conv      func(unsafe.Pointer, unsafe.Pointer) error
+			ctxConv   func(context.Context, unsafe.Pointer, unsafe.Pointer) error
+			nestedCtx bool
+		)
+		if hasCustom && custom.ContextFunc != nil {
+			ctxConv, err = converterContextConv(dstField.Type, srcField.Type, custom)
+		} else if hasCustom {
+			conv, err = converterConv(dstField.Type, srcField.Type, custom)
+		} else if isOneof(srcField) && isSumType(dstField.Type) {
+			conv, err = oneofToSumConv(dstField.Type, srcType, srcField, opts)
+		} else if isOneof(dstField) && isSumType(srcField.Type) {
+			conv, err = sumToOneofConv(dstType, dstField, srcField.Type, opts)
+		} else if subMask != nil {
+			conv, err = maskedConv(dstField.Type, srcField.Type, subMask, opts)
+		} else if transforms, terr := fieldTransforms(dstField, srcField, opts, top); terr != nil {
+			err = terr
+		} else if len(transforms) > 0 {
+			conv, err = transformedConv(dstField.Type, srcField.Type, transforms, opts)
+		} else if keyField, ok := copyTagOption(dstField, "key"); ok {
+			conv, err = sliceToMapConv(dstField.Type, srcField.Type, keyField, opts)
+		} else if format, ok, ferr := fieldDecimalFormat(dstField, srcField, opts, top); ferr != nil {
+			err = ferr
+		} else if ok {
+			conv, err = valConvWithOptions(dstField.Type, srcField.Type, decimalFormatOptions(opts, format))
+		} else if ctxConv, err = contextValConv(dstField.Type, srcField.Type, opts); err != nil || ctxConv != nil {
+			nestedCtx = true
+		} else if len(dstHops) > 0 {
+			conv, err = valConvWithOptions(dstField.Type, srcField.Type, opts)
 		}
-		fc, err := fieldCopier(dstField.Type, srcField.Type, dstField.Offset, srcField.Offset)
 		if err != nil {
-			return nil, serr.Wrap("", err, serr.String("srcField", srcField.Name))
+			return nil, compileFieldError(err, srcField.Name, dstField.Type, srcField.Type)
+		}
+		if so, ok := fieldSliceOptions(dstField, srcField, opts, top); ok {
+			process, err := sliceProcessor(dstField.Type, so)
+			if err != nil {
+				return nil, compileFieldError(err, srcField.Name, dstField.Type, srcField.Type)
+			}
+			if nestedCtx && so.SkipInvalid {
+				ctxConv, err = contextSliceConv(dstField.Type, srcField.Type, opts, true)
+				if err != nil {
+					return nil, compileFieldError(err, srcField.Name, dstField.Type, srcField.Type)
+				}
+			}
+			if ctxConv != nil {
+				ctxConv = processedContextConv(dstField.Type, ctxConv, process)
+			} else if conv == nil {
+				if so.SkipInvalid {
+					conv, err = skippingSliceConv(dstField.Type, srcField.Type, unsharedOptions(opts))
+				} else {
+					conv, err = valConvWithOptions(dstField.Type, srcField.Type, unsharedOptions(opts))
+				}
+				if err != nil {
+					return nil, compileFieldError(err, srcField.Name, dstField.Type, srcField.Type)
+				}
+			}
+			if conv != nil {
+				conv = processedConv(dstField.Type, conv, process)
+			}
 		}
-		fieldCopiers = append(fieldCopiers, fc)
+		if rec := fieldRecorder(opts, top); rec != nil {
+			if ctxConv == nil {
+				if conv == nil {
+					conv, err = valConvWithOptions(dstField.Type, srcField.Type, opts)
+					if err != nil {
+						return nil, compileFieldError(err, srcField.Name, dstField.Type, srcField.Type)
+					}
+				}
+				ctxConv = contextConv(conv)
+			}
+			ctxConv, err = recordingConv(fieldPath(dstType, dstField.Index), dstField.Type, ctxConv, rec)
+			if err != nil {
+				return nil, compileFieldError(err, srcField.Name, dstField.Type, srcField.Type)
+			}
+		}
+		if len(srcHops) > 0 {
+			if ctxConv == nil && conv == nil {
+				conv, err = valConvWithOptions(dstField.Type, srcField.Type, opts)
+				if err != nil {
+					return nil, compileFieldError(err, srcField.Name, dstField.Type, srcField.Type)
+				}
+			}
+			onNil := nilHandler(dstField.Type, opts)
+			if ctxConv != nil {
+				ctxConv = promotedFieldContextConv(srcHops, srcOffset, ctxConv, onNil)
+			} else {
+				conv = promotedFieldConv(srcHops, srcOffset, conv, onNil)
+			}
+			srcOffset = 0
+		}
+		if srcField.Anonymous && srcField.Type.Kind() == reflect.Pointer {
+			copiedEmbedded = append(copiedEmbedded, srcField.Index)
+		}
+		switch {
+		case ctxConv != nil && len(dstHops) > 0:
+			prog.addContextField(srcField.Name, dstField.Type, srcField.Type, 0, srcOffset, nestedFieldContextConv(dstHops, dstOffset, ctxConv, opts), opts)
+		case ctxConv != nil:
+			prog.addContextField(srcField.Name, dstField.Type, srcField.Type, dstOffset, srcOffset, ctxConv, opts)
+		case len(dstHops) > 0:
+			prog.addCustomField(srcField.Name, dstField.Type, srcField.Type, 0, srcOffset, nestedFieldConv(dstHops, dstOffset, conv, opts), opts)
+		case conv != nil:
+			prog.addCustomField(srcField.Name, dstField.Type, srcField.Type, dstOffset, srcOffset, conv, opts)
+		default:
+			if err := prog.addField(srcField.Name, dstField.Type, srcField.Type, dstOffset, srcOffset, opts); err != nil {
+				return nil, compileFieldError(err, srcField.Name, dstField.Type, srcField.Type)
+			}
+		}
+		allocs += allocEstimate(dstField.Type, srcField.Type)
 	}
-	fieldCopiers = slices.Clip(fieldCopiers)
-	copier = func(dst, src unsafe.Pointer) error {
-		for _, fc := range fieldCopiers {
-			if err := fc(dst, src); err != nil {
-				return err
+	if closeCond != nil {
+		closeCond()
+	}
+	if top && opts != nil {
+		for _, name := range sortedKeys(opts.Constants) {
+			f, offset, err := destinationField(dstType, name)
+			if err != nil {
+				return nil, compileFieldError(err, name, dstType, srcType)
+			}
+			conv, err := constantConv(f.Type, opts.Constants[name], opts)
+			if err != nil {
+				return nil, compileFieldError(err, name, f.Type, reflect.TypeOf(opts.Constants[name]))
+			}
+			if err := prog.addRecordedField(name, dstType, f, srcType, offset, conv, opts); err != nil {
+				return nil, compileFieldError(err, name, f.Type, srcType)
 			}
 		}
-		return nil
+		for _, name := range sortedKeys(opts.Computed) {
+			f, offset, err := destinationField(dstType, name)
+			if err != nil {
+				return nil, compileFieldError(err, name, dstType, srcType)
+			}
+			if err := prog.addRecordedField(name, dstType, f, srcType, offset, computedConv(f.Type, srcType, opts.Computed[name], opts), opts); err != nil {
+				return nil, compileFieldError(err, name, f.Type, srcType)
+			}
+		}
+		for _, name := range sortedKeys(opts.Unions) {
+			f, offset, err := destinationField(dstType, name)
+			if err != nil {
+				return nil, compileFieldError(err, name, dstType, srcType)
+			}
+			conv, err := taggedUnionConv(dstType, f, srcType, opts.Unions[name], opts)
+			if err != nil {
+				return nil, compileFieldError(err, name, f.Type, srcType)
+			}
+			if err := prog.addRecordedField(name, dstType, f, srcType, offset, conv, opts); err != nil {
+				return nil, compileFieldError(err, name, f.Type, srcType)
+			}
+		}
+	}
+	for _, df := range describeType(dstType).fields {
+		dstField := df.StructField
+		if !flattensOneof(dstField, srcType) || isUnionField(dstField.Name, opts, top) {
+			continue
+		}
+		conv, err := fieldsToOneof(dstType, srcType, dstField, opts)
+		if err != nil {
+			return nil, compileFieldError(err, dstField.Name, dstField.Type, srcType)
+		}
+		prog.addCustomField(dstField.Name, dstField.Type, srcType, 0, 0, conv, opts)
+	}
+	if mask != nil {
+		if err := unmatchedMaskError(dstType, srcType, mask, matched); err != nil {
+			return nil, err
+		}
+	}
+	prog.clip()
+	copier := prog.run
+	if top && opts != nil && opts.Limits != (CopyLimits{}) {
+		copier = limited(copier, opts.Limits, dstType, srcType)
+	}
+	if top && opts != nil && opts.ValidateContext != nil {
+		copier = validated(copier, opts.ValidateContext, dstType, srcType)
+	} else if top && opts != nil && opts.Validate != nil {
+		validate := opts.Validate
+		copier = validated(copier, func(_ context.Context, x interface{}) error {
+			return validate(x)
+		}, dstType, srcType)
+	}
+	stats := CopyStats{
+		DstType: dstType,
+		SrcType: srcType,
+		Fields:  len(prog.instrs),
+		Allocs:  allocs,
+	}
+	if top && opts != nil && opts.Instrument != nil {
+		copier = instrumented(copier, opts.Instrument, stats)
+	}
+	if top && opts != nil && opts.Tracer != nil {
+		copier = traced(copier, opts.Tracer, stats)
 	}
-	cacheMtx.Lock()
-	defer cacheMtx.Unlock()
-	copiers[key] = copier
 	return copier, nil
 }
 
-func memcopy(dst, src unsafe.Pointer, size uintptr) {
-	switch size {
-	case 8:
-		*(*[8]byte)(dst) = *(*[8]byte)(src)
-	case 16:
-		*(*[16]byte)(dst) = *(*[16]byte)(src)
-	case 24:
-		*(*[24]byte)(dst) = *(*[24]byte)(src)
-	default:
-		copy(unsafe.Slice((*byte)(dst), size), unsafe.Slice((*byte)(src), size))
+func instrumented(copier func(context.Context, unsafe.Pointer, unsafe.Pointer) error, instrument func(CopyStats), stats CopyStats) func(context.Context, unsafe.Pointer, unsafe.Pointer) error {
+	return func(ctx context.Context, dst, src unsafe.Pointer) error {
+		start := time.Now()
+		err := copier(ctx, dst, src)
+		stats := stats
+		stats.Duration = time.Since(start)
+		stats.Err = err
+		instrument(stats)
+		return err
+	}
+}
+
+// destSlice returns a slice of the type and length to convert into. If reuse is set and the capacity
+// of the destination slice suffices, the destination slice is resliced and its elements are zeroed.
+func destSlice(t reflect.Type, dst unsafe.Pointer, n int, reuse bool) reflect.Value {
+	if reuse {
+		cur := reflect.NewAt(t, dst).Elem()
+		if !cur.IsNil() && cur.Cap() >= n {
+			r := cur.Slice(0, max(n, cur.Len()))
+			r.Clear()
+			return r.Slice(0, n)
+		}
+	}
+	return reflect.MakeSlice(t, n, n)
+}
+
+// nestedFieldConv returns a converter writing into a field nested in the destination through pointers.
+// Nil pointers are allocated unless the options disable it, in which case the field isn't copied.
+func nestedFieldConv(hops []pointerHop, offset uintptr, conv func(unsafe.Pointer, unsafe.Pointer) error, opts *CopierOptions) func(unsafe.Pointer, unsafe.Pointer) error {
+	noAlloc := opts != nil && opts.NoAutoAlloc
+	return func(dst, src unsafe.Pointer) error {
+		dst, ok := nestedDest(hops, dst, noAlloc)
+		if !ok {
+			return nil
+		}
+		return conv(unsafe.Add(dst, offset), src)
+	}
+}
+
+// nestedFieldContextConv is the context-aware counterpart of [nestedFieldConv].
+func nestedFieldContextConv(hops []pointerHop, offset uintptr, conv func(context.Context, unsafe.Pointer, unsafe.Pointer) error, opts *CopierOptions) func(context.Context, unsafe.Pointer, unsafe.Pointer) error {
+	noAlloc := opts != nil && opts.NoAutoAlloc
+	return func(ctx context.Context, dst, src unsafe.Pointer) error {
+		dst, ok := nestedDest(hops, dst, noAlloc)
+		if !ok {
+			return nil
+		}
+		return conv(ctx, unsafe.Add(dst, offset), src)
+	}
+}
+
+// promotedFieldConv returns a converter reading a field promoted through embedded pointers in the source.
+// Nil pointers are handled by the nil handler.
+func promotedFieldConv(hops []pointerHop, offset uintptr, conv func(unsafe.Pointer, unsafe.Pointer) error, onNil func(unsafe.Pointer) error) func(unsafe.Pointer, unsafe.Pointer) error {
+	return func(dst, src unsafe.Pointer) error {
+		src, ok := nestedSrc(hops, src)
+		if !ok {
+			return onNil(dst)
+		}
+		return conv(dst, unsafe.Add(src, offset))
+	}
+}
+
+// promotedFieldContextConv is the context-aware counterpart of [promotedFieldConv].
+func promotedFieldContextConv(hops []pointerHop, offset uintptr, conv func(context.Context, unsafe.Pointer, unsafe.Pointer) error, onNil func(unsafe.Pointer) error) func(context.Context, unsafe.Pointer, unsafe.Pointer) error {
+	return func(ctx context.Context, dst, src unsafe.Pointer) error {
+		src, ok := nestedSrc(hops, src)
+		if !ok {
+			return onNil(dst)
+		}
+		return conv(ctx, dst, unsafe.Add(src, offset))
+	}
+}
+
+// nestedSrc follows the pointers to the structure holding a promoted source field. It returns false if a pointer is nil.
+func nestedSrc(hops []pointerHop, src unsafe.Pointer) (unsafe.Pointer, bool) {
+	for _, h := range hops {
+		src = *(*unsafe.Pointer)(unsafe.Add(src, h.offset))
+		if src == nil {
+			return nil, false
+		}
+	}
+	return src, true
+}
+
+// nestedDest follows the pointers to the structure holding a nested field, allocating nil pointers unless noAlloc is set.
+// It returns false if a nil pointer wasn't allocated.
+func nestedDest(hops []pointerHop, dst unsafe.Pointer, noAlloc bool) (unsafe.Pointer, bool) {
+	for _, h := range hops {
+		p := (*unsafe.Pointer)(unsafe.Add(dst, h.offset))
+		if *p == nil {
+			if noAlloc {
+				return nil, false
+			}
+			*p = reflect.New(h.elemType).UnsafePointer()
+		}
+		dst = *p
+	}
+	return dst, true
+}
+
+// renamedField returns the name of the destination field the source field is renamed to by the options
+// or, if tags is set, by the `copy` tag of the source field, the options taking precedence.
+func renamedField(f reflect.StructField, opts *CopierOptions, top, tags bool) (string, bool) {
+	if top && opts != nil {
+		if rename, ok := opts.FieldRenames[f.Name]; ok {
+			return rename, true
+		}
+	}
+	if name := copyTagName(f); tags && name != "" {
+		return name, true
+	}
+	return "", false
+}
+
+// sortedKeys returns the keys of the map in a deterministic order.
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// fieldCondition returns the condition deciding whether the source field is copied.
+func fieldCondition(name string, opts *CopierOptions, top bool) (func(interface{}) bool, bool) {
+	if !top || opts == nil {
+		return nil, false
+	}
+	cond, ok := opts.Conditions[name]
+	return cond, ok
+}
+
+// customConverter returns the custom converter for the source field.
+func customConverter(name string, opts *CopierOptions, top bool) (Converter, bool) {
+	if !top || opts == nil {
+		return Converter{}, false
+	}
+	conv, ok := opts.Converters[name]
+	return conv, ok
+}
+
+// converterConv turns a custom converter into a converter for a pair of types. The types have to match those of the custom converter.
+func converterConv(dstType, srcType reflect.Type, c Converter) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	conv, err := converterContextConv(dstType, srcType, c)
+	if err != nil {
+		return nil, err
+	}
+	return withoutContext(conv), nil
+}
+
+// converterContextConv returns a context-aware converter using the custom converter.
+func converterContextConv(dstType, srcType reflect.Type, c Converter) (func(context.Context, unsafe.Pointer, unsafe.Pointer) error, error) {
+	if c.DstType != dstType || c.SrcType != srcType {
+		return nil, serr.Wrap("", ErrUnsupportedTypePair, serr.String("srcType", srcType.String()), serr.String("dstType", dstType.String()),
+			serr.String("convSrcType", c.SrcType.String()), serr.String("convDstType", c.DstType.String()))
+	}
+	f := c.ContextFunc
+	if f == nil {
+		f = func(_ context.Context, x interface{}) (interface{}, error) {
+			return c.Func(x)
+		}
+	}
+	return func(ctx context.Context, dst, src unsafe.Pointer) error {
+		y, err := f(ctx, reflect.NewAt(srcType, src).Elem().Interface())
+		if err != nil {
+			return err
+		}
+		d := reflect.NewAt(dstType, dst).Elem()
+		if y == nil {
+			d.SetZero()
+		} else {
+			d.Set(reflect.ValueOf(y))
+		}
+		return nil
+	}, nil
+}
+
+// validated runs the validation on the destination object after a successful copy.
+func validated(copier func(context.Context, unsafe.Pointer, unsafe.Pointer) error, validate func(context.Context, interface{}) error, dstType, srcType reflect.Type) func(context.Context, unsafe.Pointer, unsafe.Pointer) error {
+	return func(ctx context.Context, dst, src unsafe.Pointer) error {
+		err := copier(ctx, dst, src)
+		if _, skipped := err.(*SkippedElementsError); err != nil && !skipped {
+			return err
+		}
+		if err := validate(ctx, reflect.NewAt(dstType, dst).Interface()); err != nil {
+			return &CopyError{
+				DstType: dstType,
+				SrcType: srcType,
+				Err:     fmt.Errorf("%w: %w", ErrValidation, err),
+			}
+		}
+		return err
 	}
 }
 
+// recovering turns panics in the converter into errors.
+func recovering(conv func(unsafe.Pointer, unsafe.Pointer) error) func(unsafe.Pointer, unsafe.Pointer) error {
+	return func(dst, src unsafe.Pointer) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = serr.Wrap("", ErrPanic, serr.Any("panic", r))
+			}
+		}()
+		return conv(dst, src)
+	}
+}
+
+// recoveringContext turns panics in the context-aware converter into errors.
+func recoveringContext(conv func(context.Context, unsafe.Pointer, unsafe.Pointer) error) func(context.Context, unsafe.Pointer, unsafe.Pointer) error {
+	return func(ctx context.Context, dst, src unsafe.Pointer) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = serr.Wrap("", ErrPanic, serr.Any("panic", r))
+			}
+		}()
+		return conv(ctx, dst, src)
+	}
+}
+
+// allocEstimate estimates the number of heap allocations needed for copying a value of the source type into the destination type.
+func allocEstimate(dstType, srcType reflect.Type) int {
+	if dstType == srcType {
+		return 0
+	}
+	switch dstType.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		return 1
+	case reflect.String:
+		if srcType.Kind() != reflect.String {
+			return 1
+		}
+	}
+	return 0
+}
+
+// typedCopier returns a function copying values of the type.
+// Values containing pointers are copied with the GC's write barriers in place
+// so that it's safe to copy them into heap memory such as the backing arrays of destination slices.
+func typedCopier(t reflect.Type) func(dst, src unsafe.Pointer) {
+	if !hasPointers(t) {
+		size := t.Size()
+		return func(dst, src unsafe.Pointer) {
+			memcopy(dst, src, size)
+		}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return func(dst, src unsafe.Pointer) {
+			*(*string)(dst) = *(*string)(src)
+		}
+	case reflect.Pointer, reflect.UnsafePointer, reflect.Map, reflect.Chan, reflect.Func:
+		return func(dst, src unsafe.Pointer) {
+			*(*unsafe.Pointer)(dst) = *(*unsafe.Pointer)(src)
+		}
+	case reflect.Slice:
+		return func(dst, src unsafe.Pointer) {
+			*(*[]byte)(dst) = *(*[]byte)(src)
+		}
+	}
+	return func(dst, src unsafe.Pointer) {
+		reflect.NewAt(t, dst).Elem().Set(reflect.NewAt(t, src).Elem())
+	}
+}
+
+// hasPointers checks whether values of the type contain pointers.
+func hasPointers(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Pointer, reflect.UnsafePointer, reflect.Map, reflect.Chan, reflect.Func, reflect.Interface, reflect.Slice:
+		return true
+	case reflect.Array:
+		return t.Len() > 0 && hasPointers(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if hasPointers(t.Field(i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // ValueCopier returns a copier for values of any type (provided the pair of types is supported).
 func ValueCopier[D, S any]() (func(*D, *S) error, error) {
 	c, err := valConv(reflect.TypeFor[D](), reflect.TypeFor[S]())
@@ -174,17 +1029,84 @@ func Cast[D, S any](f func(*D, *S) error) Copying[D, S] {
 	return Copying[D, S](f)
 }
 
+// valConv returns a converter for a pair of types.
 func valConv(dstType, srcType reflect.Type) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	return valConvWithOptions(dstType, srcType, nil)
+}
+
+// valConvWithOptions returns a converter for a pair of types with custom options.
+// Converters are memoized by the type pair and the contents of the options so that they are shared by all the copiers using them.
+func valConvWithOptions(dstType, srcType reflect.Type, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	opts = canonicalOptions(opts)
+	if opts != nil && opts.NoCache {
+		return compileValConv(dstType, srcType, opts)
+	}
+	key := copierTypePair{
+		dst:  dstType,
+		src:  srcType,
+		opts: opts,
+	}
+	if conv, ok := cachedValConv(key); ok {
+		return conv, nil
+	}
+	conv, err := compileValConv(dstType, srcType, opts)
+	if err != nil {
+		return nil, err
+	}
+	storeValConv(key, conv)
+	return conv, nil
+}
+
+func compileValConv(dstType, srcType reflect.Type, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	if conv, ok, err := typeConverter(dstType, srcType, opts); ok {
+		return conv, err
+	}
+	if conv, ok := providedConv(dstType, srcType); ok {
+		return conv, nil
+	}
 	dstPtrType := reflect.PointerTo(dstType)
 	srcPtrType := reflect.PointerTo(srcType)
+	onNil := nilHandler(dstType, opts)
 	switch {
-	case dstType == srcType:
-		size := dstType.Size()
+	case opts != nil && opts.ShareSlices && dstType.Kind() == reflect.Slice && srcType.Kind() == reflect.Slice && dstType.Elem() == srcType.Elem():
 		return func(dst, src unsafe.Pointer) error {
-			memcopy(dst, src, size)
+			if reflect.NewAt(srcType, src).Elem().IsNil() {
+				return onNil(dst)
+			}
+			// slices with identical element types have identical headers
+			*(*[]byte)(dst) = *(*[]byte)(src)
+			return nil
+		}, nil
+
+	case opts != nil && opts.DeepCopy && dstType == srcType && hasPointers(dstType):
+		return deepConv(dstType, opts)
+
+	case adjustsTimes(opts) && dstType == types.Time && srcType == types.Time:
+		return func(dst, src unsafe.Pointer) error {
+			*(*time.Time)(dst) = adjustTime(*(*time.Time)(src), opts)
+			return nil
+		}, nil
+
+	case adjustsTimes(opts) && dstType == types.TimePtr && srcType == types.TimePtr:
+		return func(dst, src unsafe.Pointer) error {
+			if t := *(**time.Time)(src); t != nil {
+				*(**time.Time)(dst) = pointer.To(adjustTime(*t, opts))
+				return nil
+			}
+			return onNil(dst)
+		}, nil
+
+	case dstType == srcType && !convertsElements(dstType, opts):
+		cp := typedCopier(dstType)
+		return func(dst, src unsafe.Pointer) error {
+			cp(dst, src)
 			return nil
 		}, nil
 
+	case hasContainerRecipe(dstType, srcType):
+		recipe, _ := containerRecipe(dstType, srcType)
+		return containerConv(dstType, srcType, recipe, opts), nil
+
 	case dstType.Kind() == reflect.String && srcType.Kind() == reflect.String && dstType.Implements(types.ClosedEnum):
 		validator := func(x string) error {
 			e := reflect.ValueOf(x).Convert(dstType).Interface().(enums.ClosedEnum)
@@ -193,28 +1115,112 @@ func valConv(dstType, srcType reflect.Type) (func(unsafe.Pointer, unsafe.Pointer
 			}
 			return serr.New("bad value for closed enum", serr.String("value", x), serr.String("dstType", dstType.Name()))
 		}
+		fallback := enumFallback(dstType, opts)
 		return func(dst, src unsafe.Pointer) error {
 			x := (*string)(src)
 			if err := validator(*x); err != nil {
+				if fallback != nil {
+					fallback(dst, *x)
+					return nil
+				}
+				return err
+			}
+			y := (*string)(dst)
+			*y = *x
+			return nil
+		}, nil
+
+	case isIntEnum(dstType) && isInteger(srcType):
+		return intEnumFromIntConv(dstType, srcType, opts), nil
+
+	case isIntEnum(dstType) && srcType.Kind() == reflect.String:
+		return intEnumFromStringConv(dstType, srcType, opts)
+
+	case isIntEnum(srcType) && dstType.Kind() == reflect.String:
+		return intEnumToStringConv(dstType, srcType)
+
+	case srcType == anyPtrType:
+		return anyUnpackConv(dstType, opts), nil
+
+	case dstType == anyPtrType && srcType.Implements(protoMessageType):
+		return anyPackConv(srcType, opts), nil
+
+	case isNullable(srcType):
+		return fromNullableConv(dstType, nullableTypes[srcType], opts)
+
+	case isNullable(dstType):
+		return toNullableConv(nullableTypes[dstType], srcType, opts)
+
+	case opts != nil && opts.TimeStrings && srcType == types.Date && dstType == types.String:
+		return func(dst, src unsafe.Pointer) error {
+			*(*string)(dst) = (*(*date.Date)(src)).String()
+			return nil
+		}, nil
+
+	case opts != nil && opts.TimeStrings && dstType == types.Date && srcType == types.String:
+		return func(dst, src unsafe.Pointer) error {
+			x := *(*string)(src)
+			if x == "" {
+				return onNil(dst)
+			}
+			d, err := date.ParseISO(x)
+			if err != nil {
+				return err
+			}
+			*(*date.Date)(dst) = d
+			return nil
+		}, nil
+
+	case srcType == types.StructpbPtr && isPBRecord(dstType):
+		return structpbToStructConv(dstType, opts)
+
+	case dstType == types.StructpbPtr && isPBRecord(srcType):
+		return structToStructpbConv(srcType, opts)
+
+	case srcType == periodOfDaysType && dstType == types.String:
+		return func(dst, src unsafe.Pointer) error {
+			*(*string)(dst) = formatPeriodOfDays(*(*timespan.PeriodOfDays)(src))
+			return nil
+		}, nil
+
+	case dstType == periodOfDaysType && srcType == types.String:
+		return func(dst, src unsafe.Pointer) error {
+			x := *(*string)(src)
+			if x == "" {
+				return onNil(dst)
+			}
+			p, err := parsePeriodOfDays(x)
+			if err != nil {
 				return err
 			}
-			y := (*string)(dst)
-			*y = *x
+			*(*timespan.PeriodOfDays)(dst) = p
 			return nil
 		}, nil
 
-	case srcPtrType.ConvertibleTo(dstPtrType):
+	case srcPtrType.ConvertibleTo(dstPtrType) && !convertsElements(srcType, opts):
+		cp := typedCopier(dstType)
 		return func(dst, src unsafe.Pointer) error {
 			converted := reflect.NewAt(srcType, src).Convert(dstPtrType)
-			copy(unsafe.Slice((*byte)(dst), dstType.Size()), unsafe.Slice((*byte)(converted.UnsafePointer()), dstType.Size()))
+			cp(dst, converted.UnsafePointer())
+			return nil
+		}, nil
+
+	case opts != nil && opts.StrictNumeric && isNumber(dstType) && isNumber(srcType):
+		return func(dst, src unsafe.Pointer) error {
+			v := reflect.NewAt(srcType, src).Elem()
+			if !representable(v, dstType) {
+				return serr.Wrap("", ErrPrecisionLoss, serr.Any("value", v.Interface()), serr.String("dstType", dstType.Name()))
+			}
+			reflect.NewAt(dstType, dst).Elem().Set(v.Convert(dstType))
 			return nil
 		}, nil
 
-	case srcType.ConvertibleTo(dstType):
+	case srcType.ConvertibleTo(dstType) && !convertsElements(srcType, opts):
+		cp := typedCopier(dstType)
 		return func(dst, src unsafe.Pointer) error {
 			converted := reflect.NewAt(srcType, src).Elem().Convert(dstType)
 			if converted.CanAddr() {
-				copy(unsafe.Slice((*byte)(dst), dstType.Size()), unsafe.Slice((*byte)(converted.Addr().UnsafePointer()), dstType.Size()))
+				cp(dst, converted.Addr().UnsafePointer())
 			} else {
 				reflect.NewAt(dstType, dst).Elem().Set(converted)
 			}
@@ -223,7 +1229,7 @@ func valConv(dstType, srcType reflect.Type) (func(unsafe.Pointer, unsafe.Pointer
 
 	case srcType == types.Time && dstType == types.TimestampPtr:
 		return func(dst, src unsafe.Pointer) error {
-			t := *(*time.Time)(src)
+			t := truncateTime(*(*time.Time)(src), opts)
 			*(**timestamppb.Timestamp)(dst) = timestamppb.New(t)
 			return nil
 		}, nil
@@ -231,24 +1237,77 @@ func valConv(dstType, srcType reflect.Type) (func(unsafe.Pointer, unsafe.Pointer
 	case srcType == types.TimePtr && dstType == types.TimestampPtr:
 		return func(dst, src unsafe.Pointer) error {
 			if t := *(**time.Time)(src); t != nil {
-				*(**timestamppb.Timestamp)(dst) = timestamppb.New(*t)
+				*(**timestamppb.Timestamp)(dst) = timestamppb.New(truncateTime(*t, opts))
+				return nil
 			}
-			return nil
+			return onNil(dst)
 		}, nil
 
 	case dstType == types.Time && srcType == types.TimestampPtr:
+		loc := timeLocation(opts)
 		return func(dst, src unsafe.Pointer) error {
 			if ts := *(**timestamppb.Timestamp)(src); ts.IsValid() {
-				*(*time.Time)(dst) = ts.AsTime()
+				*(*time.Time)(dst) = truncateTime(ts.AsTime(), opts).In(loc)
+				return nil
 			}
-			return nil
+			return onNil(dst)
 		}, nil
 
 	case dstType == types.TimePtr && srcType == types.TimestampPtr:
+		loc := timeLocation(opts)
+		return func(dst, src unsafe.Pointer) error {
+			if ts := *(**timestamppb.Timestamp)(src); ts.IsValid() {
+				*(**time.Time)(dst) = pointer.To(truncateTime(ts.AsTime(), opts).In(loc))
+				return nil
+			}
+			return onNil(dst)
+		}, nil
+
+	case opts != nil && opts.TimeStrings && srcType == types.Time && dstType == types.String:
+		layout := timeLayout(opts)
+		return func(dst, src unsafe.Pointer) error {
+			*(*string)(dst) = adjustTime(*(*time.Time)(src), opts).Format(layout)
+			return nil
+		}, nil
+
+	case opts != nil && opts.TimeStrings && dstType == types.Time && srcType == types.String:
+		layout := timeLayout(opts)
+		return func(dst, src unsafe.Pointer) error {
+			x := *(*string)(src)
+			if x == "" {
+				return onNil(dst)
+			}
+			t, err := time.Parse(layout, x)
+			if err != nil {
+				return err
+			}
+			*(*time.Time)(dst) = adjustTime(t, opts)
+			return nil
+		}, nil
+
+	case opts != nil && opts.TimeStrings && srcType == types.TimestampPtr && dstType == types.String:
+		layout := timeLayout(opts)
+		loc := timeLocation(opts)
 		return func(dst, src unsafe.Pointer) error {
 			if ts := *(**timestamppb.Timestamp)(src); ts.IsValid() {
-				*(**time.Time)(dst) = pointer.To(ts.AsTime())
+				*(*string)(dst) = truncateTime(ts.AsTime(), opts).In(loc).Format(layout)
+				return nil
+			}
+			return onNil(dst)
+		}, nil
+
+	case opts != nil && opts.TimeStrings && dstType == types.TimestampPtr && srcType == types.String:
+		layout := timeLayout(opts)
+		return func(dst, src unsafe.Pointer) error {
+			x := *(*string)(src)
+			if x == "" {
+				return onNil(dst)
+			}
+			t, err := time.Parse(layout, x)
+			if err != nil {
+				return err
 			}
+			*(**timestamppb.Timestamp)(dst) = timestamppb.New(truncateTime(t, opts))
 			return nil
 		}, nil
 
@@ -260,13 +1319,38 @@ func valConv(dstType, srcType reflect.Type) (func(unsafe.Pointer, unsafe.Pointer
 		}, nil
 
 	case srcType == types.TimestampPtr && dstType == types.Date:
+		loc := dateLocation(opts)
 		return func(dst, src unsafe.Pointer) error {
 			if ts := *(**timestamppb.Timestamp)(src); ts.IsValid() {
-				*(*date.Date)(dst) = date.NewAt(ts.AsTime())
+				*(*date.Date)(dst) = date.NewAt(ts.AsTime().In(loc))
+				return nil
+			}
+			return onNil(dst)
+		}, nil
+
+	case srcType == types.Time && dstType == types.Date:
+		return func(dst, src unsafe.Pointer) error {
+			t := *(*time.Time)(src)
+			if opts != nil && opts.DateLocation != nil {
+				t = t.In(opts.DateLocation)
 			}
+			*(*date.Date)(dst) = date.NewAt(t)
 			return nil
 		}, nil
 
+	case srcType == types.Date && dstType == types.Time:
+		loc := dateLocation(opts)
+		return func(dst, src unsafe.Pointer) error {
+			*(*time.Time)(dst) = (*(*date.Date)(src)).MidnightIn(loc)
+			return nil
+		}, nil
+
+	case srcType == dateRangeType && isRangeStruct(dstType):
+		return fromDateRangeConv(dstType, opts)
+
+	case dstType == dateRangeType && isRangeStruct(srcType):
+		return toDateRangeConv(srcType, opts)
+
 	case srcType == types.Date && dstType == types.TimestampPtr:
 		return func(dst, src unsafe.Pointer) error {
 			x := *(*date.Date)(src)
@@ -277,7 +1361,7 @@ func valConv(dstType, srcType reflect.Type) (func(unsafe.Pointer, unsafe.Pointer
 	case dstType == types.UUID && srcType == types.String:
 		return func(dst, src unsafe.Pointer) error {
 			x := *(*string)(src)
-			u, err := uuid.Parse(x)
+			u, err := parseAnyUUID(x)
 			if err != nil {
 				return err
 			}
@@ -295,7 +1379,7 @@ func valConv(dstType, srcType reflect.Type) (func(unsafe.Pointer, unsafe.Pointer
 	case dstType == types.ULID && srcType == types.String:
 		return func(dst, src unsafe.Pointer) error {
 			x := *(*string)(src)
-			u, err := ulid.Parse(x)
+			u, err := parseAnyULID(x)
 			if err != nil {
 				return err
 			}
@@ -304,6 +1388,13 @@ func valConv(dstType, srcType reflect.Type) (func(unsafe.Pointer, unsafe.Pointer
 		}, nil
 
 	case srcType == types.Decimal && dstType == types.String:
+		if opts != nil && opts.DecimalFormat != nil {
+			format := *opts.DecimalFormat
+			return func(dst, src unsafe.Pointer) error {
+				*(*string)(dst) = format.format(*(*decimal.Decimal)(src))
+				return nil
+			}, nil
+		}
 		return func(dst, src unsafe.Pointer) error {
 			x := (*decimal.Decimal)(src)
 			*(*string)(dst) = x.String()
@@ -323,17 +1414,92 @@ func valConv(dstType, srcType reflect.Type) (func(unsafe.Pointer, unsafe.Pointer
 			return nil
 		}, nil
 
+	case dstType == types.Decimal && isFloat(srcType):
+		var rounding *DecimalRounding
+		if opts != nil {
+			rounding = opts.DecimalRounding
+		}
+		strict := opts != nil && opts.StrictNumeric
+		return func(dst, src unsafe.Pointer) error {
+			x := reflect.NewAt(srcType, src).Elem().Float()
+			if math.IsNaN(x) || math.IsInf(x, 0) {
+				if !strict {
+					// Decimals have no such values so they're handled like missing ones.
+					return onNil(dst)
+				}
+				return serr.Wrap("", ErrPrecisionLoss, serr.Any("value", x), serr.String("dstType", dstType.Name()))
+			}
+			var d decimal.Decimal
+			if srcType.Kind() == reflect.Float32 {
+				d = decimal.NewFromFloat32(float32(x))
+			} else {
+				d = decimal.NewFromFloat(x)
+			}
+			if rounding != nil {
+				d = rounding.round(d)
+			}
+			*(*decimal.Decimal)(dst) = d
+			return nil
+		}, nil
+
+	case srcType == types.Decimal && isFloat(dstType):
+		strict := opts != nil && opts.StrictNumeric
+		var rounding *DecimalRounding
+		if opts != nil {
+			rounding = opts.DecimalRounding
+		}
+		return func(dst, src unsafe.Pointer) error {
+			x := *(*decimal.Decimal)(src)
+			if rounding != nil {
+				x = rounding.round(x)
+			}
+			f, exact := x.Float64()
+			if strict && (!exact || math.IsInf(f, 0) || dstType.Kind() == reflect.Float32 && float64(float32(f)) != f) {
+				return serr.Wrap("", ErrPrecisionLoss, serr.String("value", x.String()), serr.String("dstType", dstType.Name()))
+			}
+			reflect.NewAt(dstType, dst).Elem().SetFloat(f)
+			return nil
+		}, nil
+
+	case opts != nil && opts.MinorUnitExponent > 0 && dstType == types.Decimal && srcType.Kind() == reflect.Int64:
+		exp := opts.MinorUnitExponent
+		return func(dst, src unsafe.Pointer) error {
+			*(*decimal.Decimal)(dst) = decimal.New(*(*int64)(src), -exp)
+			return nil
+		}, nil
+
+	case opts != nil && opts.MinorUnitExponent > 0 && srcType == types.Decimal && dstType.Kind() == reflect.Int64:
+		exp := opts.MinorUnitExponent
+		var rounding *DecimalRounding
+		if opts.DecimalRounding != nil {
+			rounding = &DecimalRounding{Mode: opts.DecimalRounding.Mode, MaxScale: exp}
+		}
+		return func(dst, src unsafe.Pointer) error {
+			x := *(*decimal.Decimal)(src)
+			if rounding != nil {
+				x = rounding.round(x)
+			}
+			units := x.Shift(exp)
+			if !units.IsInteger() || !units.BigInt().IsInt64() {
+				return serr.Wrap("", ErrPrecisionLoss, serr.String("value", x.String()), serr.String("dstType", dstType.Name()))
+			}
+			*(*int64)(dst) = units.IntPart()
+			return nil
+		}, nil
+
 	case srcType == types.LanguageTag && dstType == types.String:
+		normalize := languageNormalizer(opts)
 		return func(dst, src unsafe.Pointer) error {
-			x := (*language.Tag)(src)
-			*(*string)(dst) = x.String()
+			x := *(*language.Tag)(src)
+			*(*string)(dst) = normalize(x).String()
 			return nil
 		}, nil
 
 	case dstType == types.LanguageTag && srcType == types.String:
+		parse := languageParser(opts)
 		return func(dst, src unsafe.Pointer) error {
 			x := *(*string)(src)
-			t, err := language.Parse(x)
+			t, err := parse(x)
 			if err != nil {
 				return err
 			}
@@ -341,17 +1507,20 @@ func valConv(dstType, srcType reflect.Type) (func(unsafe.Pointer, unsafe.Pointer
 			return nil
 		}, nil
 
+	case srcType.Kind() == reflect.Interface && dstType.Kind() != reflect.Interface:
+		return ifaceConv(dstType, srcType, opts), nil
+
 	case srcType.Implements(types.Copiable):
 		if !reflect.Zero(srcType).Interface().(iface.Copiable).CanCopyTo(dstType) {
 			return nil, serr.New("can't copy", serr.String("srcType", srcType.Name()), serr.String("dstType", dstType.Name()))
 		}
-		dstSize := dstType.Size()
+		cp := typedCopier(dstType)
 		return func(dst, src unsafe.Pointer) error {
+			if *(*unsafe.Pointer)(src) == nil {
+				return onNil(dst)
+			}
 			ptr := reflect.NewAt(srcType.Elem(), *(*unsafe.Pointer)(src)).Interface().(iface.Copiable).Copy(dstType)
-			copy(
-				unsafe.Slice((*byte)(dst), dstSize),
-				unsafe.Slice((*byte)(ptr), dstSize),
-			)
+			cp(dst, ptr)
 			return nil
 		}, nil
 
@@ -363,24 +1532,53 @@ func valConv(dstType, srcType reflect.Type) (func(unsafe.Pointer, unsafe.Pointer
 				return nil
 			}, nil
 		}
-		elConv, err := valConv(dstElType, srcElType)
+		elConv, err := valConvWithOptions(dstElType, srcElType, opts)
 		if err != nil {
 			return nil, err
 		}
 		return func(dst, src unsafe.Pointer) error {
 			if p := *(*unsafe.Pointer)(src); p != nil {
 				newPtr := reflect.New(dstElType).UnsafePointer()
-				if err := elConv(newPtr, p); err != nil {
+				err := elConv(newPtr, p)
+				if _, skipped := err.(*SkippedElementsError); err != nil && !skipped {
 					return err
 				}
 				*(*unsafe.Pointer)(dst) = newPtr
+				return err
 			}
-			return nil
+			return onNil(dst)
 		}, nil
 
+	case isSet(srcType) && dstType.Kind() == reflect.Slice:
+		return setToSliceConv(dstType, srcType, opts)
+
+	case isSet(dstType) && srcType.Kind() == reflect.Slice:
+		return sliceToSetConv(dstType, srcType, opts)
+
+	case srcType.Kind() == reflect.Map && dstType.Kind() == reflect.Slice && isMapEntry(dstType.Elem()):
+		return mapToEntriesConv(dstType, srcType, opts)
+
+	case dstType.Kind() == reflect.Map && srcType.Kind() == reflect.Slice && isMapEntry(srcType.Elem()):
+		return entriesToMapConv(dstType, srcType, opts)
+
 	case srcType.Kind() == reflect.Slice && dstType.Kind() == reflect.Slice:
 		dstElType, srcElType := dstType.Elem(), srcType.Elem()
-		elConv, err := valConv(dstElType, srcElType)
+		reuse := opts != nil && opts.ReuseSlices
+		if copiedBytewise(dstElType, srcElType, opts) {
+			elSize := dstElType.Size()
+			return func(dst, src unsafe.Pointer) error {
+				srcSlice := reflect.NewAt(srcType, src).Elem()
+				if srcSlice.IsNil() {
+					return onNil(dst)
+				}
+				len := srcSlice.Len()
+				dstSlice := destSlice(dstType, dst, len, reuse)
+				copy(unsafe.Slice((*byte)(dstSlice.UnsafePointer()), uintptr(len)*elSize), unsafe.Slice((*byte)(srcSlice.UnsafePointer()), uintptr(len)*elSize))
+				reflect.NewAt(dstType, dst).Elem().Set(dstSlice)
+				return nil
+			}, nil
+		}
+		elConv, err := valConvWithOptions(dstElType, srcElType, opts)
 		if err != nil {
 			return nil, err
 		}
@@ -389,20 +1587,27 @@ func valConv(dstType, srcType reflect.Type) (func(unsafe.Pointer, unsafe.Pointer
 		return func(dst, src unsafe.Pointer) error {
 			srcSlice := reflect.NewAt(srcType, src).Elem()
 			if srcSlice.IsNil() {
-				return nil
+				return onNil(dst)
 			}
 			len := srcSlice.Len()
-			dstSlice := reflect.MakeSlice(dstType, len, len)
+			dstSlice := destSlice(dstType, dst, len, reuse)
 			srcPtr := srcSlice.UnsafePointer()
 			dstPtr := dstSlice.UnsafePointer()
-			for i := 0; i < len; i++ {
-				if err := elConv(dstPtr, srcPtr); err != nil {
-					return err
+			var skipped *SkippedElementsError
+			for i := uintptr(0); i < uintptr(len); i++ {
+				if err := elConv(unsafe.Add(dstPtr, i*dstElSize), unsafe.Add(srcPtr, i*srcElSize)); err != nil {
+					if se, ok := err.(*SkippedElementsError); ok {
+						// the element is kept and its skipped elements are reported
+						skipped = addSkipped(skipped, se, strconv.Itoa(int(i)))
+						continue
+					}
+					return elementError(err, int(i), dstElType, srcElType)
 				}
-				dstPtr = unsafe.Add(dstPtr, dstElSize)
-				srcPtr = unsafe.Add(srcPtr, srcElSize)
 			}
 			reflect.NewAt(dstType, dst).Elem().Set(dstSlice)
+			if skipped != nil {
+				return skipped
+			}
 			return nil
 		}, nil
 
@@ -414,11 +1619,12 @@ func valConv(dstType, srcType reflect.Type) (func(unsafe.Pointer, unsafe.Pointer
 				if x := x.GetPtr(); x != nil {
 					x := *(*time.Time)(x)
 					*(**timestamppb.Timestamp)(dst) = timestamppb.New(x)
+					return nil
 				}
-				return nil
+				return onNil(dst)
 			}, nil
 		}
-		conv, err := valConv(dstType.Elem(), maybeType)
+		conv, err := valConvWithOptions(dstType.Elem(), maybeType, opts)
 		if err != nil {
 			return nil, err
 		}
@@ -426,37 +1632,43 @@ func valConv(dstType, srcType reflect.Type) (func(unsafe.Pointer, unsafe.Pointer
 			x := reflect.NewAt(srcType, src).Interface().(maybe.Iface)
 			if x := x.GetPtr(); x != nil {
 				v := reflect.New(dstType.Elem())
-				if err := conv(v.UnsafePointer(), x); err != nil {
+				err := conv(v.UnsafePointer(), x)
+				if _, skipped := err.(*SkippedElementsError); err != nil && !skipped {
 					return err
 				}
 				*(*unsafe.Pointer)(dst) = v.UnsafePointer()
+				return err
 			}
-			return nil
+			return onNil(dst)
 		}, nil
 
 	case dstPtrType.Implements(types.Maybe) && srcType.Kind() == reflect.Pointer:
 		maybeType := reflect.Zero(dstPtrType).Interface().(maybe.Iface).MaybeType()
 		if srcType == types.TimestampPtr && maybeType == types.Time {
+			loc := timeLocation(opts)
 			return func(dst, src unsafe.Pointer) error {
 				x := *(**timestamppb.Timestamp)(src)
 				if x.IsValid() {
 					y := reflect.NewAt(dstType, dst).Interface().(maybe.Iface)
-					y.SetPtr(unsafe.Pointer(pointer.To(x.AsTime())))
+					y.SetPtr(unsafe.Pointer(pointer.To(truncateTime(x.AsTime(), opts).In(loc))))
+					return nil
 				}
-				return nil
+				return onNil(dst)
 			}, nil
 		}
 		if srcType == types.TimestampPtr && maybeType == types.Date {
+			loc := dateLocation(opts)
 			return func(dst, src unsafe.Pointer) error {
 				x := *(**timestamppb.Timestamp)(src)
 				if x.IsValid() {
 					y := reflect.NewAt(dstType, dst).Interface().(maybe.Iface)
-					y.SetPtr(unsafe.Pointer(pointer.To(date.NewAt(x.AsTime()))))
+					y.SetPtr(unsafe.Pointer(pointer.To(date.NewAt(x.AsTime().In(loc)))))
+					return nil
 				}
-				return nil
+				return onNil(dst)
 			}, nil
 		}
-		conv, err := valConv(maybeType, srcType.Elem())
+		conv, err := valConvWithOptions(maybeType, srcType.Elem(), opts)
 		if err != nil {
 			return nil, err
 		}
@@ -468,13 +1680,14 @@ func valConv(dstType, srcType reflect.Type) (func(unsafe.Pointer, unsafe.Pointer
 				}
 				y := reflect.NewAt(dstType, dst).Interface().(maybe.Iface)
 				y.SetPtr(v.UnsafePointer())
+				return nil
 			}
-			return nil
+			return onNil(dst)
 		}, nil
 
 	case dstPtrType.Implements(types.Maybe) && srcType.Kind() != reflect.Pointer:
 		maybeType := reflect.Zero(dstPtrType).Interface().(maybe.Iface).MaybeType()
-		conv, err := valConv(maybeType, srcType)
+		conv, err := valConvWithOptions(maybeType, srcType, opts)
 		if err != nil {
 			return nil, err
 		}
@@ -490,9 +1703,23 @@ func valConv(dstType, srcType reflect.Type) (func(unsafe.Pointer, unsafe.Pointer
 			return nil
 		}, nil
 
+	case srcPtrType.Implements(types.Maybe) && dstType.Kind() != reflect.Pointer:
+		maybeType := reflect.Zero(srcPtrType).Interface().(maybe.Iface).MaybeType()
+		conv, err := valConvWithOptions(dstType, maybeType, opts)
+		if err != nil {
+			return nil, err
+		}
+		return func(dst, src unsafe.Pointer) error {
+			x := reflect.NewAt(srcType, src).Interface().(maybe.Iface)
+			if x := x.GetPtr(); x != nil {
+				return conv(dst, x)
+			}
+			return onNil(dst)
+		}, nil
+
 	case srcPtrType.Implements(types.Required):
 		reqType := reflect.Zero(srcPtrType).Interface().(validate.RequiredIface).RequiredType()
-		conv, err := valConv(dstType, reqType)
+		conv, err := valConvWithOptions(dstType, reqType, opts)
 		if err != nil {
 			return nil, err
 		}
@@ -506,21 +1733,26 @@ func valConv(dstType, srcType reflect.Type) (func(unsafe.Pointer, unsafe.Pointer
 		}, nil
 
 	case dstType.Kind() == reflect.Pointer:
-		conv, err := valConv(dstType.Elem(), srcType)
+		conv, err := valConvWithOptions(dstType.Elem(), srcType, opts)
 		if err != nil {
 			return nil, err
 		}
+		skipZero := opts != nil && opts.NilPolicy == NilSkipZero
 		return func(dst, src unsafe.Pointer) error {
 			v := reflect.New(dstType.Elem())
-			if err := conv(v.UnsafePointer(), src); err != nil {
-				return nil
+			err := conv(v.UnsafePointer(), src)
+			if _, skipped := err.(*SkippedElementsError); err != nil && !skipped {
+				if skipZero && reflect.NewAt(srcType, src).Elem().IsZero() {
+					return nil
+				}
+				return err
 			}
 			*(*unsafe.Pointer)(dst) = v.UnsafePointer()
-			return nil
+			return err
 		}, nil
 
 	case srcType.Kind() == reflect.Pointer:
-		conv, err := valConv(dstType, srcType.Elem())
+		conv, err := valConvWithOptions(dstType, srcType.Elem(), opts)
 		if err != nil {
 			return nil, err
 		}
@@ -528,11 +1760,11 @@ func valConv(dstType, srcType reflect.Type) (func(unsafe.Pointer, unsafe.Pointer
 			if x := *(*unsafe.Pointer)(src); x != nil {
 				return conv(dst, x)
 			}
-			return nil
+			return onNil(dst)
 		}, nil
 
 	case dstType.Kind() == reflect.Struct && srcType.Kind() == reflect.Struct:
-		copier, err := CopierForPair(dstType, srcType)
+		copier, err := nestedCopier(dstType, srcType, opts)
 		if err != nil {
 			return nil, err
 		}
@@ -541,19 +1773,17 @@ func valConv(dstType, srcType reflect.Type) (func(unsafe.Pointer, unsafe.Pointer
 		}, nil
 
 	case dstType == dynmapType && srcType.Kind() == reflect.Struct:
-		fm := make(map[string][]int)
-		for _, f := range reflect.VisibleFields(srcType) {
-			if f.PkgPath != "" {
-				continue
-			}
-			if f.Tag.Get("kv") == "-" {
-				continue
-			}
-			key := f.Name
-			if k := f.Tag.Get("key"); k != "" {
-				key = k
+		fm := dynmapFields(srcType)
+		var norms map[string]func(reflect.Value) (interface{}, error)
+		if opts != nil && opts.NormalizeMaps {
+			norms = make(map[string]func(reflect.Value) (interface{}, error), len(fm))
+			for k, idx := range fm {
+				norm, err := mapNormalizer(srcType.FieldByIndex(idx).Type, opts)
+				if err != nil {
+					return nil, err
+				}
+				norms[k] = norm
 			}
-			fm[key] = f.Index
 		}
 		return func(dst, src unsafe.Pointer) error {
 			mv := reflect.NewAt(dstType, dst).Elem()
@@ -563,26 +1793,22 @@ func valConv(dstType, srcType reflect.Type) (func(unsafe.Pointer, unsafe.Pointer
 			m := mv.Interface().(map[string]interface{})
 			v := reflect.NewAt(srcType, src).Elem()
 			for k, idx := range fm {
-				m[k] = v.FieldByIndex(idx).Interface()
+				f := v.FieldByIndex(idx)
+				if norm := norms[k]; norm != nil {
+					x, err := norm(f)
+					if err != nil {
+						return fieldError(err, srcType.FieldByIndex(idx).Name, dstType, f.Type())
+					}
+					m[k] = x
+					continue
+				}
+				m[k] = f.Interface()
 			}
 			return nil
 		}, nil
 
 	case srcType == dynmapType && dstType.Kind() == reflect.Struct:
-		fm := make(map[string][]int)
-		for _, f := range reflect.VisibleFields(dstType) {
-			if f.PkgPath != "" {
-				continue
-			}
-			if f.Tag.Get("kv") == "-" {
-				continue
-			}
-			key := f.Name
-			if k := f.Tag.Get("key"); k != "" {
-				key = k
-			}
-			fm[key] = f.Index
-		}
+		desc := describeType(dstType)
 		return func(dst, src unsafe.Pointer) error {
 			s := reflect.NewAt(dstType, dst).Elem()
 			mv := reflect.NewAt(srcType, src).Elem()
@@ -590,8 +1816,8 @@ func valConv(dstType, srcType reflect.Type) (func(unsafe.Pointer, unsafe.Pointer
 				mv.Set(reflect.MakeMap(srcType))
 			}
 			m := mv.Interface().(map[string]interface{})
-			for k, idx := range fm {
-				x, ok := m[k]
+			for k, idx := range desc.keys {
+				x, ok := lookupKey(m, k, desc.aliases[k])
 				if !ok {
 					return serr.New("missing field in structure for key", serr.String("key", k))
 				}
@@ -610,19 +1836,292 @@ func valConv(dstType, srcType reflect.Type) (func(unsafe.Pointer, unsafe.Pointer
 	}
 }
 
-func fieldCopier(dstType, srcType reflect.Type, dstOffset, srcOffset uintptr) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
-	conv, err := valConv(dstType, srcType)
+// dynmapFields returns the indices of the exported fields of the structure keyed by their dynamic map keys.
+// The key is given by the `key` tag and defaults to the field name. The tag may list alternative keys after
+// the canonical one, e.g. `key:"customer_id,customerId,cid"`, which are accepted when copying maps into structures
+// while structures are copied into maps under the canonical keys. The returned map is shared and mustn't be modified.
+func dynmapFields(t reflect.Type) map[string][]int {
+	return describeType(t).keys
+}
+
+// adjustsTimes checks whether the options change copied times.
+func adjustsTimes(opts *CopierOptions) bool {
+	return opts != nil && (opts.TimeLocation != nil || opts.StripMonotonic || opts.TimePrecision > 0)
+}
+
+// adjustTime converts the time into the location and truncates it according to the options.
+func adjustTime(t time.Time, opts *CopierOptions) time.Time {
+	t = truncateTime(t, opts)
+	if opts != nil && opts.TimeLocation != nil {
+		t = t.In(opts.TimeLocation)
+	}
+	return t
+}
+
+// truncateTime truncates the time to the precision given by the options and strips its monotonic clock reading
+// if the options call for it. Truncated times have no monotonic clock readings either.
+func truncateTime(t time.Time, opts *CopierOptions) time.Time {
+	switch {
+	case opts == nil:
+		return t
+	case opts.TimePrecision > 0:
+		return t.Truncate(opts.TimePrecision)
+	case opts.StripMonotonic:
+		return t.Round(0)
+	}
+	return t
+}
+
+// timeLocation returns the location times are converted into. Times converted from protobuf timestamps are in UTC by default.
+func timeLocation(opts *CopierOptions) *time.Location {
+	if opts != nil && opts.TimeLocation != nil {
+		return opts.TimeLocation
+	}
+	return time.UTC
+}
+
+// timeLayout returns the layout of times converted from and to strings.
+func timeLayout(opts *CopierOptions) string {
+	if opts != nil && opts.TimeLayout != "" {
+		return opts.TimeLayout
+	}
+	return time.RFC3339Nano
+}
+
+// dateLocation returns the location in which protobuf timestamps are truncated to dates.
+func dateLocation(opts *CopierOptions) *time.Location {
+	if opts != nil && opts.DateLocation != nil {
+		return opts.DateLocation
+	}
+	return time.UTC
+}
+
+// isNumber checks whether the type is an integer or a floating-point type.
+func isNumber(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// representable checks whether the number converted to the numeric type keeps its value, including its sign.
+func representable(v reflect.Value, t reflect.Type) bool {
+	dst := reflect.Zero(t)
+	switch {
+	case v.CanInt():
+		x := v.Int()
+		switch {
+		case dst.CanInt():
+			return !dst.OverflowInt(x)
+		case dst.CanUint():
+			return x >= 0 && !dst.OverflowUint(uint64(x))
+		}
+		f := float64(x)
+		if t.Kind() == reflect.Float32 {
+			f = float64(float32(f))
+		}
+		return f >= -(1<<63) && f < 1<<63 && int64(f) == x
+	case v.CanUint():
+		x := v.Uint()
+		switch {
+		case dst.CanInt():
+			return x <= math.MaxInt64 && !dst.OverflowInt(int64(x))
+		case dst.CanUint():
+			return !dst.OverflowUint(x)
+		}
+		f := float64(x)
+		if t.Kind() == reflect.Float32 {
+			f = float64(float32(f))
+		}
+		return f < 1<<64 && uint64(f) == x
+	}
+	f := v.Float()
+	switch {
+	case dst.CanInt():
+		bits := t.Bits()
+		return f == math.Trunc(f) && f >= -math.Ldexp(1, bits-1) && f < math.Ldexp(1, bits-1)
+	case dst.CanUint():
+		return f == math.Trunc(f) && f >= 0 && f < math.Ldexp(1, t.Bits())
+	}
+	return t.Kind() == reflect.Float64 || math.IsNaN(f) || float64(float32(f)) == f
+}
+
+// isFloat checks whether the type is a floating-point type.
+func isFloat(t reflect.Type) bool {
+	return t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64
+}
+
+// nilHandler returns the function handling nil source values according to the nil policy.
+func nilHandler(dstType reflect.Type, opts *CopierOptions) func(unsafe.Pointer) error {
+	policy := NilSkip
+	if opts != nil {
+		policy = opts.NilPolicy
+	}
+	switch policy {
+	case NilZero:
+		return func(dst unsafe.Pointer) error {
+			reflect.NewAt(dstType, dst).Elem().SetZero()
+			return nil
+		}
+	case NilError:
+		return func(unsafe.Pointer) error {
+			return serr.Wrap("", ErrNilSource, serr.String("dstType", dstType.String()))
+		}
+	}
+	return func(unsafe.Pointer) error {
+		return nil
+	}
+}
+
+// checkRenames fails if a source field renamed by the options doesn't exist unless OmitNotFound is set
+// so that renames left behind by changes of the source structure don't go unnoticed.
+func checkRenames(dstType, srcType reflect.Type, opts *CopierOptions, top bool) error {
+	if !top || opts == nil || opts.OmitNotFound {
+		return nil
+	}
+	for _, name := range sortedKeys(opts.FieldRenames) {
+		if f, ok := srcType.FieldByName(name); !ok || !f.IsExported() {
+			return &CopyError{
+				DstType:   dstType,
+				SrcType:   srcType,
+				FieldPath: []string{name},
+				Err:       serr.Wrap("", ErrFieldNotFound, serr.String("srcField", name), serr.String("srcType", srcType.Name())),
+			}
+		}
+	}
+	return nil
+}
+
+// checkAmbiguity fails if a field name which is ambiguous in the source structure exists in the destination structure.
+// Fields which aren't copied because of the options are ignored.
+func checkAmbiguity(dstType, srcType reflect.Type, opts *CopierOptions, top bool) error {
+	for name, paths := range describeType(srcType).ambiguous {
+		if top && opts != nil && (slices.Contains(opts.FieldsToOmit, name) || opts.FieldsToCopy != nil && !slices.Contains(opts.FieldsToCopy, name)) {
+			continue
+		}
+		if _, ok := dstType.FieldByName(name); ok {
+			return ambiguityError(dstType, srcType, name, paths)
+		}
+	}
+	return nil
+}
+
+func ambiguityError(dstType, srcType reflect.Type, name string, paths []string) error {
+	return &CopyError{
+		DstType:   dstType,
+		SrcType:   srcType,
+		FieldPath: []string{name},
+		Err:       serr.Wrap("", ErrAmbiguousField, serr.String("field", name), serr.String("paths", strings.Join(paths, ","))),
+	}
+}
+
+// nestedCopier returns a copier for a pair of structs nested in a copied value.
+// Nested copiers with options aren't cached on their own since they're compiled as part of their parent.
+// Pairs of structures declared by a manifest are copied with their own options.
+func nestedCopier(dstType, srcType reflect.Type, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	copier, err := nestedContextCopier(dstType, srcType, opts)
 	if err != nil {
 		return nil, err
 	}
-	return func(dst, src unsafe.Pointer) error {
-		dst = unsafe.Add(dst, dstOffset)
-		src = unsafe.Add(src, srcOffset)
-		return conv(dst, src)
-	}, nil
+	return withoutContext(copier), nil
+}
+
+// sameLayout checks whether values of the two types can be copied bytewise.
+// Only pointer-free types are considered so that bytewise copies don't bypass the GC's write barriers.
+func sameLayout(t1, t2 reflect.Type) bool {
+	if t1.Kind() != t2.Kind() || t1.Size() != t2.Size() {
+		return false
+	}
+	switch t1.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	case reflect.Array:
+		return t1.Len() == t2.Len() && sameLayout(t1.Elem(), t2.Elem())
+	}
+	return false
+}
+
+// copiedBytewise checks whether values of the source type are converted into the destination type by copying them bytewise,
+// i.e. whether the types have the same layout and no conversion such as the validation of closed enums applies to them.
+func copiedBytewise(dstType, srcType reflect.Type, opts *CopierOptions) bool {
+	if !sameLayout(dstType, srcType) {
+		return false
+	}
+	for {
+		if _, ok, _ := typeConverter(dstType, srcType, opts); ok {
+			return false
+		}
+		if _, ok := providedConv(dstType, srcType); ok {
+			return false
+		}
+		if dstType.Kind() != reflect.Array {
+			break
+		}
+		dstType, srcType = dstType.Elem(), srcType.Elem()
+	}
+	return dstType == srcType || !dstType.Implements(types.ClosedEnum)
+}
+
+// TypedCopy copies the contents of the source object to the destination object.
+// It's the package's entry point: by default the objects are copied by a copier compiled for their types,
+// which is cached by the contents of the options, see [CopierForPairWith].
+// [WithEngine] selects the adapter engine instead, which also copies maps and adapters but ignores the other options.
+func TypedCopy[D, S any](dst *D, src *S, opts ...CopierOption) error {
+	return CopyContext(context.Background(), dst, src, opts...)
+}
+
+// CopyContext copies the contents of the source object to the destination object like [TypedCopy]. The context is passed on
+// to context-aware converters and hooks such as [Converter.ContextFunc] and [CopierOptions.ValidateContext], e.g. for
+// tenant-specific formatting. The context reaches the fields of nested structures, including those behind pointers
+// and in slice elements. The adapter engine passes on the background context.
+func CopyContext[D, S any](ctx context.Context, dst *D, src *S, opts ...CopierOption) error {
+	if dst == nil {
+		return serr.Wrap("", ErrNilPointer, serr.String("type", reflect.TypeFor[*D]().String()))
+	}
+	if src == nil {
+		return serr.Wrap("", ErrNilPointer, serr.String("type", reflect.TypeFor[*S]().String()))
+	}
+	var o *CopierOptions
+	if len(opts) > 0 {
+		o = NewCopierOptions(opts...)
+		if o.Engine == EngineAdapter {
+			return CopyV1(adapterTarget(dst), adapterTarget(src), o.AdapterOptions...)
+		}
+	}
+	copier, err := ContextCopierForPairWithOptions(reflect.TypeFor[D](), reflect.TypeFor[S](), o)
+	if err != nil {
+		return err
+	}
+	return copier(ctx, unsafe.Pointer(dst), unsafe.Pointer(src))
+}
+
+// MustTypedCopy copies the contents of the source object to the destination object like [TypedCopy].
+// It panics on error.
+func MustTypedCopy[D, S any](dst *D, src *S, opts ...CopierOption) {
+	if err := TypedCopy(dst, src, opts...); err != nil {
+		panic(err)
+	}
+}
+
+// adapterTarget returns the object which an adapter is created for by the adapter engine.
+// Maps and interfaces holding adapters are adapted by value.
+func adapterTarget[T any](p *T) interface{} {
+	switch reflect.TypeFor[T]().Kind() {
+	case reflect.Map, reflect.Interface:
+		return *p
+	}
+	return p
 }
 
 // NewCopy copies the contents of the source object to the destination object.
+//
+// Deprecated: Use [TypedCopy].
 func NewCopy(dst, src interface{}) error {
 	dstVal := reflect.ValueOf(dst)
 	srcVal := reflect.ValueOf(src)
@@ -642,12 +2141,24 @@ func NewCopy(dst, src interface{}) error {
 }
 
 // NewerCopy copies the contents of the source object to the destination object.
+//
+// Deprecated: Use [TypedCopy].
 func NewerCopy[T, U any](dst *T, src *U) error {
-	copier, err := CopierForPair(reflect.TypeFor[T](), reflect.TypeFor[U]())
-	if err != nil {
-		return err
+	return TypedCopy(dst, src)
+}
+
+// CopyAs copies the source object into a new object of the destination type.
+func CopyAs[D, S any](src *S) (*D, error) {
+	var dst D
+	if err := TypedCopy(&dst, src); err != nil {
+		return nil, err
 	}
-	return copier(unsafe.Pointer(dst), unsafe.Pointer(src))
+	return &dst, nil
+}
+
+// MustCopyAs copies the source object into a new object of the destination type. It panics on error.
+func MustCopyAs[D, S any](src *S) *D {
+	return must.Must(CopyAs[D](src))
 }
 
 // TypedCopierForPair creates a typed copier for a pair of structs.
@@ -669,10 +2180,10 @@ func SliceCopierForPair[D, S any]() (func([]*S) ([]*D, error), error) {
 	}
 	return func(src []*S) ([]*D, error) {
 		r := make([]*D, 0, len(src))
-		for _, x := range src {
+		for i, x := range src {
 			var y D
 			if err := c(&y, x); err != nil {
-				return nil, err
+				return nil, elementError(err, i, reflect.TypeFor[D](), reflect.TypeFor[S]())
 			}
 			r = append(r, &y)
 		}
@@ -680,6 +2191,39 @@ func SliceCopierForPair[D, S any]() (func([]*S) ([]*D, error), error) {
 	}, nil
 }
 
+// SliceCopierForPairWithOptions creates a typed copier for a pair of slices which post-processes the copied slices according to the options.
+// If invalid elements are skipped, the copied slice is returned along with a [SkippedElementsError] reporting them.
+func SliceCopierForPairWithOptions[D, S any](so SliceOptions) (func([]*S) ([]*D, error), error) {
+	var (
+		c   func([]*S) ([]*D, error)
+		err error
+	)
+	if so.SkipInvalid {
+		c, err = skippingSliceCopier[D, S]()
+	} else {
+		c, err = SliceCopierForPair[D, S]()
+	}
+	if err != nil {
+		return nil, err
+	}
+	process, err := sliceProcessor(reflect.TypeFor[[]*D](), so)
+	if err != nil {
+		return nil, err
+	}
+	copier := func(src []*S) ([]*D, error) {
+		r, err := c(src)
+		if _, skipped := err.(*SkippedElementsError); err != nil && !skipped {
+			return nil, err
+		}
+		process(reflect.ValueOf(&r).Elem())
+		return r, err
+	}
+	if so.Tracer != nil {
+		copier = tracedSlice(copier, so.Tracer)
+	}
+	return copier, nil
+}
+
 // MustSliceCopierForPair creates a typed copier for a pair of slices. It panics on error.
 func MustSliceCopierForPair[D, S any]() func([]*S) ([]*D, error) {
 	return must.Must(SliceCopierForPair[D, S]())
@@ -687,11 +2231,11 @@ func MustSliceCopierForPair[D, S any]() func([]*S) ([]*D, error) {
 
 // CopyMap creates a copy of the given slice.
 // Types T and U have to be compatible.
-// [Copy] is used for mapping between the two types.
+// [TypedCopy] is used for mapping between the two types.
 func CopyMap[T, U any](l []*T) ([]*U, error) {
 	return slice.FallibleFmap(func(x *T) (*U, error) {
 		var r U
-		if err := Copy(&r, x); err != nil {
+		if err := TypedCopy(&r, x); err != nil {
 			return nil, err
 		}
 		return &r, nil
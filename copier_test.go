@@ -3,8 +3,11 @@ package keyvalue
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"reflect"
+	"runtime"
 	"strconv"
 	"testing"
 	"time"
@@ -14,6 +17,7 @@ import (
 	"github.com/mailstepcz/enums"
 	"github.com/mailstepcz/maybe"
 	"github.com/mailstepcz/pointer"
+	"github.com/mailstepcz/types"
 	"github.com/mailstepcz/validate"
 	"github.com/oklog/ulid/v2"
 	"github.com/rickb777/date/v2"
@@ -59,7 +63,7 @@ func TestClosedEnumCopySuccess(t *testing.T) {
 
 	var dst enumDst
 	u := "c3"
-	err := Copy(&dst, &enumSrc{
+	err := TypedCopy(&dst, &enumSrc{
 		X: "a1",
 		Y: AbcEnum("b2"),
 		U: &u,
@@ -76,11 +80,139 @@ func TestClosedEnumCopyFailure(t *testing.T) {
 	req := require.New(t)
 
 	var dst enumDst
-	err := Copy(&dst, &enumSrc{X: "aa11"})
+	err := TypedCopy(&dst, &enumSrc{X: "aa11"})
 	req.NotNil(err)
 	req.Equal("bad value for closed enum value=aa11 dstType=AbcEnum", err.Error())
 }
 
+type Priority int32
+
+const (
+	PriorityLow Priority = iota + 1
+	PriorityHigh
+)
+
+var priorityNames = map[Priority]string{PriorityLow: "low", PriorityHigh: "high"}
+
+func (v Priority) EnumValueIsValid() bool {
+	_, ok := priorityNames[v]
+	return ok
+}
+
+func (v Priority) Value() (driver.Value, error) {
+	return int64(v), nil
+}
+
+func (v Priority) DefaultValue() string {
+	return priorityNames[PriorityLow]
+}
+
+func (v Priority) String() string {
+	return priorityNames[v]
+}
+
+func (v *Priority) UnmarshalText(text []byte) error {
+	for p, name := range priorityNames {
+		if name == string(text) {
+			*v = p
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown priority %q", text)
+}
+
+type intEnumDst struct {
+	FromInt    Priority
+	FromString Priority
+	ToString   string
+	ToInt      int64
+	Maybe      maybe.Maybe[Priority]
+}
+
+type intEnumSrc struct {
+	FromInt    int
+	FromString string
+	ToString   Priority
+	ToInt      Priority
+	Maybe      *string
+}
+
+func TestIntClosedEnumCopy(t *testing.T) {
+	req := require.New(t)
+
+	var dst intEnumDst
+	err := TypedCopy(&dst, &intEnumSrc{
+		FromInt:    2,
+		FromString: "low",
+		ToString:   PriorityHigh,
+		ToInt:      PriorityLow,
+		Maybe:      pointer.To("high"),
+	})
+	req.NoError(err)
+	req.Equal(intEnumDst{
+		FromInt:    PriorityHigh,
+		FromString: PriorityLow,
+		ToString:   "high",
+		ToInt:      1,
+		Maybe:      maybe.Unit(PriorityHigh),
+	}, dst)
+
+	err = TypedCopy(&dst, &intEnumSrc{FromInt: 3, FromString: "low", ToString: PriorityHigh})
+	req.EqualError(err, "bad value for closed enum value=3 dstType=Priority")
+	err = TypedCopy(&dst, &intEnumSrc{FromInt: 1, FromString: "urgent", ToString: PriorityHigh})
+	req.ErrorContains(err, "bad value for closed enum")
+	err = TypedCopy(&dst, &intEnumSrc{FromInt: 1, FromString: "low"})
+	req.EqualError(err, "bad value for closed enum value=0 srcType=Priority")
+}
+
+func TestIntClosedEnumSlice(t *testing.T) {
+	req := require.New(t)
+
+	var dst struct{ Priorities []Priority }
+	req.NoError(TypedCopy(&dst, &struct{ Priorities []int32 }{Priorities: []int32{2, 1}}))
+	req.Equal([]Priority{PriorityHigh, PriorityLow}, dst.Priorities)
+
+	err := TypedCopy(&dst, &struct{ Priorities []int32 }{Priorities: []int32{9}})
+	req.ErrorContains(err, "bad value for closed enum")
+
+	var arrays struct{ Priorities [][1]Priority }
+	req.Error(TypedCopy(&arrays, &struct{ Priorities [][1]int32 }{Priorities: [][1]int32{{9}}}))
+
+	type count int32
+	var doubled struct{ Values []count }
+	req.NoError(TypedCopy(&doubled, &struct{ Values []int32 }{Values: []int32{1, 2}}, WithTypeConverter(func(x int32) (count, error) {
+		return count(2 * x), nil
+	})))
+	req.Equal([]count{2, 4}, doubled.Values)
+}
+
+func TestClosedEnumDefaults(t *testing.T) {
+	req := require.New(t)
+
+	var fallbacks []EnumFallback
+	opts := []CopierOption{WithEnumFallback(func(f EnumFallback) {
+		fallbacks = append(fallbacks, f)
+	})}
+
+	var dst enumDst
+	req.NoError(TypedCopy(&dst, &enumSrc{X: "aa11"}, opts...))
+	req.Equal(AbcEnum("a1"), dst.X)
+	req.Equal([]EnumFallback{{DstType: reflect.TypeFor[AbcEnum](), Value: "aa11", Default: AbcEnum("a1")}}, fallbacks)
+
+	fallbacks = nil
+	var intDst intEnumDst
+	req.NoError(TypedCopy(&intDst, &intEnumSrc{FromInt: 3, FromString: "urgent", ToString: PriorityHigh}, opts...))
+	req.Equal(PriorityLow, intDst.FromInt)
+	req.Equal(PriorityLow, intDst.FromString)
+	req.Len(fallbacks, 2)
+	req.Equal(3, fallbacks[0].Value)
+	req.Equal("urgent", fallbacks[1].Value)
+
+	req.NoError(TypedCopy(&dst, &enumSrc{X: "bb22"}, WithEnumPolicy(EnumDefault)))
+	req.Equal(AbcEnum("a1"), dst.X)
+	req.Error(TypedCopy(&dst, &enumSrc{X: "bb22"}, WithEnumPolicy(EnumError)))
+}
+
 type embeddedDst struct {
 	S string
 	U string
@@ -385,7 +517,7 @@ func (x *copierSrc3) toDst() (*copierDst3, error) {
 
 func BenchmarkOldCopy(b *testing.B) {
 	var lr interface{}
-	copiers = make(map[copierTypePair]func(unsafe.Pointer, unsafe.Pointer) error)
+	ResetCache()
 	uid1, uid2, uid3 := uuid.New(), uuid.New(), uuid.New()
 	uids1, uids2, uids3 := uid1.String(), uid2.String(), uid3.String()
 	tm1, tm2 := time.Unix(12345678, 0).UTC(), time.Unix(12345679, 0).UTC()
@@ -413,7 +545,7 @@ func BenchmarkOldCopy(b *testing.B) {
 
 func BenchmarkNewCopy(b *testing.B) {
 	var lr interface{}
-	copiers = make(map[copierTypePair]func(unsafe.Pointer, unsafe.Pointer) error)
+	ResetCache()
 	if _, err := CopierForPair(reflect.TypeOf((*copierDst3)(nil)).Elem(), reflect.TypeOf((*copierSrc3)(nil)).Elem()); err != nil {
 		b.Errorf("copying failed: %v", err)
 	}
@@ -444,7 +576,7 @@ func BenchmarkNewCopy(b *testing.B) {
 
 func BenchmarkNewerCopy(b *testing.B) {
 	var lr interface{}
-	copiers = make(map[copierTypePair]func(unsafe.Pointer, unsafe.Pointer) error)
+	ResetCache()
 	if _, err := CopierForPair(reflect.TypeOf((*copierDst3)(nil)).Elem(), reflect.TypeOf((*copierSrc3)(nil)).Elem()); err != nil {
 		b.Errorf("copying failed: %v", err)
 	}
@@ -475,7 +607,7 @@ func BenchmarkNewerCopy(b *testing.B) {
 
 func BenchmarkTypedCopy(b *testing.B) {
 	var lr interface{}
-	copiers = make(map[copierTypePair]func(unsafe.Pointer, unsafe.Pointer) error)
+	ResetCache()
 	copier, err := CopierForPair(reflect.TypeOf((*copierDst3)(nil)).Elem(), reflect.TypeOf((*copierSrc3)(nil)).Elem())
 	if err != nil {
 		b.Errorf("copying failed: %v", err)
@@ -510,7 +642,7 @@ func BenchmarkTypedCopy(b *testing.B) {
 
 func BenchmarkTypedReflectionCopy(b *testing.B) {
 	var lr interface{}
-	copiers = make(map[copierTypePair]func(unsafe.Pointer, unsafe.Pointer) error)
+	ResetCache()
 	copier, err := CopierForPair(reflect.TypeOf((*copierDst3)(nil)).Elem(), reflect.TypeOf((*copierSrc3)(nil)).Elem())
 	if err != nil {
 		b.Errorf("copying failed: %v", err)
@@ -552,7 +684,7 @@ func BenchmarkTypedReflectionCopy(b *testing.B) {
 
 func BenchmarkJinzhuCopy(b *testing.B) {
 	var lr interface{}
-	copiers = make(map[copierTypePair]func(unsafe.Pointer, unsafe.Pointer) error)
+	ResetCache()
 	uid1, uid2, uid3 := uuid.New(), uuid.New(), uuid.New()
 	uids1, uids2, uids3 := uid1.String(), uid2.String(), uid3.String()
 	tm1, tm2 := time.Unix(12345678, 0).UTC(), time.Unix(12345679, 0).UTC()
@@ -624,7 +756,7 @@ type reqDst struct {
 func TestCopierRequired(t *testing.T) {
 	req := require.New(t)
 
-	copiers = make(map[copierTypePair]func(unsafe.Pointer, unsafe.Pointer) error)
+	ResetCache()
 
 	copier, err := CopierForPair(reflect.TypeFor[reqDst](), reflect.TypeFor[reqSrc]())
 	req.Nil(err)
@@ -679,7 +811,7 @@ func (x *copInnerSrc) Copy(t reflect.Type) unsafe.Pointer {
 func TestCopierCopiable(t *testing.T) {
 	req := require.New(t)
 
-	copiers = make(map[copierTypePair]func(unsafe.Pointer, unsafe.Pointer) error)
+	ResetCache()
 
 	copier, err := CopierForPair(reflect.TypeFor[copOuterDst](), reflect.TypeFor[copOuterSrc]())
 	req.Nil(err)
@@ -695,12 +827,83 @@ func TestCopierCopiable(t *testing.T) {
 	req.Equal("1234", dst.X.S)
 }
 
+type panickingSrc struct{}
+
+func (x *panickingSrc) CanCopyTo(t reflect.Type) bool {
+	return true
+}
+
+func (x *panickingSrc) Copy(t reflect.Type) unsafe.Pointer {
+	panic("can't copy type " + t.String())
+}
+
+func TestCopierRecoverPanics(t *testing.T) {
+	req := require.New(t)
+
+	type srcS struct {
+		X *panickingSrc
+	}
+	type dstS struct {
+		X *copInnerDst
+	}
+
+	copier, err := CopierForPairWithOptions(reflect.TypeFor[dstS](), reflect.TypeFor[srcS](), &CopierOptions{RecoverPanics: true})
+	req.NoError(err)
+
+	var dst dstS
+	err = copier(unsafe.Pointer(&dst), unsafe.Pointer(&srcS{X: &panickingSrc{}}))
+	req.ErrorIs(err, ErrPanic)
+	var ce *CopyError
+	req.ErrorAs(err, &ce)
+	req.Equal("X", ce.Path())
+}
+
+var errNameRequired = errors.New("name required")
+
+type validatedDst struct {
+	Name string
+}
+
+func (x *validatedDst) validate() error {
+	if x.Name == "" {
+		return errNameRequired
+	}
+	return nil
+}
+
+func TestCopierValidate(t *testing.T) {
+	req := require.New(t)
+
+	type srcS struct {
+		Name string
+	}
+
+	copier, err := CopierForPairWithOptions(reflect.TypeFor[validatedDst](), reflect.TypeFor[srcS](), &CopierOptions{
+		Validate: func(x interface{}) error {
+			return x.(*validatedDst).validate()
+		},
+	})
+	req.NoError(err)
+
+	var dst validatedDst
+	err = copier(unsafe.Pointer(&dst), unsafe.Pointer(&srcS{Name: "John"}))
+	req.NoError(err)
+	req.Equal("John", dst.Name)
+
+	err = copier(unsafe.Pointer(&dst), unsafe.Pointer(&srcS{}))
+	req.ErrorIs(err, ErrValidation)
+	req.ErrorIs(err, errNameRequired)
+	var ce *CopyError
+	req.ErrorAs(err, &ce)
+	req.Equal(reflect.TypeFor[validatedDst](), ce.DstType)
+}
+
 func TestCopierCreationSuccess(t *testing.T) {
 	req := require.New(t)
 
-	copiers = make(map[copierTypePair]func(unsafe.Pointer, unsafe.Pointer) error)
+	ResetCache()
 
-	copier, err := CopierForPair(reflect.TypeOf((*copierDst1)(nil)).Elem(), reflect.TypeOf((*copierSrc1)(nil)).Elem())
+	copier, err := CopierForPairWithOptions(reflect.TypeOf((*copierDst1)(nil)).Elem(), reflect.TypeOf((*copierSrc1)(nil)).Elem(), &CopierOptions{NilPolicy: NilSkipZero})
 	req.Nil(err)
 
 	var dst copierDst1
@@ -904,7 +1107,7 @@ func TestUlidCopy(t *testing.T) {
 		ULID6: nil,
 	}
 	var dst ulidDst
-	err := Copy(&dst, &src)
+	err := TypedCopy(&dst, &src)
 	req.NoError(err)
 
 	req.Equal(src.ULID1.String(), dst.ULID1)
@@ -915,10 +1118,51 @@ func TestUlidCopy(t *testing.T) {
 	req.False(dst.ULID6.Valid)
 }
 
+type idMigrationSrc struct {
+	UUIDToULID uuid.UUID
+	ULIDToUUID ulid.ULID
+	ULIDString string
+	UUIDString string
+}
+
+type idMigrationDst struct {
+	UUIDToULID ulid.ULID
+	ULIDToUUID uuid.UUID
+	ULIDString uuid.UUID
+	UUIDString ulid.ULID
+}
+
+func TestUUIDULIDCopy(t *testing.T) {
+	req := require.New(t)
+
+	u := uuid.New()
+	l := ulid.Make()
+	src := idMigrationSrc{
+		UUIDToULID: u,
+		ULIDToUUID: l,
+		ULIDString: l.String(),
+		UUIDString: u.String(),
+	}
+	var dst idMigrationDst
+	req.NoError(TypedCopy(&dst, &src))
+	req.Equal([16]byte(u), [16]byte(dst.UUIDToULID))
+	req.Equal([16]byte(l), [16]byte(dst.ULIDToUUID))
+	req.Equal([16]byte(l), [16]byte(dst.ULIDString))
+	req.Equal([16]byte(u), [16]byte(dst.UUIDString))
+
+	var back idMigrationSrc
+	req.NoError(TypedCopy(&back, &dst))
+	req.Equal(u, back.UUIDToULID)
+	req.Equal(l, back.ULIDToUUID)
+
+	src.ULIDString = "01ARZ3NDEKTSV4RRFFQ69G5FAU"
+	req.Error(TypedCopy(&dst, &src))
+}
+
 func TestCopierCreationErrFieldNotInDestination(t *testing.T) {
 	req := require.New(t)
 
-	copiers = make(map[copierTypePair]func(unsafe.Pointer, unsafe.Pointer) error)
+	ResetCache()
 
 	_, err := CopierForPair(reflect.TypeOf((*copierDst2)(nil)).Elem(), reflect.TypeOf((*copierSrc1)(nil)).Elem())
 	req.NotNil(err)
@@ -929,7 +1173,7 @@ func TestCopierCreationErrFieldNotInDestination(t *testing.T) {
 func TestCopierCreationNotSubsumedSuccess(t *testing.T) {
 	req := require.New(t)
 
-	copiers = make(map[copierTypePair]func(unsafe.Pointer, unsafe.Pointer) error)
+	ResetCache()
 
 	copier, err := CopierForPairWithOptions(
 		reflect.TypeOf((*copierDst2)(nil)).Elem(),
@@ -954,7 +1198,7 @@ func TestCopierCreationNotSubsumedSuccess(t *testing.T) {
 func TestCopierCreationFieldsToOmitSuccess(t *testing.T) {
 	req := require.New(t)
 
-	copiers = make(map[copierTypePair]func(unsafe.Pointer, unsafe.Pointer) error)
+	ResetCache()
 
 	copier, err := CopierForPairWithOptions(
 		reflect.TypeOf((*copierDst2)(nil)).Elem(),
@@ -979,7 +1223,7 @@ func TestCopierCreationFieldsToOmitSuccess(t *testing.T) {
 func TestCopierCreationFieldsToCopySuccess(t *testing.T) {
 	req := require.New(t)
 
-	copiers = make(map[copierTypePair]func(unsafe.Pointer, unsafe.Pointer) error)
+	ResetCache()
 
 	copier, err := CopierForPairWithOptions(
 		reflect.TypeOf((*copierDst2)(nil)).Elem(),
@@ -1001,6 +1245,468 @@ func TestCopierCreationFieldsToCopySuccess(t *testing.T) {
 	req.Equal("text", dst.S)
 }
 
+func TestCopierTagRenames(t *testing.T) {
+	req := require.New(t)
+
+	type address struct {
+		Town string `copy:"City"`
+	}
+	type customerModel struct {
+		CustomerID uuid.UUID `copy:"ID"`
+		FullName   string
+		Address    address
+	}
+	type addressDTO struct {
+		City string
+	}
+	type customerDTO struct {
+		ID      string
+		Name    string `copy:"FullName"`
+		Address addressDTO
+	}
+
+	u := uuid.New()
+	var dto customerDTO
+	req.NoError(TypedCopy(&dto, &customerModel{CustomerID: u, FullName: "Jane", Address: address{Town: "Brno"}}))
+	req.Equal(customerDTO{ID: u.String(), Name: "Jane", Address: addressDTO{City: "Brno"}}, dto)
+
+	var model customerModel
+	req.NoError(TypedCopy(&model, &dto))
+	req.Equal(customerModel{CustomerID: u, FullName: "Jane", Address: address{Town: "Brno"}}, model)
+
+	dto = customerDTO{}
+	req.NoError(TypedCopy(&dto, &customerModel{CustomerID: u, FullName: "Jane"}, WithFieldMap(map[string]string{"CustomerID": "Name"}), WithFieldsToOmit("FullName")))
+	req.Equal(customerDTO{Name: u.String()}, dto)
+
+	type nameOnly struct {
+		Name string
+	}
+	_, err := CopierForPair(reflect.TypeFor[customerDTO](), reflect.TypeFor[nameOnly]())
+	req.ErrorIs(err, ErrFieldNotFound)
+}
+
+func TestCopierInstrumentation(t *testing.T) {
+	req := require.New(t)
+
+	var stats []CopyStats
+	copier, err := CopierForPairWithOptions(
+		reflect.TypeFor[copierDst2](),
+		reflect.TypeFor[copierSrc4](),
+		&CopierOptions{
+			OmitNotFound: true,
+			Instrument: func(s CopyStats) {
+				stats = append(stats, s)
+			},
+		})
+	req.NoError(err)
+
+	var dst copierDst2
+	err = copier(unsafe.Pointer(&dst), unsafe.Pointer(&copierSrc4{
+		N: 1234,
+		S: "text",
+		X: 12.34,
+	}))
+	req.NoError(err)
+
+	req.Len(stats, 1)
+	req.Equal(reflect.TypeFor[copierDst2](), stats[0].DstType)
+	req.Equal(reflect.TypeFor[copierSrc4](), stats[0].SrcType)
+	req.Equal(2, stats[0].Fields)
+	req.NoError(stats[0].Err)
+}
+
+func TestCopierNilPolicy(t *testing.T) {
+	type dstS struct {
+		T time.Time
+		P *uuid.UUID
+		L []uuid.UUID
+	}
+	type srcS struct {
+		T *timestamppb.Timestamp
+		P *string
+		L []string
+	}
+	u := uuid.New()
+	initial := dstS{
+		T: time.Unix(1234, 0),
+		P: &u,
+		L: []uuid.UUID{u},
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		req := require.New(t)
+
+		copier, err := TypedCopierForPair[dstS, srcS]()
+		req.NoError(err)
+		dst := initial
+		err = copier(&dst, &srcS{})
+		req.NoError(err)
+		req.Equal(initial, dst)
+	})
+
+	t.Run("zero", func(t *testing.T) {
+		req := require.New(t)
+
+		copier, err := CopierForPairWithOptions(reflect.TypeFor[dstS](), reflect.TypeFor[srcS](), &CopierOptions{NilPolicy: NilZero})
+		req.NoError(err)
+		dst := initial
+		err = copier(unsafe.Pointer(&dst), unsafe.Pointer(&srcS{}))
+		req.NoError(err)
+		req.Equal(dstS{}, dst)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		req := require.New(t)
+
+		copier, err := CopierForPairWithOptions(reflect.TypeFor[dstS](), reflect.TypeFor[srcS](), &CopierOptions{NilPolicy: NilError})
+		req.NoError(err)
+		var dst dstS
+		err = copier(unsafe.Pointer(&dst), unsafe.Pointer(&srcS{}))
+		req.ErrorIs(err, ErrNilSource)
+	})
+
+	t.Run("error propagation into pointer", func(t *testing.T) {
+		req := require.New(t)
+
+		type dstS struct {
+			P *uuid.UUID
+		}
+		type srcS struct {
+			P string
+		}
+		var dst dstS
+		err := TypedCopy(&dst, &srcS{P: "uuid"})
+		req.EqualError(err, "invalid UUID length: 4")
+		err = TypedCopy(&dst, &srcS{})
+		req.EqualError(err, "invalid UUID length: 0")
+		err = TypedCopy(&dst, &srcS{}, WithNilPolicy(NilSkipZero))
+		req.NoError(err)
+		req.Nil(dst.P)
+	})
+}
+
+func TestCopierStrictNumeric(t *testing.T) {
+	type dstS struct {
+		N int
+		D decimal.Decimal
+		F float64
+	}
+	type srcS struct {
+		N float64
+		D float64
+		F decimal.Decimal
+	}
+
+	t.Run("lenient", func(t *testing.T) {
+		req := require.New(t)
+
+		var dst dstS
+		err := TypedCopy(&dst, &srcS{N: 12.5, D: 12.25, F: decimal.RequireFromString("0.1")})
+		req.NoError(err)
+		req.Equal(dstS{N: 12, D: decimal.RequireFromString("12.25"), F: 0.1}, dst)
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		req := require.New(t)
+
+		copier, err := CopierForPairWithOptions(reflect.TypeFor[dstS](), reflect.TypeFor[srcS](), &CopierOptions{StrictNumeric: true})
+		req.NoError(err)
+
+		var dst dstS
+		err = copier(unsafe.Pointer(&dst), unsafe.Pointer(&srcS{N: 12, D: 12.25, F: decimal.RequireFromString("0.5")}))
+		req.NoError(err)
+		req.Equal(dstS{N: 12, D: decimal.RequireFromString("12.25"), F: 0.5}, dst)
+
+		err = copier(unsafe.Pointer(&dst), unsafe.Pointer(&srcS{N: 12.5}))
+		req.ErrorIs(err, ErrPrecisionLoss)
+		err = copier(unsafe.Pointer(&dst), unsafe.Pointer(&srcS{F: decimal.RequireFromString("0.1")}))
+		req.ErrorIs(err, ErrPrecisionLoss)
+		err = copier(unsafe.Pointer(&dst), unsafe.Pointer(&srcS{D: math.NaN()}))
+		req.ErrorIs(err, ErrPrecisionLoss)
+	})
+
+	t.Run("lenient NaN", func(t *testing.T) {
+		req := require.New(t)
+
+		dst := dstS{D: decimal.New(1, 0)}
+		err := TypedCopy(&dst, &srcS{D: math.NaN()})
+		req.NoError(err)
+		req.Equal("1", dst.D.String())
+	})
+
+	t.Run("sign and range", func(t *testing.T) {
+		type dstS struct {
+			U uint
+			I int8
+			F float32
+			N int64
+		}
+		type srcS struct {
+			U int
+			I float64
+			F int64
+			N float64
+		}
+
+		req := require.New(t)
+
+		copier, err := CopierForPairWithOptions(reflect.TypeFor[dstS](), reflect.TypeFor[srcS](), &CopierOptions{StrictNumeric: true})
+		req.NoError(err)
+
+		var dst dstS
+		err = copier(unsafe.Pointer(&dst), unsafe.Pointer(&srcS{U: 1, I: -128, F: 1 << 24, N: -(1 << 63)}))
+		req.NoError(err)
+		req.Equal(dstS{U: 1, I: -128, F: 1 << 24, N: -(1 << 63)}, dst)
+
+		for _, src := range []srcS{
+			{U: -1},
+			{I: 128},
+			{I: math.NaN()},
+			{F: 1<<24 + 1},
+			{N: 1 << 63},
+			{N: math.Inf(-1)},
+		} {
+			err = copier(unsafe.Pointer(&dst), unsafe.Pointer(&src))
+			req.ErrorIs(err, ErrPrecisionLoss, src)
+		}
+	})
+}
+
+func TestCopierDecimalRounding(t *testing.T) {
+	type dstS struct {
+		D decimal.Decimal
+		F float64
+		G float32
+	}
+	type srcS struct {
+		D float64
+		F decimal.Decimal
+		G decimal.Decimal
+	}
+
+	for mode, expected := range map[RoundingMode][2]string{
+		RoundHalfUp:   {"2.35", "-2.35"},
+		RoundHalfEven: {"2.34", "-2.34"},
+		RoundDown:     {"2.34", "-2.34"},
+		RoundUp:       {"2.35", "-2.35"},
+		RoundFloor:    {"2.34", "-2.35"},
+		RoundCeil:     {"2.35", "-2.34"},
+	} {
+		req := require.New(t)
+
+		copier, err := CopierForPairWithOptions(reflect.TypeFor[dstS](), reflect.TypeFor[srcS](), &CopierOptions{
+			DecimalRounding: &DecimalRounding{Mode: mode, MaxScale: 2},
+		})
+		req.NoError(err)
+
+		var dst dstS
+		err = copier(unsafe.Pointer(&dst), unsafe.Pointer(&srcS{D: 2.345, F: decimal.RequireFromString("-2.345")}))
+		req.NoError(err)
+		req.Equal(expected[0], dst.D.String(), mode)
+		req.Equal(decimal.RequireFromString(expected[1]).InexactFloat64(), dst.F, mode)
+	}
+
+	req := require.New(t)
+	var dst dstS
+	err := TypedCopy(&dst, &srcS{F: decimal.New(1, 400), G: decimal.New(1, 40)})
+	req.NoError(err)
+	req.True(math.IsInf(dst.F, 1))
+	req.True(math.IsInf(float64(dst.G), 1))
+	err = TypedCopy(&dst, &srcS{F: decimal.New(1, 400)}, WithStrictNumeric())
+	req.ErrorIs(err, ErrPrecisionLoss)
+	err = TypedCopy(&dst, &srcS{G: decimal.New(1, 40)}, WithStrictNumeric())
+	req.ErrorIs(err, ErrPrecisionLoss)
+}
+
+func TestCopierMinorUnits(t *testing.T) {
+	type payment struct {
+		Amount int64
+		Fee    int64
+	}
+	type billing struct {
+		Amount decimal.Decimal
+		Fee    decimal.Decimal
+	}
+
+	req := require.New(t)
+
+	toBilling, err := CopierForPairWithOptions(reflect.TypeFor[billing](), reflect.TypeFor[payment](), &CopierOptions{MinorUnitExponent: 2})
+	req.NoError(err)
+	var b billing
+	req.NoError(toBilling(unsafe.Pointer(&b), unsafe.Pointer(&payment{Amount: 1234, Fee: -5})))
+	req.Equal("12.34", b.Amount.String())
+	req.Equal("-0.05", b.Fee.String())
+
+	toPayment, err := CopierForPairWithOptions(reflect.TypeFor[payment](), reflect.TypeFor[billing](), &CopierOptions{MinorUnitExponent: 2})
+	req.NoError(err)
+	var p payment
+	req.NoError(toPayment(unsafe.Pointer(&p), unsafe.Pointer(&b)))
+	req.Equal(payment{Amount: 1234, Fee: -5}, p)
+
+	err = toPayment(unsafe.Pointer(&p), unsafe.Pointer(&billing{Amount: decimal.RequireFromString("1.005")}))
+	req.ErrorIs(err, ErrPrecisionLoss)
+	err = toPayment(unsafe.Pointer(&p), unsafe.Pointer(&billing{Amount: decimal.New(1, 20)}))
+	req.ErrorIs(err, ErrPrecisionLoss)
+
+	toPayment, err = CopierForPairWithOptions(reflect.TypeFor[payment](), reflect.TypeFor[billing](), &CopierOptions{
+		MinorUnitExponent: 2,
+		DecimalRounding:   &DecimalRounding{Mode: RoundHalfEven},
+	})
+	req.NoError(err)
+	req.NoError(toPayment(unsafe.Pointer(&p), unsafe.Pointer(&billing{Amount: decimal.RequireFromString("1.005")})))
+	req.Equal(int64(100), p.Amount)
+
+	_, err = CopierForPair(reflect.TypeFor[payment](), reflect.TypeFor[billing]())
+	req.Error(err)
+}
+
+func TestCopierConditions(t *testing.T) {
+	type order struct {
+		Status    string
+		DeletedAt *time.Time
+		Note      string
+		Total     int
+	}
+	type orderDTO struct {
+		Status    string
+		DeletedAt string
+		Note      string
+		Total     int64
+	}
+
+	req := require.New(t)
+
+	copier, err := CopierForPairWithOptions(reflect.TypeFor[orderDTO](), reflect.TypeFor[order](), &CopierOptions{
+		TimeStrings: true,
+		Conditions: map[string]func(interface{}) bool{
+			"DeletedAt": func(src interface{}) bool {
+				return src.(*order).Status == "deleted"
+			},
+			"Total": func(src interface{}) bool {
+				return src.(*order).Total > 0
+			},
+		},
+	})
+	req.NoError(err)
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var dst orderDTO
+	req.NoError(copier(unsafe.Pointer(&dst), unsafe.Pointer(&order{Status: "active", DeletedAt: &at, Note: "n", Total: -1})))
+	req.Equal(orderDTO{Status: "active", Note: "n"}, dst)
+
+	dst = orderDTO{}
+	req.NoError(copier(unsafe.Pointer(&dst), unsafe.Pointer(&order{Status: "deleted", DeletedAt: &at, Note: "n", Total: 5})))
+	req.Equal(orderDTO{Status: "deleted", DeletedAt: "2024-05-01T12:00:00Z", Note: "n", Total: 5}, dst)
+}
+
+func TestCopierComputed(t *testing.T) {
+	type line struct {
+		FirstName string
+		LastName  string
+		Price     decimal.Decimal
+		Quantity  int
+	}
+	type lineDTO struct {
+		FirstName   string
+		LastName    string
+		Price       string
+		Quantity    int
+		DisplayName string
+		TotalPrice  string
+		Note        string
+	}
+
+	req := require.New(t)
+
+	copier, err := CopierForPairWithOptions(reflect.TypeFor[lineDTO](), reflect.TypeFor[line](), &CopierOptions{
+		Computed: map[string]func(interface{}) (interface{}, error){
+			"DisplayName": func(src interface{}) (interface{}, error) {
+				l := src.(*line)
+				return l.FirstName + " " + l.LastName, nil
+			},
+			"TotalPrice": func(src interface{}) (interface{}, error) {
+				l := src.(*line)
+				return l.Price.Mul(decimal.NewFromInt(int64(l.Quantity))), nil
+			},
+			"Note": func(src interface{}) (interface{}, error) {
+				return nil, nil
+			},
+		},
+	})
+	req.NoError(err)
+
+	dst := lineDTO{Note: "keep"}
+	req.NoError(copier(unsafe.Pointer(&dst), unsafe.Pointer(&line{FirstName: "John", LastName: "Doe", Price: decimal.RequireFromString("1.25"), Quantity: 4})))
+	req.Equal("John Doe", dst.DisplayName)
+	req.Equal("5", dst.TotalPrice)
+	req.Equal("keep", dst.Note)
+
+	_, err = CopierForPairWithOptions(reflect.TypeFor[lineDTO](), reflect.TypeFor[line](), &CopierOptions{
+		Computed: map[string]func(interface{}) (interface{}, error){
+			"Missing": func(src interface{}) (interface{}, error) { return 1, nil },
+		},
+	})
+	req.ErrorIs(err, ErrFieldNotFound)
+
+	copier, err = CopierForPairWithOptions(reflect.TypeFor[lineDTO](), reflect.TypeFor[line](), &CopierOptions{
+		Computed: map[string]func(interface{}) (interface{}, error){
+			"TotalPrice": func(src interface{}) (interface{}, error) { return nil, errors.New("overflow") },
+		},
+	})
+	req.NoError(err)
+	err = copier(unsafe.Pointer(&dst), unsafe.Pointer(&line{}))
+	var cerr *CopyError
+	req.ErrorAs(err, &cerr)
+	req.Equal("TotalPrice", cerr.Path())
+}
+
+func TestCopierConstants(t *testing.T) {
+	type event struct {
+		Name string
+	}
+	type eventRow struct {
+		Name          string
+		Source        string
+		SchemaVersion int16
+		Received      maybe.Maybe[string]
+		Tags          []string
+	}
+
+	req := require.New(t)
+
+	copier, err := CopierForPairWithOptions(reflect.TypeFor[eventRow](), reflect.TypeFor[event](), &CopierOptions{
+		Constants: map[string]interface{}{
+			"Source":        "api",
+			"SchemaVersion": 3,
+			"Received":      "yes",
+			"Tags":          nil,
+		},
+	})
+	req.NoError(err)
+
+	dst := eventRow{Tags: []string{"old"}}
+	req.NoError(copier(unsafe.Pointer(&dst), unsafe.Pointer(&event{Name: "created"})))
+	req.Equal(eventRow{Name: "created", Source: "api", SchemaVersion: 3, Received: maybe.Unit("yes")}, dst)
+
+	copier, err = CopierForPairWithOptions(reflect.TypeFor[eventRow](), reflect.TypeFor[event](), &CopierOptions{
+		Constants: map[string]interface{}{"Tags": []string{"imported"}},
+	})
+	req.NoError(err)
+	dst = eventRow{}
+	req.NoError(copier(unsafe.Pointer(&dst), unsafe.Pointer(&event{Name: "created"})))
+	dst.Tags[0] = "changed"
+	dst = eventRow{}
+	req.NoError(copier(unsafe.Pointer(&dst), unsafe.Pointer(&event{Name: "created"})))
+	req.Equal([]string{"imported"}, dst.Tags)
+
+	_, err = CopierForPairWithOptions(reflect.TypeFor[eventRow](), reflect.TypeFor[event](), &CopierOptions{
+		Constants: map[string]interface{}{"SchemaVersion": "three"},
+	})
+	var cerr *CopyError
+	req.ErrorAs(err, &cerr)
+	req.Equal("SchemaVersion", cerr.Path())
+}
+
 func TestFieldConv(t *testing.T) {
 	t.Run("Required[T] -> T", func(t *testing.T) {
 		req := require.New(t)
@@ -1099,6 +1805,24 @@ func TestValConv(t *testing.T) {
 		req.Equal([]alias{12, 34, 56}, dst)
 	})
 
+	t.Run("[]UUID -> []~UUID", func(t *testing.T) {
+		req := require.New(t)
+
+		type alias uuid.UUID
+		u1, u2 := uuid.New(), uuid.New()
+		var (
+			dst []alias
+			src = []uuid.UUID{u1, u2}
+		)
+		f, err := valConv(reflect.TypeOf(dst), reflect.TypeOf(src))
+		req.NoError(err)
+		err = f(unsafe.Pointer(&dst), unsafe.Pointer(&src))
+		req.NoError(err)
+		req.Equal([]alias{alias(u1), alias(u2)}, dst)
+		src[0] = uuid.Nil
+		req.Equal(alias(u1), dst[0])
+	})
+
 	t.Run("*int -> Maybe[int]", func(t *testing.T) {
 		req := require.New(t)
 
@@ -1257,6 +1981,17 @@ func TestValConv(t *testing.T) {
 	})
 }
 
+func TestValConvMemoized(t *testing.T) {
+	req := require.New(t)
+
+	_, err := valConv(types.UUID, types.String)
+	req.NoError(err)
+	valConvMtx.RLock()
+	_, ok := valConvs[copierTypePair{dst: types.UUID, src: types.String}]
+	valConvMtx.RUnlock()
+	req.True(ok)
+}
+
 func TestSliceCopier(t *testing.T) {
 	type D struct {
 		ID uuid.UUID
@@ -1288,7 +2023,10 @@ func TestSliceCopier(t *testing.T) {
 		src := []*S{&S{u1.String()}, &S{u2.String()}, &S{u3.String()}, &S{"uuid"}}
 		_, err = copier(src)
 		req.NotNil(err)
-		req.Equal("invalid UUID length: 4", err.Error())
+		req.Equal("invalid UUID length: 4 index=3", err.Error())
+		var ce *CopyError
+		req.ErrorAs(err, &ce)
+		req.Equal("3.ID", ce.Path())
 	})
 }
 
@@ -1352,7 +2090,7 @@ func BenchmarkWrappedSliceCopy(b *testing.B) {
 	var lr interface{}
 	for i := 0; i < b.N; i++ {
 		var dst sliceWrapperDst
-		if err := Copy(&dst, &src); err != nil {
+		if err := TypedCopy(&dst, &src); err != nil {
 			b.Fatal("failed copy")
 		}
 		lr = &dst
@@ -1360,6 +2098,58 @@ func BenchmarkWrappedSliceCopy(b *testing.B) {
 	gr = lr
 }
 
+func BenchmarkSameLayoutSliceCopy(b *testing.B) {
+	type alias int64
+	src := make([]int64, 1024)
+	for i := range src {
+		src[i] = int64(i)
+	}
+	f, err := valConv(reflect.TypeFor[[]alias](), reflect.TypeFor[[]int64]())
+	if err != nil {
+		b.Fatal(err)
+	}
+	var lr interface{}
+	for i := 0; i < b.N; i++ {
+		var dst []alias
+		if err := f(unsafe.Pointer(&dst), unsafe.Pointer(&src)); err != nil {
+			b.Fatal(err)
+		}
+		lr = dst
+	}
+	gr = lr
+}
+
+func TestHasPointers(t *testing.T) {
+	req := require.New(t)
+
+	req.False(hasPointers(reflect.TypeFor[int]()))
+	req.False(hasPointers(reflect.TypeFor[uuid.UUID]()))
+	req.False(hasPointers(reflect.TypeFor[copyStruct]().Field(0).Type))
+	req.True(hasPointers(reflect.TypeFor[copyStruct]()))
+	req.True(hasPointers(reflect.TypeFor[[2]*int]()))
+	req.True(hasPointers(reflect.TypeFor[time.Time]()))
+}
+
+func TestSliceOfPointerfulElements(t *testing.T) {
+	req := require.New(t)
+
+	type alias copyStruct
+	src := make([]copyStruct, 100)
+	for i := range src {
+		src[i] = copyStruct{N: i, S: strconv.Itoa(i)}
+	}
+	f, err := valConv(reflect.TypeFor[[]alias](), reflect.TypeFor[[]copyStruct]())
+	req.NoError(err)
+	var dst []alias
+	err = f(unsafe.Pointer(&dst), unsafe.Pointer(&src))
+	req.NoError(err)
+	src = nil
+	runtime.GC()
+	for i, x := range dst {
+		req.Equal(alias{N: i, S: strconv.Itoa(i)}, x)
+	}
+}
+
 func TestMemcopy(t *testing.T) {
 	req := require.New(t)
 
@@ -1437,11 +2227,70 @@ func TestStructToMap(t *testing.T) {
 
 	src := s{X: p{S: "abcd", N: 1234}}
 	var dst d
-	err := Copy(&dst, &src)
+	err := TypedCopy(&dst, &src)
 	req.NoError(err)
 	req.Equal(map[string]interface{}{"S": "abcd", "Num": 1234}, dst.X)
 }
 
+func TestStructToMapNormalized(t *testing.T) {
+	req := require.New(t)
+
+	type line struct {
+		SKU    ulid.ULID
+		Amount decimal.Decimal
+	}
+	type order struct {
+		ID      uuid.UUID `key:"id"`
+		Created time.Time `key:"created"`
+		Lines   []line    `key:"lines"`
+		Parent  *order    `key:"parent"`
+		Count   int       `key:"count"`
+		Labels  map[string]time.Time
+	}
+	type d struct {
+		X map[string]interface{}
+	}
+	type s struct {
+		X order
+	}
+
+	u := uuid.New()
+	sku := ulid.Make()
+	tm := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	src := s{X: order{
+		ID:      u,
+		Created: tm,
+		Lines:   []line{{SKU: sku, Amount: decimal.RequireFromString("1.5")}},
+		Count:   2,
+		Labels:  map[string]time.Time{"paid": tm},
+	}}
+	src.X.Parent = &order{ID: u, Created: tm}
+
+	var dst d
+	req.NoError(TypedCopy(&dst, &src, WithNormalizedMaps(), WithDecimalFormat(DecimalFormat{Scale: 2})))
+	req.Equal(map[string]interface{}{
+		"id":      u.String(),
+		"created": "2024-05-06T07:08:09Z",
+		"lines": []interface{}{
+			map[string]interface{}{"SKU": sku.String(), "Amount": "1.50"},
+		},
+		"parent": map[string]interface{}{
+			"id":      u.String(),
+			"created": "2024-05-06T07:08:09Z",
+			"lines":   nil,
+			"parent":  nil,
+			"count":   0,
+			"Labels":  nil,
+		},
+		"count":  2,
+		"Labels": map[string]interface{}{"paid": "2024-05-06T07:08:09Z"},
+	}, dst.X)
+
+	dst = d{}
+	req.NoError(TypedCopy(&dst, &src))
+	req.Equal(u, dst.X["id"])
+}
+
 func TestStructFromMap(t *testing.T) {
 	req := require.New(t)
 
@@ -1458,11 +2307,44 @@ func TestStructFromMap(t *testing.T) {
 
 	src := s{X: map[string]interface{}{"S": "abcd", "Num": 1234}}
 	var dst d
-	err := Copy(&dst, &src)
+	err := TypedCopy(&dst, &src)
 	req.NoError(err)
 	req.Equal(p{S: "abcd", N: 1234}, dst.X)
 }
 
+func TestStructMapKeyAliases(t *testing.T) {
+	req := require.New(t)
+
+	type p struct {
+		CustomerID string `key:"customer_id,customerId,cid"`
+		Name       string
+	}
+	type d struct {
+		X p
+	}
+	type s struct {
+		X map[string]interface{}
+	}
+
+	var dst d
+	req.NoError(TypedCopy(&dst, &s{X: map[string]interface{}{"customerId": "c1", "cid": "c2", "Name": "n"}}))
+	req.Equal(p{CustomerID: "c1", Name: "n"}, dst.X)
+
+	req.NoError(TypedCopy(&dst, &s{X: map[string]interface{}{"customer_id": "c0", "cid": "c2", "Name": "n"}}))
+	req.Equal(p{CustomerID: "c0", Name: "n"}, dst.X)
+
+	var m s
+	req.NoError(TypedCopy(&m, &dst))
+	req.Equal(map[string]interface{}{"customer_id": "c0", "Name": "n"}, m.X)
+
+	var q p
+	a, err := NewStructAdapter(&q, &NamingOption{Naming: KeyTags})
+	req.NoError(err)
+	req.NoError(a.Set("cid", "c3"))
+	req.Equal("c3", q.CustomerID)
+	req.ErrorIs(a.Set("CustomerID", "c4"), ErrNoSuchField)
+}
+
 type struct1 struct {
 	ID string
 }
@@ -1484,6 +2366,128 @@ func TestCopyMap(t *testing.T) {
 
 }
 
+func TestCopyAs(t *testing.T) {
+	req := require.New(t)
+	u := uuid.New()
+
+	res, err := CopyAs[struct2](&struct1{ID: u.String()})
+	req.NoError(err)
+	req.Equal(u, res.ID)
+
+	_, err = CopyAs[struct2](&struct1{ID: "xyz"})
+	req.Error(err)
+}
+
+func TestMustCopy(t *testing.T) {
+	req := require.New(t)
+	u := uuid.New()
+
+	req.Equal(u, MustCopyAs[struct2](&struct1{ID: u.String()}).ID)
+	req.Panics(func() {
+		MustCopyAs[struct2](&struct1{ID: "xyz"})
+	})
+
+	var dst struct2
+	MustCopy(&dst, &struct1{ID: u.String()})
+	req.Equal(u, dst.ID)
+	req.Panics(func() {
+		MustCopy(&dst, &struct1{ID: "xyz"})
+	})
+
+	dst = struct2{}
+	MustTypedCopy(&dst, &struct1{ID: u.String()})
+	req.Equal(u, dst.ID)
+	req.Panics(func() {
+		MustTypedCopy(&dst, &struct1{ID: "xyz"})
+	})
+}
+
+func TestCopyInterfaces(t *testing.T) {
+	req := require.New(t)
+	u := uuid.New()
+
+	var (
+		dst struct2
+		src interface{} = &struct1{ID: u.String()}
+	)
+	req.NoError(Copy(&dst, src))
+	req.Equal(u, dst.ID)
+	req.ErrorIs(Copy(&dst, &src), ErrUnsupportedTypePair)
+}
+
+func TestCopyWithOptions(t *testing.T) {
+	type source struct {
+		ID    string
+		Extra int
+	}
+	type target struct {
+		ID uuid.UUID
+	}
+
+	req := require.New(t)
+	u := uuid.New()
+
+	var dst target
+	req.ErrorIs(TypedCopy(&dst, &source{ID: u.String()}), ErrFieldNotFound)
+	req.NoError(TypedCopy(&dst, &source{ID: u.String()}, WithOmitNotFound()))
+	req.Equal(u, dst.ID)
+	_, ok := cachedCopier(copierTypePair{
+		dst:  reflect.TypeFor[target](),
+		src:  reflect.TypeFor[source](),
+		opts: canonicalOptions(NewCopierOptions(WithOmitNotFound())),
+	})
+	req.True(ok)
+
+	m := map[string]interface{}{}
+	req.NoError(TypedCopy(&m, &source{ID: "abc", Extra: 1}, WithEngine(EngineAdapter)))
+	req.Equal(map[string]interface{}{"ID": "abc", "Extra": 1}, m)
+
+	var a Adapter
+	a, err := NewMapAdapter(map[string]interface{}{"ID": u.String()})
+	req.NoError(err)
+	dst = target{}
+	req.NoError(TypedCopy(&dst, &a, WithEngine(EngineAdapter)))
+	req.Equal(u, dst.ID)
+}
+
+func TestCopyAllocations(t *testing.T) {
+	type source struct {
+		ID   int
+		Name string
+		Tags []string
+	}
+	type target struct {
+		ID   int
+		Name string
+		Tags []string
+	}
+
+	req := require.New(t)
+
+	src := &source{ID: 1, Name: "a", Tags: []string{"a"}}
+	req.NoError(TypedCopy(&target{}, src))
+	allocs := testing.AllocsPerRun(100, func() {
+		var dst target
+		if err := TypedCopy(&dst, src); err != nil {
+			t.Fatal(err)
+		}
+	})
+	req.LessOrEqual(allocs, 1.0, "only the destination may be allocated")
+
+	req.ErrorIs(TypedCopy(&target{}, (*source)(nil)), ErrNilPointer)
+
+	var seen *source
+	req.NoError(TypedCopy(&target{}, src, func(o *CopierOptions) {
+		o.Computed = map[string]func(interface{}) (interface{}, error){
+			"Name": func(x interface{}) (interface{}, error) {
+				seen = x.(*source)
+				return nil, nil
+			},
+		}
+	}))
+	req.Same(src, seen, "hooks receive the caller's source object")
+}
+
 func ExampleCopy() {
 	type source struct {
 		ID string
@@ -1536,3 +2540,452 @@ func ExampleCopy_third() {
 	}
 	// Output: error: invalid UUID format
 }
+
+type timeZoneDst struct {
+	At      time.Time
+	AtPtr   *time.Time
+	FromPB  time.Time
+	AsText  string
+	Parsed  time.Time
+	ToPB    *timestamppb.Timestamp
+	MaybeAt maybe.Maybe[time.Time]
+}
+
+type timeZoneSrc struct {
+	At      time.Time
+	AtPtr   *time.Time
+	FromPB  *timestamppb.Timestamp
+	AsText  time.Time
+	Parsed  string
+	ToPB    time.Time
+	MaybeAt *timestamppb.Timestamp
+}
+
+func TestCopierTimeLocation(t *testing.T) {
+	req := require.New(t)
+
+	prague := time.FixedZone("CEST", 2*3600)
+	at := time.Date(2024, 5, 1, 23, 30, 0, 0, time.FixedZone("EST", -5*3600))
+	src := timeZoneSrc{
+		At:      at,
+		AtPtr:   &at,
+		FromPB:  timestamppb.New(at),
+		AsText:  at,
+		Parsed:  "2024-05-01T23:30:00-05:00",
+		ToPB:    at,
+		MaybeAt: timestamppb.New(at),
+	}
+
+	copier, err := CopierForPairWithOptions(reflect.TypeFor[timeZoneDst](), reflect.TypeFor[timeZoneSrc](), &CopierOptions{TimeLocation: time.UTC, TimeStrings: true})
+	req.NoError(err)
+	var dst timeZoneDst
+	req.NoError(copier(unsafe.Pointer(&dst), unsafe.Pointer(&src)))
+	req.Equal(time.UTC, dst.At.Location())
+	req.Equal(time.UTC, dst.AtPtr.Location())
+	req.Equal(time.UTC, dst.FromPB.Location())
+	req.Equal("2024-05-02T04:30:00Z", dst.AsText)
+	req.Equal(time.UTC, dst.Parsed.Location())
+	req.True(dst.Parsed.Equal(at))
+	req.True(dst.ToPB.AsTime().Equal(at))
+	req.Equal(time.UTC, dst.MaybeAt.Val.Location())
+
+	copier, err = CopierForPairWithOptions(reflect.TypeFor[timeZoneDst](), reflect.TypeFor[timeZoneSrc](), &CopierOptions{TimeLocation: prague, TimeStrings: true})
+	req.NoError(err)
+	dst = timeZoneDst{}
+	req.NoError(copier(unsafe.Pointer(&dst), unsafe.Pointer(&src)))
+	req.Equal(prague, dst.At.Location())
+	req.Equal(prague, dst.FromPB.Location())
+	req.Equal("2024-05-02T06:30:00+02:00", dst.AsText)
+	req.Equal(prague, dst.MaybeAt.Val.Location())
+	req.True(dst.At.Equal(at))
+
+	_, err = CopierForPair(reflect.TypeFor[timeZoneDst](), reflect.TypeFor[timeZoneSrc]())
+	req.Error(err)
+
+	copier, err = CopierForPairWithOptions(reflect.TypeFor[timeZoneDst](), reflect.TypeFor[timeZoneSrc](), &CopierOptions{TimeStrings: true})
+	req.NoError(err)
+	dst = timeZoneDst{}
+	req.NoError(copier(unsafe.Pointer(&dst), unsafe.Pointer(&src)))
+	req.Equal(at.Location(), dst.At.Location())
+	req.Equal("2024-05-01T23:30:00-05:00", dst.AsText)
+}
+
+func TestCopierTimePrecision(t *testing.T) {
+	req := require.New(t)
+
+	now := time.Now()
+	at := time.Date(2024, 5, 1, 23, 30, 0, 123456789, time.UTC)
+	src := timeZoneSrc{
+		At:      now,
+		AtPtr:   &at,
+		FromPB:  timestamppb.New(at),
+		AsText:  at,
+		Parsed:  "2024-05-01T23:30:00.123456789Z",
+		ToPB:    at,
+		MaybeAt: timestamppb.New(at),
+	}
+
+	var dst timeZoneDst
+	req.NoError(TypedCopy(&dst, &src, WithTimeStrings(), WithStripMonotonic()))
+	req.Equal(now.Round(0), dst.At)
+	req.NotEqual(now, dst.At)
+	req.True(dst.At.Equal(now))
+	req.Equal(at, *dst.AtPtr)
+
+	dst = timeZoneDst{}
+	req.NoError(TypedCopy(&dst, &src, WithTimeStrings(), WithTimePrecision(time.Millisecond)))
+	millis := time.Date(2024, 5, 1, 23, 30, 0, 123000000, time.UTC)
+	req.Equal(now.Truncate(time.Millisecond), dst.At)
+	req.Equal(millis, *dst.AtPtr)
+	req.Equal(millis, dst.FromPB)
+	req.Equal("2024-05-01T23:30:00.123Z", dst.AsText)
+	req.Equal(millis, dst.Parsed)
+	req.Equal(millis, dst.ToPB.AsTime())
+	req.Equal(millis, dst.MaybeAt.Val)
+
+	var roundTrip timeZoneSrc
+	req.NoError(TypedCopy(&dst, &timeZoneSrc{At: now, ToPB: now}, WithTimeStrings(), WithTimePrecision(time.Microsecond)))
+	req.NoError(TypedCopy(&roundTrip, &timeZoneDst{ToPB: dst.ToPB}, WithOmitNotFound(), WithTimeStrings(), WithTimePrecision(time.Microsecond)))
+	req.Equal(dst.At.In(time.UTC), roundTrip.ToPB)
+}
+
+func TestCopierDecimalFormat(t *testing.T) {
+	type price struct {
+		Amount   decimal.Decimal
+		Tax      *decimal.Decimal
+		Discount decimal.Decimal
+		Rates    []decimal.Decimal
+	}
+	type priceDTO struct {
+		Amount   string
+		Tax      string
+		Discount string `copy:",scale=1"`
+		Rates    []string
+	}
+
+	req := require.New(t)
+
+	tax := decimal.RequireFromString("2.125")
+	src := price{
+		Amount:   decimal.RequireFromString("10000"),
+		Tax:      &tax,
+		Discount: decimal.RequireFromString("0.25"),
+		Rates:    []decimal.Decimal{decimal.RequireFromString("1e3")},
+	}
+
+	var dst priceDTO
+	req.NoError(TypedCopy(&dst, &src))
+	req.Equal(priceDTO{Amount: "10000", Tax: "2.125", Discount: "0.3", Rates: []string{"1000"}}, dst)
+
+	dst = priceDTO{}
+	req.NoError(TypedCopy(&dst, &src, WithDecimalFormat(DecimalFormat{Scale: 2, Mode: RoundHalfEven})))
+	req.Equal(priceDTO{Amount: "10000.00", Tax: "2.12", Discount: "0.2", Rates: []string{"1000.00"}}, dst)
+
+	dst = priceDTO{}
+	req.NoError(TypedCopy(&dst, &src, WithFieldDecimalFormat("Tax", DecimalFormat{Scale: 4}), WithFieldDecimalFormat("Discount", DecimalFormat{Scale: 0})))
+	req.Equal(priceDTO{Amount: "10000", Tax: "2.1250", Discount: "0", Rates: []string{"1000"}}, dst)
+}
+
+type timestampStringDst struct {
+	At      *timestamppb.Timestamp
+	Missing *timestamppb.Timestamp
+	Text    string
+	Empty   string
+}
+
+type timestampStringSrc struct {
+	At      string
+	Missing string
+	Text    *timestamppb.Timestamp
+	Empty   *timestamppb.Timestamp
+}
+
+func TestCopierTimestampString(t *testing.T) {
+	req := require.New(t)
+
+	at := time.Date(2024, 5, 1, 12, 30, 15, 500, time.UTC)
+	var dst timestampStringDst
+	_, err := CopierForPair(reflect.TypeFor[timestampStringDst](), reflect.TypeFor[timestampStringSrc]())
+	req.Error(err)
+	err = TypedCopy(&dst, &timestampStringSrc{
+		At:   "2024-05-01T14:30:15.0000005+02:00",
+		Text: timestamppb.New(at),
+	}, WithTimeStrings())
+	req.NoError(err)
+	req.True(dst.At.AsTime().Equal(at))
+	req.Nil(dst.Missing)
+	req.Equal("2024-05-01T12:30:15.0000005Z", dst.Text)
+	req.Empty(dst.Empty)
+
+	err = TypedCopy(&dst, &timestampStringSrc{At: "yesterday"}, WithTimeStrings())
+	req.Error(err)
+
+	copier, err := CopierForPairWithOptions(reflect.TypeFor[timestampStringDst](), reflect.TypeFor[timestampStringSrc](), &CopierOptions{
+		TimeLayout:   time.DateTime,
+		TimeLocation: time.FixedZone("CEST", 2*3600),
+		TimeStrings:  true,
+	})
+	req.NoError(err)
+	dst = timestampStringDst{}
+	err = copier(unsafe.Pointer(&dst), unsafe.Pointer(&timestampStringSrc{
+		At:   "2024-05-01 12:30:15",
+		Text: timestamppb.New(at),
+	}))
+	req.NoError(err)
+	req.True(dst.At.AsTime().Equal(at.Truncate(time.Second)))
+	req.Equal("2024-05-01 14:30:15", dst.Text)
+}
+
+type dateLocationDst struct {
+	FromPB    date.Date
+	FromTime  date.Date
+	MaybeDate maybe.Maybe[date.Date]
+}
+
+type dateLocationSrc struct {
+	FromPB    *timestamppb.Timestamp
+	FromTime  time.Time
+	MaybeDate *timestamppb.Timestamp
+}
+
+func TestCopierDateLocation(t *testing.T) {
+	req := require.New(t)
+
+	// 23:30 UTC is already the next day in the warehouse
+	at := time.Date(2024, 5, 1, 23, 30, 0, 0, time.UTC)
+	src := dateLocationSrc{
+		FromPB:    timestamppb.New(at),
+		FromTime:  at,
+		MaybeDate: timestamppb.New(at),
+	}
+
+	copier, err := CopierForPair(reflect.TypeFor[dateLocationDst](), reflect.TypeFor[dateLocationSrc]())
+	req.NoError(err)
+	var dst dateLocationDst
+	req.NoError(copier(unsafe.Pointer(&dst), unsafe.Pointer(&src)))
+	req.Equal(date.New(2024, 5, 1), dst.FromPB)
+	req.Equal(date.New(2024, 5, 1), dst.FromTime)
+	req.Equal(maybe.Unit(date.New(2024, 5, 1)), dst.MaybeDate)
+
+	warehouse := time.FixedZone("CEST", 2*3600)
+	copier, err = CopierForPairWithOptions(reflect.TypeFor[dateLocationDst](), reflect.TypeFor[dateLocationSrc](), &CopierOptions{DateLocation: warehouse})
+	req.NoError(err)
+	dst = dateLocationDst{}
+	req.NoError(copier(unsafe.Pointer(&dst), unsafe.Pointer(&src)))
+	req.Equal(date.New(2024, 5, 2), dst.FromPB)
+	req.Equal(date.New(2024, 5, 2), dst.FromTime)
+	req.Equal(maybe.Unit(date.New(2024, 5, 2)), dst.MaybeDate)
+}
+
+func TestCopierNestedDestination(t *testing.T) {
+	type address struct {
+		Street string
+		City   string
+		zip    string
+	}
+	type contact struct {
+		Phone string
+	}
+	type customer struct {
+		Name  string
+		City  string
+		Phone string
+	}
+	type customerRow struct {
+		Name    string
+		Address *address
+		*contact
+	}
+
+	req := require.New(t)
+
+	opts := &CopierOptions{FieldRenames: map[string]string{"City": "Address.City"}}
+	_, err := CopierForPairWithOptions(reflect.TypeFor[customerRow](), reflect.TypeFor[customer](), opts)
+	req.ErrorIs(err, ErrFieldNotFound)
+
+	opts = &CopierOptions{FieldRenames: map[string]string{"City": "Address.City"}, EmbeddedPointers: true}
+	copier, err := CopierForPairWithOptions(reflect.TypeFor[customerRow](), reflect.TypeFor[customer](), opts)
+	req.NoError(err)
+
+	var dst customerRow
+	req.NoError(copier(unsafe.Pointer(&dst), unsafe.Pointer(&customer{Name: "John", City: "Prague", Phone: "123"})))
+	req.Equal("John", dst.Name)
+	req.Equal(&address{City: "Prague"}, dst.Address)
+	req.Equal(&contact{Phone: "123"}, dst.contact)
+
+	addr := &address{Street: "Main"}
+	dst = customerRow{Address: addr}
+	req.NoError(copier(unsafe.Pointer(&dst), unsafe.Pointer(&customer{City: "Brno"})))
+	req.Same(addr, dst.Address)
+	req.Equal(address{Street: "Main", City: "Brno"}, *addr)
+
+	opts = &CopierOptions{FieldRenames: map[string]string{"City": "Address.City"}, EmbeddedPointers: true, NoAutoAlloc: true}
+	copier, err = CopierForPairWithOptions(reflect.TypeFor[customerRow](), reflect.TypeFor[customer](), opts)
+	req.NoError(err)
+	dst = customerRow{}
+	req.NoError(copier(unsafe.Pointer(&dst), unsafe.Pointer(&customer{Name: "John", City: "Prague", Phone: "123"})))
+	req.Equal(customerRow{Name: "John"}, dst)
+
+	_, err = CopierForPairWithOptions(reflect.TypeFor[customerRow](), reflect.TypeFor[customer](), &CopierOptions{FieldRenames: map[string]string{"City": "Address.Zip"}})
+	req.ErrorIs(err, ErrFieldNotFound)
+	_, err = CopierForPairWithOptions(reflect.TypeFor[customerRow](), reflect.TypeFor[customer](), &CopierOptions{
+		FieldRenames:     map[string]string{"City": "Address.zip"},
+		EmbeddedPointers: true,
+	})
+	req.ErrorIs(err, ErrFieldNotFound)
+	_, err = CopierForPairWithOptions(reflect.TypeFor[customerRow](), reflect.TypeFor[customer](), &CopierOptions{
+		FieldRenames:     map[string]string{"Town": "Address.City"},
+		EmbeddedPointers: true,
+	})
+	var ce *CopyError
+	req.ErrorAs(err, &ce)
+	req.ErrorIs(err, ErrFieldNotFound)
+	req.Equal("Town", ce.Path())
+	_, err = CopierForPairWithOptions(reflect.TypeFor[customerRow](), reflect.TypeFor[customer](), &CopierOptions{
+		FieldRenames:     map[string]string{"Town": "Address.City"},
+		EmbeddedPointers: true,
+		OmitNotFound:     true,
+	})
+	req.NoError(err)
+}
+
+func TestCopierFieldRenames(t *testing.T) {
+	type person struct {
+		Name  string
+		Email string
+	}
+	type personRow struct {
+		FullName string
+		Mail     string
+	}
+
+	req := require.New(t)
+
+	opts := &CopierOptions{
+		FieldRenames: map[string]string{"Name": "FullName", "Email": "Mail"},
+	}
+	copier, err := CopierForPairWithOptions(reflect.TypeFor[personRow](), reflect.TypeFor[person](), opts)
+	req.NoError(err)
+	var dst personRow
+	req.NoError(copier(unsafe.Pointer(&dst), unsafe.Pointer(&person{Name: "John", Email: "john@example.com"})))
+	req.Equal(personRow{FullName: "John", Mail: "john@example.com"}, dst)
+
+	h, err := HandleForPair(reflect.TypeFor[personRow](), reflect.TypeFor[person](), opts)
+	req.NoError(err)
+	rev, irreversible, err := h.ReverseCopier()
+	req.NoError(err)
+	req.Empty(irreversible)
+	var src person
+	req.NoError(rev.Copy(&src, &dst))
+	req.Equal(person{Name: "John", Email: "john@example.com"}, src)
+}
+
+func TestCopierReuseSlices(t *testing.T) {
+	type scoreValue int32
+	type row struct {
+		IDs    []string
+		Scores []int32
+	}
+	type rowDTO struct {
+		IDs    []uuid.UUID
+		Scores []scoreValue
+	}
+
+	req := require.New(t)
+
+	copier, err := CopierForPairWithOptions(reflect.TypeFor[rowDTO](), reflect.TypeFor[row](), &CopierOptions{ReuseSlices: true})
+	req.NoError(err)
+
+	u1, u2 := uuid.New(), uuid.New()
+	ids := make([]uuid.UUID, 3, 4)
+	scores := make([]scoreValue, 0, 2)
+	dst := rowDTO{IDs: ids, Scores: scores}
+	req.NoError(copier(unsafe.Pointer(&dst), unsafe.Pointer(&row{IDs: []string{u1.String(), u2.String()}, Scores: []int32{1, 2}})))
+	req.Equal([]uuid.UUID{u1, u2}, dst.IDs)
+	req.Equal([]scoreValue{1, 2}, dst.Scores)
+	req.Same(&ids[0], &dst.IDs[0])
+	req.Equal(uuid.Nil, ids[2])
+	req.Same(&scores[:1][0], &dst.Scores[0])
+
+	req.NoError(copier(unsafe.Pointer(&dst), unsafe.Pointer(&row{IDs: []string{u1.String(), u2.String(), u1.String(), u2.String(), u1.String()}, Scores: []int32{3}})))
+	req.Len(dst.IDs, 5)
+	req.NotSame(&ids[0], &dst.IDs[0])
+	req.Equal([]scoreValue{3}, dst.Scores)
+	req.Same(&scores[:1][0], &dst.Scores[0])
+}
+
+func TestCopierShareSlices(t *testing.T) {
+	type item struct {
+		ID   int
+		Tags []string
+	}
+	type result struct {
+		Items []item
+		Names []string
+	}
+	type resultDTO struct {
+		Items []item
+		Names []string `copy:",sort"`
+	}
+
+	req := require.New(t)
+
+	src := result{Items: []item{{ID: 1, Tags: []string{"a"}}, {ID: 2}}, Names: []string{"b", "a"}}
+	var dst resultDTO
+	req.NoError(TypedCopy(&dst, &src, WithDeepCopy(), WithShareSlices()))
+	req.Equal(src.Items, dst.Items)
+	req.Same(&src.Items[0], &dst.Items[0])
+	req.Equal([]string{"a", "b"}, dst.Names)
+	req.Equal([]string{"b", "a"}, src.Names)
+
+	dst = resultDTO{}
+	req.NoError(TypedCopy(&dst, &src, WithDeepCopy()))
+	req.NotSame(&src.Items[0], &dst.Items[0])
+	req.NotSame(&src.Items[0].Tags[0], &dst.Items[0].Tags[0])
+
+	dst = resultDTO{Items: []item{{ID: 3}}}
+	req.NoError(TypedCopy(&dst, &result{}, WithShareSlices(), WithNilPolicy(NilZero)))
+	req.Nil(dst.Items)
+}
+
+func TestCopierNestedSlices(t *testing.T) {
+	type cell struct {
+		ID string
+	}
+	type cellDTO struct {
+		ID uuid.UUID
+	}
+	type grid struct {
+		IDs   [][]string
+		Cells [][]*cell
+		Cube  [][][]string
+	}
+	type gridDTO struct {
+		IDs   [][]uuid.UUID
+		Cells [][]*cellDTO
+		Cube  [][][]uuid.UUID
+	}
+
+	req := require.New(t)
+
+	u1, u2 := uuid.New(), uuid.New()
+	var dst gridDTO
+	req.NoError(TypedCopy(&dst, &grid{
+		IDs:   [][]string{{u1.String()}, nil, {u1.String(), u2.String()}},
+		Cells: [][]*cell{{{ID: u1.String()}, nil}, {}},
+		Cube:  [][][]string{{{u2.String()}}},
+	}))
+	req.Equal([][]uuid.UUID{{u1}, nil, {u1, u2}}, dst.IDs)
+	req.Equal([][]*cellDTO{{{ID: u1}, nil}, {}}, dst.Cells)
+	req.Equal([][][]uuid.UUID{{{u2}}}, dst.Cube)
+
+	err := TypedCopy(&dst, &grid{IDs: [][]string{{u1.String()}, {u2.String(), "uuid"}}})
+	req.EqualError(err, "invalid UUID length: 4 index=1")
+	var ce *CopyError
+	req.ErrorAs(err, &ce)
+	req.Equal("IDs.1.1", ce.Path())
+
+	err = TypedCopy(&dst, &grid{Cells: [][]*cell{{{ID: u1.String()}}, {nil, {ID: "uuid"}}}})
+	req.ErrorAs(err, &ce)
+	req.Equal("Cells.1.1.ID", ce.Path())
+}
@@ -0,0 +1,12 @@
+// Command copiercheck checks the pairs of types passed to the copier.
+// It can be run on its own or by go vet with the -vettool flag.
+package main
+
+import (
+	"github.com/mailstepcz/keyvalue/copiercheck"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(copiercheck.Analyzer)
+}
@@ -0,0 +1,358 @@
+// Package copiercheck defines an analyzer which checks the pairs of types passed to the copier
+// and reports the pairs for which no copier could be created.
+//
+// The analyzer recognises calls of CopierForPair, TypedCopierForPair, NewerCopy, NewCopy, Copy and TypedCopy
+// as well as calls of CopierForPairWith without options and CopierForPairWithOptions and HandleForPair with nil options.
+// It's conservative, i.e. it only reports missing and ambiguous fields, non-structure types and pairs of basic types
+// which can't be converted. Fields of structures with protobuf oneofs aren't checked and neither are fields promoted
+// through embedded pointers and neither are fields converted by named converters selected by their tags, e.g. `copy:",conv=name"`.
+// Integer-backed closed enums are accepted in place of strings like the copier converts them from and to their names.
+package copiercheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"reflect"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const keyvaluePath = "github.com/mailstepcz/keyvalue"
+
+// Analyzer reports pairs of types for which the copier would fail.
+var Analyzer = &analysis.Analyzer{
+	Name:     "copiercheck",
+	Doc:      "check that copiers can be created for the pairs of types passed to the copier",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	insp.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		if !ok || fn.Pkg() == nil || fn.Pkg().Path() != keyvaluePath {
+			return
+		}
+		dst, src := callPair(pass, call, fn)
+		if dst == nil || src == nil {
+			return
+		}
+		c := &checker{visited: make(map[[2]types.Type]bool)}
+		if problem := c.checkPair(dst, src, nil); problem != "" {
+			pass.Reportf(call.Pos(), "no copier to %s from %s: %s", dst, src, problem)
+		}
+	})
+	return nil, nil
+}
+
+// callPair resolves the destination and source types of a call of the copier.
+func callPair(pass *analysis.Pass, call *ast.CallExpr, fn *types.Func) (types.Type, types.Type) {
+	switch fn.Name() {
+	case "CopierForPair", "CopierForPairWith":
+		if len(call.Args) == 2 {
+			return reflectedType(pass, call.Args[0]), reflectedType(pass, call.Args[1])
+		}
+	case "CopierForPairWithOptions", "HandleForPair":
+		if len(call.Args) == 3 && isNil(pass, call.Args[2]) {
+			return reflectedType(pass, call.Args[0]), reflectedType(pass, call.Args[1])
+		}
+	case "TypedCopierForPair", "NewerCopy":
+		if targs := typeArgs(pass, call.Fun); targs != nil && targs.Len() == 2 {
+			return targs.At(0), targs.At(1)
+		}
+	case "NewCopy", "Copy", "TypedCopy":
+		if len(call.Args) == 2 {
+			return pointee(pass.TypesInfo.TypeOf(call.Args[0])), pointee(pass.TypesInfo.TypeOf(call.Args[1]))
+		}
+	}
+	return nil, nil
+}
+
+// typeArgs returns the type arguments of the instantiated generic function.
+func typeArgs(pass *analysis.Pass, fun ast.Expr) *types.TypeList {
+	switch f := ast.Unparen(fun).(type) {
+	case *ast.IndexExpr:
+		fun = f.X
+	case *ast.IndexListExpr:
+		fun = f.X
+	}
+	var id *ast.Ident
+	switch f := ast.Unparen(fun).(type) {
+	case *ast.Ident:
+		id = f
+	case *ast.SelectorExpr:
+		id = f.Sel
+	default:
+		return nil
+	}
+	return pass.TypesInfo.Instances[id].TypeArgs
+}
+
+// reflectedType resolves the type represented by an expression of type [reflect.Type].
+// It understands reflect.TypeFor[T](), reflect.TypeOf(x) and reflect.TypeOf((*T)(nil)).Elem().
+func reflectedType(pass *analysis.Pass, e ast.Expr) types.Type {
+	call, ok := ast.Unparen(e).(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	if sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr); ok && sel.Sel.Name == "Elem" && len(call.Args) == 0 {
+		return pointee(reflectedType(pass, sel.X))
+	}
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != "reflect" {
+		return nil
+	}
+	switch fn.Name() {
+	case "TypeFor":
+		if targs := typeArgs(pass, call.Fun); targs != nil && targs.Len() == 1 {
+			return targs.At(0)
+		}
+	case "TypeOf":
+		if len(call.Args) == 1 {
+			return pass.TypesInfo.TypeOf(call.Args[0])
+		}
+	}
+	return nil
+}
+
+func pointee(t types.Type) types.Type {
+	if t == nil {
+		return nil
+	}
+	if p, ok := t.Underlying().(*types.Pointer); ok {
+		return p.Elem()
+	}
+	return nil
+}
+
+func isNil(pass *analysis.Pass, e ast.Expr) bool {
+	tv, ok := pass.TypesInfo.Types[e]
+	return ok && tv.IsNil()
+}
+
+// checker checks pairs of types mirroring the rules of the copier.
+type checker struct {
+	visited map[[2]types.Type]bool
+}
+
+// checkPair checks a pair of structures and returns the description of the problem or an empty string.
+func (c *checker) checkPair(dst, src types.Type, path []string) string {
+	key := [2]types.Type{dst, src}
+	if c.visited[key] {
+		return ""
+	}
+	c.visited[key] = true
+	dstStruct, ok1 := dst.Underlying().(*types.Struct)
+	srcStruct, ok2 := src.Underlying().(*types.Struct)
+	if !ok1 || !ok2 {
+		return "type not struct"
+	}
+	if hasOneof(dstStruct) || hasOneof(srcStruct) {
+		return ""
+	}
+	// renamed are the destination fields renamed by their tags keyed by the names of the source fields
+	renamed := make(map[string]string)
+	dstTags := make(map[string]reflect.StructTag)
+	for _, g := range visibleFields(dstStruct) {
+		if name := copyTagName(g.tag); name != "" {
+			renamed[name] = g.Name()
+		}
+		dstTags[g.Name()] = g.tag
+	}
+	for _, f := range visibleFields(srcStruct) {
+		fieldPath := append(path[:len(path):len(path)], f.Name())
+		name := f.Name()
+		if rename := copyTagName(f.tag); rename != "" {
+			if strings.Contains(rename, ".") {
+				// nested renames aren't checked
+				continue
+			}
+			name = rename
+		} else if rename, ok := renamed[name]; ok {
+			name = rename
+		}
+		obj, index, _ := types.LookupFieldOrMethod(dst, false, f.Pkg(), name)
+		df, ok := obj.(*types.Var)
+		if !ok {
+			if obj == nil && index != nil {
+				return fmt.Sprintf("field %s is ambiguous in %s", strings.Join(fieldPath, "."), dst)
+			}
+			return fmt.Sprintf("field %s not found in %s", strings.Join(fieldPath, "."), dst)
+		}
+		if tagConverterName(f.tag) != "" || tagConverterName(dstTags[df.Name()]) != "" {
+			// named converters are registered at run time
+			continue
+		}
+		if problem := c.checkValue(df.Type(), f.Type(), fieldPath); problem != "" {
+			return problem
+		}
+	}
+	return ""
+}
+
+// checkValue checks a pair of field types. Pairs which the copier might support are accepted.
+func (c *checker) checkValue(dst, src types.Type, path []string) string {
+	if types.Identical(dst, src) {
+		return ""
+	}
+	switch d := dst.Underlying().(type) {
+	case *types.Struct:
+		if _, ok := src.Underlying().(*types.Struct); ok && isPlain(dst) && isPlain(src) {
+			return c.checkPair(dst, src, path)
+		}
+	case *types.Pointer:
+		if s, ok := src.Underlying().(*types.Pointer); ok && isPlain(dst) && isPlain(src) {
+			return c.checkValue(d.Elem(), s.Elem(), path)
+		}
+	case *types.Slice:
+		if s, ok := src.Underlying().(*types.Slice); ok {
+			return c.checkValue(d.Elem(), s.Elem(), path)
+		}
+	case *types.Basic:
+		s, ok := src.Underlying().(*types.Basic)
+		if !ok || !isPlain(dst) || !isPlain(src) || types.ConvertibleTo(src, dst) {
+			break
+		}
+		// integer-backed closed enums are converted from and to their names
+		if isIntEnum(dst) && s.Info()&types.IsString != 0 && hasMethod(types.NewPointer(dst), "UnmarshalText") {
+			break
+		}
+		if isIntEnum(src) && d.Info()&types.IsString != 0 && hasMethod(src, "String") {
+			break
+		}
+		return fmt.Sprintf("field %s can't be copied to %s from %s", strings.Join(path, "."), dst, src)
+	}
+	return ""
+}
+
+// isPlain checks whether the type has no special meaning for the copier, i.e. it isn't converted implicitly
+// and it isn't a maybe, required or self-copying type.
+func isPlain(t types.Type) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return true
+	}
+	if pkg := named.Obj().Pkg(); pkg != nil && slices.ContainsFunc(specialPackages, func(path string) bool {
+		return pkg.Path() == path || strings.HasPrefix(pkg.Path(), path+"/")
+	}) {
+		return false
+	}
+	mset := types.NewMethodSet(types.NewPointer(named))
+	for _, m := range specialMethods {
+		if mset.Lookup(nil, m) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	// specialPackages are the packages whose types are converted implicitly by the copier.
+	specialPackages = []string{
+		"time",
+		"github.com/google/uuid",
+		"github.com/oklog/ulid",
+		"github.com/shopspring/decimal",
+		"github.com/rickb777/date",
+		"golang.org/x/text/language",
+		"github.com/jackc/pgx/v5/pgtype",
+		"google.golang.org/protobuf/types/known",
+	}
+	// specialMethods are the methods of maybe, required and self-copying types.
+	specialMethods = []string{"MaybeType", "RequiredType", "CanCopyTo"}
+)
+
+// isIntEnum checks whether the type is an integer-backed closed enum.
+func isIntEnum(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&types.IsInteger != 0 &&
+		hasMethod(t, "EnumValueIsValid") && hasMethod(t, "DefaultValue") && hasMethod(t, "Value")
+}
+
+// hasMethod checks whether the method set of the type has the method.
+func hasMethod(t types.Type, name string) bool {
+	return types.NewMethodSet(t).Lookup(nil, name) != nil
+}
+
+// visibleField is a field which the copier copies along with its tag.
+type visibleField struct {
+	*types.Var
+	tag reflect.StructTag
+}
+
+// visibleFields returns the exported fields which the copier copies, including the promoted ones.
+// Shallower fields take precedence over more deeply promoted ones.
+func visibleFields(st *types.Struct) []visibleField {
+	type candidate struct {
+		field visibleField
+		depth int
+	}
+	var (
+		names []string
+		best  = make(map[string]candidate)
+	)
+	var walk func(st *types.Struct, depth int)
+	walk = func(st *types.Struct, depth int) {
+		for i := 0; i < st.NumFields(); i++ {
+			f := st.Field(i)
+			tag := reflect.StructTag(st.Tag(i))
+			if f.Exported() && tag.Get("kv") != "-" {
+				c, ok := best[f.Name()]
+				if !ok {
+					names = append(names, f.Name())
+				}
+				if !ok || depth < c.depth {
+					best[f.Name()] = candidate{field: visibleField{Var: f, tag: tag}, depth: depth}
+				}
+			}
+			if f.Embedded() && depth < 8 {
+				if est, ok := f.Type().Underlying().(*types.Struct); ok {
+					walk(est, depth+1)
+				}
+			}
+		}
+	}
+	walk(st, 0)
+	fields := make([]visibleField, 0, len(names))
+	for _, name := range names {
+		fields = append(fields, best[name].field)
+	}
+	return fields
+}
+
+// copyTagName returns the name of the field of the other structure given in the `copy` tag, e.g. `copy:"CustomerID"`.
+func copyTagName(tag reflect.StructTag) string {
+	name, _, _ := strings.Cut(tag.Get("copy"), ",")
+	return name
+}
+
+// tagConverterName returns the name of the named converter given in the `copy` tag, e.g. `copy:",conv=name"`.
+func tagConverterName(tag reflect.StructTag) string {
+	_, opts, _ := strings.Cut(tag.Get("copy"), ",")
+	for _, opt := range strings.Split(opts, ",") {
+		if name, ok := strings.CutPrefix(opt, "conv="); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+func hasOneof(st *types.Struct) bool {
+	for i := 0; i < st.NumFields(); i++ {
+		if _, ok := reflect.StructTag(st.Tag(i)).Lookup("protobuf_oneof"); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package a
+
+import (
+	"database/sql/driver"
+	"errors"
+	"reflect"
+
+	"github.com/mailstepcz/keyvalue"
+)
+
+type Base struct {
+	ID string
+}
+
+type Inner struct {
+	Street string
+}
+
+type Src struct {
+	Base
+	Name    string
+	Age     int
+	Address Inner
+	Secret  string `kv:"-"`
+}
+
+type Dst struct {
+	Base
+	Name    string
+	Age     int64
+	Address Inner
+}
+
+type DstMissing struct {
+	Base
+	Name    string
+	Address Inner
+}
+
+type DstBadType struct {
+	Base
+	Name    string
+	Age     bool
+	Address Inner
+}
+
+type InnerOther struct {
+	City string
+}
+
+type DstNested struct {
+	Base
+	Name    string
+	Age     int
+	Address *InnerOther
+}
+
+type SrcNested struct {
+	Base
+	Name    string
+	Age     int
+	Address *Inner
+}
+
+type SrcRenamed struct {
+	Base
+	FullName string `copy:"Name"`
+	Years    int
+	Address  Inner
+}
+
+type DstRenamed struct {
+	Base
+	Name    string
+	Age     int `copy:"Years"`
+	Address Inner
+}
+
+type Status int
+
+func (s Status) EnumValueIsValid() bool        { return s >= 0 && s <= 1 }
+func (s Status) DefaultValue() string          { return "open" }
+func (s Status) Value() (driver.Value, error)  { return int64(s), nil }
+func (s Status) String() string                { return [...]string{"open", "closed"}[s] }
+func (s *Status) UnmarshalText(b []byte) error { return errors.New("not implemented") }
+
+type Level int
+
+func (l Level) EnumValueIsValid() bool       { return true }
+func (l Level) DefaultValue() string         { return "low" }
+func (l Level) Value() (driver.Value, error) { return int64(l), nil }
+
+type Ticket struct {
+	Status string
+	Level  string
+	Age    bool
+	Cents  string `copy:",conv=cents"`
+}
+
+type TicketDTO struct {
+	Status Status
+	Level  Level
+	Age    int `copy:",conv=age"`
+	Cents  int64
+}
+
+type TicketView struct {
+	Status string
+	Level  bool
+	Age    string
+	Cents  string
+}
+
+func f() {
+	keyvalue.CopierForPair(reflect.TypeFor[Dst](), reflect.TypeFor[Src]())
+	keyvalue.CopierForPair(reflect.TypeFor[DstMissing](), reflect.TypeFor[Src]())            // want `no copier to a.DstMissing from a.Src: field Age not found in a.DstMissing`
+	keyvalue.CopierForPair(reflect.TypeOf((*DstBadType)(nil)).Elem(), reflect.TypeOf(Src{})) // want `field Age can't be copied to bool from int`
+	keyvalue.CopierForPairWith(reflect.TypeFor[DstMissing](), reflect.TypeFor[Src]())        // want `field Age not found`
+	keyvalue.CopierForPairWith(reflect.TypeFor[DstMissing](), reflect.TypeFor[Src](), nil)
+	keyvalue.CopierForPairWithOptions(reflect.TypeFor[DstMissing](), reflect.TypeFor[Src](), &keyvalue.CopierOptions{})
+	keyvalue.HandleForPair(reflect.TypeFor[DstMissing](), reflect.TypeFor[Src](), nil) // want `field Age not found`
+	keyvalue.TypedCopierForPair[DstMissing, Src]()                                     // want `field Age not found`
+	keyvalue.TypedCopierForPair[int, Src]()                                            // want `type not struct`
+	keyvalue.TypedCopierForPair[DstNested, SrcNested]()                                // want `field Address.Street not found in a.InnerOther`
+	keyvalue.TypedCopierForPair[DstRenamed, SrcRenamed]()
+	keyvalue.TypedCopierForPair[DstMissing, SrcRenamed]() // want `field Years not found`
+	keyvalue.TypedCopierForPair[TicketDTO, Ticket]()      // want `field Level can't be copied to a.Level from string`
+	keyvalue.TypedCopierForPair[TicketView, TicketDTO]()  // want `field Level can't be copied to bool from a.Level`
+
+	var (
+		dst DstMissing
+		src Src
+	)
+	keyvalue.NewerCopy(&dst, &src) // want `field Age not found`
+	keyvalue.Copy(&dst, &src)      // want `field Age not found`
+	keyvalue.TypedCopy(&dst, &src) // want `field Age not found`
+}
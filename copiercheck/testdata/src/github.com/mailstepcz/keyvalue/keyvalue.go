@@ -0,0 +1,44 @@
+package keyvalue
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+type CopierOptions struct{}
+
+type Handle struct{}
+
+func CopierForPair(dstType, srcType reflect.Type) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	return nil, nil
+}
+
+func CopierForPairWith(dstType, srcType reflect.Type, opts ...CopierOption) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	return nil, nil
+}
+
+func CopierForPairWithOptions(dstType, srcType reflect.Type, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	return nil, nil
+}
+
+func HandleForPair(dstType, srcType reflect.Type, opts *CopierOptions) (*Handle, error) {
+	return nil, nil
+}
+
+func TypedCopierForPair[D, S any]() (func(*D, *S) error, error) {
+	return nil, nil
+}
+
+func NewerCopy[T, U any](dst *T, src *U) error {
+	return nil
+}
+
+type CopierOption func(*CopierOptions)
+
+func TypedCopy[D, S any](dst *D, src *S, opts ...CopierOption) error {
+	return nil
+}
+
+func Copy(dst, src interface{}) error {
+	return nil
+}
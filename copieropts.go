@@ -0,0 +1,311 @@
+package keyvalue
+
+import (
+	"reflect"
+	"time"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// Engine is an engine copying objects in [TypedCopy].
+type Engine int
+
+// copy engines
+const (
+	// EngineCompiled copies structures with copiers compiled for their types. It supports all [CopierOptions].
+	EngineCompiled Engine = iota
+	// EngineAdapter copies objects field by field through [Adapter]s like [CopyV1].
+	// Besides structures it copies maps and adapters such as [HeaderAdapter] and [FormAdapter].
+	EngineAdapter
+)
+
+// CopierOption is a functional option configuring the copiers created by [CopierForPairWith].
+// Options are applied in order to a fresh [CopierOptions] so later options override earlier ones.
+type CopierOption func(*CopierOptions)
+
+// NewCopierOptions creates copier options from functional options.
+// The result can be passed on to [CopierForPairWithOptions] and reused so that the copiers are cached.
+func NewCopierOptions(opts ...CopierOption) *CopierOptions {
+	o := new(CopierOptions)
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithOptions sets all options to a copy of the options.
+// It's meant to be used as the first option when extending an existing set of options.
+func WithOptions(opts *CopierOptions) CopierOption {
+	return func(o *CopierOptions) {
+		if opts != nil {
+			*o = *opts
+		}
+	}
+}
+
+// WithOmitNotFound makes source fields which have no matching destination field be skipped.
+func WithOmitNotFound() CopierOption {
+	return func(o *CopierOptions) {
+		o.OmitNotFound = true
+	}
+}
+
+// WithFieldsToCopy restricts the copy to the fields.
+func WithFieldsToCopy(fields ...string) CopierOption {
+	return func(o *CopierOptions) {
+		o.FieldsToCopy = append(o.FieldsToCopy, fields...)
+	}
+}
+
+// WithFieldsToOmit excludes the fields from the copy.
+func WithFieldsToOmit(fields ...string) CopierOption {
+	return func(o *CopierOptions) {
+		o.FieldsToOmit = append(o.FieldsToOmit, fields...)
+	}
+}
+
+// WithFieldMap copies the source fields to the destination fields they're mapped to, like [CopierOptions.FieldRenames].
+func WithFieldMap(fields map[string]string) CopierOption {
+	return func(o *CopierOptions) {
+		renames := make(map[string]string, len(o.FieldRenames)+len(fields))
+		for k, v := range o.FieldRenames {
+			renames[k] = v
+		}
+		for k, v := range fields {
+			renames[k] = v
+		}
+		o.FieldRenames = renames
+	}
+}
+
+// WithNilPolicy sets the policy for handling nil source values.
+func WithNilPolicy(policy NilPolicy) CopierOption {
+	return func(o *CopierOptions) {
+		o.NilPolicy = policy
+	}
+}
+
+// WithEnumPolicy sets the policy for handling invalid closed enum values.
+func WithEnumPolicy(policy EnumPolicy) CopierOption {
+	return func(o *CopierOptions) {
+		o.EnumPolicy = policy
+	}
+}
+
+// WithEnumFallback replaces invalid closed enum values with the default values of the enums like [EnumDefault]
+// and reports the replaced values to the hook.
+func WithEnumFallback(hook func(EnumFallback)) CopierOption {
+	return func(o *CopierOptions) {
+		o.EnumPolicy = EnumDefault
+		o.OnEnumFallback = hook
+	}
+}
+
+// WithNaming sets the strategy matching source fields with destination fields.
+func WithNaming(naming NamingStrategy) CopierOption {
+	return func(o *CopierOptions) {
+		o.Naming = naming
+	}
+}
+
+// WithFieldMask restricts the copy to the destination fields selected by the mask.
+func WithFieldMask(mask *fieldmaskpb.FieldMask) CopierOption {
+	return func(o *CopierOptions) {
+		o.FieldMask = mask
+	}
+}
+
+// WithConverter copies the source field with the custom converter.
+func WithConverter(field string, conv Converter) CopierOption {
+	return func(o *CopierOptions) {
+		converters := make(map[string]Converter, len(o.Converters)+1)
+		for k, v := range o.Converters {
+			converters[k] = v
+		}
+		converters[field] = conv
+		o.Converters = converters
+	}
+}
+
+// WithTypeConverter converts all the values of type S into values of type D with the function
+// like [CopierOptions.TypeConverters].
+func WithTypeConverter[S, D any](f func(S) (D, error)) CopierOption {
+	// the function is wrapped once so that options reusing the option share the cached copiers
+	conv := func(x interface{}) (interface{}, error) {
+		return f(x.(S))
+	}
+	return func(o *CopierOptions) {
+		converters := make(map[TypePair]func(interface{}) (interface{}, error), len(o.TypeConverters)+1)
+		for k, v := range o.TypeConverters {
+			converters[k] = v
+		}
+		converters[TypePair{reflect.TypeFor[S](), reflect.TypeFor[D]()}] = conv
+		o.TypeConverters = converters
+	}
+}
+
+// WithTaggedUnion copies the tagged union in the source structure into the destination field.
+func WithTaggedUnion(field string, union TaggedUnion) CopierOption {
+	return func(o *CopierOptions) {
+		unions := make(map[string]TaggedUnion, len(o.Unions)+1)
+		for k, v := range o.Unions {
+			unions[k] = v
+		}
+		unions[field] = union
+		o.Unions = unions
+	}
+}
+
+// WithInstrument invokes the function after each copy performed by the copier.
+func WithInstrument(f func(CopyStats)) CopierOption {
+	return func(o *CopierOptions) {
+		o.Instrument = f
+	}
+}
+
+// WithTracer traces each copy performed by the copier with the tracer.
+func WithTracer(tracer CopyTracer) CopierOption {
+	return func(o *CopierOptions) {
+		o.Tracer = tracer
+	}
+}
+
+// WithValidate validates the destination object after each successful copy performed by the copier.
+func WithValidate(f func(interface{}) error) CopierOption {
+	return func(o *CopierOptions) {
+		o.Validate = f
+	}
+}
+
+// WithRecoverPanics converts panics in field copiers into errors wrapping [ErrPanic].
+func WithRecoverPanics() CopierOption {
+	return func(o *CopierOptions) {
+		o.RecoverPanics = true
+	}
+}
+
+// WithStrictNumeric makes lossy numeric conversions fail with [ErrPrecisionLoss].
+func WithStrictNumeric() CopierOption {
+	return func(o *CopierOptions) {
+		o.StrictNumeric = true
+	}
+}
+
+// WithDeepCopy makes values of the same type be copied recursively.
+func WithDeepCopy() CopierOption {
+	return func(o *CopierOptions) {
+		o.DeepCopy = true
+	}
+}
+
+// WithReuseSlices makes converted slices be written into the existing destination slices.
+func WithReuseSlices() CopierOption {
+	return func(o *CopierOptions) {
+		o.ReuseSlices = true
+	}
+}
+
+// WithShareSlices makes slices with identical element types share the backing arrays of the source slices.
+func WithShareSlices() CopierOption {
+	return func(o *CopierOptions) {
+		o.ShareSlices = true
+	}
+}
+
+// WithNormalizedMaps makes structures copied into dynamic maps be stored as strings, nested maps and slices
+// like [CopierOptions.NormalizeMaps].
+func WithNormalizedMaps() CopierOption {
+	return func(o *CopierOptions) {
+		o.NormalizeMaps = true
+	}
+}
+
+// WithLimits bounds the source values copied by the copier.
+func WithLimits(limits CopyLimits) CopierOption {
+	return func(o *CopierOptions) {
+		o.Limits = limits
+	}
+}
+
+// WithNoAutoAlloc makes fields nested in the destination through nil pointers be skipped.
+func WithNoAutoAlloc() CopierOption {
+	return func(o *CopierOptions) {
+		o.NoAutoAlloc = true
+	}
+}
+
+// WithEmbeddedPointers makes fields promoted through embedded pointers in the destination be copied.
+func WithEmbeddedPointers() CopierOption {
+	return func(o *CopierOptions) {
+		o.EmbeddedPointers = true
+	}
+}
+
+// WithDecimalFormat formats decimals converted to strings with the format.
+func WithDecimalFormat(format DecimalFormat) CopierOption {
+	return func(o *CopierOptions) {
+		o.DecimalFormat = &format
+	}
+}
+
+// WithFieldDecimalFormat formats the decimals copied from the source field to strings with the format.
+func WithFieldDecimalFormat(field string, format DecimalFormat) CopierOption {
+	return func(o *CopierOptions) {
+		formats := make(map[string]DecimalFormat, len(o.DecimalFormats)+1)
+		for k, v := range o.DecimalFormats {
+			formats[k] = v
+		}
+		formats[field] = format
+		o.DecimalFormats = formats
+	}
+}
+
+// WithTimeLocation makes copied times be converted into the location.
+func WithTimeLocation(loc *time.Location) CopierOption {
+	return func(o *CopierOptions) {
+		o.TimeLocation = loc
+	}
+}
+
+// WithStripMonotonic removes the monotonic clock readings from copied times.
+func WithStripMonotonic() CopierOption {
+	return func(o *CopierOptions) {
+		o.StripMonotonic = true
+	}
+}
+
+// WithTimePrecision truncates copied times to the precision.
+func WithTimePrecision(precision time.Duration) CopierOption {
+	return func(o *CopierOptions) {
+		o.TimePrecision = precision
+	}
+}
+
+// WithTimeLayout sets the layout of times converted from and to strings.
+func WithTimeLayout(layout string) CopierOption {
+	return func(o *CopierOptions) {
+		o.TimeLayout = layout
+	}
+}
+
+// WithTimeStrings enables conversions of times and dates from and to strings.
+func WithTimeStrings() CopierOption {
+	return func(o *CopierOptions) {
+		o.TimeStrings = true
+	}
+}
+
+// WithEngine selects the engine copying objects in [TypedCopy]. The adapter options only apply to [EngineAdapter].
+func WithEngine(engine Engine, opts ...Option) CopierOption {
+	return func(o *CopierOptions) {
+		o.Engine = engine
+		o.AdapterOptions = append(o.AdapterOptions[:len(o.AdapterOptions):len(o.AdapterOptions)], opts...)
+	}
+}
+
+// WithRecorder reports the changes the copier makes to the destination fields to the recorder.
+func WithRecorder(rec ChangeRecorder) CopierOption {
+	return func(o *CopierOptions) {
+		o.Recorder = rec
+	}
+}
@@ -0,0 +1,73 @@
+package keyvalue
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+type optsCustomer struct {
+	Name     string
+	City     string
+	Password string
+	Extra    int
+}
+
+type optsCustomerRow struct {
+	Name     string
+	Town     string
+	Password string
+}
+
+func TestCopierForPairFunctionalOptions(t *testing.T) {
+	req := require.New(t)
+
+	_, err := CopierForPair(reflect.TypeFor[optsCustomerRow](), reflect.TypeFor[optsCustomer]())
+	req.ErrorIs(err, ErrFieldNotFound)
+
+	opts := []CopierOption{
+		WithOmitNotFound(),
+		WithFieldsToOmit("Password"),
+		WithFieldMap(map[string]string{"City": "Town"}),
+	}
+	copier, err := CopierForPairWith(reflect.TypeFor[optsCustomerRow](), reflect.TypeFor[optsCustomer](), opts...)
+	req.NoError(err)
+
+	var dst optsCustomerRow
+	req.NoError(copier(unsafe.Pointer(&dst), unsafe.Pointer(&optsCustomer{Name: "John", City: "Prague", Password: "secret", Extra: 1})))
+	req.Equal(optsCustomerRow{Name: "John", Town: "Prague"}, dst)
+
+	_, ok := cachedCopier(copierTypePair{
+		dst:  reflect.TypeFor[optsCustomerRow](),
+		src:  reflect.TypeFor[optsCustomer](),
+		opts: canonicalOptions(NewCopierOptions(opts...)),
+	})
+	req.True(ok)
+
+	var f func(reflect.Type, reflect.Type) (func(unsafe.Pointer, unsafe.Pointer) error, error) = CopierForPair
+	_, err = f(reflect.TypeFor[optsCustomer](), reflect.TypeFor[optsCustomer]())
+	req.NoError(err)
+}
+
+func TestNewCopierOptions(t *testing.T) {
+	req := require.New(t)
+
+	base := &CopierOptions{OmitNotFound: true, FieldRenames: map[string]string{"A": "B"}}
+	opts := NewCopierOptions(
+		WithOptions(base),
+		WithFieldMap(map[string]string{"C": "D"}),
+		WithFieldsToCopy("A"),
+		WithFieldsToCopy("C"),
+		WithNilPolicy(NilZero),
+		WithStrictNumeric(),
+	)
+	req.True(opts.OmitNotFound)
+	req.Equal(map[string]string{"A": "B", "C": "D"}, opts.FieldRenames)
+	req.Equal(map[string]string{"A": "B"}, base.FieldRenames)
+	req.Equal([]string{"A", "C"}, opts.FieldsToCopy)
+	req.Equal(NilZero, opts.NilPolicy)
+	req.True(opts.StrictNumeric)
+	req.False(opts.NoCache)
+}
@@ -0,0 +1,171 @@
+package keyvalue
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"github.com/mailstepcz/serr"
+	"github.com/mailstepcz/types"
+	"github.com/rickb777/date/v2"
+	"github.com/rickb777/date/v2/timespan"
+)
+
+var (
+	// ErrBadPeriod is returned if a string isn't an ISO 8601 period of days.
+	ErrBadPeriod = errors.New("bad period of days")
+)
+
+var (
+	dateRangeType    = reflect.TypeFor[timespan.DateRange]()
+	periodOfDaysType = reflect.TypeFor[timespan.PeriodOfDays]()
+
+	rangeStructs   = make(map[reflect.Type]bool)
+	rangeStructsMu sync.RWMutex
+)
+
+// RegisterDateRange registers the type T as a structure describing a date range by its From and To fields,
+// which makes it convertible from and to [timespan.DateRange]. The bounds are converted from and to dates
+// like other fields, e.g. from and to strings, times and protobuf timestamps, and strings are always supported.
+// Like in [timespan.DateRange], the range is half-open so the To date isn't included.
+// It panics if T isn't a structure with exported From and To fields.
+func RegisterDateRange[T any]() {
+	t := reflect.TypeFor[T]()
+	if !hasRangeFields(t) {
+		panic(fmt.Sprintf("keyvalue: %s isn't a structure with From and To fields", t))
+	}
+	rangeStructsMu.Lock()
+	defer rangeStructsMu.Unlock()
+	rangeStructs[t] = true
+}
+
+// hasRangeFields checks whether the type is a structure with exported From and To fields.
+func hasRangeFields(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t == dateRangeType {
+		return false
+	}
+	from, ok := t.FieldByName("From")
+	if !ok || len(from.Index) != 1 || from.PkgPath != "" {
+		return false
+	}
+	to, ok := t.FieldByName("To")
+	return ok && len(to.Index) == 1 && to.PkgPath == ""
+}
+
+// isRangeStruct checks whether the type was registered by [RegisterDateRange].
+func isRangeStruct(t reflect.Type) bool {
+	rangeStructsMu.RLock()
+	defer rangeStructsMu.RUnlock()
+	return rangeStructs[t]
+}
+
+// rangeBoundOptions returns the options the bounds of date ranges are converted with.
+// Dates are converted from and to strings in range structures even if the options don't enable it generally.
+func rangeBoundOptions(opts *CopierOptions) *CopierOptions {
+	var o CopierOptions
+	if opts != nil {
+		o = *opts
+	}
+	o.TimeStrings = true
+	return &o
+}
+
+// formatPeriodOfDays formats the number of days as an ISO 8601 period such as P31D.
+func formatPeriodOfDays(p timespan.PeriodOfDays) string {
+	if p < 0 {
+		return "-P" + strconv.Itoa(int(-p)) + "D"
+	}
+	return "P" + strconv.Itoa(int(p)) + "D"
+}
+
+// parsePeriodOfDays parses an ISO 8601 period given in days or weeks such as P31D or P2W.
+func parsePeriodOfDays(s string) (timespan.PeriodOfDays, error) {
+	x, neg := strings.CutPrefix(s, "-")
+	x, ok := strings.CutPrefix(x, "P")
+	if !ok || len(x) < 2 {
+		return 0, serr.Wrap("", ErrBadPeriod, serr.String("period", s))
+	}
+	factor := 1
+	switch x[len(x)-1] {
+	case 'D':
+	case 'W':
+		factor = 7
+	default:
+		return 0, serr.Wrap("", ErrBadPeriod, serr.String("period", s))
+	}
+	n, err := strconv.Atoi(x[:len(x)-1])
+	if err != nil || n < 0 {
+		return 0, serr.Wrap("", ErrBadPeriod, serr.String("period", s))
+	}
+	if neg {
+		n = -n
+	}
+	return timespan.PeriodOfDays(n * factor), nil
+}
+
+// fromDateRangeConv returns a converter of date ranges into range structures.
+// Empty ranges are handled by the nil policy.
+func fromDateRangeConv(dstType reflect.Type, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	from, _ := dstType.FieldByName("From")
+	to, _ := dstType.FieldByName("To")
+	opts = rangeBoundOptions(opts)
+	fromConv, err := valConvWithOptions(from.Type, types.Date, opts)
+	if err != nil {
+		return nil, compileFieldError(err, from.Name, from.Type, types.Date)
+	}
+	toConv, err := valConvWithOptions(to.Type, types.Date, opts)
+	if err != nil {
+		return nil, compileFieldError(err, to.Name, to.Type, types.Date)
+	}
+	onNil := nilHandler(dstType, opts)
+	return func(dst, src unsafe.Pointer) error {
+		r := *(*timespan.DateRange)(src)
+		if r.IsZero() {
+			return onNil(dst)
+		}
+		start, end := r.Start(), r.End()
+		if err := fromConv(unsafe.Add(dst, from.Offset), unsafe.Pointer(&start)); err != nil {
+			return fieldError(err, from.Name, from.Type, types.Date)
+		}
+		if err := toConv(unsafe.Add(dst, to.Offset), unsafe.Pointer(&end)); err != nil {
+			return fieldError(err, to.Name, to.Type, types.Date)
+		}
+		return nil
+	}, nil
+}
+
+// toDateRangeConv returns a converter of range structures into date ranges.
+// Ranges whose bounds are both zero are converted into zero ranges.
+func toDateRangeConv(srcType reflect.Type, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	from, _ := srcType.FieldByName("From")
+	to, _ := srcType.FieldByName("To")
+	opts = rangeBoundOptions(opts)
+	fromConv, err := valConvWithOptions(types.Date, from.Type, opts)
+	if err != nil {
+		return nil, compileFieldError(err, from.Name, types.Date, from.Type)
+	}
+	toConv, err := valConvWithOptions(types.Date, to.Type, opts)
+	if err != nil {
+		return nil, compileFieldError(err, to.Name, types.Date, to.Type)
+	}
+	return func(dst, src unsafe.Pointer) error {
+		s := reflect.NewAt(srcType, src).Elem()
+		if s.Field(from.Index[0]).IsZero() && s.Field(to.Index[0]).IsZero() {
+			*(*timespan.DateRange)(dst) = timespan.DateRange{}
+			return nil
+		}
+		var start, end date.Date
+		if err := fromConv(unsafe.Pointer(&start), unsafe.Add(src, from.Offset)); err != nil {
+			return fieldError(err, from.Name, types.Date, from.Type)
+		}
+		if err := toConv(unsafe.Pointer(&end), unsafe.Add(src, to.Offset)); err != nil {
+			return fieldError(err, to.Name, types.Date, to.Type)
+		}
+		*(*timespan.DateRange)(dst) = timespan.BetweenDates(start, end)
+		return nil
+	}, nil
+}
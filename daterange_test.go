@@ -0,0 +1,104 @@
+package keyvalue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rickb777/date/v2"
+	"github.com/rickb777/date/v2/timespan"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type stringRange struct {
+	From string
+	To   string
+}
+
+type timestampRange struct {
+	From *timestamppb.Timestamp
+	To   *timestamppb.Timestamp
+}
+
+type dateRange struct {
+	From date.Date
+	To   date.Date
+}
+
+type rangeDomain struct {
+	Validity timespan.DateRange
+	Billing  timespan.DateRange
+	Shipping timespan.DateRange
+	Empty    timespan.DateRange
+	Days     timespan.PeriodOfDays
+	Period   timespan.PeriodOfDays
+}
+
+type rangeDTO struct {
+	Validity stringRange
+	Billing  timestampRange
+	Shipping dateRange
+	Empty    stringRange
+	Days     int
+	Period   string
+}
+
+type unregisteredRange struct {
+	From string
+	To   string
+}
+
+type unregisteredRangeDTO struct {
+	Validity unregisteredRange
+}
+
+func init() {
+	RegisterDateRange[stringRange]()
+	RegisterDateRange[timestampRange]()
+	RegisterDateRange[dateRange]()
+}
+
+func TestDateRange(t *testing.T) {
+	req := require.New(t)
+
+	may := timespan.NewMonthOf(2024, time.May)
+	src := rangeDomain{
+		Validity: may,
+		Billing:  may,
+		Shipping: timespan.OneDayRange(date.New(2024, 5, 3)),
+		Days:     may.Days(),
+		Period:   -14,
+	}
+
+	var dto rangeDTO
+	req.NoError(TypedCopy(&dto, &src))
+	req.Equal(stringRange{From: "2024-05-01", To: "2024-06-01"}, dto.Validity)
+	req.True(dto.Billing.From.AsTime().Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)))
+	req.True(dto.Billing.To.AsTime().Equal(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)))
+	req.Equal(dateRange{From: date.New(2024, 5, 3), To: date.New(2024, 5, 4)}, dto.Shipping)
+	req.Equal(stringRange{}, dto.Empty)
+	req.Equal(31, dto.Days)
+	req.Equal("-P14D", dto.Period)
+
+	var back rangeDomain
+	req.NoError(TypedCopy(&back, &dto))
+	req.Equal(src, back)
+
+	dto.Validity.To = "June"
+	err := TypedCopy(&back, &dto)
+	var cerr *CopyError
+	req.ErrorAs(err, &cerr)
+	req.Equal("Validity.To", cerr.Path())
+
+	dto = rangeDTO{Period: "P2W"}
+	req.NoError(TypedCopy(&back, &dto))
+	req.Equal(timespan.PeriodOfDays(14), back.Period)
+
+	dto.Period = "P1M"
+	req.ErrorIs(TypedCopy(&back, &dto), ErrBadPeriod)
+
+	var unregistered unregisteredRangeDTO
+	req.NoError(TypedCopy(&unregistered, &struct{ Validity timespan.DateRange }{Validity: may}))
+	req.Equal(unregisteredRange{}, unregistered.Validity)
+	req.Panics(func() { RegisterDateRange[rangeDTO]() })
+}
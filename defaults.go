@@ -0,0 +1,51 @@
+package keyvalue
+
+import (
+	"reflect"
+
+	"github.com/mailstepcz/serr"
+)
+
+// FillDefaults writes the default values into the empty fields of the destination object, i.e. zero values,
+// nil pointers, slices and maps and empty maybe values. Fields are keyed like in dynamic maps, by the `key` tag
+// or by the field name, and the default values are converted to the field types like by the copier.
+// The destination object has to be a pointer to a structure.
+func FillDefaults(dst interface{}, defaults map[string]interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Type().Elem().Kind() != reflect.Struct {
+		return ErrTypeNotStruct
+	}
+	if v.IsNil() {
+		return ErrNilPointer
+	}
+	v = v.Elem()
+	desc := describeType(v.Type())
+	for k, x := range defaults {
+		idx, ok := desc.dynmapField(k)
+		if !ok {
+			return &CopyError{
+				DstType:   v.Type(),
+				SrcType:   dynmapType,
+				FieldPath: []string{k},
+				Err:       serr.Wrap("", ErrFieldNotFound, serr.String("key", k)),
+			}
+		}
+		if x == nil {
+			continue
+		}
+		f, err := v.FieldByIndexErr(idx)
+		if err != nil || !isEmpty(f) {
+			continue
+		}
+		xv := reflect.New(reflect.TypeOf(x))
+		xv.Elem().Set(reflect.ValueOf(x))
+		conv, err := valConv(f.Type(), xv.Type().Elem())
+		if err != nil {
+			return fieldError(err, k, f.Type(), xv.Type().Elem())
+		}
+		if err := conv(f.Addr().UnsafePointer(), xv.UnsafePointer()); err != nil {
+			return fieldError(err, k, f.Type(), xv.Type().Elem())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package keyvalue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mailstepcz/maybe"
+	"github.com/stretchr/testify/require"
+)
+
+type defaultsConfig struct {
+	Host    string `key:"host"`
+	Port    int32  `key:"port"`
+	Timeout *int64 `key:"timeout"`
+	Debug   bool
+	Region  maybe.Maybe[string] `key:"region"`
+	Tags    []string            `key:"tags"`
+	Started time.Time           `key:"started"`
+}
+
+func TestFillDefaults(t *testing.T) {
+	req := require.New(t)
+
+	now := time.Now()
+	cfg := defaultsConfig{Host: "example.com"}
+	err := FillDefaults(&cfg, map[string]interface{}{
+		"host":    "localhost",
+		"port":    8080,
+		"timeout": 30,
+		"Debug":   true,
+		"region":  "eu",
+		"tags":    []string{"a"},
+		"started": now,
+	})
+	req.NoError(err)
+	req.Equal("example.com", cfg.Host)
+	req.Equal(int32(8080), cfg.Port)
+	req.Equal(int64(30), *cfg.Timeout)
+	req.True(cfg.Debug)
+	req.Equal(maybe.Unit("eu"), cfg.Region)
+	req.Equal([]string{"a"}, cfg.Tags)
+	req.Equal(now, cfg.Started)
+
+	err = FillDefaults(&cfg, map[string]interface{}{"port": 1, "region": "us"})
+	req.NoError(err)
+	req.Equal(int32(8080), cfg.Port)
+	req.Equal(maybe.Unit("eu"), cfg.Region)
+
+	err = FillDefaults(&cfg, map[string]interface{}{"unknown": 1})
+	req.ErrorIs(err, ErrFieldNotFound)
+
+	err = FillDefaults(&defaultsConfig{}, map[string]interface{}{"port": "abc"})
+	var cerr *CopyError
+	req.ErrorAs(err, &cerr)
+	req.Equal("port", cerr.Path())
+}
@@ -0,0 +1,88 @@
+package keyvalue
+
+import (
+	"reflect"
+	"strings"
+)
+
+// FieldDiff is a change of a destination field which copying the source object would make.
+type FieldDiff struct {
+	FieldPath []string
+	Before    interface{}
+	After     interface{}
+}
+
+// Path returns the dotted field path.
+func (d FieldDiff) Path() string {
+	return strings.Join(d.FieldPath, ".")
+}
+
+// Diff reports the fields of the destination object which would change if the source object were copied into it.
+// The destination object itself is left untouched. Nested structures, including those referenced by pointers
+// in both the original and the updated object, are compared field by field.
+func Diff[D, S any](d *D, s *S) ([]FieldDiff, error) {
+	if reflect.TypeFor[D]().Kind() != reflect.Struct {
+		return nil, ErrTypeNotStruct
+	}
+	if d == nil || s == nil {
+		return nil, ErrNilPointer
+	}
+	after, err := Clone(d)
+	if err != nil {
+		return nil, err
+	}
+	if err := TypedCopy(after, s); err != nil {
+		return nil, err
+	}
+	return diffStructs(nil, nil, reflect.ValueOf(d).Elem(), reflect.ValueOf(after).Elem()), nil
+}
+
+func diffStructs(diffs []FieldDiff, path []string, before, after reflect.Value) []FieldDiff {
+	t := before.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		diffs = diffValues(diffs, append(path[:len(path):len(path)], f.Name), before.Field(i), after.Field(i))
+	}
+	return diffs
+}
+
+func diffValues(diffs []FieldDiff, path []string, before, after reflect.Value) []FieldDiff {
+	switch {
+	case isMergeable(before.Type()):
+		return diffStructs(diffs, path, before, after)
+	case before.Kind() == reflect.Pointer && isMergeable(before.Type().Elem()) && !before.IsNil() && !after.IsNil():
+		return diffStructs(diffs, path, before.Elem(), after.Elem())
+	}
+	if valuesEqual(before, after) {
+		return diffs
+	}
+	return append(diffs, FieldDiff{
+		FieldPath: path,
+		Before:    before.Interface(),
+		After:     after.Interface(),
+	})
+}
+
+// valuesEqual compares two values of the same type. Values with an Equal method such as [time.Time]
+// or decimal.Decimal are compared using it, other values are compared deeply.
+func valuesEqual(a, b reflect.Value) bool {
+	if m, ok := a.Type().MethodByName("Equal"); ok && a.Kind() != reflect.Pointer && m.Type.NumIn() == 2 && m.Type.In(1) == a.Type() &&
+		m.Type.NumOut() == 1 && m.Type.Out(0).Kind() == reflect.Bool {
+		return m.Func.Call([]reflect.Value{a, b})[0].Bool()
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+// EqualAcross checks whether the destination object is equal to the source object converted to the destination type.
+// Equivalent representations such as a UUID and its string or a time and its protobuf timestamp are equal.
+// Destination fields which the source object isn't copied to are ignored.
+func EqualAcross[D, S any](d *D, s *S) (bool, error) {
+	diffs, err := Diff(d, s)
+	if err != nil {
+		return false, err
+	}
+	return len(diffs) == 0, nil
+}
@@ -0,0 +1,115 @@
+package keyvalue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type diffAddress struct {
+	Street string
+	City   string
+}
+
+type diffCustomer struct {
+	ID      uuid.UUID
+	Name    string
+	Age     int
+	Address diffAddress
+	Billing *diffAddress
+}
+
+type diffCustomerDTO struct {
+	ID      string
+	Name    string
+	Age     int32
+	Address diffAddress
+	Billing *diffAddress
+}
+
+func TestDiff(t *testing.T) {
+	req := require.New(t)
+
+	id := uuid.New()
+	d := &diffCustomer{
+		ID:      id,
+		Name:    "John",
+		Age:     30,
+		Address: diffAddress{Street: "Main", City: "Prague"},
+		Billing: &diffAddress{Street: "Side", City: "Brno"},
+	}
+	s := &diffCustomerDTO{
+		ID:      id.String(),
+		Name:    "Johnny",
+		Age:     30,
+		Address: diffAddress{Street: "Main", City: "Brno"},
+		Billing: &diffAddress{Street: "Long", City: "Brno"},
+	}
+
+	diffs, err := Diff(d, s)
+	req.NoError(err)
+	req.Equal([]FieldDiff{
+		{FieldPath: []string{"Name"}, Before: "John", After: "Johnny"},
+		{FieldPath: []string{"Address", "City"}, Before: "Prague", After: "Brno"},
+		{FieldPath: []string{"Billing", "Street"}, Before: "Side", After: "Long"},
+	}, diffs)
+	req.Equal("Address.City", diffs[1].Path())
+	req.Equal("John", d.Name)
+	req.Equal("Side", d.Billing.Street)
+
+	d.Billing = nil
+	s.Name = "John"
+	s.Address.City = "Prague"
+	diffs, err = Diff(d, s)
+	req.NoError(err)
+	req.Len(diffs, 1)
+	req.Equal("Billing", diffs[0].Path())
+	req.Nil(diffs[0].Before)
+
+	_, err = Diff(d, (*diffCustomerDTO)(nil))
+	req.ErrorIs(err, ErrNilPointer)
+}
+
+type equalDomain struct {
+	ID      uuid.UUID
+	Created time.Time
+	Price   decimal.Decimal
+	Note    string
+}
+
+type equalProto struct {
+	ID      string
+	Created *timestamppb.Timestamp
+	Price   string
+}
+
+func TestEqualAcross(t *testing.T) {
+	req := require.New(t)
+
+	loc := time.FixedZone("CET", 3600)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, loc)
+	d := &equalDomain{
+		ID:      uuid.New(),
+		Created: now,
+		Price:   decimal.RequireFromString("12.50"),
+		Note:    "not in proto",
+	}
+	p := &equalProto{
+		ID:      d.ID.String(),
+		Created: timestamppb.New(now),
+		Price:   "12.5",
+	}
+
+	eq, err := EqualAcross(d, p)
+	req.NoError(err)
+	req.True(eq)
+
+	p.Price = "12.51"
+	eq, err = EqualAcross(d, p)
+	req.NoError(err)
+	req.False(eq)
+}
@@ -0,0 +1,155 @@
+package keyvalue
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"unsafe"
+
+	"github.com/mailstepcz/enums"
+	"github.com/mailstepcz/serr"
+	"github.com/mailstepcz/types"
+)
+
+var (
+	stringerType        = reflect.TypeFor[fmt.Stringer]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// EnumPolicy is a policy for handling invalid closed enum values.
+type EnumPolicy int
+
+// enum policies
+const (
+	// EnumError makes the copy fail.
+	EnumError EnumPolicy = iota
+	// EnumDefault replaces invalid values with the default values of the enums given by their DefaultValue method.
+	// It's meant for ingesting legacy data which mustn't be rejected as a whole because of malformed values.
+	EnumDefault
+)
+
+// EnumFallback reports an invalid closed enum value replaced with the default value of the enum,
+// see [CopierOptions.OnEnumFallback].
+type EnumFallback struct {
+	DstType reflect.Type
+	Value   interface{}
+	Default interface{}
+}
+
+// enumDefault returns the default value of the closed enum type. The default value of integer-backed enums
+// is parsed by their UnmarshalText method.
+func enumDefault(t reflect.Type) (reflect.Value, bool) {
+	s := reflect.Zero(t).Interface().(enums.ClosedEnum).DefaultValue()
+	var v reflect.Value
+	if t.Kind() == reflect.String {
+		v = reflect.ValueOf(s).Convert(t)
+	} else {
+		p := reflect.New(t)
+		u, ok := p.Interface().(encoding.TextUnmarshaler)
+		if !ok || u.UnmarshalText([]byte(s)) != nil {
+			return reflect.Value{}, false
+		}
+		v = p.Elem()
+	}
+	return v, v.Interface().(enums.ClosedEnum).EnumValueIsValid()
+}
+
+// enumFallback returns a function writing the default value of the closed enum into the destination in place of
+// the invalid value if the options call for it. It returns nil if invalid values make the copy fail.
+func enumFallback(dstType reflect.Type, opts *CopierOptions) func(unsafe.Pointer, interface{}) {
+	if opts == nil || opts.EnumPolicy != EnumDefault {
+		return nil
+	}
+	def, ok := enumDefault(dstType)
+	if !ok {
+		return nil
+	}
+	hook := opts.OnEnumFallback
+	return func(dst unsafe.Pointer, x interface{}) {
+		reflect.NewAt(dstType, dst).Elem().Set(def)
+		if hook != nil {
+			hook(EnumFallback{
+				DstType: dstType,
+				Value:   x,
+				Default: def.Interface(),
+			})
+		}
+	}
+}
+
+// isInteger checks whether the type is an integer type.
+func isInteger(t reflect.Type) bool {
+	return isNumber(t) && !isFloat(t)
+}
+
+// isIntEnum checks whether the type is a closed enum backed by an integer type.
+func isIntEnum(t reflect.Type) bool {
+	return isInteger(t) && t.Implements(types.ClosedEnum)
+}
+
+// badEnumValue returns the error of an invalid closed enum value.
+func badEnumValue(x interface{}, dstType reflect.Type) error {
+	return serr.New("bad value for closed enum", serr.Any("value", x), serr.String("dstType", dstType.Name()))
+}
+
+// intEnumFromIntConv returns a converter of integers into an integer-backed closed enum validating the values.
+func intEnumFromIntConv(dstType, srcType reflect.Type, opts *CopierOptions) func(unsafe.Pointer, unsafe.Pointer) error {
+	fallback := enumFallback(dstType, opts)
+	return func(dst, src unsafe.Pointer) error {
+		v := reflect.NewAt(srcType, src).Elem().Convert(dstType)
+		if !v.Interface().(enums.ClosedEnum).EnumValueIsValid() {
+			if fallback != nil {
+				fallback(dst, reflect.NewAt(srcType, src).Elem().Interface())
+				return nil
+			}
+			return badEnumValue(reflect.NewAt(srcType, src).Elem().Interface(), dstType)
+		}
+		reflect.NewAt(dstType, dst).Elem().Set(v)
+		return nil
+	}
+}
+
+// intEnumFromStringConv returns a converter of names into an integer-backed closed enum.
+// The names are parsed by the UnmarshalText method of the enum type.
+func intEnumFromStringConv(dstType, srcType reflect.Type, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	if !reflect.PointerTo(dstType).Implements(textUnmarshalerType) {
+		return nil, serr.Wrap("", ErrUnsupportedTypePair, serr.String("dstType", dstType.String()), serr.String("srcType", srcType.String()))
+	}
+	fallback := enumFallback(dstType, opts)
+	return func(dst, src unsafe.Pointer) error {
+		x := reflect.NewAt(srcType, src).Elem().String()
+		v := reflect.New(dstType)
+		if err := v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(x)); err != nil {
+			if fallback != nil {
+				fallback(dst, x)
+				return nil
+			}
+			return serr.Wrap("bad value for closed enum", err, serr.String("value", x), serr.String("dstType", dstType.Name()))
+		}
+		if !v.Elem().Interface().(enums.ClosedEnum).EnumValueIsValid() {
+			if fallback != nil {
+				fallback(dst, x)
+				return nil
+			}
+			return badEnumValue(x, dstType)
+		}
+		reflect.NewAt(dstType, dst).Elem().Set(v.Elem())
+		return nil
+	}, nil
+}
+
+// intEnumToStringConv returns a converter of an integer-backed closed enum into names.
+// The names are given by the String method of the enum type.
+func intEnumToStringConv(dstType, srcType reflect.Type) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	if !srcType.Implements(stringerType) {
+		return nil, serr.Wrap("", ErrUnsupportedTypePair, serr.String("dstType", dstType.String()), serr.String("srcType", srcType.String()))
+	}
+	return func(dst, src unsafe.Pointer) error {
+		v := reflect.NewAt(srcType, src).Elem().Interface()
+		if !v.(enums.ClosedEnum).EnumValueIsValid() {
+			return serr.New("bad value for closed enum", serr.Any("value", v), serr.String("srcType", srcType.Name()))
+		}
+		reflect.NewAt(dstType, dst).Elem().SetString(v.(fmt.Stringer).String())
+		return nil
+	}, nil
+}
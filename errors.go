@@ -0,0 +1,120 @@
+package keyvalue
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/mailstepcz/serr"
+)
+
+// CopyError is an error which occurred while creating a copier or copying a value.
+// DstType and SrcType are the types of the failing pair of values and FieldPath locates them
+// within the structures being copied.
+type CopyError struct {
+	DstType   reflect.Type
+	SrcType   reflect.Type
+	FieldPath []string
+	Err       error
+	// indexed tells whether Err has been attributed the index of a slice element.
+	indexed bool
+}
+
+// Error returns the message of the underlying error.
+func (e *CopyError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *CopyError) Unwrap() error {
+	return e.Err
+}
+
+// Path returns the dotted field path.
+func (e *CopyError) Path() string {
+	return strings.Join(e.FieldPath, ".")
+}
+
+// SkippedElementsError reports the slice elements which were skipped because they couldn't be converted.
+// It's returned once the copy is complete, so the destination holds everything else.
+type SkippedElementsError struct {
+	// Elements holds the errors of the skipped elements. Their field paths include the indices of the elements.
+	Elements []*CopyError
+}
+
+// Error returns the number of skipped elements.
+func (e *SkippedElementsError) Error() string {
+	return fmt.Sprintf("%d invalid slice elements skipped", len(e.Elements))
+}
+
+// Unwrap returns the errors of the skipped elements.
+func (e *SkippedElementsError) Unwrap() []error {
+	errs := make([]error, len(e.Elements))
+	for i, ce := range e.Elements {
+		errs[i] = ce
+	}
+	return errs
+}
+
+// addSkipped adds the skipped elements of a field to the accumulated ones.
+func addSkipped(acc, se *SkippedElementsError, field string) *SkippedElementsError {
+	if acc == nil {
+		acc = &SkippedElementsError{}
+	}
+	for _, ce := range se.Elements {
+		ce.FieldPath = append([]string{field}, ce.FieldPath...)
+		acc.Elements = append(acc.Elements, ce)
+	}
+	return acc
+}
+
+// fieldError attributes an error to a field. Errors which are already of type [CopyError] get the field prepended to their path.
+func fieldError(err error, field string, dstType, srcType reflect.Type) error {
+	if ce, ok := err.(*CopyError); ok {
+		ce.FieldPath = append([]string{field}, ce.FieldPath...)
+		return ce
+	}
+	return &CopyError{
+		DstType:   dstType,
+		SrcType:   srcType,
+		FieldPath: []string{field},
+		Err:       err,
+	}
+}
+
+// compileFieldError attributes an error which occurred during copier creation to a field.
+func compileFieldError(err error, field string, dstType, srcType reflect.Type) error {
+	if ce, ok := err.(*CopyError); ok {
+		ce.Err = serr.Wrap("", ce.Err, serr.String("srcField", field))
+		ce.FieldPath = append([]string{field}, ce.FieldPath...)
+		return ce
+	}
+	return &CopyError{
+		DstType:   dstType,
+		SrcType:   srcType,
+		FieldPath: []string{field},
+		Err:       serr.Wrap("", err, serr.String("srcField", field)),
+	}
+}
+
+// elementError attributes an error to an element of a slice. The index becomes a segment of the field path
+// and an attribute of the underlying error. Only the innermost index of nested slices becomes an attribute.
+func elementError(err error, index int, dstType, srcType reflect.Type) *CopyError {
+	segment := strconv.Itoa(index)
+	if ce, ok := err.(*CopyError); ok {
+		if !ce.indexed {
+			ce.Err = serr.Wrap("", ce.Err, serr.Int("index", index))
+			ce.indexed = true
+		}
+		ce.FieldPath = append([]string{segment}, ce.FieldPath...)
+		return ce
+	}
+	return &CopyError{
+		DstType:   dstType,
+		SrcType:   srcType,
+		FieldPath: []string{segment},
+		Err:       serr.Wrap("", err, serr.Int("index", index)),
+		indexed:   true,
+	}
+}
@@ -0,0 +1,85 @@
+package keyvalue
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mailstepcz/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyError(t *testing.T) {
+	type innerDst struct {
+		ID uuid.UUID
+	}
+	type innerSrc struct {
+		ID string
+	}
+	type outerDst struct {
+		Inner innerDst
+	}
+	type outerSrc struct {
+		Inner innerSrc
+	}
+
+	t.Run("copying", func(t *testing.T) {
+		req := require.New(t)
+
+		var dst outerDst
+		err := TypedCopy(&dst, &outerSrc{Inner: innerSrc{ID: "uuid"}})
+		req.EqualError(err, "invalid UUID length: 4")
+
+		var ce *CopyError
+		req.True(errors.As(err, &ce))
+		req.Equal([]string{"Inner", "ID"}, ce.FieldPath)
+		req.Equal("Inner.ID", ce.Path())
+		req.Equal(types.UUID, ce.DstType)
+		req.Equal(types.String, ce.SrcType)
+		req.True(uuid.IsInvalidLengthError(ce.Err))
+	})
+
+	t.Run("slice element", func(t *testing.T) {
+		req := require.New(t)
+
+		type listDst struct {
+			Items []innerDst
+			IDs   []uuid.UUID
+		}
+		type listSrc struct {
+			Items []innerSrc
+			IDs   []string
+		}
+		var dst listDst
+		err := TypedCopy(&dst, &listSrc{IDs: []string{uuid.NewString(), "uuid"}})
+		req.EqualError(err, "invalid UUID length: 4 index=1")
+
+		var ce *CopyError
+		req.True(errors.As(err, &ce))
+		req.Equal("IDs.1", ce.Path())
+		req.Equal(types.UUID, ce.DstType)
+
+		err = TypedCopy(&dst, &listSrc{Items: []innerSrc{{ID: uuid.NewString()}, {ID: uuid.NewString()}, {ID: "uuid"}}})
+		req.True(errors.As(err, &ce))
+		req.Equal("Items.2.ID", ce.Path())
+	})
+
+	t.Run("creation", func(t *testing.T) {
+		req := require.New(t)
+
+		type dstS struct {
+			N int
+		}
+		type srcS struct {
+			N int
+			X float64
+		}
+		_, err := CopierForPair(reflect.TypeFor[dstS](), reflect.TypeFor[srcS]())
+		req.ErrorIs(err, ErrFieldNotFound)
+
+		var ce *CopyError
+		req.True(errors.As(err, &ce))
+		req.Equal("X", ce.Path())
+	})
+}
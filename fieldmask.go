@@ -0,0 +1,116 @@
+package keyvalue
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+	"unsafe"
+
+	"github.com/mailstepcz/serr"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// maskTree is a parsed field mask. A nil subtree selects the whole field.
+type maskTree map[string]maskTree
+
+// parseFieldMask parses the paths of a field mask as described by AIP-161.
+// It returns nil if the mask selects everything, that is if it's nil or contains the wildcard path.
+func parseFieldMask(fm *fieldmaskpb.FieldMask) maskTree {
+	if fm == nil || slices.Contains(fm.GetPaths(), "*") {
+		return nil
+	}
+	mask := make(maskTree)
+	for _, path := range fm.GetPaths() {
+		mask.add(strings.Split(path, "."))
+	}
+	return mask
+}
+
+func (m maskTree) add(path []string) {
+	sub, ok := m[path[0]]
+	if ok && sub == nil {
+		return
+	}
+	if len(path) == 1 {
+		m[path[0]] = nil
+		return
+	}
+	if sub == nil {
+		sub = make(maskTree)
+		m[path[0]] = sub
+	}
+	sub.add(path[1:])
+}
+
+// maskName returns the name of the field used in field mask paths.
+// It's the protobuf name of the field if available, otherwise the field name in snake case.
+func maskName(f reflect.StructField) string {
+	for _, part := range strings.Split(f.Tag.Get("protobuf"), ",") {
+		if name, ok := strings.CutPrefix(part, "name="); ok {
+			return name
+		}
+	}
+	return snakeCase(f.Name)
+}
+
+// snakeCase converts a Go identifier into snake case keeping acronyms together, e.g. UserID becomes user_id.
+func snakeCase(s string) string {
+	rs := []rune(s)
+	var b strings.Builder
+	for i, r := range rs {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(rs[i-1]) || i+1 < len(rs) && unicode.IsLower(rs[i+1]) && unicode.IsUpper(rs[i-1])) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// maskedConv returns a converter copying only the masked fields of a nested structure.
+// A nil destination pointer is allocated while a nil source pointer is handled by the nil policy.
+func maskedConv(dstType, srcType reflect.Type, mask maskTree, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	switch {
+	case dstType.Kind() == reflect.Struct && srcType.Kind() == reflect.Struct:
+		return compileCopier(dstType, srcType, opts, false, mask)
+
+	case dstType.Kind() == reflect.Pointer && srcType.Kind() == reflect.Pointer:
+		dstElType := dstType.Elem()
+		conv, err := maskedConv(dstElType, srcType.Elem(), mask, opts)
+		if err != nil {
+			return nil, err
+		}
+		onNil := nilHandler(dstType, opts)
+		return func(dst, src unsafe.Pointer) error {
+			p := *(*unsafe.Pointer)(src)
+			if p == nil {
+				return onNil(dst)
+			}
+			d := *(*unsafe.Pointer)(dst)
+			if d == nil {
+				d = reflect.New(dstElType).UnsafePointer()
+				*(*unsafe.Pointer)(dst) = d
+			}
+			return conv(d, p)
+		}, nil
+	}
+	return nil, serr.New("field mask path traverses a non-message field", serr.String("srcType", srcType.String()), serr.String("dstType", dstType.String()))
+}
+
+// unmatchedMaskError reports a field mask path which doesn't match any copied field.
+func unmatchedMaskError(dstType, srcType reflect.Type, mask maskTree, matched []string) error {
+	for name := range mask {
+		if !slices.Contains(matched, name) {
+			return &CopyError{
+				DstType:   dstType,
+				SrcType:   srcType,
+				FieldPath: []string{name},
+				Err:       serr.Wrap("", ErrFieldNotFound, serr.String("maskPath", name), serr.String("dstType", dstType.Name())),
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package keyvalue
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestSnakeCase(t *testing.T) {
+	req := require.New(t)
+
+	req.Equal("name", snakeCase("Name"))
+	req.Equal("display_name", snakeCase("DisplayName"))
+	req.Equal("user_id", snakeCase("UserID"))
+	req.Equal("http_server", snakeCase("HTTPServer"))
+}
+
+func TestFieldMask(t *testing.T) {
+	req := require.New(t)
+
+	type address struct {
+		Street string
+		City   string
+	}
+	type pbUser struct {
+		DisplayName string   `protobuf:"bytes,1,opt,name=display_name,json=displayName,proto3"`
+		Email       string   `protobuf:"bytes,2,opt,name=email,proto3"`
+		Address     *address `protobuf:"bytes,3,opt,name=address,proto3"`
+		Home        address  `protobuf:"bytes,4,opt,name=home,proto3"`
+	}
+	type user struct {
+		DisplayName string
+		Email       string
+		Address     *address
+		Home        address
+	}
+
+	opts := &CopierOptions{
+		FieldMask: &fieldmaskpb.FieldMask{Paths: []string{"display_name", "address.city", "home.street"}},
+		NoCache:   true,
+	}
+	c, err := HandleForPair(reflect.TypeFor[user](), reflect.TypeFor[pbUser](), opts)
+	req.NoError(err)
+
+	dst := user{
+		DisplayName: "old",
+		Email:       "old@example.com",
+		Address:     &address{Street: "Old Street", City: "Old City"},
+		Home:        address{Street: "Home Street", City: "Home City"},
+	}
+	err = c.Copy(&dst, &pbUser{
+		DisplayName: "new",
+		Email:       "new@example.com",
+		Address:     &address{Street: "New Street", City: "New City"},
+		Home:        address{Street: "New Home Street", City: "New Home City"},
+	})
+	req.NoError(err)
+	req.Equal(user{
+		DisplayName: "new",
+		Email:       "old@example.com",
+		Address:     &address{Street: "Old Street", City: "New City"},
+		Home:        address{Street: "New Home Street", City: "Home City"},
+	}, dst)
+
+	dst = user{}
+	err = c.Copy(&dst, &pbUser{Address: &address{City: "City"}})
+	req.NoError(err)
+	req.Equal(&address{City: "City"}, dst.Address)
+
+	_, err = CopierForPairWithOptions(reflect.TypeFor[user](), reflect.TypeFor[pbUser](), &CopierOptions{
+		FieldMask: &fieldmaskpb.FieldMask{Paths: []string{"nickname"}},
+		NoCache:   true,
+	})
+	req.True(errors.Is(err, ErrFieldNotFound))
+
+	_, err = CopierForPairWithOptions(reflect.TypeFor[user](), reflect.TypeFor[pbUser](), &CopierOptions{
+		FieldMask: &fieldmaskpb.FieldMask{Paths: []string{"email.domain"}},
+		NoCache:   true,
+	})
+	req.Error(err)
+}
+
+func TestFieldMaskWildcard(t *testing.T) {
+	req := require.New(t)
+
+	type s struct {
+		A, B int
+	}
+	opts := &CopierOptions{
+		FieldMask: &fieldmaskpb.FieldMask{Paths: []string{"*"}},
+		NoCache:   true,
+	}
+	c, err := HandleForPair(reflect.TypeFor[s](), reflect.TypeFor[s](), opts)
+	req.NoError(err)
+	var dst s
+	req.NoError(c.Copy(&dst, &s{A: 1, B: 2}))
+	req.Equal(s{A: 1, B: 2}, dst)
+}
@@ -0,0 +1,160 @@
+package keyvalue
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// fieldOffset computes the offset of a possibly promoted field relative to the outermost structure.
+// It fails if the field is promoted through an embedded pointer.
+func fieldOffset(t reflect.Type, index []int) (uintptr, bool) {
+	var offset uintptr
+	for i, idx := range index {
+		if t.Kind() != reflect.Struct {
+			return 0, false
+		}
+		f := t.Field(idx)
+		offset += f.Offset
+		if i < len(index)-1 {
+			t = f.Type
+		}
+	}
+	return offset, true
+}
+
+// pointerHop is a pointer to a nested structure traversed on the way to a destination field.
+type pointerHop struct {
+	// offset is the offset of the pointer within the structure holding it.
+	offset uintptr
+	// elemType is the type of the structure the pointer references.
+	elemType reflect.Type
+}
+
+// fieldHops computes the pointers traversed on the way to a possibly nested field and the offset of the field
+// relative to the structure referenced by the last pointer, or to the outermost structure if there are none.
+func fieldHops(t reflect.Type, index []int) ([]pointerHop, uintptr, bool) {
+	var (
+		hops   []pointerHop
+		offset uintptr
+	)
+	for i, idx := range index {
+		if t.Kind() != reflect.Struct {
+			return nil, 0, false
+		}
+		f := t.Field(idx)
+		offset += f.Offset
+		if i < len(index)-1 {
+			t = f.Type
+			if t.Kind() == reflect.Pointer {
+				t = t.Elem()
+				hops = append(hops, pointerHop{offset: offset, elemType: t})
+				offset = 0
+			}
+		}
+	}
+	return hops, offset, true
+}
+
+// viaEmbeddedPointer checks whether the possibly nested field is promoted through an embedded pointer.
+func viaEmbeddedPointer(t reflect.Type, index []int) bool {
+	for _, idx := range index[:len(index)-1] {
+		f := t.Field(idx)
+		t = f.Type
+		if t.Kind() == reflect.Pointer {
+			if f.Anonymous {
+				return true
+			}
+			t = t.Elem()
+		}
+	}
+	return false
+}
+
+// fieldByPath returns the field selected by a dot-separated path of field names such as "Address.City".
+// The path may traverse nested structures and pointers to them. The index of the returned field spans the whole path.
+// Unexported fields aren't found.
+func fieldByPath(t reflect.Type, path string) (reflect.StructField, bool) {
+	var (
+		f     reflect.StructField
+		index []int
+	)
+	for i, name := range strings.Split(path, ".") {
+		if i > 0 {
+			t = f.Type
+			if t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+			if t.Kind() != reflect.Struct {
+				return reflect.StructField{}, false
+			}
+		}
+		var ok bool
+		f, ok = t.FieldByName(name)
+		if !ok || f.PkgPath != "" {
+			return reflect.StructField{}, false
+		}
+		index = append(index, f.Index...)
+	}
+	f.Index = index
+	return f, true
+}
+
+// ambiguousFields returns the exported field names which are ambiguous due to being promoted
+// from several embedded structures at the same depth, along with the paths of the conflicting fields.
+func ambiguousFields(t reflect.Type) map[string][]string {
+	type candidate struct {
+		path  string
+		depth int
+	}
+	candidates := make(map[string][]candidate)
+	var walk func(t reflect.Type, prefix []string, parents []reflect.Type)
+	walk = func(t reflect.Type, prefix []string, parents []reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			path := append(slices.Clip(prefix), f.Name)
+			if f.IsExported() {
+				candidates[f.Name] = append(candidates[f.Name], candidate{
+					path:  strings.Join(path, "."),
+					depth: len(prefix),
+				})
+			}
+			if f.Anonymous {
+				ft := f.Type
+				if ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				if ft.Kind() == reflect.Struct && !slices.Contains(parents, ft) {
+					walk(ft, path, append(parents, ft))
+				}
+			}
+		}
+	}
+	walk(t, nil, []reflect.Type{t})
+
+	var ambiguous map[string][]string
+	for name, cs := range candidates {
+		minDepth := cs[0].depth
+		for _, c := range cs {
+			minDepth = min(minDepth, c.depth)
+		}
+		var paths []string
+		for _, c := range cs {
+			if c.depth == minDepth {
+				paths = append(paths, c.path)
+			}
+		}
+		if len(paths) > 1 {
+			if ambiguous == nil {
+				ambiguous = make(map[string][]string)
+			}
+			ambiguous[name] = paths
+		}
+	}
+	return ambiguous
+}
+
+// isPrefix checks whether the field index is a prefix of the other one, i.e. the field holds the other field.
+func isPrefix(prefix, index []int) bool {
+	return len(prefix) <= len(index) && slices.Equal(prefix, index[:len(prefix)])
+}
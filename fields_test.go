@@ -0,0 +1,148 @@
+package keyvalue
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+type embeddedA struct {
+	X int
+	Y string
+}
+
+type embeddedB struct {
+	X int
+	Z string
+}
+
+func TestAmbiguousFields(t *testing.T) {
+	req := require.New(t)
+
+	type s struct {
+		embeddedA
+		embeddedB
+		Z int
+	}
+	req.Equal(map[string][]string{
+		"X": {"embeddedA.X", "embeddedB.X"},
+	}, ambiguousFields(reflect.TypeFor[s]()))
+}
+
+func TestCopierAmbiguousFields(t *testing.T) {
+	t.Run("source", func(t *testing.T) {
+		req := require.New(t)
+
+		type srcS struct {
+			embeddedA
+			embeddedB
+		}
+		type dstS struct {
+			X int
+		}
+		_, err := CopierForPair(reflect.TypeFor[dstS](), reflect.TypeFor[srcS]())
+		req.ErrorIs(err, ErrAmbiguousField)
+		req.EqualError(err, "ambiguous field field=X paths=embeddedA.X,embeddedB.X")
+	})
+
+	t.Run("omitted", func(t *testing.T) {
+		req := require.New(t)
+
+		type srcS struct {
+			embeddedA
+			embeddedB
+		}
+		type dstS struct {
+			X int
+			Y string
+		}
+		copier, err := CopierForPairWithOptions(reflect.TypeFor[dstS](), reflect.TypeFor[srcS](), &CopierOptions{
+			FieldsToOmit: []string{"X"},
+			OmitNotFound: true,
+		})
+		req.NoError(err)
+		var dst dstS
+		req.NoError(copier(unsafe.Pointer(&dst), unsafe.Pointer(&srcS{embeddedA: embeddedA{X: 1, Y: "abcd"}})))
+		req.Equal(dstS{Y: "abcd"}, dst)
+	})
+
+	t.Run("destination", func(t *testing.T) {
+		req := require.New(t)
+
+		type srcS struct {
+			X int
+		}
+		type dstS struct {
+			embeddedA
+			embeddedB
+		}
+		_, err := CopierForPair(reflect.TypeFor[dstS](), reflect.TypeFor[srcS]())
+		req.ErrorIs(err, ErrAmbiguousField)
+	})
+}
+
+func TestCopierPromotedFields(t *testing.T) {
+	req := require.New(t)
+
+	type srcS struct {
+		N int
+		embeddedA
+	}
+	type dstS struct {
+		X int
+		Y string
+		N int
+	}
+	var dst dstS
+	err := TypedCopy(&dst, &srcS{N: 1, embeddedA: embeddedA{X: 2, Y: "abcd"}})
+	req.NoError(err)
+	req.Equal(dstS{X: 2, Y: "abcd", N: 1}, dst)
+}
+
+func TestCopierFieldsPromotedThroughPointers(t *testing.T) {
+	type srcS struct {
+		N int
+		*embeddedA
+	}
+	type dstS struct {
+		X int
+		Y *string
+		N int
+	}
+
+	req := require.New(t)
+
+	var dst dstS
+	err := TypedCopy(&dst, &srcS{N: 1, embeddedA: &embeddedA{X: 2, Y: "abcd"}})
+	req.NoError(err)
+	req.Equal(2, dst.X)
+	req.Equal("abcd", *dst.Y)
+	req.Equal(1, dst.N)
+
+	dst = dstS{X: 3}
+	err = TypedCopy(&dst, &srcS{N: 1})
+	req.NoError(err)
+	req.Equal(dstS{X: 3, N: 1}, dst)
+
+	err = TypedCopy(&dst, &srcS{N: 1}, WithNilPolicy(NilError))
+	req.ErrorIs(err, ErrNilSource)
+
+	t.Run("embedded pointer copied", func(t *testing.T) {
+		type dstS struct {
+			N int
+			*embeddedA
+		}
+
+		req := require.New(t)
+
+		src := srcS{N: 1, embeddedA: &embeddedA{X: 2, Y: "abcd"}}
+		var dst dstS
+		err := TypedCopy(&dst, &src)
+		req.ErrorIs(err, ErrFieldNotFound)
+		err = TypedCopy(&dst, &src, WithEmbeddedPointers())
+		req.NoError(err)
+		req.Equal(dstS{N: 1, embeddedA: &embeddedA{X: 2, Y: "abcd"}}, dst)
+	})
+}
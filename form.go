@@ -0,0 +1,179 @@
+package keyvalue
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+)
+
+var (
+	fileHeaderType    = reflect.TypeFor[*multipart.FileHeader]()
+	multipartFileType = reflect.TypeFor[multipart.File]()
+)
+
+// FormNames is a naming strategy matching fields with form fields.
+// The form field name is taken from the `form` tag and defaults to the field name.
+// Fields tagged with `form:"-"` aren't copied.
+func FormNames(f reflect.StructField) string {
+	switch tag := f.Tag.Get("form"); tag {
+	case "-":
+		return ""
+	case "":
+		return f.Name
+	default:
+		return tag
+	}
+}
+
+// FormAdapter is a key-value adapter for urlencoded and multipart forms.
+// Form values are enumerated as strings, or as slices of strings if repeated, which are parsed into numbers,
+// booleans, UUIDs and times when set in structures. File parts are enumerated as [*multipart.FileHeader], or as slices
+// of them if repeated, which are read into []byte fields and opened for io.Reader fields. Files opened for structures
+// have to be closed by their users.
+type FormAdapter struct {
+	values map[string][]string
+	files  map[string][]*multipart.FileHeader
+}
+
+// NewFormAdapter creates a new adapter for a form, which is either a [*multipart.Form], [url.Values] or a map[string][]string.
+func NewFormAdapter(obj interface{}) (*FormAdapter, error) {
+	switch form := obj.(type) {
+	case *multipart.Form:
+		if form == nil {
+			return nil, ErrNilPointer
+		}
+		return &FormAdapter{
+			values: form.Value,
+			files:  form.File,
+		}, nil
+	case url.Values:
+		return &FormAdapter{
+			values: form,
+		}, nil
+	case map[string][]string:
+		return &FormAdapter{
+			values: form,
+		}, nil
+	}
+	return nil, fmt.Errorf("type %T isn't a form: %w", obj, ErrBadType)
+}
+
+// NewRequestFormAdapter parses the form of the HTTP request and creates a new adapter for it.
+// Multipart forms are parsed with up to maxMemory bytes of their file parts stored in memory.
+// The form values include the query parameters like [http.Request.Form].
+func NewRequestFormAdapter(r *http.Request, maxMemory int64) (*FormAdapter, error) {
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt == "multipart/form-data" {
+		if err := r.ParseMultipartForm(maxMemory); err != nil {
+			return nil, err
+		}
+		return &FormAdapter{
+			values: r.Form,
+			files:  r.MultipartForm.File,
+		}, nil
+	}
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	return &FormAdapter{
+		values: r.Form,
+	}, nil
+}
+
+// EnumFields enumerates all the form values and files.
+func (a *FormAdapter) EnumFields(fn func(string, Value) error) error {
+	for k, vs := range a.values {
+		if len(vs) == 0 {
+			continue
+		}
+		var v interface{} = vs
+		if len(vs) == 1 {
+			v = vs[0]
+		}
+		if err := fn(k, InterfaceValue{value: v}); err != nil {
+			return err
+		}
+	}
+	for k, fs := range a.files {
+		if len(fs) == 0 {
+			continue
+		}
+		var v interface{} = fs
+		if len(fs) == 1 {
+			v = fs[0]
+		}
+		if err := fn(k, InterfaceValue{value: v}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Set sets the values of a form field. Slices other than byte slices set repeated values.
+// File headers are set as file parts of multipart forms. Nil values, including nil slices, leave the form unchanged.
+func (a *FormAdapter) Set(name string, value interface{}) error {
+	switch fs := value.(type) {
+	case *multipart.FileHeader:
+		if fs == nil {
+			return nil
+		}
+		return a.setFiles(name, []*multipart.FileHeader{fs})
+	case []*multipart.FileHeader:
+		return a.setFiles(name, fs)
+	}
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || v.Kind() == reflect.Slice && v.IsNil() {
+		return nil
+	}
+	var values []string
+	if v.Kind() == reflect.Slice && !isHeaderBytes(v.Type()) {
+		values = make([]string, v.Len())
+		for i := range values {
+			b, err := headerBytes(v.Index(i))
+			if err != nil {
+				return fmt.Errorf("field '%s': %w", name, err)
+			}
+			values[i] = string(b)
+		}
+	} else {
+		b, err := headerBytes(v)
+		if err != nil {
+			return fmt.Errorf("field '%s': %w", name, err)
+		}
+		values = []string{string(b)}
+	}
+	a.values[name] = values
+	return nil
+}
+
+// setFiles sets the file parts of a form field.
+func (a *FormAdapter) setFiles(name string, fs []*multipart.FileHeader) error {
+	if a.files == nil {
+		return fmt.Errorf("field '%s' of a form without files: %w", name, ErrBadType)
+	}
+	a.files[name] = fs
+	return nil
+}
+
+// readFileHeader reads the contents of the file part.
+func readFileHeader(fh *multipart.FileHeader) ([]byte, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+var (
+	_ Adapter = (*FormAdapter)(nil)
+)
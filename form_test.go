@@ -0,0 +1,106 @@
+package keyvalue
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type uploadRequest struct {
+	ID          uuid.UUID `form:"id"`
+	Name        string    `form:"name"`
+	Count       int       `form:"count"`
+	Tags        []string  `form:"tag"`
+	Avatar      []byte    `form:"avatar"`
+	Document    io.Reader `form:"document"`
+	Attachments [][]byte  `form:"attachment"`
+	Internal    string    `form:"-"`
+}
+
+func TestMultipartFormToStruct(t *testing.T) {
+	req := require.New(t)
+
+	u := uuid.New()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	req.NoError(mw.WriteField("id", u.String()))
+	req.NoError(mw.WriteField("name", "report"))
+	req.NoError(mw.WriteField("count", "2"))
+	req.NoError(mw.WriteField("tag", "a"))
+	req.NoError(mw.WriteField("tag", "b"))
+	for name, content := range map[string]string{"avatar": "png", "document": "pdf"} {
+		w, err := mw.CreateFormFile(name, name+".bin")
+		req.NoError(err)
+		_, err = w.Write([]byte(content))
+		req.NoError(err)
+	}
+	for _, content := range []string{"one", "two"} {
+		w, err := mw.CreateFormFile("attachment", content+".txt")
+		req.NoError(err)
+		_, err = w.Write([]byte(content))
+		req.NoError(err)
+	}
+	req.NoError(mw.Close())
+
+	r := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	a, err := NewRequestFormAdapter(r, 1<<20)
+	req.NoError(err)
+
+	var ur uploadRequest
+	req.NoError(CopyV1(&ur, a, &NamingOption{Naming: FormNames}))
+	req.Equal(u, ur.ID)
+	req.Equal("report", ur.Name)
+	req.Equal(2, ur.Count)
+	req.Equal([]string{"a", "b"}, ur.Tags)
+	req.Equal([]byte("png"), ur.Avatar)
+	req.Equal([][]byte{[]byte("one"), []byte("two")}, ur.Attachments)
+	doc, err := io.ReadAll(ur.Document)
+	req.NoError(err)
+	req.Equal([]byte("pdf"), doc)
+	req.NoError(ur.Document.(io.Closer).Close())
+
+	a, err = NewFormAdapter(url.Values{"count": {"many"}})
+	req.NoError(err)
+	req.Error(CopyV1(&ur, a, &NamingOption{Naming: FormNames}))
+}
+
+func TestURLEncodedForm(t *testing.T) {
+	req := require.New(t)
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name=report&tag=a&count=3"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	a, err := NewRequestFormAdapter(r, 0)
+	req.NoError(err)
+
+	var ur uploadRequest
+	req.NoError(CopyV1(&ur, a, &NamingOption{Naming: FormNames}))
+	req.Equal("report", ur.Name)
+	req.Equal(3, ur.Count)
+	req.Equal([]string{"a"}, ur.Tags)
+
+	values := make(url.Values)
+	a, err = NewFormAdapter(values)
+	req.NoError(err)
+	ur.Internal = "internal"
+	req.NoError(CopyV1(a, &ur, &NamingOption{Naming: FormNames}))
+	req.Equal(url.Values{
+		"id":    {uuid.Nil.String()},
+		"name":  {"report"},
+		"count": {"3"},
+		"tag":   {"a"},
+	}, values)
+
+	req.ErrorIs(a.Set("file", &multipart.FileHeader{}), ErrBadType)
+
+	_, err = NewFormAdapter(map[string]string{})
+	req.ErrorIs(err, ErrBadType)
+}
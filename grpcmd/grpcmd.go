@@ -0,0 +1,126 @@
+// Package grpcmd adapts gRPC metadata so that structures are copied from and to the metadata of contexts.
+package grpcmd
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/mailstepcz/keyvalue"
+	"google.golang.org/grpc/metadata"
+)
+
+// binarySuffix is the suffix of the keys of binary metadata.
+const binarySuffix = "-bin"
+
+// Names is a naming strategy matching fields with gRPC metadata keys.
+// The key is taken from the `md` tag and defaults to the field name. Keys are lower-cased like gRPC canonicalizes them.
+// Fields tagged with `md:"-"` aren't copied.
+func Names(f reflect.StructField) string {
+	switch tag := f.Tag.Get("md"); tag {
+	case "-":
+		return ""
+	case "":
+		return strings.ToLower(f.Name)
+	default:
+		return strings.ToLower(tag)
+	}
+}
+
+// Adapter is a key-value adapter for gRPC metadata.
+// Values are enumerated as strings, or as slices of strings if repeated, which are parsed into numbers, booleans,
+// UUIDs and times when set in structures. Values of binary keys, which end with -bin, are enumerated as byte slices.
+// Values set in metadata are formatted like form values, as strings, and slices set repeated values.
+type Adapter struct {
+	md   metadata.MD
+	form *keyvalue.FormAdapter
+}
+
+// NewAdapter creates a new adapter for gRPC metadata.
+func NewAdapter(md metadata.MD) *Adapter {
+	form, _ := keyvalue.NewFormAdapter(map[string][]string(md))
+	return &Adapter{
+		md:   md,
+		form: form,
+	}
+}
+
+// EnumFields enumerates all the metadata keys with values.
+func (a *Adapter) EnumFields(fn func(string, keyvalue.Value) error) error {
+	for k, vs := range a.md {
+		if len(vs) == 0 {
+			continue
+		}
+		var v interface{} = vs
+		if strings.HasSuffix(k, binarySuffix) {
+			v = binaryValue(vs)
+		} else if len(vs) == 1 {
+			v = vs[0]
+		}
+		if err := fn(k, value{v}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Set sets the values of a metadata key, which is canonicalized. Nil values leave the metadata unchanged.
+func (a *Adapter) Set(name string, value interface{}) error {
+	return a.form.Set(strings.ToLower(name), value)
+}
+
+// value is a metadata value.
+type value struct {
+	v interface{}
+}
+
+// Interface returns the metadata value.
+func (v value) Interface() interface{} {
+	return v.v
+}
+
+// binaryValue returns the only binary value or all the binary values if there are several.
+func binaryValue(vs []string) interface{} {
+	if len(vs) == 1 {
+		return []byte(vs[0])
+	}
+	bs := make([][]byte, len(vs))
+	for i, v := range vs {
+		bs[i] = []byte(v)
+	}
+	return bs
+}
+
+// CopyIncoming copies the incoming metadata of the context into the structure, matching fields by [Names].
+// Keys without fields are ignored so that metadata added by clients and proxies doesn't break the copy.
+func CopyIncoming(ctx context.Context, dst interface{}) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	a, err := keyvalue.NewStructAdapter(dst, &keyvalue.NamingOption{Naming: Names})
+	if err != nil {
+		return err
+	}
+	return NewAdapter(md).EnumFields(func(name string, value keyvalue.Value) error {
+		if err := a.Set(name, value.Interface()); err != nil && !errors.Is(err, keyvalue.ErrNoSuchField) {
+			return err
+		}
+		return nil
+	})
+}
+
+// AppendOutgoing returns a context whose outgoing metadata are those of the context with the fields of the structure
+// added under the keys given by [Names]. Fields which are nil are skipped.
+func AppendOutgoing(ctx context.Context, src interface{}) (context.Context, error) {
+	md := metadata.MD{}
+	if err := keyvalue.CopyV1(NewAdapter(md), src, &keyvalue.NamingOption{Naming: Names}); err != nil {
+		return nil, err
+	}
+	if outgoing, ok := metadata.FromOutgoingContext(ctx); ok {
+		md = metadata.Join(outgoing, md)
+	}
+	return metadata.NewOutgoingContext(ctx, md), nil
+}
+
+var (
+	_ keyvalue.Adapter = (*Adapter)(nil)
+)
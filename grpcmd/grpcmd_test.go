@@ -0,0 +1,74 @@
+package grpcmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mailstepcz/keyvalue"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+type callContext struct {
+	RequestID uuid.UUID `md:"x-request-id"`
+	Tenant    string
+	Roles     []string `md:"x-role"`
+	Attempt   int      `md:"x-attempt"`
+	Trace     []byte   `md:"trace-bin"`
+	Token     *string  `md:"authorization"`
+	Local     string   `md:"-"`
+}
+
+func TestMetadataToStruct(t *testing.T) {
+	req := require.New(t)
+
+	u := uuid.New()
+	md := metadata.Pairs(
+		"X-Request-ID", u.String(),
+		"tenant", "acme",
+		"x-role", "admin",
+		"x-role", "auditor",
+		"x-attempt", "2",
+		"trace-bin", "\x00\x01",
+		"user-agent", "grpc-go",
+	)
+	var cc callContext
+	req.NoError(CopyIncoming(metadata.NewIncomingContext(context.Background(), md), &cc))
+	req.Equal(u, cc.RequestID)
+	req.Equal("acme", cc.Tenant)
+	req.Equal([]string{"admin", "auditor"}, cc.Roles)
+	req.Equal(2, cc.Attempt)
+	req.Equal([]byte{0, 1}, cc.Trace)
+	req.Nil(cc.Token)
+
+	req.Error(keyvalue.CopyV1(&cc, NewAdapter(md), &keyvalue.NamingOption{Naming: Names}))
+
+	req.NoError(CopyIncoming(context.Background(), &cc))
+}
+
+func TestStructToMetadata(t *testing.T) {
+	req := require.New(t)
+
+	u := uuid.New()
+	cc := callContext{
+		RequestID: u,
+		Tenant:    "acme",
+		Roles:     []string{"admin", "auditor"},
+		Attempt:   1,
+		Local:     "local",
+	}
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-existing", "kept")
+	ctx, err := AppendOutgoing(ctx, &cc)
+	req.NoError(err)
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	req.True(ok)
+	req.Equal(metadata.MD{
+		"x-existing":   {"kept"},
+		"x-request-id": {u.String()},
+		"tenant":       {"acme"},
+		"x-role":       {"admin", "auditor"},
+		"x-attempt":    {"1"},
+	}, md)
+}
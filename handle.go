@@ -0,0 +1,92 @@
+package keyvalue
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"unsafe"
+
+	"github.com/mailstepcz/serr"
+)
+
+var (
+	// ErrTypeMismatch signifies that a value doesn't match the type a copier was created for.
+	ErrTypeMismatch = errors.New("type mismatch")
+	// ErrNilPointer signifies a nil pointer passed to a copier.
+	ErrNilPointer = errors.New("nil pointer")
+)
+
+// Handle is a copier which remembers the pair of types it was created for and validates its arguments on use.
+// It's a safe alternative to the raw copiers returned by [CopierForPair].
+type Handle struct {
+	dstType, srcType reflect.Type
+	opts             *CopierOptions
+	copier           func(unsafe.Pointer, unsafe.Pointer) error
+}
+
+// HandleForPair creates a copier handle for a pair of structs.
+func HandleForPair(dstType, srcType reflect.Type, opts *CopierOptions) (*Handle, error) {
+	copier, err := CopierForPairWithOptions(dstType, srcType, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Handle{
+		dstType: dstType,
+		srcType: srcType,
+		opts:    opts,
+		copier:  copier,
+	}, nil
+}
+
+// DstType returns the destination type.
+func (h *Handle) DstType() reflect.Type {
+	return h.dstType
+}
+
+// SrcType returns the source type.
+func (h *Handle) SrcType() reflect.Type {
+	return h.srcType
+}
+
+// Raw returns the underlying raw copier.
+func (h *Handle) Raw() func(unsafe.Pointer, unsafe.Pointer) error {
+	return h.copier
+}
+
+// Copy copies the source object to the destination object.
+// Both arguments have to be non-nil pointers to the types the handle was created for.
+func (h *Handle) Copy(dst, src interface{}) error {
+	dstPtr, err := checkedPointer(dst, h.dstType)
+	if err != nil {
+		return err
+	}
+	srcPtr, err := checkedPointer(src, h.srcType)
+	if err != nil {
+		return err
+	}
+	return h.copier(dstPtr, srcPtr)
+}
+
+func checkedPointer(x interface{}, t reflect.Type) (unsafe.Pointer, error) {
+	v := reflect.ValueOf(x)
+	if !v.IsValid() || v.Type() != reflect.PointerTo(t) {
+		return nil, serr.Wrap("", ErrTypeMismatch, serr.String("expected", reflect.PointerTo(t).String()), serr.String("actual", fmt.Sprintf("%T", x)))
+	}
+	if v.IsNil() {
+		return nil, serr.Wrap("", ErrNilPointer, serr.String("type", v.Type().String()))
+	}
+	return v.UnsafePointer(), nil
+}
+
+// TypedHandle converts the handle into a typed copier. It fails if the handle was created for another pair of types.
+func TypedHandle[D, S any](h *Handle) (func(*D, *S) error, error) {
+	if h.dstType != reflect.TypeFor[D]() || h.srcType != reflect.TypeFor[S]() {
+		return nil, serr.Wrap("", ErrTypeMismatch, serr.String("dstType", h.dstType.String()), serr.String("srcType", h.srcType.String()))
+	}
+	return func(dst *D, src *S) error {
+		if dst == nil || src == nil {
+			return ErrNilPointer
+		}
+		return h.copier(unsafe.Pointer(dst), unsafe.Pointer(src))
+	}, nil
+}
@@ -0,0 +1,43 @@
+package keyvalue
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandle(t *testing.T) {
+	req := require.New(t)
+
+	h, err := HandleForPair(reflect.TypeFor[struct2](), reflect.TypeFor[struct1](), nil)
+	req.NoError(err)
+	req.Equal(reflect.TypeFor[struct2](), h.DstType())
+	req.Equal(reflect.TypeFor[struct1](), h.SrcType())
+
+	u := uuid.New()
+	var dst struct2
+	err = h.Copy(&dst, &struct1{ID: u.String()})
+	req.NoError(err)
+	req.Equal(u, dst.ID)
+
+	err = h.Copy(&struct1{}, &dst)
+	req.ErrorIs(err, ErrTypeMismatch)
+	err = h.Copy(dst, &struct1{})
+	req.ErrorIs(err, ErrTypeMismatch)
+	err = h.Copy(&dst, nil)
+	req.ErrorIs(err, ErrTypeMismatch)
+	err = h.Copy((*struct2)(nil), &struct1{})
+	req.ErrorIs(err, ErrNilPointer)
+
+	f, err := TypedHandle[struct2, struct1](h)
+	req.NoError(err)
+	dst = struct2{}
+	err = f(&dst, &struct1{ID: u.String()})
+	req.NoError(err)
+	req.Equal(u, dst.ID)
+
+	_, err = TypedHandle[struct1, struct2](h)
+	req.ErrorIs(err, ErrTypeMismatch)
+}
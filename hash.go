@@ -0,0 +1,142 @@
+package keyvalue
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding"
+	"encoding/binary"
+	"hash"
+	"math"
+	"reflect"
+	"slices"
+	"time"
+	"unsafe"
+
+	"github.com/mailstepcz/serr"
+)
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// HashAcross copies the source object into a new object of the canonical type and returns its SHA-256 fingerprint.
+// Copying different representations of an entity into the same canonical type yields the same fingerprint.
+// The fingerprint is computed deterministically from exported fields, map keys are sorted, times are compared as instants
+// and structures without exported fields are hashed by their textual form.
+func HashAcross[S any](s *S, canonicalType reflect.Type) ([]byte, error) {
+	if s == nil {
+		return nil, ErrNilPointer
+	}
+	copier, err := CopierForPair(canonicalType, reflect.TypeFor[S]())
+	if err != nil {
+		return nil, err
+	}
+	dst := reflect.New(canonicalType)
+	if err := copier(dst.UnsafePointer(), unsafe.Pointer(s)); err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	if err := hashValue(h, dst.Elem()); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// hashValue writes an unambiguous encoding of the value to the hash.
+func hashValue(h hash.Hash, v reflect.Value) error {
+	var buf [8]byte
+	writeUint := func(x uint64) {
+		binary.BigEndian.PutUint64(buf[:], x)
+		h.Write(buf[:])
+	}
+	writeBytes := func(b []byte) {
+		writeUint(uint64(len(b)))
+		h.Write(b)
+	}
+
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		writeUint(uint64(t.Unix()))
+		writeUint(uint64(t.Nanosecond()))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			writeUint(1)
+		} else {
+			writeUint(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		writeUint(math.Float64bits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		writeUint(math.Float64bits(real(v.Complex())))
+		writeUint(math.Float64bits(imag(v.Complex())))
+	case reflect.String:
+		writeBytes([]byte(v.String()))
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			writeUint(0)
+			return nil
+		}
+		writeUint(1)
+		if v.Kind() == reflect.Interface {
+			writeBytes([]byte(v.Elem().Type().String()))
+		}
+		return hashValue(h, v.Elem())
+	case reflect.Slice, reflect.Array:
+		writeUint(uint64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			if err := hashValue(h, v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		entries := make([][]byte, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			entry := sha256.New()
+			if err := hashValue(entry, iter.Key()); err != nil {
+				return err
+			}
+			if err := hashValue(entry, iter.Value()); err != nil {
+				return err
+			}
+			entries = append(entries, entry.Sum(nil))
+		}
+		slices.SortFunc(entries, bytes.Compare)
+		writeUint(uint64(len(entries)))
+		for _, e := range entries {
+			h.Write(e)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		exported := false
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			exported = true
+			writeBytes([]byte(f.Name))
+			if err := hashValue(h, v.Field(i)); err != nil {
+				return err
+			}
+		}
+		if !exported && t.Implements(textMarshalerType) {
+			text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+			if err != nil {
+				return err
+			}
+			writeBytes(text)
+		}
+	default:
+		return serr.Wrap("", ErrUnsupportedTypePair, serr.String("type", v.Type().String()))
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package keyvalue
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type hashCanonical struct {
+	ID      uuid.UUID
+	Name    string
+	Created time.Time
+	Labels  map[string]string
+}
+
+type hashProto struct {
+	ID      string
+	Name    string
+	Created *timestamppb.Timestamp
+	Labels  map[string]string
+}
+
+func TestHashAcross(t *testing.T) {
+	req := require.New(t)
+
+	id := uuid.New()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	labels := map[string]string{"a": "1", "b": "2", "c": "3"}
+	canonical := reflect.TypeFor[hashCanonical]()
+
+	h1, err := HashAcross(&hashProto{ID: id.String(), Name: "x", Created: timestamppb.New(now), Labels: labels}, canonical)
+	req.NoError(err)
+	h2, err := HashAcross(&hashCanonical{ID: id, Name: "x", Created: now, Labels: labels}, canonical)
+	req.NoError(err)
+	req.Equal(h1, h2)
+	req.Len(h1, 32)
+
+	h3, err := HashAcross(&hashCanonical{ID: id, Name: "y", Created: now, Labels: labels}, canonical)
+	req.NoError(err)
+	req.NotEqual(h1, h3)
+
+	_, err = HashAcross((*hashProto)(nil), canonical)
+	req.ErrorIs(err, ErrNilPointer)
+}
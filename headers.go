@@ -0,0 +1,182 @@
+package keyvalue
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// HeaderNames is a naming strategy matching fields with message headers.
+// The header name is taken from the `header` tag and defaults to the field name.
+// Fields tagged with `header:"-"` aren't copied.
+func HeaderNames(f reflect.StructField) string {
+	switch tag := f.Tag.Get("header"); tag {
+	case "-":
+		return ""
+	case "":
+		return f.Name
+	default:
+		return tag
+	}
+}
+
+// HeaderAdapter is a key-value adapter for message headers such as those of Kafka messages.
+// Header values are enumerated as strings which are parsed into numbers, booleans, UUIDs and times
+// when set in structures. Values set in headers are formatted as strings.
+type HeaderAdapter struct {
+	m        map[string][]byte
+	slice    reflect.Value
+	keyField reflect.StructField
+	valField reflect.StructField
+}
+
+// NewHeaderAdapter creates a new adapter for message headers, which are either a map[string][]byte or a pointer
+// to a slice of structures with the fields Key and Value, or pointers to them, such as *[]kafka.Header.
+// Keys of slice headers may be strings or byte slices. Headers set in slices replace the first header with the same key
+// or are appended.
+func NewHeaderAdapter(obj interface{}) (*HeaderAdapter, error) {
+	if m, ok := obj.(map[string][]byte); ok {
+		return &HeaderAdapter{
+			m: m,
+		}, nil
+	}
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return nil, fmt.Errorf("type %T isn't message headers: %w", obj, ErrBadType)
+	}
+	keyField, valField, ok := entryFields(v.Type().Elem().Elem())
+	if !ok || !isHeaderBytes(keyField.Type) && keyField.Type.Kind() != reflect.String || !isHeaderBytes(valField.Type) {
+		return nil, fmt.Errorf("type %T isn't message headers: %w", obj, ErrBadType)
+	}
+	return &HeaderAdapter{
+		slice:    v.Elem(),
+		keyField: keyField,
+		valField: valField,
+	}, nil
+}
+
+// isHeaderBytes checks whether the type is a byte slice.
+func isHeaderBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+// EnumFields enumerates all the headers. Headers repeated in slices are enumerated in order.
+func (a *HeaderAdapter) EnumFields(fn func(string, Value) error) error {
+	if a.m != nil {
+		for k, v := range a.m {
+			if err := fn(k, InterfaceValue{value: string(v)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for i := 0; i < a.slice.Len(); i++ {
+		h, ok := a.header(i)
+		if !ok {
+			continue
+		}
+		k := h.FieldByIndex(a.keyField.Index)
+		v := h.FieldByIndex(a.valField.Index)
+		if err := fn(headerString(k), InterfaceValue{value: string(v.Bytes())}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Set sets the value of a header. Nil values leave the headers unchanged.
+func (a *HeaderAdapter) Set(name string, value interface{}) error {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	b, err := headerBytes(v)
+	if err != nil {
+		return fmt.Errorf("header '%s': %w", name, err)
+	}
+	if a.m != nil {
+		a.m[name] = b
+		return nil
+	}
+	for i := 0; i < a.slice.Len(); i++ {
+		if h, ok := a.header(i); ok && headerString(h.FieldByIndex(a.keyField.Index)) == name {
+			h.FieldByIndex(a.valField.Index).SetBytes(b)
+			return nil
+		}
+	}
+	elType := a.slice.Type().Elem()
+	el := reflect.New(elType).Elem()
+	h := el
+	if elType.Kind() == reflect.Pointer {
+		el.Set(reflect.New(elType.Elem()))
+		h = el.Elem()
+	}
+	if k := h.FieldByIndex(a.keyField.Index); k.Kind() == reflect.String {
+		k.SetString(name)
+	} else {
+		k.SetBytes([]byte(name))
+	}
+	h.FieldByIndex(a.valField.Index).SetBytes(b)
+	a.slice.Set(reflect.Append(a.slice, el))
+	return nil
+}
+
+// header returns the header at the index. Nil headers are skipped.
+func (a *HeaderAdapter) header(i int) (reflect.Value, bool) {
+	h := a.slice.Index(i)
+	if h.Kind() == reflect.Pointer {
+		if h.IsNil() {
+			return reflect.Value{}, false
+		}
+		h = h.Elem()
+	}
+	return h, true
+}
+
+// headerString returns the header key as a string.
+func headerString(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return k.String()
+	}
+	return string(k.Bytes())
+}
+
+// headerBytes formats the value as a header value.
+// Values which marshal themselves as text, such as UUIDs and times, are marshaled and durations are formatted like 1m30s.
+func headerBytes(rv reflect.Value) ([]byte, error) {
+	if m, ok := rv.Interface().(encoding.TextMarshaler); ok {
+		return m.MarshalText()
+	}
+	switch {
+	case rv.Type() == durationType:
+		return []byte(time.Duration(rv.Int()).String()), nil
+	case isHeaderBytes(rv.Type()):
+		return rv.Bytes(), nil
+	case rv.Kind() == reflect.String:
+		return []byte(rv.String()), nil
+	case rv.Kind() == reflect.Bool:
+		return strconv.AppendBool(nil, rv.Bool()), nil
+	case isFloat(rv.Type()):
+		return strconv.AppendFloat(nil, rv.Float(), 'g', -1, rv.Type().Bits()), nil
+	case rv.CanInt():
+		return strconv.AppendInt(nil, rv.Int(), 10), nil
+	case rv.CanUint():
+		return strconv.AppendUint(nil, rv.Uint(), 10), nil
+	}
+	if s, ok := rv.Interface().(fmt.Stringer); ok {
+		return []byte(s.String()), nil
+	}
+	return nil, fmt.Errorf("type %s: %w", rv.Type(), ErrBadType)
+}
+
+var (
+	_ Adapter = (*HeaderAdapter)(nil)
+)
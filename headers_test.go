@@ -0,0 +1,117 @@
+package keyvalue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type kafkaHeader struct {
+	Key   string
+	Value []byte
+}
+
+type recordHeader struct {
+	Key   []byte
+	Value []byte
+}
+
+type messageMetadata struct {
+	RequestID uuid.UUID `header:"x-request-id"`
+	Attempt   int       `header:"x-attempt"`
+	Weight    float64   `header:"x-weight"`
+	Replay    bool      `header:"x-replay"`
+	SentAt    time.Time `header:"x-sent-at"`
+	Source    string
+	Trace     *string `header:"x-trace"`
+	Local     string  `header:"-"`
+}
+
+func TestHeadersToStruct(t *testing.T) {
+	req := require.New(t)
+
+	u := uuid.New()
+	headers := []kafkaHeader{
+		{Key: "x-request-id", Value: []byte(u.String())},
+		{Key: "x-attempt", Value: []byte("3")},
+		{Key: "x-weight", Value: []byte("1.5")},
+		{Key: "x-replay", Value: []byte("true")},
+		{Key: "x-sent-at", Value: []byte("2024-05-06T07:08:09.5Z")},
+		{Key: "Source", Value: []byte("orders")},
+	}
+	a, err := NewHeaderAdapter(&headers)
+	req.NoError(err)
+
+	var md messageMetadata
+	req.NoError(CopyV1(&md, a, &NamingOption{Naming: HeaderNames}))
+	req.Equal(u, md.RequestID)
+	req.Equal(3, md.Attempt)
+	req.Equal(1.5, md.Weight)
+	req.True(md.Replay)
+	req.True(time.Date(2024, 5, 6, 7, 8, 9, 5e8, time.UTC).Equal(md.SentAt))
+	req.Equal("orders", md.Source)
+	req.Nil(md.Trace)
+
+	headers = append(headers, kafkaHeader{Key: "x-attempt", Value: []byte("many")})
+	req.Error(CopyV1(&md, a, &NamingOption{Naming: HeaderNames}))
+}
+
+func TestStructToHeaders(t *testing.T) {
+	u := uuid.New()
+	md := messageMetadata{
+		RequestID: u,
+		Attempt:   2,
+		Weight:    0.25,
+		SentAt:    time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC),
+		Source:    "orders",
+		Local:     "local",
+	}
+
+	t.Run("map", func(t *testing.T) {
+		req := require.New(t)
+
+		headers := make(map[string][]byte)
+		a, err := NewHeaderAdapter(headers)
+		req.NoError(err)
+		req.NoError(CopyV1(a, &md, &NamingOption{Naming: HeaderNames}))
+		req.Equal(map[string][]byte{
+			"x-request-id": []byte(u.String()),
+			"x-attempt":    []byte("2"),
+			"x-weight":     []byte("0.25"),
+			"x-replay":     []byte("false"),
+			"x-sent-at":    []byte("2024-05-06T07:08:09Z"),
+			"Source":       []byte("orders"),
+		}, headers)
+	})
+
+	t.Run("slice", func(t *testing.T) {
+		req := require.New(t)
+
+		headers := []*recordHeader{
+			{Key: []byte("x-attempt"), Value: []byte("1")},
+			nil,
+			{Key: []byte("x-other"), Value: []byte("kept")},
+		}
+		a, err := NewHeaderAdapter(&headers)
+		req.NoError(err)
+		req.NoError(CopyV1(a, &md, &NamingOption{Naming: HeaderNames}))
+		req.Len(headers, 8)
+		req.Equal("2", string(headers[0].Value))
+		req.Nil(headers[1])
+		req.Equal("kept", string(headers[2].Value))
+		req.Equal("x-request-id", string(headers[3].Key))
+		req.Equal(u.String(), string(headers[3].Value))
+	})
+}
+
+func TestNewHeaderAdapterBadType(t *testing.T) {
+	req := require.New(t)
+
+	_, err := NewHeaderAdapter([]kafkaHeader{})
+	req.ErrorIs(err, ErrBadType)
+
+	_, err = NewHeaderAdapter(&[]struct{ Key, Value int }{})
+	req.ErrorIs(err, ErrBadType)
+}
@@ -0,0 +1,63 @@
+package keyvalue
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// ifaceConvEntry is a converter of values of a dynamic type.
+type ifaceConvEntry struct {
+	t    reflect.Type
+	conv func(unsafe.Pointer, unsafe.Pointer) error
+}
+
+// ifaceConv returns a converter of interface values dispatching on their dynamic types, e.g. for event payloads
+// stored in interface{} fields. Converters for the dynamic types are compiled when the types are first seen
+// and cached by the converter, with the most recently used one checked first.
+// Nil interfaces are handled by the nil policy.
+func ifaceConv(dstType, srcType reflect.Type, opts *CopierOptions) func(unsafe.Pointer, unsafe.Pointer) error {
+	onNil := nilHandler(dstType, opts)
+	var (
+		last  atomic.Pointer[ifaceConvEntry]
+		convs sync.Map
+	)
+	lookup := func(t reflect.Type) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+		if e := last.Load(); e != nil && e.t == t {
+			return e.conv, nil
+		}
+		var conv func(unsafe.Pointer, unsafe.Pointer) error
+		if c, ok := convs.Load(t); ok {
+			conv = c.(func(unsafe.Pointer, unsafe.Pointer) error)
+		} else {
+			c, err := valConvWithOptions(dstType, t, opts)
+			if err != nil {
+				return nil, err
+			}
+			convs.Store(t, c)
+			conv = c
+		}
+		last.Store(&ifaceConvEntry{t: t, conv: conv})
+		return conv, nil
+	}
+	return func(dst, src unsafe.Pointer) error {
+		v := reflect.NewAt(srcType, src).Elem()
+		if v.IsNil() {
+			return onNil(dst)
+		}
+		x := v.Elem()
+		conv, err := lookup(x.Type())
+		if err != nil {
+			return err
+		}
+		if x.Kind() == reflect.Pointer {
+			p := x.UnsafePointer()
+			return conv(dst, unsafe.Pointer(&p))
+		}
+		// dynamic values aren't addressable so they're copied
+		p := reflect.New(x.Type())
+		p.Elem().Set(x)
+		return conv(dst, p.UnsafePointer())
+	}
+}
@@ -0,0 +1,65 @@
+package keyvalue
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type ifaceCreated struct {
+	ID   string
+	Name string
+}
+
+type ifaceRenamed struct {
+	ID      string
+	OldName string
+}
+
+type ifaceEvent struct {
+	Kind    string
+	Payload interface{}
+}
+
+type ifacePayload struct {
+	ID   uuid.UUID
+	Name string
+}
+
+type ifaceTypedEvent struct {
+	Kind    string
+	Payload *ifacePayload
+}
+
+type ifaceNamedEvent struct {
+	Kind    string
+	Payload string
+}
+
+func TestInterfaceSourceField(t *testing.T) {
+	req := require.New(t)
+
+	u := uuid.New()
+	var dst ifaceTypedEvent
+	req.NoError(TypedCopy(&dst, &ifaceEvent{Kind: "created", Payload: &ifaceCreated{ID: u.String(), Name: "a"}}))
+	req.Equal(ifaceTypedEvent{Kind: "created", Payload: &ifacePayload{ID: u, Name: "a"}}, dst)
+
+	req.NoError(TypedCopy(&dst, &ifaceEvent{Kind: "created", Payload: ifaceCreated{ID: u.String(), Name: "b"}}))
+	req.Equal(&ifacePayload{ID: u, Name: "b"}, dst.Payload)
+
+	req.NoError(TypedCopy(&dst, &ifaceEvent{Kind: "created", Payload: &ifaceCreated{ID: u.String(), Name: "c"}}))
+	req.Equal("c", dst.Payload.Name)
+
+	dst = ifaceTypedEvent{}
+	req.ErrorIs(TypedCopy(&dst, &ifaceEvent{Kind: "renamed", Payload: ifaceRenamed{ID: u.String()}}), ErrFieldNotFound)
+
+	dst = ifaceTypedEvent{Payload: &ifacePayload{}}
+	req.NoError(TypedCopy(&dst, &ifaceEvent{Kind: "empty"}, WithNilPolicy(NilZero)))
+	req.Nil(dst.Payload)
+
+	var named ifaceNamedEvent
+	req.NoError(TypedCopy(&named, &ifaceEvent{Payload: u}))
+	req.Equal(u.String(), named.Payload)
+	req.Error(TypedCopy(&named, &ifaceEvent{Payload: []int{1}}))
+}
@@ -0,0 +1,210 @@
+package keyvalue
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"unsafe"
+)
+
+// opcode is an instruction opcode of a compiled copier.
+type opcode uint8
+
+const (
+	// opMemcopy copies aux bytes from the source offset to the destination offset.
+	// It's only used for pointer-free values.
+	opMemcopy opcode = iota
+	// opConv calls the conversion function with index aux on the field pair.
+	opConv
+	// opCtxConv calls the context-aware conversion function with index aux on the field pair.
+	opCtxConv
+	// opCond evaluates the condition with index aux on the source structure
+	// and skips the instructions guarded by the condition if it doesn't hold.
+	opCond
+)
+
+// instr is a single instruction of a compiled copier.
+type instr struct {
+	op        opcode
+	dstOffset uintptr
+	srcOffset uintptr
+	aux       uintptr
+}
+
+// program is a compiled copier represented as a flat list of instructions.
+type program struct {
+	instrs   []instr
+	convs    []func(unsafe.Pointer, unsafe.Pointer) error
+	ctxConvs []func(context.Context, unsafe.Pointer, unsafe.Pointer) error
+	conds    []condition
+	fields   []fieldInfo
+	// sealed tells whether the program has been completed. Sealed programs are shared by concurrent copies
+	// so they mustn't be modified.
+	sealed bool
+}
+
+// condition guards the copying of a field.
+type condition struct {
+	holds func(unsafe.Pointer) bool
+	// skip is the number of instructions guarded by the condition.
+	skip int
+}
+
+// fieldInfo describes the field copied by the instruction with the same index.
+type fieldInfo struct {
+	name             string
+	dstType, srcType reflect.Type
+}
+
+// addField compiles the copying of a field into the program.
+func (p *program) addField(name string, dstType, srcType reflect.Type, dstOffset, srcOffset uintptr, opts *CopierOptions) error {
+	p.fields = append(p.fields, fieldInfo{
+		name:    name,
+		dstType: dstType,
+		srcType: srcType,
+	})
+	if dstType == srcType && !hasPointers(dstType) && !convertsNested(dstType, opts) {
+		p.emit(instr{
+			op:        opMemcopy,
+			dstOffset: dstOffset,
+			srcOffset: srcOffset,
+			aux:       dstType.Size(),
+		})
+		return nil
+	}
+	conv, err := valConvWithOptions(dstType, srcType, opts)
+	if err != nil {
+		return err
+	}
+	p.addConv(conv, dstOffset, srcOffset, opts)
+	return nil
+}
+
+// addCustomField compiles the copying of a field by a custom converter into the program.
+func (p *program) addCustomField(name string, dstType, srcType reflect.Type, dstOffset, srcOffset uintptr, conv func(unsafe.Pointer, unsafe.Pointer) error, opts *CopierOptions) {
+	p.fields = append(p.fields, fieldInfo{
+		name:    name,
+		dstType: dstType,
+		srcType: srcType,
+	})
+	p.addConv(conv, dstOffset, srcOffset, opts)
+}
+
+// addContextField compiles the copying of a field by a context-aware converter into the program.
+func (p *program) addContextField(name string, dstType, srcType reflect.Type, dstOffset, srcOffset uintptr, conv func(context.Context, unsafe.Pointer, unsafe.Pointer) error, opts *CopierOptions) {
+	p.fields = append(p.fields, fieldInfo{
+		name:    name,
+		dstType: dstType,
+		srcType: srcType,
+	})
+	if opts != nil && opts.RecoverPanics {
+		conv = recoveringContext(conv)
+	}
+	p.emit(instr{
+		op:        opCtxConv,
+		dstOffset: dstOffset,
+		srcOffset: srcOffset,
+		aux:       uintptr(len(p.ctxConvs)),
+	})
+	p.ctxConvs = append(p.ctxConvs, conv)
+}
+
+// addRecordedField compiles the copying of a top-level field computed from the whole source structure into the program.
+// The changes of the field are reported to the recorder in the options if there's one.
+func (p *program) addRecordedField(name string, dstType reflect.Type, dstField reflect.StructField, srcType reflect.Type, dstOffset uintptr, conv func(unsafe.Pointer, unsafe.Pointer) error, opts *CopierOptions) error {
+	rec := fieldRecorder(opts, true)
+	if rec == nil {
+		p.addCustomField(name, dstField.Type, srcType, dstOffset, 0, conv, opts)
+		return nil
+	}
+	ctxConv, err := recordingConv(fieldPath(dstType, dstField.Index), dstField.Type, contextConv(conv), rec)
+	if err != nil {
+		return err
+	}
+	p.addContextField(name, dstField.Type, srcType, dstOffset, 0, ctxConv, opts)
+	return nil
+}
+
+// addConv appends an instruction calling the converter.
+func (p *program) addConv(conv func(unsafe.Pointer, unsafe.Pointer) error, dstOffset, srcOffset uintptr, opts *CopierOptions) {
+	if opts != nil && opts.RecoverPanics {
+		conv = recovering(conv)
+	}
+	p.emit(instr{
+		op:        opConv,
+		dstOffset: dstOffset,
+		srcOffset: srcOffset,
+		aux:       uintptr(len(p.convs)),
+	})
+	p.convs = append(p.convs, conv)
+}
+
+// addCond appends an instruction guarding the instructions compiled for a field by the condition.
+// It returns a function to be called once the guarded instructions have been added.
+func (p *program) addCond(name string, holds func(unsafe.Pointer) bool) func() {
+	p.fields = append(p.fields, fieldInfo{name: name})
+	p.emit(instr{
+		op:  opCond,
+		aux: uintptr(len(p.conds)),
+	})
+	i := len(p.conds)
+	start := len(p.instrs)
+	p.conds = append(p.conds, condition{holds: holds})
+	return func() {
+		p.conds[i].skip = len(p.instrs) - start
+	}
+}
+
+// emit appends the instruction to the program. It panics if the program has already been sealed.
+func (p *program) emit(in instr) {
+	if p.sealed {
+		panic("keyvalue: instruction added to a sealed program")
+	}
+	p.instrs = append(p.instrs, in)
+}
+
+// clip removes unused capacity from the program and seals it.
+func (p *program) clip() {
+	p.sealed = true
+	p.instrs = slices.Clip(p.instrs)
+	p.convs = slices.Clip(p.convs)
+	p.ctxConvs = slices.Clip(p.ctxConvs)
+	p.conds = slices.Clip(p.conds)
+	p.fields = slices.Clip(p.fields)
+}
+
+// run executes the program. The context is passed on to context-aware converters.
+// Fields with skipped slice elements don't stop the copy, the skipped elements are reported once it's complete.
+func (p *program) run(ctx context.Context, dst, src unsafe.Pointer) error {
+	var skipped *SkippedElementsError
+	for i := 0; i < len(p.instrs); i++ {
+		in := &p.instrs[i]
+		d := unsafe.Add(dst, in.dstOffset)
+		s := unsafe.Add(src, in.srcOffset)
+		var err error
+		switch in.op {
+		case opMemcopy:
+			memcopy(d, s, in.aux)
+		case opConv:
+			err = p.convs[in.aux](d, s)
+		case opCtxConv:
+			err = p.ctxConvs[in.aux](ctx, d, s)
+		case opCond:
+			if c := &p.conds[in.aux]; !c.holds(src) {
+				i += c.skip
+			}
+		}
+		if err != nil {
+			f := &p.fields[i]
+			if se, ok := err.(*SkippedElementsError); ok {
+				skipped = addSkipped(skipped, se, f.name)
+				continue
+			}
+			return fieldError(err, f.name, f.dstType, f.srcType)
+		}
+	}
+	if skipped != nil {
+		return skipped
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package keyvalue
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgram(t *testing.T) {
+	req := require.New(t)
+
+	type dstS struct {
+		N  int
+		ID uuid.UUID
+	}
+	type srcS struct {
+		ID string
+		N  int
+	}
+
+	var p program
+	dstType, srcType := reflect.TypeFor[dstS](), reflect.TypeFor[srcS]()
+	for _, name := range []string{"N", "ID"} {
+		df, _ := dstType.FieldByName(name)
+		sf, _ := srcType.FieldByName(name)
+		req.NoError(p.addField(name, df.Type, sf.Type, df.Offset, sf.Offset, nil))
+	}
+	p.clip()
+	req.Equal([]opcode{opMemcopy, opConv}, []opcode{p.instrs[0].op, p.instrs[1].op})
+	req.Len(p.convs, 1)
+
+	u := uuid.New()
+	var dst dstS
+	err := p.run(context.Background(), unsafe.Pointer(&dst), unsafe.Pointer(&srcS{ID: u.String(), N: 1234}))
+	req.NoError(err)
+	req.Equal(dstS{N: 1234, ID: u}, dst)
+
+	df, _ := dstType.FieldByName("N")
+	req.Panics(func() {
+		_ = p.addField("N", df.Type, df.Type, df.Offset, df.Offset, nil)
+	})
+}
+
+func TestProgramCond(t *testing.T) {
+	req := require.New(t)
+
+	type pair struct {
+		A, B, C int
+	}
+
+	var p program
+	typ := reflect.TypeFor[pair]()
+	for _, name := range []string{"A", "B", "C"} {
+		f, _ := typ.FieldByName(name)
+		if name == "B" {
+			done := p.addCond(name, func(src unsafe.Pointer) bool {
+				return (*pair)(src).A > 0
+			})
+			req.NoError(p.addField(name, f.Type, f.Type, f.Offset, f.Offset, nil))
+			done()
+			continue
+		}
+		req.NoError(p.addField(name, f.Type, f.Type, f.Offset, f.Offset, nil))
+	}
+	p.clip()
+	req.Equal([]opcode{opMemcopy, opCond, opMemcopy, opMemcopy}, []opcode{p.instrs[0].op, p.instrs[1].op, p.instrs[2].op, p.instrs[3].op})
+
+	var dst pair
+	req.NoError(p.run(context.Background(), unsafe.Pointer(&dst), unsafe.Pointer(&pair{A: 1, B: 2, C: 3})))
+	req.Equal(pair{A: 1, B: 2, C: 3}, dst)
+
+	dst = pair{}
+	req.NoError(p.run(context.Background(), unsafe.Pointer(&dst), unsafe.Pointer(&pair{A: 0, B: 2, C: 3})))
+	req.Equal(pair{C: 3}, dst)
+}
@@ -0,0 +1,75 @@
+package keyvalue
+
+import (
+	"reflect"
+	"unsafe"
+
+	"github.com/mailstepcz/serr"
+)
+
+// SliceToMapCopier creates a copier converting slices into maps keyed by the named field of the source elements,
+// e.g. []*User into map[uuid.UUID]*UserDTO keyed by ID. The elements are converted like by [TypedCopy]
+// and so are the keys, which allows e.g. string IDs to become UUID keys. Nil elements are skipped
+// and later elements replace earlier ones with the same key.
+func SliceToMapCopier[K comparable, D, S any](keyField string) (func([]S) (map[K]D, error), error) {
+	conv, err := sliceToMapConv(reflect.TypeFor[map[K]D](), reflect.TypeFor[[]S](), keyField, nil)
+	if err != nil {
+		return nil, err
+	}
+	return func(src []S) (map[K]D, error) {
+		var dst map[K]D
+		if err := conv(unsafe.Pointer(&dst), unsafe.Pointer(&src)); err != nil {
+			return nil, err
+		}
+		return dst, nil
+	}, nil
+}
+
+// sliceToMapConv returns a converter from slices into maps keyed by the named field of the source elements.
+// Destination fields select the key field with the `copy` tag, e.g. `copy:",key=ID"`.
+func sliceToMapConv(dstType, srcType reflect.Type, keyField string, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	if dstType.Kind() != reflect.Map || srcType.Kind() != reflect.Slice {
+		return nil, serr.Wrap("", ErrUnsupportedTypePair, serr.String("srcType", srcType.String()), serr.String("dstType", dstType.String()))
+	}
+	srcElType, dstElType, dstKeyType := srcType.Elem(), dstType.Elem(), dstType.Key()
+	key, keyType, err := elementKey(srcElType, keyField)
+	if err != nil {
+		return nil, err
+	}
+	keyConv, err := valConvWithOptions(dstKeyType, keyType, opts)
+	if err != nil {
+		return nil, serr.Wrap("", err, serr.String("keyField", keyField))
+	}
+	elConv, err := valConvWithOptions(dstElType, srcElType, opts)
+	if err != nil {
+		return nil, err
+	}
+	onNil := nilHandler(dstType, opts)
+	return func(dst, src unsafe.Pointer) error {
+		srcSlice := reflect.NewAt(srcType, src).Elem()
+		if srcSlice.IsNil() {
+			return onNil(dst)
+		}
+		dstMap := reflect.MakeMapWithSize(dstType, srcSlice.Len())
+		k := reflect.New(keyType).Elem()
+		for i := 0; i < srcSlice.Len(); i++ {
+			el := srcSlice.Index(i)
+			kv, ok := key(el)
+			if !ok {
+				continue
+			}
+			k.Set(kv)
+			dstKey := reflect.New(dstKeyType)
+			if err := keyConv(dstKey.UnsafePointer(), k.Addr().UnsafePointer()); err != nil {
+				return elementError(fieldError(err, keyField, dstKeyType, keyType), i, dstElType, srcElType)
+			}
+			dstEl := reflect.New(dstElType)
+			if err := elConv(dstEl.UnsafePointer(), el.Addr().UnsafePointer()); err != nil {
+				return elementError(err, i, dstElType, srcElType)
+			}
+			dstMap.SetMapIndex(dstKey.Elem(), dstEl.Elem())
+		}
+		reflect.NewAt(dstType, dst).Elem().Set(dstMap)
+		return nil
+	}, nil
+}
@@ -0,0 +1,69 @@
+package keyvalue
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type keyedProduct struct {
+	ID   string
+	Name string
+}
+
+type keyedProductDTO struct {
+	ID   uuid.UUID
+	Name string
+}
+
+type keyedCatalog struct {
+	Products []*keyedProduct
+	Featured []keyedProduct
+}
+
+type keyedCatalogDTO struct {
+	Products map[uuid.UUID]*keyedProductDTO `copy:",key=ID"`
+	Featured map[string]keyedProductDTO     `copy:",key=Name"`
+}
+
+func TestCopierSliceToMap(t *testing.T) {
+	req := require.New(t)
+
+	u1, u2 := uuid.New(), uuid.New()
+	src := keyedCatalog{
+		Products: []*keyedProduct{{ID: u1.String(), Name: "a"}, nil, {ID: u2.String(), Name: "b"}, {ID: u1.String(), Name: "c"}},
+		Featured: []keyedProduct{{ID: u1.String(), Name: "a"}},
+	}
+	var dst keyedCatalogDTO
+	req.NoError(TypedCopy(&dst, &src))
+	req.Equal(map[uuid.UUID]*keyedProductDTO{u1: {ID: u1, Name: "c"}, u2: {ID: u2, Name: "b"}}, dst.Products)
+	req.Equal(map[string]keyedProductDTO{"a": {ID: u1, Name: "a"}}, dst.Featured)
+
+	dst = keyedCatalogDTO{}
+	req.NoError(TypedCopy(&dst, &keyedCatalog{}))
+	req.Nil(dst.Products)
+
+	err := TypedCopy(&dst, &keyedCatalog{Products: []*keyedProduct{{ID: "bad"}}})
+	req.Error(err)
+	var cerr *CopyError
+	req.ErrorAs(err, &cerr)
+	req.Equal("Products.0.ID", cerr.Path())
+}
+
+func TestSliceToMapCopier(t *testing.T) {
+	req := require.New(t)
+
+	u := uuid.New()
+	c, err := SliceToMapCopier[uuid.UUID, *keyedProductDTO, *keyedProduct]("ID")
+	req.NoError(err)
+	m, err := c([]*keyedProduct{{ID: u.String(), Name: "a"}})
+	req.NoError(err)
+	req.Equal(map[uuid.UUID]*keyedProductDTO{u: {ID: u, Name: "a"}}, m)
+
+	_, err = SliceToMapCopier[uuid.UUID, *keyedProductDTO, *keyedProduct]("Missing")
+	req.ErrorIs(err, ErrFieldNotFound)
+
+	_, err = SliceToMapCopier[int, *keyedProductDTO, *keyedProduct]("ID")
+	req.Error(err)
+}
@@ -0,0 +1,53 @@
+package keyvalue
+
+import (
+	"errors"
+
+	"golang.org/x/text/language"
+)
+
+// languageParser returns a function parsing language tags as configured by the options.
+func languageParser(opts *CopierOptions) func(string) (language.Tag, error) {
+	if opts == nil || opts.LanguageCanon == 0 && len(opts.SupportedLanguages) == 0 {
+		return language.Parse
+	}
+	parse := language.Parse
+	if opts.LanguageCanon != 0 {
+		parse = opts.LanguageCanon.Parse
+	}
+	normalize := languageNormalizer(opts)
+	return func(s string) (language.Tag, error) {
+		t, err := parse(s)
+		if err != nil {
+			var verr language.ValueError
+			if len(opts.SupportedLanguages) == 0 || !errors.As(err, &verr) {
+				return t, err
+			}
+		}
+		return normalize(t), nil
+	}
+}
+
+// languageNormalizer returns a function canonicalizing language tags and matching them against the supported languages
+// as configured by the options. Tags not matching any supported language are replaced with the first one.
+func languageNormalizer(opts *CopierOptions) func(language.Tag) language.Tag {
+	if opts == nil || opts.LanguageCanon == 0 && len(opts.SupportedLanguages) == 0 {
+		return func(t language.Tag) language.Tag { return t }
+	}
+	canon := opts.LanguageCanon
+	supported := opts.SupportedLanguages
+	var matcher language.Matcher
+	if len(supported) > 0 {
+		matcher = language.NewMatcher(supported)
+	}
+	return func(t language.Tag) language.Tag {
+		if canon != 0 {
+			t, _ = canon.Canonicalize(t)
+		}
+		if matcher != nil {
+			_, i, _ := matcher.Match(t)
+			t = supported[i]
+		}
+		return t
+	}
+}
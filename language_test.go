@@ -0,0 +1,57 @@
+package keyvalue
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+type languageDst struct {
+	Lang language.Tag
+	Code string
+}
+
+type languageSrc struct {
+	Lang string
+	Code language.Tag
+}
+
+func TestCopierLanguageOptions(t *testing.T) {
+	req := require.New(t)
+
+	copier, err := CopierForPairWithOptions(reflect.TypeFor[languageDst](), reflect.TypeFor[languageSrc](), &CopierOptions{
+		SupportedLanguages: []language.Tag{language.English, language.Czech, language.German},
+	})
+	req.NoError(err)
+
+	for input, expected := range map[string]language.Tag{
+		"EN-us": language.English,
+		"cs-CZ": language.Czech,
+		"de-AT": language.German,
+		"cz":    language.English,
+		"fr":    language.English,
+	} {
+		var dst languageDst
+		req.NoError(copier(unsafe.Pointer(&dst), unsafe.Pointer(&languageSrc{Lang: input, Code: language.MustParse("cs-CZ")})), input)
+		req.Equal(expected, dst.Lang, input)
+		req.Equal("cs", dst.Code)
+	}
+
+	var dst languageDst
+	err = copier(unsafe.Pointer(&dst), unsafe.Pointer(&languageSrc{Lang: "not a tag"}))
+	req.Error(err)
+
+	copier, err = CopierForPairWithOptions(reflect.TypeFor[languageDst](), reflect.TypeFor[languageSrc](), &CopierOptions{
+		LanguageCanon: language.All,
+	})
+	req.NoError(err)
+	req.NoError(copier(unsafe.Pointer(&dst), unsafe.Pointer(&languageSrc{Lang: "sh", Code: language.Make("iw")})))
+	req.Equal("sr-Latn", dst.Lang.String())
+	req.Equal("he", dst.Code)
+
+	err = copier(unsafe.Pointer(&dst), unsafe.Pointer(&languageSrc{Lang: "cz"}))
+	req.Error(err)
+}
@@ -0,0 +1,213 @@
+package keyvalue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"unsafe"
+
+	"github.com/mailstepcz/serr"
+)
+
+var (
+	// ErrLimitExceeded is returned if the source value exceeds the [CopyLimits] of the copier.
+	ErrLimitExceeded = errors.New("copy limit exceeded")
+)
+
+// CopyLimits bounds the source values copied by a copier so that untrusted or recursive inputs,
+// such as dynamic maps decoded from JSON, can't exhaust the memory. Zero limits are unlimited.
+// The source is checked before anything is copied so the destination is left unchanged if a limit is exceeded.
+// Values referenced several times are counted once and values referencing themselves are rejected with an error
+// wrapping both [ErrLimitExceeded] and [ErrCyclicValue] if any limit is set.
+type CopyLimits struct {
+	// MaxDepth is the maximum nesting depth of structures, slices, arrays and maps within the source.
+	// Pointers and interfaces don't add to the depth.
+	MaxDepth int
+	// MaxElements is the maximum total number of slice elements and map entries.
+	MaxElements int
+	// MaxBytes is the maximum total size of the memory referenced by the source
+	// through pointers, slices, maps, strings and interfaces.
+	MaxBytes int
+}
+
+// limited checks the source against the limits before copying it.
+func limited(copier func(context.Context, unsafe.Pointer, unsafe.Pointer) error, limits CopyLimits, dstType, srcType reflect.Type) func(context.Context, unsafe.Pointer, unsafe.Pointer) error {
+	return func(ctx context.Context, dst, src unsafe.Pointer) error {
+		c := limitCounter{limits: limits, visited: make(map[limitKey]bool)}
+		if err := c.walk(reflect.NewAt(srcType, src).Elem(), 0); err != nil {
+			return &CopyError{
+				DstType: dstType,
+				SrcType: srcType,
+				Err:     err,
+			}
+		}
+		return copier(ctx, dst, src)
+	}
+}
+
+// limitCounter accumulates the size of a value checked against the limits.
+type limitCounter struct {
+	limits   CopyLimits
+	elements int
+	bytes    int
+	// visited holds the pointers, slices and maps walked so far, those on the path to the walked value being set.
+	visited map[limitKey]bool
+}
+
+// limitKey identifies a value referenced by a pointer, slice or map.
+type limitKey struct {
+	ptr unsafe.Pointer
+	typ reflect.Type
+	len int
+}
+
+// walk accounts for the value found at the depth and everything it references.
+func (c *limitCounter) walk(v reflect.Value, depth int) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return c.visit(v, func() error {
+			if err := c.addBytes(int(v.Type().Elem().Size())); err != nil {
+				return err
+			}
+			return c.walk(v.Elem(), depth)
+		})
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+		if err := c.addBytes(int(v.Type().Size())); err != nil {
+			return err
+		}
+		return c.walk(v, depth)
+
+	case reflect.String:
+		return c.addBytes(v.Len())
+
+	case reflect.Struct:
+		if err := c.enter(depth); err != nil {
+			return err
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if !hasPointers(v.Type().Field(i).Type) {
+				continue
+			}
+			if err := c.walk(v.Field(i), depth+1); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Array:
+		if !hasPointers(v.Type().Elem()) {
+			return nil
+		}
+		if err := c.enter(depth); err != nil {
+			return err
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := c.walk(v.Index(i), depth+1); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		if err := c.enter(depth); err != nil {
+			return err
+		}
+		return c.visit(v, func() error {
+			if err := c.addElements(v.Len()); err != nil {
+				return err
+			}
+			if err := c.addBytes(v.Len() * int(v.Type().Elem().Size())); err != nil {
+				return err
+			}
+			if !hasPointers(v.Type().Elem()) {
+				return nil
+			}
+			for i := 0; i < v.Len(); i++ {
+				if err := c.walk(v.Index(i), depth+1); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		if err := c.enter(depth); err != nil {
+			return err
+		}
+		return c.visit(v, func() error {
+			if err := c.addElements(v.Len()); err != nil {
+				return err
+			}
+			if err := c.addBytes(v.Len() * int(v.Type().Key().Size()+v.Type().Elem().Size())); err != nil {
+				return err
+			}
+			iter := v.MapRange()
+			for iter.Next() {
+				if err := c.walk(iter.Key(), depth+1); err != nil {
+					return err
+				}
+				if err := c.walk(iter.Value(), depth+1); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	return nil
+}
+
+// visit walks the value referenced by the pointer, slice or map unless it has been walked already.
+// Values referencing themselves, directly or through other values, are rejected.
+func (c *limitCounter) visit(v reflect.Value, walk func() error) error {
+	k := limitKey{ptr: v.UnsafePointer(), typ: v.Type()}
+	if v.Kind() == reflect.Slice {
+		k.len = v.Len()
+	}
+	if onPath, ok := c.visited[k]; ok {
+		if onPath {
+			return fmt.Errorf("%w: %w", ErrLimitExceeded, serr.Wrap("", ErrCyclicValue, serr.String("type", v.Type().String())))
+		}
+		return nil
+	}
+	c.visited[k] = true
+	err := walk()
+	c.visited[k] = false
+	return err
+}
+
+// enter checks the depth of a nested structure, slice, array or map.
+func (c *limitCounter) enter(depth int) error {
+	if c.limits.MaxDepth > 0 && depth > c.limits.MaxDepth {
+		return serr.Wrap("", ErrLimitExceeded, serr.String("limit", "depth"), serr.Int("max", c.limits.MaxDepth))
+	}
+	return nil
+}
+
+func (c *limitCounter) addElements(n int) error {
+	c.elements += n
+	if c.limits.MaxElements > 0 && c.elements > c.limits.MaxElements {
+		return serr.Wrap("", ErrLimitExceeded, serr.String("limit", "elements"), serr.Int("max", c.limits.MaxElements))
+	}
+	return nil
+}
+
+func (c *limitCounter) addBytes(n int) error {
+	c.bytes += n
+	if c.limits.MaxBytes > 0 && c.bytes > c.limits.MaxBytes {
+		return serr.Wrap("", ErrLimitExceeded, serr.String("limit", "bytes"), serr.Int("max", c.limits.MaxBytes))
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package keyvalue
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type limitedNode struct {
+	Name string
+	Next *limitedNode
+}
+
+type limitedRequest struct {
+	Attrs map[string]interface{}
+	Tags  []string
+	Head  *limitedNode
+}
+
+type limitedRequestDTO struct {
+	Attrs map[string]interface{}
+	Tags  []string
+	Head  *limitedNode
+}
+
+func TestCopyLimits(t *testing.T) {
+	req := require.New(t)
+
+	limits := CopyLimits{MaxDepth: 4, MaxElements: 10, MaxBytes: 1024}
+	src := limitedRequest{
+		Attrs: map[string]interface{}{"a": map[string]interface{}{"b": 1.0}},
+		Tags:  []string{"x", "y"},
+		Head:  &limitedNode{Name: "a", Next: &limitedNode{Name: "b"}},
+	}
+	var dst limitedRequestDTO
+	req.NoError(TypedCopy(&dst, &src, WithLimits(limits)))
+	req.Equal(src.Tags, dst.Tags)
+
+	deep := map[string]interface{}{}
+	nested := deep
+	for i := 0; i < 5; i++ {
+		m := map[string]interface{}{}
+		nested["x"] = m
+		nested = m
+	}
+	dst = limitedRequestDTO{}
+	err := TypedCopy(&dst, &limitedRequest{Attrs: deep}, WithLimits(limits))
+	req.ErrorIs(err, ErrLimitExceeded)
+	var ce *CopyError
+	req.ErrorAs(err, &ce)
+	req.Nil(dst.Attrs)
+
+	req.ErrorIs(TypedCopy(&dst, &limitedRequest{Tags: make([]string, 11)}, WithLimits(limits)), ErrLimitExceeded)
+	req.ErrorIs(TypedCopy(&dst, &limitedRequest{Tags: []string{strings.Repeat("x", 2048)}}, WithLimits(limits)), ErrLimitExceeded)
+
+	cyclic := &limitedNode{Name: "loop"}
+	cyclic.Next = cyclic
+	req.ErrorIs(TypedCopy(&dst, &limitedRequest{Head: cyclic}, WithLimits(CopyLimits{MaxDepth: 100})), ErrLimitExceeded)
+	for _, limits := range []CopyLimits{{MaxElements: 10}, {MaxBytes: 1024}} {
+		err := TypedCopy(&dst, &limitedRequest{Head: cyclic}, WithLimits(limits))
+		req.ErrorIs(err, ErrLimitExceeded)
+		req.ErrorIs(err, ErrCyclicValue)
+	}
+
+	self := map[string]interface{}{}
+	self["self"] = self
+	req.ErrorIs(TypedCopy(&dst, &limitedRequest{Attrs: self}, WithLimits(CopyLimits{MaxElements: 10})), ErrCyclicValue)
+
+	shared := &limitedNode{Name: strings.Repeat("x", 100)}
+	dst = limitedRequestDTO{}
+	req.NoError(TypedCopy(&dst, &limitedRequest{Attrs: map[string]interface{}{"a": shared, "b": shared}, Head: shared}, WithLimits(CopyLimits{MaxBytes: 300})))
+	req.Same(shared, dst.Head)
+}
@@ -0,0 +1,195 @@
+package keyvalue
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/mailstepcz/serr"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrNotRegistered signifies that a type or a converter named in a manifest hasn't been registered.
+	ErrNotRegistered = errors.New("not registered")
+)
+
+// Manifest declares copiers as data. It can be loaded from YAML or JSON.
+type Manifest struct {
+	Pairs []PairMapping `json:"pairs" yaml:"pairs"`
+}
+
+// PairMapping declares a copier for a pair of registered types.
+// Rename maps source field names to destination field names, Convert maps source field names to registered converters
+// and Split maps source field names to registered splitters. The mapping also applies to the structures of the pair
+// nested in other structures, including those in pointers and slices, copied by the copiers of the registry
+// compiled along with it or later.
+type PairMapping struct {
+	Dst          string            `json:"dst" yaml:"dst"`
+	Src          string            `json:"src" yaml:"src"`
+	Rename       map[string]string `json:"rename" yaml:"rename"`
+	Omit         []string          `json:"omit" yaml:"omit"`
+	Copy         []string          `json:"copy" yaml:"copy"`
+	OmitNotFound bool              `json:"omitNotFound" yaml:"omitNotFound"`
+	Convert      map[string]string `json:"convert" yaml:"convert"`
+	Split        map[string]string `json:"split" yaml:"split"`
+}
+
+// Registry holds named types and converters and the copiers compiled from manifests.
+// It's safe for concurrent use.
+type Registry struct {
+	types      map[string]reflect.Type
+	converters map[string]Converter
+	splitters  map[string]Splitter
+	handles    map[[2]reflect.Type]*Handle
+	options    map[[2]reflect.Type]*CopierOptions
+	mtx        sync.RWMutex
+}
+
+// NewRegistry creates a new registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		types:      make(map[string]reflect.Type),
+		converters: make(map[string]Converter),
+		splitters:  make(map[string]Splitter),
+		handles:    make(map[[2]reflect.Type]*Handle),
+		options:    make(map[[2]reflect.Type]*CopierOptions),
+	}
+}
+
+// RegisterType registers a type under the name used in manifests.
+func RegisterType[T any](r *Registry, name string) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.types[name] = reflect.TypeFor[T]()
+}
+
+// RegisterConverter registers a converter under the name used in manifests.
+func RegisterConverter[D, S any](r *Registry, name string, f func(S) (D, error)) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.converters[name] = Converter{
+		DstType: reflect.TypeFor[D](),
+		SrcType: reflect.TypeFor[S](),
+		Func: func(x interface{}) (interface{}, error) {
+			return f(x.(S))
+		},
+	}
+}
+
+// RegisterSplitter registers a splitter under the name used in manifests.
+func RegisterSplitter(r *Registry, name string, s Splitter) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.splitters[name] = s
+}
+
+// Load parses a YAML or JSON manifest and compiles the copiers it declares.
+func (r *Registry) Load(data []byte) error {
+	var m Manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	return r.Apply(&m)
+}
+
+// Apply compiles the copiers declared by the manifest. Either all of them are added to the registry or none.
+func (r *Registry) Apply(m *Manifest) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	pairOpts := make(map[[2]reflect.Type]*CopierOptions, len(r.options)+len(m.Pairs))
+	for k, opts := range r.options {
+		o := *opts
+		pairOpts[k] = &o
+	}
+	keys := make([][2]reflect.Type, len(m.Pairs))
+	for i, p := range m.Pairs {
+		key, opts, err := r.pairOptions(p)
+		if err != nil {
+			return serr.Wrap("", err, serr.String("dst", p.Dst), serr.String("src", p.Src))
+		}
+		keys[i] = key
+		pairOpts[key] = opts
+	}
+	for _, opts := range pairOpts {
+		opts.pairOptions = pairOpts
+	}
+	handles := make(map[[2]reflect.Type]*Handle, len(m.Pairs))
+	for i, p := range m.Pairs {
+		h, err := HandleForPair(keys[i][0], keys[i][1], pairOpts[keys[i]])
+		if err != nil {
+			return serr.Wrap("", err, serr.String("dst", p.Dst), serr.String("src", p.Src))
+		}
+		handles[keys[i]] = h
+	}
+	for k, h := range handles {
+		r.handles[k] = h
+	}
+	r.options = pairOpts
+	return nil
+}
+
+// pairOptions returns the pair of types and the copier options declared by the mapping. The registry must be locked.
+func (r *Registry) pairOptions(p PairMapping) ([2]reflect.Type, *CopierOptions, error) {
+	dstType, ok := r.types[p.Dst]
+	if !ok {
+		return [2]reflect.Type{}, nil, serr.Wrap("", ErrNotRegistered, serr.String("type", p.Dst))
+	}
+	srcType, ok := r.types[p.Src]
+	if !ok {
+		return [2]reflect.Type{}, nil, serr.Wrap("", ErrNotRegistered, serr.String("type", p.Src))
+	}
+	opts := &CopierOptions{
+		OmitNotFound: p.OmitNotFound,
+		FieldsToCopy: p.Copy,
+		FieldsToOmit: p.Omit,
+		FieldRenames: p.Rename,
+		NoCache:      true,
+	}
+	if len(p.Convert) > 0 {
+		opts.Converters = make(map[string]Converter, len(p.Convert))
+		for field, name := range p.Convert {
+			conv, ok := r.converters[name]
+			if !ok {
+				return [2]reflect.Type{}, nil, serr.Wrap("", ErrNotRegistered, serr.String("converter", name))
+			}
+			opts.Converters[field] = conv
+		}
+	}
+	if len(p.Split) > 0 {
+		opts.Splitters = make(map[string]Splitter, len(p.Split))
+		for field, name := range p.Split {
+			s, ok := r.splitters[name]
+			if !ok {
+				return [2]reflect.Type{}, nil, serr.Wrap("", ErrNotRegistered, serr.String("splitter", name))
+			}
+			opts.Splitters[field] = s
+		}
+	}
+	return [2]reflect.Type{dstType, srcType}, opts, nil
+}
+
+// Handle returns the copier for the pair of registered types.
+func (r *Registry) Handle(dst, src string) (*Handle, bool) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	h, ok := r.handles[[2]reflect.Type{r.types[dst], r.types[src]}]
+	return h, ok
+}
+
+// Copy copies the source object to the destination object using the copier declared for their types.
+// Both arguments have to be pointers to structures.
+func (r *Registry) Copy(dst, src interface{}) error {
+	dt, st := reflect.TypeOf(dst), reflect.TypeOf(src)
+	if dt == nil || st == nil || dt.Kind() != reflect.Pointer || st.Kind() != reflect.Pointer {
+		return serr.Wrap("", ErrUnsupportedTypePair, serr.String("dst", fmt.Sprintf("%T", dst)), serr.String("src", fmt.Sprintf("%T", src)))
+	}
+	r.mtx.RLock()
+	h, ok := r.handles[[2]reflect.Type{dt.Elem(), st.Elem()}]
+	r.mtx.RUnlock()
+	if !ok {
+		return serr.Wrap("", ErrNotRegistered, serr.String("dstType", dt.Elem().String()), serr.String("srcType", st.Elem().String()))
+	}
+	return h.Copy(dst, src)
+}
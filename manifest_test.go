@@ -0,0 +1,146 @@
+package keyvalue
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+type manifestUser struct {
+	ID       uuid.UUID
+	Name     string
+	Password string
+	Cents    int64
+}
+
+type manifestUserDTO struct {
+	ID       string
+	FullName string
+	Price    decimal.Decimal
+}
+
+const userManifest = `
+pairs:
+  - dst: UserDTO
+    src: User
+    rename:
+      Name: FullName
+      Cents: Price
+    omit: [Password]
+    convert:
+      Cents: centsToDecimal
+`
+
+func TestRegistry(t *testing.T) {
+	req := require.New(t)
+
+	r := NewRegistry()
+	RegisterType[manifestUser](r, "User")
+	RegisterType[manifestUserDTO](r, "UserDTO")
+	RegisterConverter(r, "centsToDecimal", func(x int64) (decimal.Decimal, error) {
+		return decimal.New(x, -2), nil
+	})
+	req.NoError(r.Load([]byte(userManifest)))
+
+	h, ok := r.Handle("UserDTO", "User")
+	req.True(ok)
+
+	u := uuid.New()
+	var dst manifestUserDTO
+	err := h.Copy(&dst, &manifestUser{ID: u, Name: "John", Password: "secret", Cents: 1234})
+	req.NoError(err)
+	req.Equal(u.String(), dst.ID)
+	req.Equal("John", dst.FullName)
+	req.Equal("12.34", dst.Price.String())
+
+	dst = manifestUserDTO{}
+	err = r.Copy(&dst, &manifestUser{Name: "Jane"})
+	req.NoError(err)
+	req.Equal("Jane", dst.FullName)
+
+	err = r.Copy(&manifestUser{}, &dst)
+	req.ErrorIs(err, ErrNotRegistered)
+}
+
+func TestRegistryJSON(t *testing.T) {
+	req := require.New(t)
+
+	r := NewRegistry()
+	RegisterType[manifestUser](r, "User")
+	RegisterType[manifestUserDTO](r, "UserDTO")
+
+	err := r.Load([]byte(`{"pairs": [{"dst": "UserDTO", "src": "User", "omit": ["Password", "Cents"], "rename": {"Name": "FullName"}}]}`))
+	req.NoError(err)
+	_, ok := r.Handle("UserDTO", "User")
+	req.True(ok)
+
+	err = r.Load([]byte(`{"pairs": [{"dst": "UserDTO", "src": "User", "convert": {"Cents": "unknown"}}]}`))
+	req.ErrorIs(err, ErrNotRegistered)
+
+	err = r.Load([]byte(`{"pairs": [{"dst": "UserDTO", "src": "Customer"}]}`))
+	req.ErrorIs(err, ErrNotRegistered)
+
+	err = r.Load([]byte(`{"pairs": [{"dst": "UserDTO", "src": "User"}]}`))
+	req.ErrorIs(err, ErrFieldNotFound)
+}
+
+type manifestAddress struct {
+	Street string
+	Zip    int64
+}
+
+type manifestAddressDTO struct {
+	Line string
+	Zip  string
+}
+
+type manifestCustomer struct {
+	Name      string
+	Address   manifestAddress
+	Shipping  *manifestAddress
+	Addresses []manifestAddress
+}
+
+type manifestCustomerDTO struct {
+	Name      string
+	Address   manifestAddressDTO
+	Shipping  *manifestAddressDTO
+	Addresses []manifestAddressDTO
+}
+
+func TestRegistryNestedPairs(t *testing.T) {
+	req := require.New(t)
+
+	r := NewRegistry()
+	RegisterType[manifestCustomer](r, "Customer")
+	RegisterType[manifestCustomerDTO](r, "CustomerDTO")
+	RegisterType[manifestAddress](r, "Address")
+	RegisterType[manifestAddressDTO](r, "AddressDTO")
+	RegisterConverter(r, "zip", func(x int64) (string, error) {
+		return fmt.Sprintf("%05d", x), nil
+	})
+	req.NoError(r.Load([]byte(`
+pairs:
+  - dst: AddressDTO
+    src: Address
+    rename:
+      Street: Line
+    convert:
+      Zip: zip
+`)))
+	req.NoError(r.Load([]byte(`
+pairs:
+  - dst: CustomerDTO
+    src: Customer
+`)))
+
+	addr := manifestAddress{Street: "Main 1", Zip: 1234}
+	var dst manifestCustomerDTO
+	err := r.Copy(&dst, &manifestCustomer{Name: "John", Address: addr, Shipping: &addr, Addresses: []manifestAddress{addr}})
+	req.NoError(err)
+	expected := manifestAddressDTO{Line: "Main 1", Zip: "01234"}
+	req.Equal(manifestCustomerDTO{Name: "John", Address: expected, Shipping: &expected, Addresses: []manifestAddressDTO{expected}}, dst)
+}
@@ -0,0 +1,149 @@
+package keyvalue
+
+import (
+	"reflect"
+	"slices"
+	"unsafe"
+)
+
+// entryFields returns the key and value fields of a structure describing a map entry such as those
+// generated for protobuf maps modelled as repeated messages. The fields are either tagged with `entry:"key"`
+// and `entry:"value"` or named Key and Value. Pointers to such structures describe map entries too.
+func entryFields(t reflect.Type) (reflect.StructField, reflect.StructField, bool) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return reflect.StructField{}, reflect.StructField{}, false
+	}
+	var key, value reflect.StructField
+	var hasKey, hasValue bool
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		switch f.Tag.Get("entry") {
+		case "key":
+			key, hasKey = f, true
+		case "value":
+			value, hasValue = f, true
+		}
+	}
+	if !hasKey {
+		key, hasKey = t.FieldByName("Key")
+	}
+	if !hasValue {
+		value, hasValue = t.FieldByName("Value")
+	}
+	if !hasKey || !hasValue || len(key.Index) != 1 || len(value.Index) != 1 || key.PkgPath != "" || value.PkgPath != "" {
+		return reflect.StructField{}, reflect.StructField{}, false
+	}
+	return key, value, true
+}
+
+// isMapEntry checks whether the type describes a map entry.
+func isMapEntry(t reflect.Type) bool {
+	_, _, ok := entryFields(t)
+	return ok
+}
+
+// mapToEntriesConv returns a converter of maps into slices of map entries.
+// The entries are ordered by their keys if the keys are numbers, strings or have a Compare method.
+func mapToEntriesConv(dstType, srcType reflect.Type, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	entryType := dstType.Elem()
+	ptr := entryType.Kind() == reflect.Pointer
+	if ptr {
+		entryType = entryType.Elem()
+	}
+	keyField, valueField, _ := entryFields(entryType)
+	keyConv, err := valConvWithOptions(keyField.Type, srcType.Key(), opts)
+	if err != nil {
+		return nil, compileFieldError(err, keyField.Name, keyField.Type, srcType.Key())
+	}
+	valueConv, err := valConvWithOptions(valueField.Type, srcType.Elem(), opts)
+	if err != nil {
+		return nil, compileFieldError(err, valueField.Name, valueField.Type, srcType.Elem())
+	}
+	compare, ordered := compareFunc(srcType.Key())
+	onNil := nilHandler(dstType, opts)
+	return func(dst, src unsafe.Pointer) error {
+		srcMap := reflect.NewAt(srcType, src).Elem()
+		if srcMap.IsNil() {
+			return onNil(dst)
+		}
+		keys := srcMap.MapKeys()
+		if ordered {
+			slices.SortFunc(keys, compare)
+		}
+		dstSlice := reflect.MakeSlice(dstType, len(keys), len(keys))
+		k := reflect.New(srcType.Key()).Elem()
+		v := reflect.New(srcType.Elem()).Elem()
+		for i, key := range keys {
+			k.Set(key)
+			v.Set(srcMap.MapIndex(key))
+			entry := dstSlice.Index(i)
+			if ptr {
+				entry.Set(reflect.New(entryType))
+				entry = entry.Elem()
+			}
+			p := entry.Addr().UnsafePointer()
+			if err := keyConv(unsafe.Add(p, keyField.Offset), k.Addr().UnsafePointer()); err != nil {
+				return fieldError(err, keyField.Name, keyField.Type, srcType.Key())
+			}
+			if err := valueConv(unsafe.Add(p, valueField.Offset), v.Addr().UnsafePointer()); err != nil {
+				return fieldError(err, valueField.Name, valueField.Type, srcType.Elem())
+			}
+		}
+		reflect.NewAt(dstType, dst).Elem().Set(dstSlice)
+		return nil
+	}, nil
+}
+
+// entriesToMapConv returns a converter of slices of map entries into maps.
+// Nil entries are skipped and later entries replace earlier ones with the same key.
+func entriesToMapConv(dstType, srcType reflect.Type, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	entryType := srcType.Elem()
+	ptr := entryType.Kind() == reflect.Pointer
+	if ptr {
+		entryType = entryType.Elem()
+	}
+	keyField, valueField, _ := entryFields(entryType)
+	keyConv, err := valConvWithOptions(dstType.Key(), keyField.Type, opts)
+	if err != nil {
+		return nil, compileFieldError(err, keyField.Name, dstType.Key(), keyField.Type)
+	}
+	valueConv, err := valConvWithOptions(dstType.Elem(), valueField.Type, opts)
+	if err != nil {
+		return nil, compileFieldError(err, valueField.Name, dstType.Elem(), valueField.Type)
+	}
+	onNil := nilHandler(dstType, opts)
+	return func(dst, src unsafe.Pointer) error {
+		srcSlice := reflect.NewAt(srcType, src).Elem()
+		if srcSlice.IsNil() {
+			return onNil(dst)
+		}
+		dstMap := reflect.MakeMapWithSize(dstType, srcSlice.Len())
+		for i := 0; i < srcSlice.Len(); i++ {
+			entry := srcSlice.Index(i)
+			if ptr {
+				if entry.IsNil() {
+					continue
+				}
+				entry = entry.Elem()
+			}
+			p := entry.Addr().UnsafePointer()
+			k := reflect.New(dstType.Key())
+			if err := keyConv(k.UnsafePointer(), unsafe.Add(p, keyField.Offset)); err != nil {
+				return fieldError(err, keyField.Name, dstType.Key(), keyField.Type)
+			}
+			v := reflect.New(dstType.Elem())
+			if err := valueConv(v.UnsafePointer(), unsafe.Add(p, valueField.Offset)); err != nil {
+				return fieldError(err, valueField.Name, dstType.Elem(), valueField.Type)
+			}
+			dstMap.SetMapIndex(k.Elem(), v.Elem())
+		}
+		reflect.NewAt(dstType, dst).Elem().Set(dstMap)
+		return nil
+	}, nil
+}
@@ -0,0 +1,57 @@
+package keyvalue
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type labelEntry struct {
+	state uint8
+	Key   string
+	Value int64
+}
+
+type ownerEntry struct {
+	Team   string `entry:"key"`
+	Person string `entry:"value"`
+}
+
+type labelled struct {
+	Labels map[string]int
+	Owners map[string]uuid.UUID
+}
+
+type labelledMsg struct {
+	Labels []*labelEntry
+	Owners []ownerEntry
+}
+
+func TestCopierMapEntries(t *testing.T) {
+	req := require.New(t)
+
+	u1, u2 := uuid.New(), uuid.New()
+	src := labelled{
+		Labels: map[string]int{"b": 2, "a": 1, "c": 3},
+		Owners: map[string]uuid.UUID{"x": u1, "y": u2},
+	}
+	var msg labelledMsg
+	req.NoError(TypedCopy(&msg, &src))
+	req.Equal([]*labelEntry{{Key: "a", Value: 1}, {Key: "b", Value: 2}, {Key: "c", Value: 3}}, msg.Labels)
+	req.Equal([]ownerEntry{{Team: "x", Person: u1.String()}, {Team: "y", Person: u2.String()}}, msg.Owners)
+
+	var back labelled
+	req.NoError(TypedCopy(&back, &msg))
+	req.Equal(src, back)
+
+	back = labelled{}
+	req.NoError(TypedCopy(&back, &labelledMsg{Labels: []*labelEntry{{Key: "a", Value: 1}, nil, {Key: "a", Value: 2}}}))
+	req.Equal(map[string]int{"a": 2}, back.Labels)
+	req.Nil(back.Owners)
+
+	err := TypedCopy(&back, &labelledMsg{Owners: []ownerEntry{{Team: "x", Person: "bad"}}})
+	var cerr *CopyError
+	req.ErrorAs(err, &cerr)
+	req.Equal("Owners.Person", cerr.Path())
+}
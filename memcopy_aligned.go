@@ -0,0 +1,20 @@
+//go:build !(386 || amd64 || arm64 || ppc64 || ppc64le || s390x)
+
+package keyvalue
+
+import "unsafe"
+
+// memcopy copies size bytes. The fixed-size cases use byte arrays which have no alignment requirements
+// so that it's correct on architectures with strict alignment such as arm and wasm.
+func memcopy(dst, src unsafe.Pointer, size uintptr) {
+	switch size {
+	case 8:
+		*(*[8]byte)(dst) = *(*[8]byte)(src)
+	case 16:
+		*(*[16]byte)(dst) = *(*[16]byte)(src)
+	case 24:
+		*(*[24]byte)(dst) = *(*[24]byte)(src)
+	default:
+		copy(unsafe.Slice((*byte)(dst), size), unsafe.Slice((*byte)(src), size))
+	}
+}
@@ -0,0 +1,22 @@
+package keyvalue
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemcopyUnaligned(t *testing.T) {
+	req := require.New(t)
+
+	for _, size := range []uintptr{1, 8, 16, 24, 40} {
+		src := make([]byte, size+1)
+		for i := range src {
+			src[i] = byte(i + 1)
+		}
+		dst := make([]byte, size+3)
+		memcopy(unsafe.Pointer(&dst[3]), unsafe.Pointer(&src[1]), size)
+		req.Equal(src[1:], dst[3:], size)
+	}
+}
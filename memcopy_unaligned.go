@@ -0,0 +1,20 @@
+//go:build 386 || amd64 || arm64 || ppc64 || ppc64le || s390x
+
+package keyvalue
+
+import "unsafe"
+
+// memcopy copies size bytes. The architectures it's built for tolerate unaligned memory accesses
+// so the fixed-size cases are copied word by word.
+func memcopy(dst, src unsafe.Pointer, size uintptr) {
+	switch size {
+	case 8:
+		*(*uint64)(dst) = *(*uint64)(src)
+	case 16:
+		*(*[2]uint64)(dst) = *(*[2]uint64)(src)
+	case 24:
+		*(*[3]uint64)(dst) = *(*[3]uint64)(src)
+	default:
+		copy(unsafe.Slice((*byte)(dst), size), unsafe.Slice((*byte)(src), size))
+	}
+}
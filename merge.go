@@ -0,0 +1,122 @@
+package keyvalue
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/mailstepcz/maybe"
+	"github.com/mailstepcz/serr"
+	"github.com/mailstepcz/types"
+)
+
+var (
+	// ErrMergeConflict signifies that both merged objects have different non-empty values of a field.
+	ErrMergeConflict = errors.New("merge conflict")
+)
+
+// MergeStrategy determines which value is kept when both merged objects have a non-empty value of a field.
+type MergeStrategy int
+
+// merge strategies
+const (
+	// MergeSrcWins overwrites the destination value with the source value.
+	MergeSrcWins MergeStrategy = iota
+	// MergeDstWins keeps the destination value.
+	MergeDstWins
+	// MergeError makes the merge fail with [ErrMergeConflict] unless the values are equal.
+	MergeError
+)
+
+// Merge overlays the source object onto the destination object. Empty source values, i.e. nil pointers, slices, maps
+// and interfaces, empty maybe values and zero values, never overwrite destination values, while empty destination values
+// are always overwritten. Nested structures, including those referenced by pointers in both objects, are merged recursively.
+// Unexported fields and fields tagged with `kv:"-"` are left untouched.
+// Structures referenced by pointers in both objects are merged into new structures so that the structures they share
+// with other values aren't modified, while the pointers, slices and maps taken from the source are shared with it.
+// The destination object is left unchanged if the merge fails.
+func Merge[T any](dst, src *T, strategy MergeStrategy) error {
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		return ErrTypeNotStruct
+	}
+	if dst == nil || src == nil {
+		return ErrNilPointer
+	}
+	merged := *dst
+	if err := mergeStructs(reflect.ValueOf(&merged).Elem(), reflect.ValueOf(src).Elem(), strategy); err != nil {
+		return err
+	}
+	*dst = merged
+	return nil
+}
+
+func mergeStructs(dst, src reflect.Value, strategy MergeStrategy) error {
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || f.Tag.Get("kv") == "-" {
+			continue
+		}
+		if err := mergeValues(dst.Field(i), src.Field(i), strategy); err != nil {
+			return fieldError(err, f.Name, f.Type, f.Type)
+		}
+	}
+	return nil
+}
+
+func mergeValues(dst, src reflect.Value, strategy MergeStrategy) error {
+	if isEmpty(src) {
+		return nil
+	}
+	if isEmpty(dst) {
+		dst.Set(src)
+		return nil
+	}
+	switch {
+	case isMergeable(dst.Type()):
+		return mergeStructs(dst, src, strategy)
+	case dst.Kind() == reflect.Pointer && isMergeable(dst.Type().Elem()):
+		merged := reflect.New(dst.Type().Elem())
+		merged.Elem().Set(dst.Elem())
+		if err := mergeStructs(merged.Elem(), src.Elem(), strategy); err != nil {
+			return err
+		}
+		dst.Set(merged)
+		return nil
+	}
+	switch strategy {
+	case MergeSrcWins:
+		dst.Set(src)
+	case MergeError:
+		if !reflect.DeepEqual(dst.Interface(), src.Interface()) {
+			return serr.Wrap("", ErrMergeConflict, serr.Any("dst", dst.Interface()), serr.Any("src", src.Interface()))
+		}
+	}
+	return nil
+}
+
+// isEmpty checks whether the value is considered missing like by the nil policy of the copier.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		return v.IsNil()
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(types.Maybe) {
+		return v.Addr().Interface().(maybe.Iface).GetPtr() == nil
+	}
+	return v.IsZero()
+}
+
+// isMergeable checks whether the structure is merged field by field. Maybe values and structures without exported fields
+// such as [time.Time] are merged as a whole.
+func isMergeable(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || reflect.PointerTo(t).Implements(types.Maybe) {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath == "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package keyvalue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mailstepcz/maybe"
+	"github.com/mailstepcz/pointer"
+	"github.com/stretchr/testify/require"
+)
+
+type mergeAddress struct {
+	Street string
+	City   string
+}
+
+type mergeCustomer struct {
+	Name     string
+	Email    *string
+	Phone    maybe.Maybe[string]
+	Tags     []string
+	Created  time.Time
+	Address  mergeAddress
+	Billing  *mergeAddress
+	Internal string `kv:"-"`
+}
+
+func TestMerge(t *testing.T) {
+	req := require.New(t)
+
+	now := time.Now()
+	dst := mergeCustomer{
+		Name:    "John",
+		Address: mergeAddress{Street: "Main"},
+		Billing: &mergeAddress{City: "Prague"},
+	}
+	src := mergeCustomer{
+		Name:     "Johnny",
+		Email:    pointer.To("john@example.com"),
+		Phone:    maybe.Unit("123"),
+		Tags:     []string{"vip"},
+		Created:  now,
+		Address:  mergeAddress{Street: "Side", City: "Brno"},
+		Billing:  &mergeAddress{Street: "Long"},
+		Internal: "x",
+	}
+
+	merged := dst
+	merged.Billing = &mergeAddress{City: "Prague"}
+	req.NoError(Merge(&merged, &src, MergeSrcWins))
+	req.Equal("Johnny", merged.Name)
+	req.Equal("john@example.com", *merged.Email)
+	req.Equal(maybe.Unit("123"), merged.Phone)
+	req.Equal([]string{"vip"}, merged.Tags)
+	req.Equal(now, merged.Created)
+	req.Equal(mergeAddress{Street: "Side", City: "Brno"}, merged.Address)
+	req.Equal(&mergeAddress{Street: "Long", City: "Prague"}, merged.Billing)
+	req.Empty(merged.Internal)
+
+	merged = dst
+	merged.Billing = &mergeAddress{City: "Prague"}
+	req.NoError(Merge(&merged, &src, MergeDstWins))
+	req.Equal("John", merged.Name)
+	req.Equal("john@example.com", *merged.Email)
+	req.Equal(mergeAddress{Street: "Main", City: "Brno"}, merged.Address)
+
+	merged = dst
+	err := Merge(&merged, &src, MergeError)
+	req.ErrorIs(err, ErrMergeConflict)
+	var cerr *CopyError
+	req.ErrorAs(err, &cerr)
+	req.Equal([]string{"Name"}, cerr.FieldPath)
+
+	merged = mergeCustomer{Name: "Johnny", Address: mergeAddress{City: "Brno"}}
+	req.NoError(Merge(&merged, &src, MergeError))
+	req.Equal(mergeAddress{Street: "Side", City: "Brno"}, merged.Address)
+
+	merged = mergeCustomer{Address: mergeAddress{City: "Olomouc"}}
+	err = Merge(&merged, &src, MergeError)
+	req.ErrorIs(err, ErrMergeConflict)
+	req.ErrorAs(err, &cerr)
+	req.Equal([]string{"Address", "City"}, cerr.FieldPath)
+
+	billing := &mergeAddress{City: "Prague"}
+	merged = mergeCustomer{Billing: billing}
+	req.NoError(Merge(&merged, &src, MergeSrcWins))
+	req.Equal(&mergeAddress{Street: "Long", City: "Prague"}, merged.Billing)
+	req.Equal(&mergeAddress{City: "Prague"}, billing)
+
+	merged = mergeCustomer{Name: "John", Billing: billing, Address: mergeAddress{City: "Olomouc"}}
+	err = Merge(&merged, &mergeCustomer{Name: "John", Email: pointer.To("john@example.com"), Address: mergeAddress{City: "Brno"}}, MergeError)
+	req.ErrorIs(err, ErrMergeConflict)
+	req.Equal(mergeCustomer{Name: "John", Billing: billing, Address: mergeAddress{City: "Olomouc"}}, merged)
+	req.Same(billing, merged.Billing)
+
+	req.ErrorIs(Merge(nil, &src, MergeSrcWins), ErrNilPointer)
+	req.ErrorIs(Merge(pointer.To(1), pointer.To(2), MergeSrcWins), ErrTypeNotStruct)
+}
@@ -0,0 +1,117 @@
+package keyvalue
+
+import (
+	"reflect"
+
+	"github.com/mailstepcz/serr"
+)
+
+// mergePatchOptions are the options of the conversions of patched values.
+var mergePatchOptions = &CopierOptions{StrictNumeric: true}
+
+// ApplyMergePatch applies a JSON merge patch as described by RFC 7396 to the destination structure.
+// Keys are matched with fields like when copying from dynamic maps. Null values zero the fields,
+// missing keys leave the fields untouched and nested objects are merged into structures and dynamic maps.
+// Other values replace the fields and are converted by the same rules as copied values except that numbers
+// which the fields can't represent exactly, such as fractional numbers patched into integer fields,
+// are rejected with [ErrPrecisionLoss].
+func ApplyMergePatch(dst interface{}, patch map[string]interface{}) error {
+	v := reflect.ValueOf(dst)
+	if dst == nil || v.Kind() == reflect.Pointer && v.IsNil() {
+		return ErrNilPointer
+	}
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return serr.Wrap("", ErrTypeNotStruct, serr.String("dstType", v.Type().String()))
+	}
+	return mergeStruct(v.Elem(), patch)
+}
+
+// mergeStruct merges the patch into the structure.
+func mergeStruct(s reflect.Value, patch map[string]interface{}) error {
+	desc := describeType(s.Type())
+	for k, x := range patch {
+		idx, ok := desc.dynmapField(k)
+		if !ok {
+			return &CopyError{
+				DstType:   s.Type(),
+				SrcType:   dynmapType,
+				FieldPath: []string{k},
+				Err:       serr.Wrap("", ErrFieldNotFound, serr.String("key", k), serr.String("dstType", s.Type().Name())),
+			}
+		}
+		f, err := s.FieldByIndexErr(idx)
+		if err != nil {
+			return fieldError(err, k, nil, dynmapType)
+		}
+		if err := mergeValue(f, x); err != nil {
+			return fieldError(err, k, f.Type(), reflect.TypeOf(x))
+		}
+	}
+	return nil
+}
+
+// mergeValue merges the patch value into the addressable value.
+func mergeValue(v reflect.Value, x interface{}) error {
+	if x == nil {
+		v.SetZero()
+		return nil
+	}
+	switch x := x.(type) {
+	case map[string]interface{}:
+		switch {
+		case v.Type() == dynmapType:
+			m, _ := v.Interface().(map[string]interface{})
+			v.Set(reflect.ValueOf(mergeMap(m, x)))
+			return nil
+		case v.Kind() == reflect.Struct:
+			return mergeStruct(v, x)
+		case v.Kind() == reflect.Pointer && v.Type().Elem().Kind() == reflect.Struct:
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			return mergeStruct(v.Elem(), x)
+		}
+	case []interface{}:
+		if v.Kind() == reflect.Slice && v.Type().Elem() != reflect.TypeFor[interface{}]() {
+			s := reflect.MakeSlice(v.Type(), len(x), len(x))
+			for i, el := range x {
+				if err := mergeValue(s.Index(i), el); err != nil {
+					return err
+				}
+			}
+			v.Set(s)
+			return nil
+		}
+	}
+	xv := reflect.ValueOf(x)
+	if xv.Type().AssignableTo(v.Type()) {
+		v.Set(xv)
+		return nil
+	}
+	conv, err := valConvWithOptions(v.Type(), xv.Type(), mergePatchOptions)
+	if err != nil {
+		return err
+	}
+	tmp := reflect.New(xv.Type())
+	tmp.Elem().Set(xv)
+	return conv(v.Addr().UnsafePointer(), tmp.UnsafePointer())
+}
+
+// mergeMap merges the patch into the dynamic map. The map is updated in place unless it's nil.
+func mergeMap(m, patch map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		m = make(map[string]interface{}, len(patch))
+	}
+	for k, x := range patch {
+		switch x := x.(type) {
+		case nil:
+			delete(m, k)
+		case map[string]interface{}:
+			sub, _ := m[k].(map[string]interface{})
+			m[k] = mergeMap(sub, x)
+		default:
+			m[k] = x
+		}
+	}
+	return m
+}
@@ -0,0 +1,84 @@
+package keyvalue
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mailstepcz/maybe"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	req := require.New(t)
+
+	type address struct {
+		Street string `key:"street"`
+		City   string `key:"city"`
+	}
+	type person struct {
+		ID       uuid.UUID              `key:"id"`
+		Name     string                 `key:"name"`
+		Nickname maybe.Maybe[string]    `key:"nickname"`
+		Age      int                    `key:"age"`
+		Tags     []string               `key:"tags"`
+		Address  *address               `key:"address"`
+		Home     address                `key:"home"`
+		Extra    map[string]interface{} `key:"extra"`
+	}
+
+	u := uuid.New()
+	p := person{
+		Name:     "John",
+		Nickname: maybe.Unit("Johnny"),
+		Age:      40,
+		Tags:     []string{"a", "b"},
+		Home:     address{Street: "Home Street", City: "Home City"},
+		Extra:    map[string]interface{}{"a": 1.0, "b": map[string]interface{}{"c": 2.0, "d": 3.0}},
+	}
+
+	var patch map[string]interface{}
+	err := json.Unmarshal([]byte(`{
+		"id": "`+u.String()+`",
+		"nickname": null,
+		"age": 41,
+		"tags": ["c"],
+		"address": {"city": "New City"},
+		"home": {"street": "New Street"},
+		"extra": {"a": null, "b": {"c": null, "e": 4}}
+	}`), &patch)
+	req.NoError(err)
+
+	err = ApplyMergePatch(&p, patch)
+	req.NoError(err)
+	req.Equal(person{
+		ID:      u,
+		Name:    "John",
+		Age:     41,
+		Tags:    []string{"c"},
+		Address: &address{City: "New City"},
+		Home:    address{Street: "New Street", City: "Home City"},
+		Extra:   map[string]interface{}{"b": map[string]interface{}{"d": 3.0, "e": 4.0}},
+	}, p)
+
+	err = ApplyMergePatch(&p, map[string]interface{}{"address": map[string]interface{}{"zip": "12345"}})
+	req.ErrorIs(err, ErrFieldNotFound)
+	var ce *CopyError
+	req.ErrorAs(err, &ce)
+	req.Equal("address.zip", ce.Path())
+
+	err = ApplyMergePatch(&p, map[string]interface{}{"id": "not a uuid"})
+	req.Error(err)
+
+	err = ApplyMergePatch(&p, map[string]interface{}{"age": 41.5})
+	req.ErrorIs(err, ErrPrecisionLoss)
+	req.Equal(41, p.Age)
+
+	err = ApplyMergePatch(p, patch)
+	req.ErrorIs(err, ErrTypeNotStruct)
+
+	err = ApplyMergePatch(nil, patch)
+	req.ErrorIs(err, ErrNilPointer)
+	err = ApplyMergePatch((*person)(nil), patch)
+	req.ErrorIs(err, ErrNilPointer)
+}
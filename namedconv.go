@@ -0,0 +1,153 @@
+package keyvalue
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/mailstepcz/serr"
+)
+
+var (
+	errorType = reflect.TypeFor[error]()
+
+	namedConverters   = make(map[namedConverterKey]Converter)
+	namedConvertersMu sync.RWMutex
+)
+
+type namedConverterKey struct {
+	name     string
+	dst, src reflect.Type
+}
+
+// RegisterNamedConverter registers a converter which fields can select by name using the `copy` tag,
+// e.g. `copy:",conv=moneyCents"`. Converters for different pairs of types, such as both directions
+// of a conversion, can be registered under the same name.
+func RegisterNamedConverter[D, S any](name string, f func(S) (D, error)) {
+	registerNamedConverter(name, Converter{
+		DstType: reflect.TypeFor[D](),
+		SrcType: reflect.TypeFor[S](),
+		Func: func(x interface{}) (interface{}, error) {
+			return f(x.(S))
+		},
+	})
+}
+
+// RegisterNamedContextConverter registers a converter like [RegisterNamedConverter] which receives the context
+// passed on to the copier, see [CopyContext]. Copies without a context, such as those by adapters, pass on the background context.
+func RegisterNamedContextConverter[D, S any](name string, f func(context.Context, S) (D, error)) {
+	registerNamedConverter(name, Converter{
+		DstType: reflect.TypeFor[D](),
+		SrcType: reflect.TypeFor[S](),
+		Func: func(x interface{}) (interface{}, error) {
+			return f(context.Background(), x.(S))
+		},
+		ContextFunc: func(ctx context.Context, x interface{}) (interface{}, error) {
+			return f(ctx, x.(S))
+		},
+	})
+}
+
+// RegisterNamedConv registers a converter like [RegisterNamedConverter] for callers which don't know the types statically.
+// The function has to be of the form func(S) (D, error) or func(S) D, otherwise [ErrUnsupportedTypePair] is returned.
+func RegisterNamedConv(name string, f any) error {
+	v := reflect.ValueOf(f)
+	t := reflect.TypeOf(f)
+	if t == nil || t.Kind() != reflect.Func || v.IsNil() || t.NumIn() != 1 || t.IsVariadic() ||
+		(t.NumOut() != 1 && (t.NumOut() != 2 || t.Out(1) != errorType)) {
+		return serr.Wrap("", ErrUnsupportedTypePair, serr.String("converter", name), serr.String("type", fmt.Sprintf("%T", f)))
+	}
+	registerNamedConverter(name, Converter{
+		DstType: t.Out(0),
+		SrcType: t.In(0),
+		Func: func(x interface{}) (interface{}, error) {
+			arg := reflect.New(t.In(0)).Elem()
+			if x != nil {
+				arg.Set(reflect.ValueOf(x))
+			}
+			out := v.Call([]reflect.Value{arg})
+			if len(out) == 2 && !out[1].IsNil() {
+				return nil, out[1].Interface().(error)
+			}
+			return out[0].Interface(), nil
+		},
+	})
+	return nil
+}
+
+// registerNamedConverter stores the converter under the name for its pair of types.
+func registerNamedConverter(name string, c Converter) {
+	namedConvertersMu.Lock()
+	defer namedConvertersMu.Unlock()
+	namedConverters[namedConverterKey{name, c.DstType, c.SrcType}] = c
+}
+
+// LookupNamedConv returns the converter registered under the name for the pair of types.
+// It's safe for concurrent use with [RegisterNamedConverter] and [RegisterNamedConv].
+func LookupNamedConv(name string, dstType, srcType reflect.Type) (Converter, bool) {
+	namedConvertersMu.RLock()
+	defer namedConvertersMu.RUnlock()
+	c, ok := namedConverters[namedConverterKey{name, dstType, srcType}]
+	return c, ok
+}
+
+// tagConverterName returns the name of the converter selected by the `copy` tag of the field.
+func tagConverterName(f reflect.StructField) string {
+	name, _ := copyTagOption(f, "conv")
+	return name
+}
+
+// copyTagName returns the name given in the `copy` tag of the field, e.g. `copy:"CustomerID"`,
+// which is the name of the field of the other structure the field is copied from or to.
+func copyTagName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("copy"), ",")
+	return name
+}
+
+// copyTagOption returns the value of the option given in the `copy` tag of the field, e.g. `copy:",conv=name"`.
+// Options without a value such as `copy:",dedup"` have an empty value.
+func copyTagOption(f reflect.StructField, option string) (string, bool) {
+	tag, ok := f.Tag.Lookup("copy")
+	if !ok {
+		return "", false
+	}
+	parts := strings.Split(tag, ",")
+	for _, part := range parts[1:] {
+		if part == option {
+			return "", true
+		}
+		if value, ok := strings.CutPrefix(part, option+"="); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// fieldConverter returns the custom converter for the pair of fields given or named by the options
+// or selected by the `copy` tag of either field, the destination field taking precedence.
+func fieldConverter(dstField, srcField reflect.StructField, opts *CopierOptions, top bool) (Converter, bool, error) {
+	if c, ok := customConverter(srcField.Name, opts, top); ok {
+		return c, true, nil
+	}
+	var name string
+	if top && opts != nil {
+		name = opts.NamedConverters[srcField.Name]
+	}
+	if name == "" {
+		name = tagConverterName(dstField)
+	}
+	if name == "" {
+		name = tagConverterName(srcField)
+	}
+	if name == "" {
+		return Converter{}, false, nil
+	}
+	c, ok := LookupNamedConv(name, dstField.Type, srcField.Type)
+	if !ok {
+		return Converter{}, false, serr.Wrap("", ErrNotRegistered, serr.String("converter", name),
+			serr.String("dstType", dstField.Type.String()), serr.String("srcType", srcField.Type.String()))
+	}
+	return c, true, nil
+}
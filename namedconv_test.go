@@ -0,0 +1,125 @@
+package keyvalue
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+type namedConvInvoice struct {
+	Total decimal.Decimal
+	Fee   decimal.Decimal
+}
+
+type namedConvPayload struct {
+	Total int64 `copy:",conv=testMoneyCents"`
+	Fee   string
+}
+
+type namedConvUnknown struct {
+	Total int64 `copy:",conv=unknown"`
+	Fee   string
+}
+
+func TestNamedConverterTag(t *testing.T) {
+	req := require.New(t)
+
+	RegisterNamedConverter("testMoneyCents", func(x decimal.Decimal) (int64, error) {
+		return x.Shift(2).IntPart(), nil
+	})
+	RegisterNamedConverter("testMoneyCents", func(x int64) (decimal.Decimal, error) {
+		return decimal.New(x, -2), nil
+	})
+
+	var dst namedConvPayload
+	req.NoError(TypedCopy(&dst, &namedConvInvoice{Total: decimal.RequireFromString("12.34"), Fee: decimal.RequireFromString("0.5")}))
+	req.Equal(namedConvPayload{Total: 1234, Fee: "0.5"}, dst)
+
+	var unknown namedConvUnknown
+	err := TypedCopy(&unknown, &namedConvInvoice{})
+	req.ErrorIs(err, ErrNotRegistered)
+
+	var back namedConvInvoice
+	req.NoError(TypedCopy(&back, &dst))
+	req.Equal("12.34", back.Total.String())
+}
+
+type namedConvLabel struct {
+	Code int
+}
+
+type namedConvLabelDTO struct {
+	Code string `copy:",conv=testLabel"`
+}
+
+func TestRegisterNamedConv(t *testing.T) {
+	req := require.New(t)
+
+	req.NoError(RegisterNamedConv("testLabel", func(x int) string {
+		return "L" + strconv.Itoa(x)
+	}))
+	req.NoError(RegisterNamedConv("testHex", func(x int) (string, error) {
+		if x < 0 {
+			return "", errors.New("negative")
+		}
+		return strconv.FormatInt(int64(x), 16), nil
+	}))
+	req.ErrorIs(RegisterNamedConv("bad", func(x, y int) string { return "" }), ErrUnsupportedTypePair)
+	req.ErrorIs(RegisterNamedConv("bad", func(x int) (string, string) { return "", "" }), ErrUnsupportedTypePair)
+	req.ErrorIs(RegisterNamedConv("bad", 1), ErrUnsupportedTypePair)
+
+	c, ok := LookupNamedConv("testHex", reflect.TypeFor[string](), reflect.TypeFor[int]())
+	req.True(ok)
+	x, err := c.Func(255)
+	req.NoError(err)
+	req.Equal("ff", x)
+	_, err = c.Func(-1)
+	req.Error(err)
+	_, ok = LookupNamedConv("testHex", reflect.TypeFor[int](), reflect.TypeFor[string]())
+	req.False(ok)
+
+	var dst namedConvLabelDTO
+	req.NoError(TypedCopy(&dst, &namedConvLabel{Code: 7}))
+	req.Equal("L7", dst.Code)
+
+	dst = namedConvLabelDTO{}
+	req.NoError(CopyV1(&dst, &namedConvLabel{Code: 8}))
+	req.Equal("L8", dst.Code)
+
+	type plainDTO struct {
+		Code string
+	}
+	opts := &CopierOptions{NamedConverters: map[string]string{"Code": "testHex"}}
+	h, err := HandleForPair(reflect.TypeFor[plainDTO](), reflect.TypeFor[namedConvLabel](), opts)
+	req.NoError(err)
+	var plain plainDTO
+	req.NoError(h.Copy(&plain, &namedConvLabel{Code: 255}))
+	req.Equal("ff", plain.Code)
+	req.Error(h.Copy(&plain, &namedConvLabel{Code: -1}))
+
+	_, err = HandleForPair(reflect.TypeFor[plainDTO](), reflect.TypeFor[namedConvLabel](), &CopierOptions{NamedConverters: map[string]string{"Code": "unknown"}})
+	req.ErrorIs(err, ErrNotRegistered)
+}
+
+type namedConvAnyDTO struct {
+	Code interface{} `copy:",conv=testNil"`
+}
+
+func TestNamedConvBuiltins(t *testing.T) {
+	req := require.New(t)
+
+	_, ok := LookupNamedConv("", reflect.TypeFor[string](), reflect.TypeFor[uuid.UUID]())
+	req.False(ok)
+
+	req.NoError(RegisterNamedConv("testNil", func(x int) interface{} {
+		return nil
+	}))
+	dst := namedConvAnyDTO{Code: "old"}
+	req.NoError(CopyV1(&dst, &namedConvLabel{Code: 1}))
+	req.Nil(dst.Code)
+}
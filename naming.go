@@ -0,0 +1,65 @@
+package keyvalue
+
+import (
+	"reflect"
+	"strings"
+)
+
+// NamingStrategy maps a structure field to the name used for matching source fields with destination fields.
+// Fields mapped to an empty name aren't copied.
+type NamingStrategy func(reflect.StructField) string
+
+// GORMColumns is a naming strategy matching fields by their GORM column names.
+// The column name is taken from the `gorm:"column:..."` tag and defaults to the field name in snake case like in GORM.
+// Fields tagged with `gorm:"-"` aren't copied.
+func GORMColumns(f reflect.StructField) string {
+	tag := f.Tag.Get("gorm")
+	if tag == "-" {
+		return ""
+	}
+	for _, setting := range strings.Split(tag, ";") {
+		key, value, ok := strings.Cut(setting, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(key), "column") {
+			return strings.TrimSpace(value)
+		}
+	}
+	return snakeCase(f.Name)
+}
+
+// FoldedNames is a naming strategy matching fields by their names ignoring case, so that initialisms spelt
+// differently such as Id and ID or Uuid and UUID match, e.g. when copying protoc-generated structures with
+// the fields Id and CreatedAt into structures with the fields ID and CreatedAt. Fields with exactly the same names
+// are matched in preference to fields whose names differ only in case.
+func FoldedNames(f reflect.StructField) string {
+	return strings.ToLower(f.Name)
+}
+
+// KeyTags is a naming strategy matching fields by their dynamic map keys.
+// The key is the first one given by the `key` tag and defaults to the field name. Structure adapters using
+// the strategy also accept the alternative keys listed in the tag.
+func KeyTags(f reflect.StructField) string {
+	if key, _, _ := strings.Cut(f.Tag.Get("key"), ","); key != "" {
+		return key
+	}
+	return f.Name
+}
+
+// fieldsByName returns the exported fields of the structure keyed by the names given by the naming strategy.
+// Shallower fields take precedence over more deeply promoted ones.
+func fieldsByName(t reflect.Type, naming NamingStrategy) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for _, fd := range describeType(t).fields {
+		f := fd.StructField
+		if !f.IsExported() {
+			continue
+		}
+		name := naming(f)
+		if name == "" {
+			continue
+		}
+		if g, ok := fields[name]; !ok || len(f.Index) < len(g.Index) {
+			fields[name] = f
+		}
+	}
+	return fields
+}
@@ -0,0 +1,114 @@
+package keyvalue
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestGORMColumns(t *testing.T) {
+	req := require.New(t)
+
+	type model struct {
+		ID       uuid.UUID `gorm:"type:uuid;primaryKey"`
+		FullName string    `gorm:"column:name;not null"`
+		Secret   string    `gorm:"-"`
+	}
+	fields := reflect.VisibleFields(reflect.TypeFor[model]())
+	req.Equal("id", GORMColumns(fields[0]))
+	req.Equal("name", GORMColumns(fields[1]))
+	req.Equal("", GORMColumns(fields[2]))
+}
+
+func TestNamingStrategy(t *testing.T) {
+	req := require.New(t)
+
+	type userModel struct {
+		ID       uuid.UUID `gorm:"primaryKey"`
+		FullName string    `gorm:"column:name"`
+		EMail    string    `gorm:"column:email"`
+		Password string    `gorm:"-"`
+	}
+	type userDTO struct {
+		ID       string
+		Name     string
+		Email    string
+		Password string
+	}
+
+	c, err := HandleForPair(reflect.TypeFor[userDTO](), reflect.TypeFor[userModel](), &CopierOptions{
+		Naming:  GORMColumns,
+		NoCache: true,
+	})
+	req.NoError(err)
+
+	u := uuid.New()
+	var dst userDTO
+	err = c.Copy(&dst, &userModel{
+		ID:       u,
+		FullName: "John Doe",
+		EMail:    "john@example.com",
+		Password: "secret",
+	})
+	req.NoError(err)
+	req.Equal(userDTO{
+		ID:    u.String(),
+		Name:  "John Doe",
+		Email: "john@example.com",
+	}, dst)
+
+	type unmatched struct {
+		Name string
+	}
+	_, err = CopierForPairWithOptions(reflect.TypeFor[unmatched](), reflect.TypeFor[userModel](), &CopierOptions{
+		Naming:  GORMColumns,
+		NoCache: true,
+	})
+	req.ErrorIs(err, ErrFieldNotFound)
+}
+
+func TestFoldedNames(t *testing.T) {
+	req := require.New(t)
+
+	type orderMessage struct {
+		Id         string
+		CustomerId string
+		Uuid       string
+		CreatedAt  *timestamppb.Timestamp
+	}
+	type order struct {
+		ID         uuid.UUID
+		CustomerID string
+		UUID       uuid.UUID
+		CreatedAt  time.Time
+	}
+
+	u1, u2 := uuid.New(), uuid.New()
+	tm := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	var dst order
+	req.NoError(TypedCopy(&dst, &orderMessage{Id: u1.String(), CustomerId: "c1", Uuid: u2.String(), CreatedAt: timestamppb.New(tm)}, WithNaming(FoldedNames)))
+	req.Equal(u1, dst.ID)
+	req.Equal("c1", dst.CustomerID)
+	req.Equal(u2, dst.UUID)
+	req.True(tm.Equal(dst.CreatedAt))
+
+	var msg orderMessage
+	req.NoError(TypedCopy(&msg, &dst, WithNaming(FoldedNames)))
+	req.Equal(u1.String(), msg.Id)
+	req.Equal(u2.String(), msg.Uuid)
+
+	type ambiguous struct {
+		Id string
+		ID string
+	}
+	var amb ambiguous
+	req.NoError(TypedCopy(&amb, &order{ID: u1}, WithNaming(FoldedNames), WithOmitNotFound()))
+	req.Equal(ambiguous{ID: u1.String()}, amb)
+
+	_, err := CopierForPair(reflect.TypeFor[order](), reflect.TypeFor[orderMessage]())
+	req.ErrorIs(err, ErrFieldNotFound)
+}
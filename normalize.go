@@ -0,0 +1,123 @@
+package keyvalue
+
+import (
+	"encoding"
+	"reflect"
+	"sync"
+	"unsafe"
+
+	"github.com/mailstepcz/types"
+)
+
+// mapNormalizer returns a function normalizing values of the type stored in dynamic maps by copies of structures.
+// Values which marshal themselves as text, such as UUIDs, ULIDs, times and decimals, are converted into strings
+// like by [TypedCopy] so that the time layout and the decimal format apply, or by their MarshalText method if there's
+// no such conversion. Nested structures become nested dynamic maps, slices and arrays become slices of normalized
+// values and maps with string keys become dynamic maps. Nil pointers, slices and maps become nil.
+func mapNormalizer(t reflect.Type, opts *CopierOptions) (func(reflect.Value) (interface{}, error), error) {
+	switch {
+	case t.Kind() != reflect.Pointer && t.Kind() != reflect.String && t.Implements(textMarshalerType):
+		conv, err := valConvWithOptions(types.String, t, opts)
+		if err != nil {
+			return func(v reflect.Value) (interface{}, error) {
+				b, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+				if err != nil {
+					return nil, err
+				}
+				return string(b), nil
+			}, nil
+		}
+		return func(v reflect.Value) (interface{}, error) {
+			var s string
+			if err := conv(unsafe.Pointer(&s), addressable(v).Addr().UnsafePointer()); err != nil {
+				return nil, err
+			}
+			return s, nil
+		}, nil
+
+	case t.Kind() == reflect.Pointer:
+		norm, err := mapNormalizer(t.Elem(), opts)
+		if err != nil {
+			return nil, err
+		}
+		return func(v reflect.Value) (interface{}, error) {
+			if v.IsNil() {
+				return nil, nil
+			}
+			return norm(v.Elem())
+		}, nil
+
+	case isPBRecord(t):
+		conv := sync.OnceValues(func() (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+			return valConvWithOptions(dynmapType, t, opts)
+		})
+		return func(v reflect.Value) (interface{}, error) {
+			c, err := conv()
+			if err != nil {
+				return nil, err
+			}
+			m := make(map[string]interface{})
+			if err := c(unsafe.Pointer(&m), addressable(v).Addr().UnsafePointer()); err != nil {
+				return nil, err
+			}
+			return m, nil
+		}, nil
+
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		// byte slices are kept as they are
+
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		norm, err := mapNormalizer(t.Elem(), opts)
+		if err != nil {
+			return nil, err
+		}
+		return func(v reflect.Value) (interface{}, error) {
+			if v.Kind() == reflect.Slice && v.IsNil() {
+				return nil, nil
+			}
+			s := make([]interface{}, v.Len())
+			for i := range s {
+				x, err := norm(v.Index(i))
+				if err != nil {
+					return nil, elementError(err, i, nil, t.Elem())
+				}
+				s[i] = x
+			}
+			return s, nil
+		}, nil
+
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+		norm, err := mapNormalizer(t.Elem(), opts)
+		if err != nil {
+			return nil, err
+		}
+		return func(v reflect.Value) (interface{}, error) {
+			if v.IsNil() {
+				return nil, nil
+			}
+			m := make(map[string]interface{}, v.Len())
+			iter := v.MapRange()
+			for iter.Next() {
+				x, err := norm(iter.Value())
+				if err != nil {
+					return nil, fieldError(err, iter.Key().String(), nil, t.Elem())
+				}
+				m[iter.Key().String()] = x
+			}
+			return m, nil
+		}, nil
+	}
+	return func(v reflect.Value) (interface{}, error) {
+		return v.Interface(), nil
+	}, nil
+}
+
+// addressable returns the value if it's addressable and an addressable copy of it otherwise.
+func addressable(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v
+	}
+	p := reflect.New(v.Type()).Elem()
+	p.Set(v)
+	return p
+}
@@ -0,0 +1,135 @@
+package keyvalue
+
+import (
+	"errors"
+	"reflect"
+	"unsafe"
+
+	"github.com/mailstepcz/maybe"
+	"github.com/mailstepcz/types"
+)
+
+var (
+	// ErrInfiniteValue signifies an infinite or NaN database value which has no counterpart in the destination type.
+	ErrInfiniteValue = errors.New("infinite value")
+
+	nullableTypes = make(map[reflect.Type]nullableType)
+)
+
+// nullableType describes a nullable type such as a database type or a protobuf wrapper wrapping a value of a plain Go type.
+type nullableType struct {
+	valueType reflect.Type
+	// get returns the pointer to the wrapped value or nil if the value is NULL.
+	get func(unsafe.Pointer) (unsafe.Pointer, error)
+	// set sets the wrapped value or NULL if the pointer is nil.
+	set func(unsafe.Pointer, unsafe.Pointer)
+}
+
+// RegisterNullable registers a nullable type N, such as a database type, wrapping values of type T.
+// The get function returns a pointer to the wrapped value or nil if the value is NULL
+// and the set function sets the wrapped value or NULL if the pointer is nil.
+// Nullable values are converted from and to values, pointers and maybe values of types convertible from and to T,
+// and NULL values are handled by the nil policy. Nullable types should be registered during initialization.
+func RegisterNullable[N, T any](get func(*N) (*T, error), set func(*N, *T)) {
+	nullableTypes[reflect.TypeFor[N]()] = nullableType{
+		valueType: reflect.TypeFor[T](),
+		get: func(p unsafe.Pointer) (unsafe.Pointer, error) {
+			x, err := get((*N)(p))
+			return unsafe.Pointer(x), err
+		},
+		set: func(p, x unsafe.Pointer) {
+			set((*N)(p), (*T)(x))
+		},
+	}
+}
+
+// isNullable checks whether the type is a registered nullable type.
+func isNullable(t reflect.Type) bool {
+	_, ok := nullableTypes[t]
+	return ok
+}
+
+// fromNullableConv returns a converter of a nullable value. NULL values are handled by the nil policy.
+func fromNullableConv(dstType reflect.Type, nt nullableType, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	onNil := nilHandler(dstType, opts)
+	if dstPtrType := reflect.PointerTo(dstType); dstPtrType.Implements(types.Maybe) {
+		// zero values are valid values unlike when converting plain values into maybe values
+		maybeType := reflect.Zero(dstPtrType).Interface().(maybe.Iface).MaybeType()
+		conv, err := valConvWithOptions(maybeType, nt.valueType, opts)
+		if err != nil {
+			return nil, err
+		}
+		return func(dst, src unsafe.Pointer) error {
+			x, err := nt.get(src)
+			if err != nil {
+				return err
+			}
+			if x == nil {
+				return onNil(dst)
+			}
+			v := reflect.New(maybeType)
+			if err := conv(v.UnsafePointer(), x); err != nil {
+				return err
+			}
+			reflect.NewAt(dstType, dst).Interface().(maybe.Iface).SetPtr(v.UnsafePointer())
+			return nil
+		}, nil
+	}
+	conv, err := valConvWithOptions(dstType, nt.valueType, opts)
+	if err != nil {
+		return nil, err
+	}
+	return func(dst, src unsafe.Pointer) error {
+		x, err := nt.get(src)
+		if err != nil {
+			return err
+		}
+		if x == nil {
+			return onNil(dst)
+		}
+		return conv(dst, x)
+	}, nil
+}
+
+// toNullableConv returns a converter into a nullable value.
+// Nil pointers and empty maybe values are converted into NULL.
+func toNullableConv(nt nullableType, srcType reflect.Type, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	var (
+		elType reflect.Type
+		deref  func(unsafe.Pointer) unsafe.Pointer
+	)
+	switch {
+	case srcType.Kind() == reflect.Pointer:
+		elType = srcType.Elem()
+		deref = func(p unsafe.Pointer) unsafe.Pointer {
+			return *(*unsafe.Pointer)(p)
+		}
+	case reflect.PointerTo(srcType).Implements(types.Maybe):
+		elType = reflect.Zero(reflect.PointerTo(srcType)).Interface().(maybe.Iface).MaybeType()
+		deref = func(p unsafe.Pointer) unsafe.Pointer {
+			return reflect.NewAt(srcType, p).Interface().(maybe.Iface).GetPtr()
+		}
+	default:
+		elType = srcType
+		deref = func(p unsafe.Pointer) unsafe.Pointer {
+			return p
+		}
+	}
+	conv, err := valConvWithOptions(nt.valueType, elType, opts)
+	if err != nil {
+		return nil, err
+	}
+	return func(dst, src unsafe.Pointer) error {
+		p := deref(src)
+		if p == nil {
+			nt.set(dst, nil)
+			return nil
+		}
+		v := reflect.New(nt.valueType).UnsafePointer()
+		if err := conv(v, p); err != nil {
+			return err
+		}
+		nt.set(dst, v)
+		return nil
+	}, nil
+}
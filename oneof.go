@@ -0,0 +1,239 @@
+package keyvalue
+
+import (
+	"reflect"
+	"unsafe"
+
+	"github.com/mailstepcz/serr"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/runtime/protoimpl"
+)
+
+// oneofVariant is a variant of a oneof field, i.e. the field of a oneof wrapper.
+type oneofVariant struct {
+	wrapper reflect.Type
+	field   reflect.StructField
+}
+
+// oneofVariants returns the variants of the oneof field of the protobuf message structure.
+// The wrappers are obtained from the message info of generated messages or from the legacy XXX_OneofWrappers method.
+func oneofVariants(msgType reflect.Type, f reflect.StructField) []oneofVariant {
+	var wrappers []interface{}
+	if m, ok := reflect.PointerTo(msgType).MethodByName("XXX_OneofWrappers"); ok {
+		wrappers, _ = m.Func.Call([]reflect.Value{reflect.Zero(m.Type.In(0))})[0].Interface().([]interface{})
+	} else if m, ok := reflect.New(msgType).Interface().(protoreflect.ProtoMessage); ok {
+		if mi, ok := m.ProtoReflect().Type().(*protoimpl.MessageInfo); ok {
+			wrappers = mi.OneofWrappers
+		}
+	}
+	var variants []oneofVariant
+	for _, w := range wrappers {
+		wt := reflect.TypeOf(w)
+		if wt.Implements(f.Type) && wt.Kind() == reflect.Pointer && wt.Elem().Kind() == reflect.Struct && wt.Elem().NumField() == 1 {
+			variants = append(variants, oneofVariant{
+				wrapper: wt,
+				field:   wt.Elem().Field(0),
+			})
+		}
+	}
+	return variants
+}
+
+// isOneof checks whether the field is a oneof field of a protobuf message structure.
+func isOneof(f reflect.StructField) bool {
+	_, ok := f.Tag.Lookup("protobuf_oneof")
+	return ok && f.Type.Kind() == reflect.Interface
+}
+
+// discriminatorField looks up the string field tagged with `oneof:"name"` which names the active variant of the oneof.
+func discriminatorField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for _, f := range reflect.VisibleFields(t) {
+		if f.IsExported() && f.Tag.Get("oneof") == name && f.Type.Kind() == reflect.String {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// flattensOneof checks whether the oneof field is mapped to the variant fields of the other structure.
+// That's the case unless the other structure has a field of the same name which isn't a discriminator.
+func flattensOneof(f reflect.StructField, other reflect.Type) bool {
+	if !isOneof(f) {
+		return false
+	}
+	of, ok := other.FieldByName(f.Name)
+	return !ok || of.Tag.Get("oneof") != ""
+}
+
+// oneofFields returns the names of the source fields which are consumed by flattened oneofs and mustn't be copied on their own.
+func oneofFields(dstType, srcType reflect.Type) []string {
+	var names []string
+	for _, f := range reflect.VisibleFields(srcType) {
+		if flattensOneof(f, dstType) {
+			names = append(names, f.Name)
+		}
+	}
+	for _, f := range reflect.VisibleFields(dstType) {
+		if !flattensOneof(f, srcType) {
+			continue
+		}
+		for _, v := range oneofVariants(dstType, f) {
+			names = append(names, v.field.Name)
+		}
+		if d, ok := discriminatorField(srcType, f.Tag.Get("protobuf_oneof")); ok {
+			names = append(names, d.Name)
+		}
+	}
+	return names
+}
+
+// oneofToFields returns a converter of the oneof field of the source structure into the destination fields named after its variants.
+// The destination fields of the inactive variants are left untouched. If no variant is set, all the variant fields are handled by the nil policy.
+// The discriminator field of the destination structure, if any, is set to the name of the active variant.
+func oneofToFields(dstType, srcType reflect.Type, f reflect.StructField, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	type target struct {
+		offset  uintptr
+		dstType reflect.Type
+		srcType reflect.Type
+		conv    func(unsafe.Pointer, unsafe.Pointer) error
+		onNil   func(unsafe.Pointer) error
+		name    string
+	}
+	srcOffset, _ := fieldOffset(srcType, f.Index)
+	targets := make(map[reflect.Type]*target)
+	for _, v := range oneofVariants(srcType, f) {
+		df, ok := dstType.FieldByName(v.field.Name)
+		var dstOffset uintptr
+		if ok {
+			dstOffset, ok = fieldOffset(dstType, df.Index)
+		}
+		if !ok {
+			if opts != nil && opts.OmitNotFound {
+				continue
+			}
+			return nil, serr.Wrap("", ErrFieldNotFound, serr.String("srcField", v.field.Name), serr.String("srcType", v.wrapper.Elem().Name()))
+		}
+		conv, err := valConvWithOptions(df.Type, v.field.Type, opts)
+		if err != nil {
+			return nil, compileFieldError(err, v.field.Name, df.Type, v.field.Type)
+		}
+		targets[v.wrapper] = &target{
+			offset:  dstOffset,
+			dstType: df.Type,
+			srcType: v.field.Type,
+			conv:    conv,
+			onNil:   nilHandler(df.Type, opts),
+			name:    v.field.Name,
+		}
+	}
+	disc, hasDisc := discriminatorField(dstType, f.Tag.Get("protobuf_oneof"))
+	var discOffset uintptr
+	if hasDisc {
+		discOffset, hasDisc = fieldOffset(dstType, disc.Index)
+	}
+	return func(dst, src unsafe.Pointer) error {
+		x := reflect.NewAt(f.Type, unsafe.Add(src, srcOffset)).Elem()
+		var active *target
+		if !x.IsNil() {
+			active = targets[x.Elem().Type()]
+		}
+		if active != nil {
+			// the fields of the inactive variants are left alone
+			if err := active.conv(unsafe.Add(dst, active.offset), x.Elem().UnsafePointer()); err != nil {
+				return fieldError(err, active.name, active.dstType, active.srcType)
+			}
+		} else {
+			for _, t := range targets {
+				if err := t.onNil(unsafe.Add(dst, t.offset)); err != nil {
+					return fieldError(err, t.name, t.dstType, t.srcType)
+				}
+			}
+		}
+		if hasDisc {
+			name := ""
+			if active != nil {
+				name = active.name
+			}
+			reflect.NewAt(disc.Type, unsafe.Add(dst, discOffset)).Elem().SetString(name)
+		}
+		return nil
+	}, nil
+}
+
+// fieldsToOneof returns a converter populating the oneof field of the destination structure from the source fields named after its variants.
+// The variant is selected by the discriminator field of the source structure if there's one, otherwise the first variant
+// whose source field isn't zero is used. If no variant is selected, the oneof field is handled by the nil policy.
+func fieldsToOneof(dstType, srcType reflect.Type, f reflect.StructField, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	type source struct {
+		wrapper reflect.Type
+		offset  uintptr
+		typ     reflect.Type
+		conv    func(unsafe.Pointer, unsafe.Pointer) error
+		name    string
+	}
+	dstOffset, _ := fieldOffset(dstType, f.Index)
+	var sources []*source
+	for _, v := range oneofVariants(dstType, f) {
+		sf, ok := srcType.FieldByName(v.field.Name)
+		var srcOffset uintptr
+		if ok {
+			srcOffset, ok = fieldOffset(srcType, sf.Index)
+		}
+		if !ok {
+			if opts != nil && opts.OmitNotFound {
+				continue
+			}
+			return nil, serr.Wrap("", ErrFieldNotFound, serr.String("srcField", v.field.Name), serr.String("srcType", srcType.Name()))
+		}
+		conv, err := valConvWithOptions(v.field.Type, sf.Type, opts)
+		if err != nil {
+			return nil, compileFieldError(err, v.field.Name, v.field.Type, sf.Type)
+		}
+		sources = append(sources, &source{
+			wrapper: v.wrapper,
+			offset:  srcOffset,
+			typ:     sf.Type,
+			conv:    conv,
+			name:    v.field.Name,
+		})
+	}
+	disc, hasDisc := discriminatorField(srcType, f.Tag.Get("protobuf_oneof"))
+	var discOffset uintptr
+	if hasDisc {
+		discOffset, hasDisc = fieldOffset(srcType, disc.Index)
+	}
+	onNil := nilHandler(f.Type, opts)
+	return func(dst, src unsafe.Pointer) error {
+		var active *source
+		if hasDisc {
+			name := reflect.NewAt(disc.Type, unsafe.Add(src, discOffset)).Elem().String()
+			if name != "" {
+				for _, s := range sources {
+					if s.name == name {
+						active = s
+						break
+					}
+				}
+				if active == nil {
+					return serr.Wrap("", ErrFieldNotFound, serr.String("variant", name), serr.String("oneof", f.Name))
+				}
+			}
+		} else {
+			for _, s := range sources {
+				if !reflect.NewAt(s.typ, unsafe.Add(src, s.offset)).Elem().IsZero() {
+					active = s
+					break
+				}
+			}
+		}
+		if active == nil {
+			return onNil(unsafe.Add(dst, dstOffset))
+		}
+		w := reflect.New(active.wrapper.Elem())
+		if err := active.conv(w.UnsafePointer(), unsafe.Add(src, active.offset)); err != nil {
+			return fieldError(err, active.name, active.wrapper.Elem().Field(0).Type, active.typ)
+		}
+		reflect.NewAt(f.Type, unsafe.Add(dst, dstOffset)).Elem().Set(w)
+		return nil
+	}, nil
+}
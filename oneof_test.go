@@ -0,0 +1,206 @@
+package keyvalue
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/mailstepcz/maybe"
+	"github.com/mailstepcz/pointer"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type contact struct {
+	Name     string
+	Channel  string `oneof:"channel"`
+	Email    *string
+	Phone    maybe.Maybe[int]
+	Nickname maybe.Maybe[string]
+}
+
+type contactWithoutDiscriminator struct {
+	Name     string
+	Email    string
+	Phone    *int
+	Nickname *string
+}
+
+type jsonScalar struct {
+	Kind        string `oneof:"kind"`
+	NumberValue maybe.Maybe[float64]
+	StringValue *string
+	BoolValue   bool
+}
+
+type jsonScalarWithoutDiscriminator struct {
+	NumberValue *float64
+	StringValue string
+}
+
+func TestOneofToFields(t *testing.T) {
+	req := require.New(t)
+
+	c, err := TypedCopierForPair[contact, pbContact]()
+	req.NoError(err)
+
+	var dst contact
+	err = c(&dst, &pbContact{
+		Name:     "John",
+		Channel:  &pbContact_Phone{Phone: 123456},
+		Nickname: pointer.To("Johnny"),
+	})
+	req.NoError(err)
+	req.Equal(contact{
+		Name:     "John",
+		Channel:  "Phone",
+		Phone:    maybe.Unit(123456),
+		Nickname: maybe.Unit("Johnny"),
+	}, dst)
+
+	dst = contact{}
+	err = c(&dst, &pbContact{Name: "John"})
+	req.NoError(err)
+	req.Equal(contact{Name: "John"}, dst)
+}
+
+func TestOneofToFieldsNilPolicy(t *testing.T) {
+	req := require.New(t)
+
+	c, err := CopierForPairWithOptions(reflect.TypeFor[contact](), reflect.TypeFor[pbContact](), &CopierOptions{NilPolicy: NilError})
+	req.NoError(err)
+
+	email := "john@example.com"
+	dst := contact{Email: &email}
+	err = c(unsafe.Pointer(&dst), unsafe.Pointer(&pbContact{
+		Name:     "John",
+		Channel:  &pbContact_Phone{Phone: 123456},
+		Nickname: pointer.To("Johnny"),
+	}))
+	req.NoError(err)
+	req.Equal(contact{
+		Name:     "John",
+		Channel:  "Phone",
+		Email:    &email,
+		Phone:    maybe.Unit(123456),
+		Nickname: maybe.Unit("Johnny"),
+	}, dst)
+
+	err = c(unsafe.Pointer(&dst), unsafe.Pointer(&pbContact{Name: "John", Nickname: pointer.To("Johnny")}))
+	req.ErrorIs(err, ErrNilSource)
+}
+
+func TestFieldsToOneof(t *testing.T) {
+	req := require.New(t)
+
+	c, err := TypedCopierForPair[pbContact, contact]()
+	req.NoError(err)
+
+	var dst pbContact
+	err = c(&dst, &contact{
+		Name:     "John",
+		Channel:  "Email",
+		Email:    pointer.To("john@example.com"),
+		Phone:    maybe.Unit(123456),
+		Nickname: maybe.Unit("Johnny"),
+	})
+	req.NoError(err)
+	req.Equal(pbContact{
+		Name:     "John",
+		Channel:  &pbContact_Email{Email: "john@example.com"},
+		Nickname: pointer.To("Johnny"),
+	}, dst)
+
+	dst = pbContact{}
+	err = c(&dst, &contact{Name: "John", Channel: "Fax"})
+	req.ErrorIs(err, ErrFieldNotFound)
+
+	c2, err := TypedCopierForPair[pbContact, contactWithoutDiscriminator]()
+	req.NoError(err)
+
+	dst = pbContact{}
+	err = c2(&dst, &contactWithoutDiscriminator{Phone: pointer.To(42)})
+	req.NoError(err)
+	req.Equal(pbContact{Channel: &pbContact_Phone{Phone: 42}}, dst)
+
+	dst = pbContact{}
+	err = c2(&dst, &contactWithoutDiscriminator{})
+	req.NoError(err)
+	req.Nil(dst.Channel)
+}
+
+func TestOneofToFieldsGenerated(t *testing.T) {
+	req := require.New(t)
+
+	c, err := HandleForPair(reflect.TypeFor[jsonScalar](), reflect.TypeFor[structpb.Value](), &CopierOptions{OmitNotFound: true})
+	req.NoError(err)
+
+	var dst jsonScalar
+	err = c.Copy(&dst, structpb.NewStringValue("abc"))
+	req.NoError(err)
+	req.Equal(jsonScalar{
+		Kind:        "StringValue",
+		StringValue: pointer.To("abc"),
+	}, dst)
+
+	dst = jsonScalar{}
+	err = c.Copy(&dst, structpb.NewNumberValue(1.5))
+	req.NoError(err)
+	req.Equal(jsonScalar{
+		Kind:        "NumberValue",
+		NumberValue: maybe.Unit(1.5),
+	}, dst)
+
+	dst = jsonScalar{}
+	err = c.Copy(&dst, &structpb.Value{})
+	req.NoError(err)
+	req.Equal(jsonScalar{}, dst)
+}
+
+func TestFieldsToOneofGenerated(t *testing.T) {
+	req := require.New(t)
+
+	c, err := HandleForPair(reflect.TypeFor[structpb.Value](), reflect.TypeFor[jsonScalar](), &CopierOptions{OmitNotFound: true})
+	req.NoError(err)
+
+	var dst structpb.Value
+	err = c.Copy(&dst, &jsonScalar{
+		Kind:        "BoolValue",
+		NumberValue: maybe.Unit(1.5),
+		BoolValue:   true,
+	})
+	req.NoError(err)
+	req.Equal(&structpb.Value_BoolValue{BoolValue: true}, dst.Kind)
+
+	err = c.Copy(&dst, &jsonScalar{Kind: "DateValue"})
+	req.ErrorIs(err, ErrFieldNotFound)
+
+	c, err = HandleForPair(reflect.TypeFor[structpb.Value](), reflect.TypeFor[jsonScalarWithoutDiscriminator](), &CopierOptions{OmitNotFound: true})
+	req.NoError(err)
+
+	var dst2 structpb.Value
+	err = c.Copy(&dst2, &jsonScalarWithoutDiscriminator{StringValue: "abc"})
+	req.NoError(err)
+	req.Equal(&structpb.Value_StringValue{StringValue: "abc"}, dst2.Kind)
+
+	var dst3 structpb.Value
+	err = c.Copy(&dst3, &jsonScalarWithoutDiscriminator{})
+	req.NoError(err)
+	req.Nil(dst3.Kind)
+}
+
+func TestOneofMissingVariant(t *testing.T) {
+	req := require.New(t)
+
+	type partial struct {
+		Email string
+	}
+	_, err := CopierForPair(reflect.TypeFor[partial](), reflect.TypeFor[pbContact]())
+	req.ErrorIs(err, ErrFieldNotFound)
+
+	_, err = CopierForPairWithOptions(reflect.TypeFor[partial](), reflect.TypeFor[pbContact](), &CopierOptions{OmitNotFound: true, NoCache: true})
+	req.NoError(err)
+
+	_, err = CopierForPair(reflect.TypeFor[jsonScalar](), reflect.TypeFor[structpb.Value]())
+	req.ErrorIs(err, ErrFieldNotFound)
+}
@@ -0,0 +1,26 @@
+package keyvalue
+
+import (
+	"reflect"
+	"strings"
+)
+
+// OpenAPIOptions sets the options for copying between types generated by oapi-codegen and domain structures.
+// Fields are matched by [OpenAPINames] and nil pointers of optional fields zero the destination fields,
+// making empty maybe values of them. Other options can follow it to customise the copy.
+func OpenAPIOptions() CopierOption {
+	return func(o *CopierOptions) {
+		o.Naming = OpenAPINames
+		o.NilPolicy = NilZero
+	}
+}
+
+// OpenAPINames is a naming strategy matching fields by their case-insensitive names so that initialisms
+// such as Id and ID match. Fields tagged with `json:"-"` aren't copied. These include the AdditionalProperties maps
+// emitted by oapi-codegen, so additional properties aren't copied.
+func OpenAPINames(f reflect.StructField) string {
+	if f.Tag.Get("json") == "-" {
+		return ""
+	}
+	return strings.ToLower(f.Name)
+}
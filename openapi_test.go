@@ -0,0 +1,60 @@
+package keyvalue
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mailstepcz/maybe"
+	"github.com/mailstepcz/pointer"
+	"github.com/stretchr/testify/require"
+)
+
+type apiPet struct {
+	Sku                  string                 `json:"sku"`
+	Name                 string                 `json:"name"`
+	Tag                  *string                `json:"tag,omitempty"`
+	OwnerRef             *string                `json:"ownerRef,omitempty"`
+	AdditionalProperties map[string]interface{} `json:"-"`
+}
+
+type domainPet struct {
+	SKU      string
+	Name     string
+	Tag      maybe.Maybe[string]
+	OwnerREF maybe.Maybe[uuid.UUID]
+}
+
+func TestOpenAPI(t *testing.T) {
+	req := require.New(t)
+
+	opts := NewCopierOptions(OpenAPIOptions())
+	toDomain, err := HandleForPair(reflect.TypeFor[domainPet](), reflect.TypeFor[apiPet](), opts)
+	req.NoError(err)
+	toAPI, err := HandleForPair(reflect.TypeFor[apiPet](), reflect.TypeFor[domainPet](), opts)
+	req.NoError(err)
+
+	owner := uuid.New()
+	dst := domainPet{Tag: maybe.Unit("old")}
+	err = toDomain.Copy(&dst, &apiPet{
+		Sku:                  "PET-1",
+		Name:                 "Rex",
+		OwnerRef:             pointer.To(owner.String()),
+		AdditionalProperties: map[string]interface{}{"x": 1},
+	})
+	req.NoError(err)
+	req.Equal(domainPet{
+		SKU:      "PET-1",
+		Name:     "Rex",
+		OwnerREF: maybe.Unit(owner),
+	}, dst)
+
+	var api apiPet
+	err = toAPI.Copy(&api, &dst)
+	req.NoError(err)
+	req.Equal(apiPet{
+		Sku:      "PET-1",
+		Name:     "Rex",
+		OwnerRef: pointer.To(owner.String()),
+	}, api)
+}
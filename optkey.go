@@ -0,0 +1,243 @@
+package keyvalue
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// maxOptionsKeyDepth bounds the nesting of the option values taken into account by [optionsKey].
+const maxOptionsKeyDepth = 16
+
+var (
+	internedOptions = make(map[string]*internedEntry)
+	canonicalOpts   = make(map[*CopierOptions]string)
+	internMtx       sync.RWMutex
+)
+
+type internedEntry struct {
+	opts *CopierOptions
+	used int64
+}
+
+// canonicalOptions returns the options with the same contents which were already used by the copiers in the cache,
+// so that options created anew for every copy, e.g. by [NewCopierOptions], share the cached copiers and converters.
+// Options whose contents can't be compared are returned as a copy which isn't cached.
+// Functions and interfaces holding pointers, such as tracers, are compared by their identity.
+// Recorders aren't part of the options used by the cached copiers, see [recordingEntry].
+func canonicalOptions(opts *CopierOptions) *CopierOptions {
+	if opts == nil || opts.NoCache {
+		return opts
+	}
+	internMtx.RLock()
+	_, ok := canonicalOpts[opts]
+	internMtx.RUnlock()
+	if ok {
+		return opts
+	}
+	key, ok := optionsKey(opts)
+	if !ok {
+		o := *opts
+		o.NoCache = true
+		return &o
+	}
+	cacheMtx.RLock()
+	limit := cacheLimit
+	cacheMtx.RUnlock()
+	internMtx.Lock()
+	defer internMtx.Unlock()
+	if e, ok := internedOptions[key]; ok {
+		e.used = cacheTick.Add(1)
+		return e.opts
+	}
+	for limit > 0 && len(internedOptions) >= limit {
+		evictInterned()
+	}
+	o := *opts
+	internedOptions[key] = &internedEntry{opts: &o, used: cacheTick.Add(1)}
+	canonicalOpts[&o] = key
+	return &o
+}
+
+// evictInterned removes the least recently used options. The interned options must be locked.
+func evictInterned() {
+	var (
+		lruKey string
+		lru    *internedEntry
+	)
+	for k, e := range internedOptions {
+		if lru == nil || e.used < lru.used {
+			lruKey, lru = k, e
+		}
+	}
+	if lru != nil {
+		delete(internedOptions, lruKey)
+		delete(canonicalOpts, lru.opts)
+	}
+}
+
+// optionsKey returns a key identifying the contents of the options.
+func optionsKey(opts *CopierOptions) (string, bool) {
+	var b strings.Builder
+	if !writeOptionsKey(&b, reflect.ValueOf(opts).Elem(), 0) {
+		return "", false
+	}
+	return b.String(), true
+}
+
+var (
+	locationPtrType  = reflect.TypeFor[*time.Location]()
+	fieldMaskPtrType = reflect.TypeFor[*fieldmaskpb.FieldMask]()
+	reflectTypeType  = reflect.TypeFor[reflect.Type]()
+	// valuePointerTypes are the types of options referencing plain values which are compared by their contents.
+	valuePointerTypes = map[reflect.Type]bool{
+		reflect.TypeFor[*DecimalRounding](): true,
+		reflect.TypeFor[*DecimalFormat]():   true,
+	}
+)
+
+func writeOptionsKey(b *strings.Builder, v reflect.Value, depth int) bool {
+	if depth > maxOptionsKeyDepth {
+		return false
+	}
+	switch v.Type() {
+	case locationPtrType:
+		if !v.IsNil() && !writeLocationKey(b, v, depth) {
+			return false
+		}
+		b.WriteByte(';')
+		return true
+	case fieldMaskPtrType:
+		if !v.IsNil() {
+			b.WriteString(strconv.Quote(strings.Join((*fieldmaskpb.FieldMask)(v.UnsafePointer()).GetPaths(), ",")))
+		}
+		b.WriteByte(';')
+		return true
+	case reflectTypeType:
+		if !v.IsNil() {
+			fmt.Fprintf(b, "%p", v.Elem().UnsafePointer())
+		}
+		b.WriteByte(';')
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		b.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		b.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		b.WriteString(strconv.FormatFloat(v.Float(), 'g', -1, 64))
+	case reflect.Complex64, reflect.Complex128:
+		b.WriteString(strconv.FormatComplex(v.Complex(), 'g', -1, 128))
+	case reflect.String:
+		b.WriteString(strconv.Quote(v.String()))
+	case reflect.Func:
+		if v.IsNil() {
+			break
+		}
+		// The closure is identified by its address rather than by its code, which closures share.
+		if !v.CanAddr() {
+			c := reflect.New(v.Type()).Elem()
+			if !v.CanInterface() {
+				return false
+			}
+			c.Set(v)
+			v = c
+		}
+		fmt.Fprintf(b, "%p", *(*unsafe.Pointer)(v.Addr().UnsafePointer()))
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		if valuePointerTypes[v.Type()] && !v.IsNil() {
+			b.WriteByte('&')
+			return writeOptionsKey(b, v.Elem(), depth+1)
+		}
+		if !v.IsNil() {
+			fmt.Fprintf(b, "%p", v.UnsafePointer())
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			break
+		}
+		b.WriteString(v.Elem().Type().String())
+		b.WriteByte(':')
+		if v.Elem().Kind() == reflect.Map {
+			// Maps behind interfaces may hold state like recorders do.
+			fmt.Fprintf(b, "%p", v.Elem().UnsafePointer())
+		} else if !writeOptionsKey(b, v.Elem(), depth+1) {
+			return false
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			break
+		}
+		fallthrough
+	case reflect.Array:
+		b.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if !writeOptionsKey(b, v.Index(i), depth+1) {
+				return false
+			}
+		}
+		b.WriteByte(']')
+	case reflect.Map:
+		if v.IsNil() {
+			break
+		}
+		entries := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			var e strings.Builder
+			if !writeOptionsKey(&e, iter.Key(), depth+1) || !writeOptionsKey(&e, iter.Value(), depth+1) {
+				return false
+			}
+			entries = append(entries, e.String())
+		}
+		sort.Strings(entries)
+		b.WriteByte('{')
+		for _, e := range entries {
+			b.WriteString(e)
+		}
+		b.WriteByte('}')
+	case reflect.Struct:
+		b.WriteByte('{')
+		for i := 0; i < v.NumField(); i++ {
+			if !writeOptionsKey(b, v.Field(i), depth+1) {
+				return false
+			}
+		}
+		b.WriteByte('}')
+	default:
+		return false
+	}
+	b.WriteByte(';')
+	return true
+}
+
+// writeLocationKey writes the name, zones and transitions of the location so that distinct locations
+// with the same name, such as fixed zones, don't share copiers. Locations whose data can't be read are keyed by their identity.
+func writeLocationKey(b *strings.Builder, v reflect.Value, depth int) bool {
+	// the name of the local location loads its data
+	_ = (*time.Location)(v.UnsafePointer()).String()
+	loc := v.Elem()
+	fields := []reflect.Value{loc.FieldByName("name"), loc.FieldByName("zone"), loc.FieldByName("tx"), loc.FieldByName("extend")}
+	for _, f := range fields {
+		if !f.IsValid() {
+			fmt.Fprintf(b, "%p", v.UnsafePointer())
+			return true
+		}
+	}
+	for _, f := range fields {
+		if !writeOptionsKey(b, f, depth+1) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,39 @@
+module github.com/mailstepcz/keyvalue/pgtypes
+
+go 1.22.0
+
+require (
+	github.com/google/uuid v1.6.0
+	github.com/jackc/pgx/v5 v5.7.2
+	github.com/mailstepcz/keyvalue v0.0.0
+	github.com/mailstepcz/maybe v0.1.1
+	github.com/mailstepcz/pointer v0.1.1
+	github.com/mailstepcz/serr v0.1.3
+	github.com/shopspring/decimal v1.4.0
+	github.com/stretchr/testify v1.10.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/fealsamh/datastructures v0.1.12 // indirect
+	github.com/fealsamh/go-utils v0.1.41 // indirect
+	github.com/govalues/decimal v0.1.36 // indirect
+	github.com/mailstepcz/enums v0.1.2 // indirect
+	github.com/mailstepcz/must v0.1.0 // indirect
+	github.com/mailstepcz/slice v0.1.0 // indirect
+	github.com/mailstepcz/types v0.1.3 // indirect
+	github.com/mailstepcz/validate v0.1.0 // indirect
+	github.com/oklog/ulid/v2 v2.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/rickb777/date/v2 v2.1.6 // indirect
+	github.com/rickb777/period v1.0.8 // indirect
+	github.com/rickb777/plural v1.4.2 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250212204824-5a70512c5d8b // indirect
+	google.golang.org/grpc v1.70.0 // indirect
+	google.golang.org/protobuf v1.36.5 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/mailstepcz/keyvalue => ../
@@ -0,0 +1,179 @@
+// Package pgtypes registers the nullable types of pgx, such as pgtype.UUID, pgtype.Text and pgtype.Numeric,
+// with the copier so that they're converted from and to plain values, pointers and maybe values.
+// It's used for its side effects:
+//
+//	import _ "github.com/mailstepcz/keyvalue/pgtypes"
+package pgtypes
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mailstepcz/keyvalue"
+	"github.com/mailstepcz/serr"
+	"github.com/shopspring/decimal"
+)
+
+func init() {
+	keyvalue.RegisterNullable(func(x *pgtype.UUID) (*uuid.UUID, error) {
+		if !x.Valid {
+			return nil, nil
+		}
+		return (*uuid.UUID)(&x.Bytes), nil
+	}, func(x *pgtype.UUID, y *uuid.UUID) {
+		if y == nil {
+			*x = pgtype.UUID{}
+			return
+		}
+		*x = pgtype.UUID{Bytes: *y, Valid: true}
+	})
+	keyvalue.RegisterNullable(func(x *pgtype.Text) (*string, error) {
+		if !x.Valid {
+			return nil, nil
+		}
+		return &x.String, nil
+	}, func(x *pgtype.Text, y *string) {
+		if y == nil {
+			*x = pgtype.Text{}
+			return
+		}
+		*x = pgtype.Text{String: *y, Valid: true}
+	})
+	keyvalue.RegisterNullable(func(x *pgtype.Bool) (*bool, error) {
+		if !x.Valid {
+			return nil, nil
+		}
+		return &x.Bool, nil
+	}, func(x *pgtype.Bool, y *bool) {
+		if y == nil {
+			*x = pgtype.Bool{}
+			return
+		}
+		*x = pgtype.Bool{Bool: *y, Valid: true}
+	})
+	keyvalue.RegisterNullable(func(x *pgtype.Int2) (*int16, error) {
+		if !x.Valid {
+			return nil, nil
+		}
+		return &x.Int16, nil
+	}, func(x *pgtype.Int2, y *int16) {
+		if y == nil {
+			*x = pgtype.Int2{}
+			return
+		}
+		*x = pgtype.Int2{Int16: *y, Valid: true}
+	})
+	keyvalue.RegisterNullable(func(x *pgtype.Int4) (*int32, error) {
+		if !x.Valid {
+			return nil, nil
+		}
+		return &x.Int32, nil
+	}, func(x *pgtype.Int4, y *int32) {
+		if y == nil {
+			*x = pgtype.Int4{}
+			return
+		}
+		*x = pgtype.Int4{Int32: *y, Valid: true}
+	})
+	keyvalue.RegisterNullable(func(x *pgtype.Int8) (*int64, error) {
+		if !x.Valid {
+			return nil, nil
+		}
+		return &x.Int64, nil
+	}, func(x *pgtype.Int8, y *int64) {
+		if y == nil {
+			*x = pgtype.Int8{}
+			return
+		}
+		*x = pgtype.Int8{Int64: *y, Valid: true}
+	})
+	keyvalue.RegisterNullable(func(x *pgtype.Float4) (*float32, error) {
+		if !x.Valid {
+			return nil, nil
+		}
+		return &x.Float32, nil
+	}, func(x *pgtype.Float4, y *float32) {
+		if y == nil {
+			*x = pgtype.Float4{}
+			return
+		}
+		*x = pgtype.Float4{Float32: *y, Valid: true}
+	})
+	keyvalue.RegisterNullable(func(x *pgtype.Float8) (*float64, error) {
+		if !x.Valid {
+			return nil, nil
+		}
+		return &x.Float64, nil
+	}, func(x *pgtype.Float8, y *float64) {
+		if y == nil {
+			*x = pgtype.Float8{}
+			return
+		}
+		*x = pgtype.Float8{Float64: *y, Valid: true}
+	})
+	keyvalue.RegisterNullable(func(x *pgtype.Timestamptz) (*time.Time, error) {
+		if !x.Valid {
+			return nil, nil
+		}
+		if x.InfinityModifier != pgtype.Finite {
+			return nil, serr.Wrap("", keyvalue.ErrInfiniteValue, serr.String("value", x.InfinityModifier.String()))
+		}
+		return &x.Time, nil
+	}, func(x *pgtype.Timestamptz, y *time.Time) {
+		if y == nil {
+			*x = pgtype.Timestamptz{}
+			return
+		}
+		*x = pgtype.Timestamptz{Time: *y, Valid: true}
+	})
+	keyvalue.RegisterNullable(func(x *pgtype.Timestamp) (*time.Time, error) {
+		if !x.Valid {
+			return nil, nil
+		}
+		if x.InfinityModifier != pgtype.Finite {
+			return nil, serr.Wrap("", keyvalue.ErrInfiniteValue, serr.String("value", x.InfinityModifier.String()))
+		}
+		return &x.Time, nil
+	}, func(x *pgtype.Timestamp, y *time.Time) {
+		if y == nil {
+			*x = pgtype.Timestamp{}
+			return
+		}
+		*x = pgtype.Timestamp{Time: *y, Valid: true}
+	})
+	keyvalue.RegisterNullable(func(x *pgtype.Date) (*time.Time, error) {
+		if !x.Valid {
+			return nil, nil
+		}
+		if x.InfinityModifier != pgtype.Finite {
+			return nil, serr.Wrap("", keyvalue.ErrInfiniteValue, serr.String("value", x.InfinityModifier.String()))
+		}
+		return &x.Time, nil
+	}, func(x *pgtype.Date, y *time.Time) {
+		if y == nil {
+			*x = pgtype.Date{}
+			return
+		}
+		*x = pgtype.Date{Time: *y, Valid: true}
+	})
+	keyvalue.RegisterNullable(func(x *pgtype.Numeric) (*decimal.Decimal, error) {
+		if !x.Valid {
+			return nil, nil
+		}
+		if x.NaN || x.InfinityModifier != pgtype.Finite {
+			return nil, serr.Wrap("", keyvalue.ErrInfiniteValue, serr.Any("nan", x.NaN), serr.String("value", x.InfinityModifier.String()))
+		}
+		d := decimal.Zero
+		if x.Int != nil {
+			d = decimal.NewFromBigInt(x.Int, x.Exp)
+		}
+		return &d, nil
+	}, func(x *pgtype.Numeric, y *decimal.Decimal) {
+		if y == nil {
+			*x = pgtype.Numeric{}
+			return
+		}
+		*x = pgtype.Numeric{Int: y.Coefficient(), Exp: y.Exponent(), Valid: true}
+	})
+}
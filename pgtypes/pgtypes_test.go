@@ -0,0 +1,93 @@
+package pgtypes
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/mailstepcz/keyvalue"
+	"github.com/mailstepcz/maybe"
+	"github.com/mailstepcz/pointer"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+type pgRow struct {
+	ID        pgtype.UUID
+	Name      pgtype.Text
+	Nickname  pgtype.Text
+	Age       pgtype.Int4
+	Price     pgtype.Numeric
+	CreatedAt pgtype.Timestamptz
+	DeletedAt pgtype.Timestamptz
+}
+
+type pgDomain struct {
+	ID        uuid.UUID
+	Name      string
+	Nickname  *string
+	Age       maybe.Maybe[int]
+	Price     decimal.Decimal
+	CreatedAt time.Time
+	DeletedAt maybe.Maybe[time.Time]
+}
+
+func TestFromPgtype(t *testing.T) {
+	req := require.New(t)
+
+	c, err := keyvalue.TypedCopierForPair[pgDomain, pgRow]()
+	req.NoError(err)
+
+	u := uuid.New()
+	now := time.Now().UTC()
+	var dst pgDomain
+	err = c(&dst, &pgRow{
+		ID:        pgtype.UUID{Bytes: u, Valid: true},
+		Name:      pgtype.Text{String: "John", Valid: true},
+		Age:       pgtype.Int4{Int32: 42, Valid: true},
+		Price:     pgtype.Numeric{Int: big.NewInt(12345), Exp: -2, Valid: true},
+		CreatedAt: pgtype.Timestamptz{Time: now, Valid: true},
+	})
+	req.NoError(err)
+	req.Equal(u, dst.ID)
+	req.Equal("John", dst.Name)
+	req.Nil(dst.Nickname)
+	req.Equal(maybe.Unit(42), dst.Age)
+	req.Equal("123.45", dst.Price.String())
+	req.Equal(now, dst.CreatedAt)
+	req.False(dst.DeletedAt.Valid)
+
+	err = c(&dst, &pgRow{
+		CreatedAt: pgtype.Timestamptz{InfinityModifier: pgtype.Infinity, Valid: true},
+	})
+	req.ErrorIs(err, keyvalue.ErrInfiniteValue)
+}
+
+func TestToPgtype(t *testing.T) {
+	req := require.New(t)
+
+	c, err := keyvalue.TypedCopierForPair[pgRow, pgDomain]()
+	req.NoError(err)
+
+	u := uuid.New()
+	now := time.Now().UTC()
+	var dst pgRow
+	err = c(&dst, &pgDomain{
+		ID:        u,
+		Name:      "John",
+		Nickname:  pointer.To("Johnny"),
+		Price:     decimal.RequireFromString("123.45"),
+		CreatedAt: now,
+		DeletedAt: maybe.Unit(now),
+	})
+	req.NoError(err)
+	req.Equal(pgtype.UUID{Bytes: u, Valid: true}, dst.ID)
+	req.Equal(pgtype.Text{String: "John", Valid: true}, dst.Name)
+	req.Equal(pgtype.Text{String: "Johnny", Valid: true}, dst.Nickname)
+	req.Equal(pgtype.Int4{}, dst.Age)
+	req.Equal(pgtype.Numeric{Int: big.NewInt(12345), Exp: -2, Valid: true}, dst.Price)
+	req.Equal(pgtype.Timestamptz{Time: now, Valid: true}, dst.CreatedAt)
+	req.Equal(pgtype.Timestamptz{Time: now, Valid: true}, dst.DeletedAt)
+}
@@ -0,0 +1,60 @@
+package keyvalue
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/mailstepcz/serr"
+)
+
+// PairSpec declares a pair of types to compile a copier for with [CompileAll].
+type PairSpec struct {
+	Dst, Src reflect.Type
+	Options  *CopierOptions
+}
+
+// PairOf declares the pair of types with the options.
+func PairOf[D, S any](opts *CopierOptions) PairSpec {
+	return PairSpec{
+		Dst:     reflect.TypeFor[D](),
+		Src:     reflect.TypeFor[S](),
+		Options: opts,
+	}
+}
+
+// PairFailure is a pair of types for which no copier could be created.
+type PairFailure struct {
+	Dst, Src reflect.Type
+	Err      error
+}
+
+// CompileReport is the outcome of compiling copiers for many pairs of types.
+type CompileReport struct {
+	// Compiled is the number of pairs for which copiers were created.
+	Compiled int
+	// Failures are the pairs for which no copiers could be created in the order in which they were declared.
+	Failures []PairFailure
+}
+
+// CompileAll creates and caches copiers for all the pairs of types so that the whole mapping surface of a service
+// can be verified at once, e.g. at startup or in a test. Failures don't stop the compilation of the remaining pairs.
+// The error joins the errors of all the failures, each annotated with its pair of types, and is nil if there are none.
+func CompileAll(specs []PairSpec) (CompileReport, error) {
+	var (
+		report CompileReport
+		errs   []error
+	)
+	for _, spec := range specs {
+		if _, err := CopierForPairWithOptions(spec.Dst, spec.Src, spec.Options); err != nil {
+			report.Failures = append(report.Failures, PairFailure{
+				Dst: spec.Dst,
+				Src: spec.Src,
+				Err: err,
+			})
+			errs = append(errs, serr.Wrap("", err, serr.String("dst", spec.Dst.String()), serr.String("src", spec.Src.String())))
+			continue
+		}
+		report.Compiled++
+	}
+	return report, errors.Join(errs...)
+}
@@ -0,0 +1,52 @@
+package keyvalue
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type precompileUser struct {
+	ID   uuid.UUID
+	Name string
+}
+
+type precompileUserDTO struct {
+	ID   string
+	Name string
+}
+
+type precompileMissing struct {
+	ID    string
+	Email string
+}
+
+func TestCompileAll(t *testing.T) {
+	req := require.New(t)
+
+	report, err := CompileAll([]PairSpec{
+		PairOf[precompileUserDTO, precompileUser](nil),
+		PairOf[precompileUser, precompileUserDTO](nil),
+	})
+	req.NoError(err)
+	req.Equal(CompileReport{Compiled: 2}, report)
+	_, ok := cachedCopier(copierTypePair{dst: reflect.TypeFor[precompileUserDTO](), src: reflect.TypeFor[precompileUser]()})
+	req.True(ok)
+
+	report, err = CompileAll([]PairSpec{
+		PairOf[precompileUserDTO, precompileUser](nil),
+		PairOf[precompileUser, precompileMissing](nil),
+		PairOf[precompileUser, precompileMissing](&CopierOptions{OmitNotFound: true}),
+		PairOf[int, precompileUser](nil),
+	})
+	req.Error(err)
+	req.ErrorIs(err, ErrFieldNotFound)
+	req.ErrorIs(err, ErrTypeNotStruct)
+	req.Equal(2, report.Compiled)
+	req.Len(report.Failures, 2)
+	req.Equal(reflect.TypeFor[precompileMissing](), report.Failures[0].Src)
+	req.ErrorIs(report.Failures[0].Err, ErrFieldNotFound)
+	req.Equal(reflect.TypeFor[int](), report.Failures[1].Dst)
+}
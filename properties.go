@@ -0,0 +1,188 @@
+package keyvalue
+
+import (
+	"bufio"
+	"encoding"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/mailstepcz/serr"
+)
+
+var (
+	// ErrBadProperties signifies that a properties or INI source is malformed.
+	ErrBadProperties = errors.New("bad properties")
+)
+
+// PropertyNames is a naming strategy matching fields with properties.
+// The property name is taken from the `prop` tag and defaults to the field name. Names are lower-cased
+// since property names are matched ignoring case. Fields tagged with `prop:"-"` aren't copied.
+func PropertyNames(f reflect.StructField) string {
+	switch tag := f.Tag.Get("prop"); tag {
+	case "-":
+		return ""
+	case "":
+		return strings.ToLower(f.Name)
+	default:
+		return strings.ToLower(tag)
+	}
+}
+
+// PropertiesAdapter is a key-value adapter for flat properties such as those of .properties and INI files.
+// Dotted names, e.g. db.host or INI keys within sections, are enumerated as nested adapters which are copied
+// into nested structures. Names are enumerated lower-cased so they're matched by [PropertyNames].
+// Values are enumerated as strings which are parsed into numbers, booleans, durations, UUIDs and times
+// when set in structures. Structures set in properties are flattened into dotted names.
+type PropertiesAdapter struct {
+	props  map[string]string
+	prefix string
+}
+
+// NewPropertiesAdapter creates a new adapter for properties keyed by their dotted names.
+func NewPropertiesAdapter(props map[string]string) *PropertiesAdapter {
+	return &PropertiesAdapter{
+		props: props,
+	}
+}
+
+// EnumFields enumerates the properties and the nested groups of properties under the prefix of the adapter.
+func (a *PropertiesAdapter) EnumFields(fn func(string, Value) error) error {
+	seen := make(map[string]struct{})
+	for _, k := range sortedKeys(a.props) {
+		name := strings.ToLower(k)
+		if !strings.HasPrefix(name, a.prefix) {
+			continue
+		}
+		name = name[len(a.prefix):]
+		group, _, nested := strings.Cut(name, ".")
+		if !nested {
+			if err := fn(name, InterfaceValue{value: a.props[k]}); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, ok := seen[group]; ok {
+			continue
+		}
+		seen[group] = struct{}{}
+		sub := &PropertiesAdapter{
+			props:  a.props,
+			prefix: a.prefix + group + ".",
+		}
+		if err := fn(group, InterfaceValue{value: sub}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Set sets the value of a property. Structures and pointers to them are flattened unless they marshal
+// themselves as text. Nil values leave the properties unchanged.
+func (a *PropertiesAdapter) Set(name string, value interface{}) error {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	key := a.prefix + name
+	if _, ok := v.Interface().(encoding.TextMarshaler); !ok && v.Kind() == reflect.Struct {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		src, err := NewStructAdapter(ptr.Interface(), &NamingOption{Naming: PropertyNames})
+		if err != nil {
+			return err
+		}
+		sub := &PropertiesAdapter{
+			props:  a.props,
+			prefix: key + ".",
+		}
+		return src.EnumFields(func(name string, value Value) error {
+			return sub.Set(name, value.Interface())
+		})
+	}
+	b, err := headerBytes(v)
+	if err != nil {
+		return serr.Wrap("", err, serr.String("property", key))
+	}
+	a.props[key] = string(b)
+	return nil
+}
+
+// ParseProperties parses properties in the .properties or INI format. Properties are separated from their values by '=', ':'
+// or whitespace, lines starting with '#', '!' or ';' are comments and lines ending with a backslash continue on the next line.
+// Properties within INI sections are named section.key. Double-quoted values are unquoted.
+func ParseProperties(r io.Reader) (map[string]string, error) {
+	props := make(map[string]string)
+	sc := bufio.NewScanner(r)
+	var (
+		section string
+		pending string
+		lineNo  int
+	)
+	for sc.Scan() {
+		lineNo++
+		line := pending + strings.TrimSpace(sc.Text())
+		pending = ""
+		if n := len(line) - len(strings.TrimRight(line, `\`)); n%2 == 1 {
+			pending = line[:len(line)-1]
+			continue
+		}
+		if err := parsePropertyLine(props, &section, line); err != nil {
+			return nil, serr.Wrap("", err, serr.Int("line", lineNo))
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if err := parsePropertyLine(props, &section, pending); err != nil {
+		return nil, serr.Wrap("", err, serr.Int("line", lineNo))
+	}
+	return props, nil
+}
+
+// parsePropertyLine parses a logical line of properties, which is either empty, a comment, a section header or a property.
+func parsePropertyLine(props map[string]string, section *string, line string) error {
+	if line == "" || strings.ContainsRune("#!;", rune(line[0])) {
+		return nil
+	}
+	if line[0] == '[' {
+		if line[len(line)-1] != ']' {
+			return ErrBadProperties
+		}
+		*section = strings.TrimSpace(line[1 : len(line)-1])
+		if *section != "" {
+			*section += "."
+		}
+		return nil
+	}
+	i := strings.IndexAny(line, "=:")
+	if i < 0 {
+		i = strings.IndexAny(line, " \t")
+	}
+	key, value := line, ""
+	if i >= 0 {
+		key, value = strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+	}
+	if key == "" {
+		return ErrBadProperties
+	}
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		if s, err := strconv.Unquote(value); err == nil {
+			value = s
+		}
+	}
+	props[*section+key] = value
+	return nil
+}
+
+var (
+	_ Adapter = (*PropertiesAdapter)(nil)
+)
@@ -0,0 +1,108 @@
+package keyvalue
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type databaseConfig struct {
+	Host     string
+	Port     int
+	Timeout  time.Duration
+	ReadOnly bool `prop:"read_only"`
+}
+
+type appConfig struct {
+	Name     string
+	Debug    bool
+	Database databaseConfig `prop:"db"`
+	Cache    *databaseConfig
+}
+
+const legacyConfig = `
+; application settings
+name = "billing service"
+Debug: true
+
+[db]
+host = db.local
+port = 5432
+timeout = 5s
+read_only = \
+  true
+
+# cache
+[cache]
+host localhost
+`
+
+func TestParseProperties(t *testing.T) {
+	req := require.New(t)
+
+	props, err := ParseProperties(strings.NewReader(legacyConfig))
+	req.NoError(err)
+	req.Equal(map[string]string{
+		"name":         "billing service",
+		"Debug":        "true",
+		"db.host":      "db.local",
+		"db.port":      "5432",
+		"db.timeout":   "5s",
+		"db.read_only": "true",
+		"cache.host":   "localhost",
+	}, props)
+
+	_, err = ParseProperties(strings.NewReader("[db\nhost=x"))
+	req.ErrorIs(err, ErrBadProperties)
+}
+
+func TestPropertiesToStruct(t *testing.T) {
+	req := require.New(t)
+
+	props, err := ParseProperties(strings.NewReader(legacyConfig))
+	req.NoError(err)
+
+	var cfg appConfig
+	req.NoError(CopyV1(&cfg, NewPropertiesAdapter(props), &NamingOption{Naming: PropertyNames}))
+	req.Equal(appConfig{
+		Name:  "billing service",
+		Debug: true,
+		Database: databaseConfig{
+			Host:     "db.local",
+			Port:     5432,
+			Timeout:  5 * time.Second,
+			ReadOnly: true,
+		},
+		Cache: &databaseConfig{
+			Host: "localhost",
+		},
+	}, cfg)
+
+	props["db.port"] = "default"
+	req.Error(CopyV1(&cfg, NewPropertiesAdapter(props), &NamingOption{Naming: PropertyNames}))
+}
+
+func TestStructToProperties(t *testing.T) {
+	req := require.New(t)
+
+	cfg := appConfig{
+		Name: "billing",
+		Database: databaseConfig{
+			Host:    "db.local",
+			Port:    5432,
+			Timeout: time.Minute,
+		},
+	}
+	props := make(map[string]string)
+	req.NoError(CopyV1(NewPropertiesAdapter(props), &cfg, &NamingOption{Naming: PropertyNames}))
+	req.Equal(map[string]string{
+		"name":         "billing",
+		"debug":        "false",
+		"db.host":      "db.local",
+		"db.port":      "5432",
+		"db.timeout":   "1m0s",
+		"db.read_only": "false",
+	}, props)
+}
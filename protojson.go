@@ -0,0 +1,253 @@
+package keyvalue
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mailstepcz/serr"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	durationType        = reflect.TypeFor[time.Duration]()
+	jsonNull            = []byte("null")
+	protoJSONMarshaling = protojson.MarshalOptions{EmitUnpopulated: true, UseEnumNumbers: true}
+)
+
+// ProtoToStruct copies a protobuf message into a structure by way of its protojson encoding. It's a fallback
+// for messages which can't be copied directly. Message fields are matched with the structure fields named
+// by the `json` or `key` tags or, failing that, with the fields whose names equal theirs ignoring case and underscores.
+// Integers encoded as strings, enums and durations are decoded into numeric fields.
+func ProtoToStruct(dst interface{}, src proto.Message) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Type().Elem().Kind() != reflect.Struct {
+		return serr.Wrap("", ErrTypeNotStruct, serr.String("dstType", fmt.Sprintf("%T", dst)))
+	}
+	if v.IsNil() {
+		return ErrNilPointer
+	}
+	data, err := protoJSONMarshaling.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return decodeProtoJSON(data, v.Elem())
+}
+
+// StructToProto copies a structure into a protobuf message by way of its protojson encoding. It's a fallback
+// for messages which can't be copied directly. Structure fields are encoded under the names given by the `json`
+// or `key` tags or, failing that, under their names in snake case, which are matched with the protobuf field names.
+func StructToProto(dst proto.Message, src interface{}) error {
+	v := reflect.ValueOf(src)
+	if v.Kind() != reflect.Pointer || v.Type().Elem().Kind() != reflect.Struct {
+		return serr.Wrap("", ErrTypeNotStruct, serr.String("srcType", fmt.Sprintf("%T", src)))
+	}
+	if v.IsNil() {
+		return ErrNilPointer
+	}
+	data, err := json.Marshal(encodeProtoJSON(v.Elem()))
+	if err != nil {
+		return err
+	}
+	return protojson.Unmarshal(data, dst)
+}
+
+// protoJSONFields returns the fields of the structure taking part in the protojson bridge along with their keys.
+// Keys not given by tags are empty.
+func protoJSONFields(t reflect.Type) ([]reflect.StructField, []string) {
+	var (
+		fields []reflect.StructField
+		keys   []string
+	)
+	for _, f := range reflect.VisibleFields(t) {
+		if f.PkgPath != "" || f.Anonymous || f.Tag.Get("kv") == "-" {
+			continue
+		}
+		if _, ok := fieldOffset(t, f.Index); !ok {
+			continue
+		}
+		key, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if key == "-" {
+			continue
+		}
+		if key == "" {
+			key, _, _ = strings.Cut(f.Tag.Get("key"), ",")
+		}
+		fields = append(fields, f)
+		keys = append(keys, key)
+	}
+	return fields, keys
+}
+
+// normalizedKey folds the differences between protobuf field names, their JSON names and Go field names.
+func normalizedKey(s string) string {
+	return strings.ToLower(strings.ReplaceAll(s, "_", ""))
+}
+
+// hasCustomJSON checks whether values of the type encode themselves.
+func hasCustomJSON(t reflect.Type) bool {
+	pt := reflect.PointerTo(t)
+	return pt.Implements(jsonUnmarshalerType) || pt.Implements(reflect.TypeFor[encoding.TextUnmarshaler]()) ||
+		t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType)
+}
+
+// decodeProtoJSON decodes the protojson encoding of a value into the addressable value.
+func decodeProtoJSON(data []byte, v reflect.Value) error {
+	if bytes.Equal(data, jsonNull) {
+		v.SetZero()
+		return nil
+	}
+	t := v.Type()
+	switch {
+	case t == durationType && data[0] == '"':
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+
+	case data[0] == '"' && (isNumber(t) || t.Kind() == reflect.Bool):
+		s, err := strconv.Unquote(string(data))
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal([]byte(s), v.Addr().Interface())
+
+	case hasCustomJSON(t):
+		// left to encoding/json
+
+	case t.Kind() == reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		return decodeProtoJSON(data, v.Elem())
+
+	case t.Kind() == reflect.Struct:
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return err
+		}
+		normalized := make(map[string]json.RawMessage, len(obj))
+		for k, raw := range obj {
+			normalized[normalizedKey(k)] = raw
+		}
+		fields, keys := protoJSONFields(t)
+		for i, f := range fields {
+			raw, ok := obj[keys[i]]
+			if !ok {
+				key := keys[i]
+				if key == "" {
+					key = f.Name
+				}
+				raw, ok = normalized[normalizedKey(key)]
+			}
+			if !ok {
+				continue
+			}
+			if err := decodeProtoJSON(raw, v.FieldByIndex(f.Index)); err != nil {
+				return fieldError(err, f.Name, f.Type, nil)
+			}
+		}
+		return nil
+
+	case t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8:
+		var elems []json.RawMessage
+		if err := json.Unmarshal(data, &elems); err != nil {
+			return err
+		}
+		s := reflect.MakeSlice(t, len(elems), len(elems))
+		for i, raw := range elems {
+			if err := decodeProtoJSON(raw, s.Index(i)); err != nil {
+				return elementError(err, i, t.Elem(), nil)
+			}
+		}
+		v.Set(s)
+		return nil
+
+	case t.Kind() == reflect.Map:
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return err
+		}
+		m := reflect.MakeMapWithSize(t, len(obj))
+		for k, raw := range obj {
+			key := reflect.New(t.Key()).Elem()
+			if t.Key().Kind() == reflect.String {
+				key.SetString(k)
+			} else if err := decodeProtoJSON([]byte(strconv.Quote(k)), key); err != nil {
+				return err
+			}
+			el := reflect.New(t.Elem()).Elem()
+			if err := decodeProtoJSON(raw, el); err != nil {
+				return fieldError(err, k, t.Elem(), nil)
+			}
+			m.SetMapIndex(key, el)
+		}
+		v.Set(m)
+		return nil
+	}
+	return json.Unmarshal(data, v.Addr().Interface())
+}
+
+// encodeProtoJSON turns the value into one whose JSON encoding protojson can decode into a message.
+func encodeProtoJSON(v reflect.Value) interface{} {
+	t := v.Type()
+	switch {
+	case t == durationType:
+		return strconv.FormatFloat(time.Duration(v.Int()).Seconds(), 'f', -1, 64) + "s"
+
+	case hasCustomJSON(t):
+		// left to encoding/json
+
+	case t.Kind() == reflect.Pointer || t.Kind() == reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return encodeProtoJSON(v.Elem())
+
+	case t.Kind() == reflect.Struct:
+		fields, keys := protoJSONFields(t)
+		obj := make(map[string]interface{}, len(fields))
+		for i, f := range fields {
+			key := keys[i]
+			if key == "" {
+				key = snakeCase(f.Name)
+			}
+			obj[key] = encodeProtoJSON(v.FieldByIndex(f.Index))
+		}
+		return obj
+
+	case t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8:
+		if v.IsNil() {
+			return nil
+		}
+		elems := make([]interface{}, v.Len())
+		for i := range elems {
+			elems[i] = encodeProtoJSON(v.Index(i))
+		}
+		return elems
+
+	case t.Kind() == reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		obj := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			obj[fmt.Sprint(iter.Key().Interface())] = encodeProtoJSON(iter.Value())
+		}
+		return obj
+	}
+	return v.Interface()
+}
@@ -0,0 +1,110 @@
+package keyvalue
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/apipb"
+	"google.golang.org/protobuf/types/known/sourcecontextpb"
+	"google.golang.org/protobuf/types/known/typepb"
+)
+
+type bridgeMethod struct {
+	Name              string
+	RequestTypeURL    string `json:"requestTypeUrl"`
+	ResponseStreaming bool
+}
+
+type bridgeSource struct {
+	File string `key:"file_name"`
+}
+
+type bridgeAPI struct {
+	Name    string
+	Methods []bridgeMethod
+	Release string `key:"version"`
+	Syntax  int32
+	Source  *bridgeSource `json:"sourceContext"`
+	Ignored string        `json:"-"`
+}
+
+type bridgeNamePart struct {
+	NamePart    string
+	IsExtension bool
+}
+
+type bridgeOption struct {
+	Name             []bridgeNamePart
+	PositiveIntValue uint64
+	NegativeIntValue int64
+	DoubleValue      float64
+	StringValue      []byte
+}
+
+func TestProtoToStruct(t *testing.T) {
+	req := require.New(t)
+
+	api := &apipb.Api{
+		Name:          "orders",
+		Methods:       []*apipb.Method{{Name: "Get", RequestTypeUrl: "type/Get", ResponseStreaming: true}, {Name: "List"}},
+		Version:       "v1",
+		Syntax:        typepb.Syntax_SYNTAX_PROTO3,
+		SourceContext: &sourcecontextpb.SourceContext{FileName: "orders.proto"},
+	}
+	dst := bridgeAPI{Ignored: "kept", Source: &bridgeSource{File: "old"}}
+	req.NoError(ProtoToStruct(&dst, api))
+	req.Equal(bridgeAPI{
+		Name:    "orders",
+		Methods: []bridgeMethod{{Name: "Get", RequestTypeURL: "type/Get", ResponseStreaming: true}, {Name: "List"}},
+		Release: "v1",
+		Syntax:  1,
+		Source:  &bridgeSource{File: "orders.proto"},
+		Ignored: "kept",
+	}, dst)
+
+	back := &apipb.Api{}
+	req.NoError(StructToProto(back, &dst))
+	req.True(proto.Equal(api, back), "%v", back)
+
+	opt := &descriptorpb.UninterpretedOption{
+		Name:             []*descriptorpb.UninterpretedOption_NamePart{{NamePart: proto.String("x"), IsExtension: proto.Bool(true)}},
+		PositiveIntValue: proto.Uint64(1 << 60),
+		NegativeIntValue: proto.Int64(-1 << 60),
+		DoubleValue:      proto.Float64(1.5),
+		StringValue:      []byte("abc"),
+	}
+	var o bridgeOption
+	req.NoError(ProtoToStruct(&o, opt))
+	req.Equal(bridgeOption{
+		Name:             []bridgeNamePart{{NamePart: "x", IsExtension: true}},
+		PositiveIntValue: 1 << 60,
+		NegativeIntValue: -1 << 60,
+		DoubleValue:      1.5,
+		StringValue:      []byte("abc"),
+	}, o)
+
+	backOpt := &descriptorpb.UninterpretedOption{}
+	req.NoError(StructToProto(backOpt, &o))
+	req.True(proto.Equal(opt, backOpt), "%v", backOpt)
+
+	req.ErrorIs(ProtoToStruct(o, opt), ErrTypeNotStruct)
+	req.ErrorIs(StructToProto(backOpt, (*bridgeOption)(nil)), ErrNilPointer)
+}
+
+func TestProtoJSONDuration(t *testing.T) {
+	type timeouts struct {
+		Timeout time.Duration
+		Limits  map[int32]string
+	}
+
+	req := require.New(t)
+
+	var dst timeouts
+	req.NoError(decodeProtoJSON([]byte(`{"timeout":"1.500s","limits":{"1":"a"}}`), reflect.ValueOf(&dst).Elem()))
+	req.Equal(timeouts{Timeout: 1500 * time.Millisecond, Limits: map[int32]string{1: "a"}}, dst)
+	req.Equal(map[string]interface{}{"timeout": "1.5s", "limits": map[string]interface{}{"1": "a"}}, encodeProtoJSON(reflect.ValueOf(dst)))
+}
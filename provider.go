@@ -0,0 +1,119 @@
+package keyvalue
+
+import (
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+var (
+	conversionProviders   []ConversionProvider
+	conversionProvidersMu sync.RWMutex
+)
+
+// ConvFunc converts the value pointed to by src into the value pointed to by dst.
+type ConvFunc func(dst, src unsafe.Pointer) error
+
+// ConversionProvider provides conversions between pairs of types which the copier doesn't support on its own
+// or which it should convert differently, so that support for third-party types can live in separate packages.
+type ConversionProvider interface {
+	// Convert returns the conversion of values of the source type into values of the destination type.
+	// It returns false if the provider doesn't handle the pair of types.
+	Convert(dstType, srcType reflect.Type) (ConvFunc, bool)
+}
+
+// ConversionProviderFunc is a function acting as a [ConversionProvider].
+type ConversionProviderFunc func(dstType, srcType reflect.Type) (ConvFunc, bool)
+
+// Convert calls the function.
+func (f ConversionProviderFunc) Convert(dstType, srcType reflect.Type) (ConvFunc, bool) {
+	return f(dstType, srcType)
+}
+
+// RegisterConversionProvider registers a provider which is consulted for each pair of field types
+// before the built-in conversions. Providers are consulted in the order of registration.
+// Conversions are cached along with the copiers, so providers should be registered during initialization.
+func RegisterConversionProvider(p ConversionProvider) {
+	conversionProvidersMu.Lock()
+	defer conversionProvidersMu.Unlock()
+	conversionProviders = append(conversionProviders, p)
+}
+
+// providedConv returns the conversion of the pair of types by the first registered provider handling it.
+func providedConv(dstType, srcType reflect.Type) (ConvFunc, bool) {
+	conversionProvidersMu.RLock()
+	providers := conversionProviders
+	conversionProvidersMu.RUnlock()
+	for _, p := range providers {
+		if conv, ok := p.Convert(dstType, srcType); ok {
+			return conv, true
+		}
+	}
+	return nil, false
+}
+
+// typeConverter returns the custom conversion of the pair of types given by the options.
+func typeConverter(dstType, srcType reflect.Type, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, bool, error) {
+	if opts == nil {
+		return nil, false, nil
+	}
+	f, ok := opts.TypeConverters[TypePair{srcType, dstType}]
+	if !ok {
+		return nil, false, nil
+	}
+	conv, err := converterConv(dstType, srcType, Converter{DstType: dstType, SrcType: srcType, Func: f})
+	return conv, true, err
+}
+
+// convertsNested checks whether values of the type, or values nested in them, are converted by type converters
+// into values of the same type, so that values of the type can't be copied as they are.
+func convertsNested(t reflect.Type, opts *CopierOptions) bool {
+	if opts == nil {
+		return false
+	}
+	if _, ok := opts.TypeConverters[TypePair{t, t}]; ok {
+		return true
+	}
+	return convertsElements(t, opts)
+}
+
+// convertsElements checks whether values nested in values of the type, such as elements and fields, are converted
+// by type converters into values of the same type, so that values of the type can't be converted as a whole.
+// Only the exported fields of structures are taken into account since the others aren't copied field by field.
+func convertsElements(t reflect.Type, opts *CopierOptions) bool {
+	if opts == nil {
+		return false
+	}
+	for pair := range opts.TypeConverters {
+		if pair.Type1 == pair.Type2 {
+			return convertsElementTypes(t, opts, make(map[reflect.Type]bool))
+		}
+	}
+	return false
+}
+
+func convertsElementTypes(t reflect.Type, opts *CopierOptions, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	var nested []reflect.Type
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		nested = append(nested, t.Elem())
+	case reflect.Map:
+		nested = append(nested, t.Key(), t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.IsExported() {
+				nested = append(nested, f.Type)
+			}
+		}
+	}
+	for _, n := range nested {
+		if _, ok := opts.TypeConverters[TypePair{n, n}]; ok || convertsElementTypes(n, opts, seen) {
+			return true
+		}
+	}
+	return false
+}
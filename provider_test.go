@@ -0,0 +1,125 @@
+package keyvalue
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+type providerCents struct {
+	Amount int64
+}
+
+type providerPrice struct {
+	Price providerCents
+}
+
+type providerPriceDTO struct {
+	Price string
+}
+
+func init() {
+	RegisterConversionProvider(ConversionProviderFunc(func(dstType, srcType reflect.Type) (ConvFunc, bool) {
+		if dstType.Kind() != reflect.String || srcType != reflect.TypeFor[providerCents]() {
+			return nil, false
+		}
+		return func(dst, src unsafe.Pointer) error {
+			c := (*providerCents)(src)
+			*(*string)(dst) = fmt.Sprintf("%d.%02d", c.Amount/100, c.Amount%100)
+			return nil
+		}, true
+	}))
+}
+
+func TestConversionProvider(t *testing.T) {
+	req := require.New(t)
+
+	var dst providerPriceDTO
+	req.NoError(TypedCopy(&dst, &providerPrice{Price: providerCents{Amount: 1234}}))
+	req.Equal("12.34", dst.Price)
+
+	_, err := CopierForPair(reflect.TypeFor[providerPrice](), reflect.TypeFor[providerPriceDTO]())
+	req.Error(err)
+}
+
+func TestTypeConverters(t *testing.T) {
+	req := require.New(t)
+
+	type money struct {
+		Cents int64
+	}
+	type line struct {
+		Price money
+	}
+	type order struct {
+		Total  money
+		Lines  []line
+		Prices []money
+	}
+	type lineDTO struct {
+		Price string
+	}
+	type orderDTO struct {
+		Total  string
+		Lines  []lineDTO
+		Prices []string
+	}
+
+	src := order{
+		Total:  money{Cents: 1234},
+		Lines:  []line{{Price: money{Cents: 5}}},
+		Prices: []money{{Cents: 100}},
+	}
+	format := WithTypeConverter(func(m money) (string, error) {
+		return fmt.Sprintf("%d.%02d", m.Cents/100, m.Cents%100), nil
+	})
+	var dst orderDTO
+	req.NoError(TypedCopy(&dst, &src, format))
+	req.Equal(orderDTO{
+		Total:  "12.34",
+		Lines:  []lineDTO{{Price: "0.05"}},
+		Prices: []string{"1.00"},
+	}, dst)
+
+	_, err := CopierForPair(reflect.TypeFor[orderDTO](), reflect.TypeFor[order]())
+	req.Error(err)
+
+	err = TypedCopy(&dst, &src, WithTypeConverter(func(money) (string, error) {
+		return "", ErrBadType
+	}))
+	req.ErrorIs(err, ErrBadType)
+	var ce *CopyError
+	req.ErrorAs(err, &ce)
+	req.Equal("Total", ce.Path())
+
+	type myInt32 int32
+	type pair struct {
+		X, Y int32
+	}
+	type counts struct {
+		A   int32
+		B   int32
+		All []int32
+		Raw []int32
+		P   pair
+	}
+	type countsDTO struct {
+		A   myInt32
+		B   int32
+		All []myInt32
+		Raw []int32
+		P   pair
+	}
+	double := WithTypeConverter(func(x int32) (myInt32, error) {
+		return myInt32(2 * x), nil
+	})
+	increment := WithTypeConverter(func(x int32) (int32, error) {
+		return x + 1, nil
+	})
+	var cdst countsDTO
+	req.NoError(TypedCopy(&cdst, &counts{A: 10, B: 20, All: []int32{1, 2}, Raw: []int32{3}, P: pair{5, 6}}, double, increment))
+	req.Equal(countsDTO{A: 20, B: 21, All: []myInt32{2, 4}, Raw: []int32{4}, P: pair{6, 7}}, cdst)
+}
@@ -0,0 +1,45 @@
+package keyvalue
+
+import (
+	"reflect"
+	"slices"
+)
+
+// Reset zeroes the fields of the destination object which a copier with the options would copy, i.e. exported fields
+// not tagged with `kv:"-"` and selected by FieldsToCopy and FieldsToOmit. Other fields are left untouched.
+// Fields promoted through embedded pointers are only zeroed if the options set EmbeddedPointers, in which case
+// they're zeroed in the referenced structures while nil embedded pointers are left nil rather than allocated.
+// It's meant for reusing pooled destination objects. The destination object has to be a pointer to a structure.
+func Reset(dst interface{}, opts *CopierOptions) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Type().Elem().Kind() != reflect.Struct {
+		return ErrTypeNotStruct
+	}
+	if v.IsNil() {
+		return ErrNilPointer
+	}
+	v = v.Elem()
+	for _, f := range reflect.VisibleFields(v.Type()) {
+		if f.PkgPath != "" || f.Anonymous || f.Tag.Get("kv") == "-" {
+			continue
+		}
+		if (opts == nil || !opts.EmbeddedPointers) && viaEmbeddedPointer(v.Type(), f.Index) {
+			continue
+		}
+		if opts != nil {
+			if slices.Contains(opts.FieldsToOmit, f.Name) {
+				continue
+			}
+			if opts.FieldsToCopy != nil && !slices.Contains(opts.FieldsToCopy, f.Name) {
+				continue
+			}
+		}
+		fv, err := v.FieldByIndexErr(f.Index)
+		if err != nil {
+			// the field is promoted through a nil embedded pointer and is zero anyway
+			continue
+		}
+		fv.SetZero()
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package keyvalue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type ResetBase struct {
+	Created string
+}
+
+type resetTarget struct {
+	ResetBase
+	*resetExtra
+	Name    string
+	Tags    []string
+	Version int `kv:"-"`
+	Owner   string
+	secret  string
+}
+
+type resetExtra struct {
+	Extra string
+}
+
+func TestReset(t *testing.T) {
+	req := require.New(t)
+
+	obj := resetTarget{
+		ResetBase: ResetBase{Created: "today"},
+		Name:      "x",
+		Tags:      []string{"a"},
+		Version:   3,
+		Owner:     "me",
+		secret:    "s",
+	}
+	req.NoError(Reset(&obj, &CopierOptions{FieldsToOmit: []string{"Owner"}}))
+	req.Equal(resetTarget{Version: 3, Owner: "me", secret: "s"}, obj)
+
+	obj = resetTarget{Name: "x", Owner: "me", resetExtra: &resetExtra{Extra: "e"}}
+	req.NoError(Reset(&obj, &CopierOptions{FieldsToCopy: []string{"Name"}}))
+	req.Equal(resetTarget{Owner: "me", resetExtra: &resetExtra{Extra: "e"}}, obj)
+
+	req.NoError(Reset(&obj, nil))
+	req.Equal("", obj.Owner)
+	req.Equal("e", obj.Extra)
+
+	req.NoError(Reset(&obj, &CopierOptions{EmbeddedPointers: true}))
+	req.Equal("", obj.Extra)
+
+	obj = resetTarget{Name: "x"}
+	req.NoError(Reset(&obj, &CopierOptions{EmbeddedPointers: true}))
+	req.Nil(obj.resetExtra)
+
+	req.ErrorIs(Reset(obj, nil), ErrTypeNotStruct)
+	req.ErrorIs(Reset((*resetTarget)(nil), nil), ErrNilPointer)
+}
@@ -0,0 +1,178 @@
+package keyvalue
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/mailstepcz/serr"
+)
+
+// IrreversibleField is a destination field of a copier which its reverse copier doesn't copy back.
+type IrreversibleField struct {
+	// Field is the name of the destination field, or the dotted path of a field nested through a rename.
+	Field string
+	// Err is the reason why the field can't be copied back.
+	Err error
+}
+
+// ReverseCopier creates a handle copying the destination type of the handle back into its source type.
+// The options of the handle are inverted: renames are reversed, named converters are looked up for the reversed pair of types
+// and field selections are mapped onto the destination fields. Fields copied by custom converters, splitters, computations
+// and constants, fields renamed into nested structures and fields whose values can't be converted back are reported
+// as irreversible and omitted from the reverse copier. Conditions aren't inverted, so the fields are always copied back.
+func (h *Handle) ReverseCopier() (*Handle, []IrreversibleField, error) {
+	opts, irreversible := reverseOptions(h.dstType, h.srcType, h.opts)
+	for {
+		rev, err := HandleForPair(h.srcType, h.dstType, opts)
+		if err == nil {
+			return rev, irreversible, nil
+		}
+		var ce *CopyError
+		if !errors.As(err, &ce) || len(ce.FieldPath) == 0 || slices.Contains(opts.FieldsToOmit, ce.FieldPath[0]) {
+			return nil, irreversible, err
+		}
+		opts.FieldsToOmit = append(opts.FieldsToOmit, ce.FieldPath[0])
+		irreversible = append(irreversible, IrreversibleField{Field: ce.FieldPath[0], Err: err})
+	}
+}
+
+// reverseOptions inverts the options of a copier so that the destination type is copied back into the source type.
+// The reversed options are never cached since they're created anew for each reverse copier.
+func reverseOptions(dstType, srcType reflect.Type, opts *CopierOptions) (*CopierOptions, []IrreversibleField) {
+	rev := &CopierOptions{NoCache: true}
+	if opts == nil {
+		return rev, nil
+	}
+	*rev = *opts
+	rev.NoCache = true
+	rev.FieldRenames = nil
+	rev.Converters = nil
+	rev.NamedConverters = nil
+	rev.Slices = nil
+	rev.StringTransforms = nil
+	rev.Splitters = nil
+	rev.Conditions = nil
+	rev.Computed = nil
+	rev.Constants = nil
+	rev.Unions = nil
+	rev.DecimalFormats = nil
+	rev.FieldMask = nil
+	rev.FieldsToCopy = nil
+	rev.FieldsToOmit = nil
+
+	var irreversible []IrreversibleField
+	omit := func(field string, err error) {
+		rev.FieldsToOmit = append(rev.FieldsToOmit, field)
+		irreversible = append(irreversible, IrreversibleField{Field: field, Err: err})
+	}
+	noInverse := func(field, reason string) error {
+		return serr.Wrap("", ErrNoInverse, serr.String("field", field), serr.String("reason", reason))
+	}
+
+	var dstFields map[string]reflect.StructField
+	if opts.Naming != nil {
+		dstFields = fieldsByName(dstType, opts.Naming)
+	}
+	// target returns the destination field the source field is copied to
+	target := func(name string) (string, bool) {
+		if rename, ok := opts.FieldRenames[name]; ok {
+			return rename, !strings.Contains(rename, ".")
+		}
+		if f, ok := srcType.FieldByName(name); ok && copyTagName(f) != "" {
+			rename := copyTagName(f)
+			return rename, !strings.Contains(rename, ".")
+		}
+		if f, ok := describeType(dstType).renamed[name]; ok {
+			return f.Name, true
+		}
+		if dstFields != nil {
+			f, ok := srcType.FieldByName(name)
+			if !ok {
+				return "", false
+			}
+			g, ok := dstFields[opts.Naming(f)]
+			return g.Name, ok
+		}
+		return name, true
+	}
+
+	for _, name := range sortedKeys(opts.FieldRenames) {
+		rename := opts.FieldRenames[name]
+		if strings.Contains(rename, ".") {
+			irreversible = append(irreversible, IrreversibleField{Field: rename, Err: noInverse(rename, "nested rename")})
+			continue
+		}
+		if rev.FieldRenames == nil {
+			rev.FieldRenames = make(map[string]string)
+		}
+		rev.FieldRenames[rename] = name
+	}
+	for _, name := range sortedKeys(opts.Converters) {
+		if field, ok := target(name); ok {
+			omit(field, noInverse(field, "custom converter"))
+		}
+	}
+	for _, name := range sortedKeys(opts.NamedConverters) {
+		conv := opts.NamedConverters[name]
+		field, ok := target(name)
+		if !ok {
+			continue
+		}
+		df, dok := dstType.FieldByName(field)
+		sf, sok := srcType.FieldByName(name)
+		if !dok || !sok {
+			continue
+		}
+		if _, ok := LookupNamedConv(conv, sf.Type, df.Type); !ok {
+			omit(field, noInverse(field, "converter "+conv))
+			continue
+		}
+		if rev.NamedConverters == nil {
+			rev.NamedConverters = make(map[string]string)
+		}
+		rev.NamedConverters[field] = conv
+	}
+	for _, name := range sortedKeys(opts.Splitters) {
+		irreversible = append(irreversible, IrreversibleField{Field: name, Err: noInverse(name, "splitter")})
+	}
+	for _, name := range sortedKeys(opts.Computed) {
+		omit(name, noInverse(name, "computed"))
+	}
+	for _, name := range sortedKeys(opts.Constants) {
+		omit(name, noInverse(name, "constant"))
+	}
+	for _, name := range sortedKeys(opts.Unions) {
+		omit(name, noInverse(name, "tagged union"))
+	}
+	for _, name := range opts.FieldsToOmit {
+		if field, ok := target(name); ok {
+			rev.FieldsToOmit = append(rev.FieldsToOmit, field)
+		}
+	}
+	if opts.FieldsToCopy != nil {
+		rev.FieldsToCopy = []string{}
+		for _, name := range opts.FieldsToCopy {
+			if field, ok := target(name); ok {
+				rev.FieldsToCopy = append(rev.FieldsToCopy, field)
+			}
+		}
+	}
+	if mask := parseFieldMask(opts.FieldMask); mask != nil {
+		selected := []string{}
+		for _, f := range reflect.VisibleFields(dstType) {
+			if _, ok := mask[maskName(f)]; ok && f.IsExported() {
+				selected = append(selected, f.Name)
+			}
+		}
+		if rev.FieldsToCopy == nil {
+			rev.FieldsToCopy = selected
+		} else {
+			rev.FieldsToCopy = slices.DeleteFunc(rev.FieldsToCopy, func(name string) bool {
+				return !slices.Contains(selected, name)
+			})
+		}
+	}
+	return rev, irreversible
+}
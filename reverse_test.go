@@ -0,0 +1,101 @@
+package keyvalue
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type reverseOrder struct {
+	ID     uuid.UUID
+	Title  string
+	Cents  int64
+	Status string
+	Tags   []string
+}
+
+type reverseOrderDTO struct {
+	ID      string
+	Name    string
+	Cents   string
+	Status  int
+	Summary string
+}
+
+func init() {
+	RegisterNamedConverter("reverseCents", func(x int64) (string, error) {
+		return strconv.FormatInt(x, 10), nil
+	})
+	RegisterNamedConverter("reverseCents", func(x string) (int64, error) {
+		return strconv.ParseInt(x, 10, 64)
+	})
+}
+
+func TestReverseCopier(t *testing.T) {
+	req := require.New(t)
+
+	h, err := HandleForPair(reflect.TypeFor[reverseOrderDTO](), reflect.TypeFor[reverseOrder](), &CopierOptions{
+		FieldRenames:    map[string]string{"Title": "Name"},
+		NamedConverters: map[string]string{"Cents": "reverseCents"},
+		FieldsToOmit:    []string{"Tags"},
+		Converters: map[string]Converter{
+			"Status": {
+				DstType: reflect.TypeFor[int](),
+				SrcType: reflect.TypeFor[string](),
+				Func: func(x interface{}) (interface{}, error) {
+					return len(x.(string)), nil
+				},
+			},
+		},
+		Computed: map[string]func(interface{}) (interface{}, error){
+			"Summary": func(x interface{}) (interface{}, error) {
+				return x.(*reverseOrder).Title, nil
+			},
+		},
+	})
+	req.NoError(err)
+
+	rev, irreversible, err := h.ReverseCopier()
+	req.NoError(err)
+	req.Equal(reflect.TypeFor[reverseOrder](), rev.DstType())
+	req.Len(irreversible, 2)
+	req.Equal("Status", irreversible[0].Field)
+	req.ErrorIs(irreversible[0].Err, ErrNoInverse)
+	req.Equal("Summary", irreversible[1].Field)
+
+	u := uuid.New()
+	var dto reverseOrderDTO
+	req.NoError(h.Copy(&dto, &reverseOrder{ID: u, Title: "order", Cents: 123, Status: "open", Tags: []string{"a"}}))
+	var order reverseOrder
+	req.NoError(rev.Copy(&order, &dto))
+	req.Equal(reverseOrder{ID: u, Title: "order", Cents: 123}, order)
+}
+
+func TestReverseCopierUnconvertible(t *testing.T) {
+	type source struct {
+		Name  string
+		Count int
+	}
+	type target struct {
+		Name  string
+		Count int
+		Extra string
+	}
+
+	req := require.New(t)
+
+	h, err := HandleForPair(reflect.TypeFor[target](), reflect.TypeFor[source](), nil)
+	req.NoError(err)
+	rev, irreversible, err := h.ReverseCopier()
+	req.NoError(err)
+	req.Len(irreversible, 1)
+	req.Equal("Extra", irreversible[0].Field)
+	req.ErrorIs(irreversible[0].Err, ErrFieldNotFound)
+
+	var src source
+	req.NoError(rev.Copy(&src, &target{Name: "a", Count: 1, Extra: "x"}))
+	req.Equal(source{Name: "a", Count: 1}, src)
+}
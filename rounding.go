@@ -0,0 +1,104 @@
+package keyvalue
+
+import (
+	"reflect"
+	"strconv"
+
+	"github.com/mailstepcz/serr"
+	"github.com/shopspring/decimal"
+)
+
+// RoundingMode is a mode of rounding decimals.
+type RoundingMode int
+
+// rounding modes
+const (
+	// RoundHalfUp rounds half away from zero.
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds half to the nearest even digit.
+	RoundHalfEven
+	// RoundDown rounds towards zero.
+	RoundDown
+	// RoundUp rounds away from zero.
+	RoundUp
+	// RoundFloor rounds towards negative infinity.
+	RoundFloor
+	// RoundCeil rounds towards positive infinity.
+	RoundCeil
+)
+
+// DecimalRounding determines how decimals converted from and to floating-point numbers are rounded.
+type DecimalRounding struct {
+	Mode RoundingMode
+	// MaxScale is the maximum number of digits after the decimal point.
+	MaxScale int32
+}
+
+// round rounds the decimal to the maximum scale.
+func (r *DecimalRounding) round(d decimal.Decimal) decimal.Decimal {
+	switch r.Mode {
+	case RoundHalfEven:
+		return d.RoundBank(r.MaxScale)
+	case RoundDown:
+		return d.RoundDown(r.MaxScale)
+	case RoundUp:
+		return d.RoundUp(r.MaxScale)
+	case RoundFloor:
+		return d.RoundFloor(r.MaxScale)
+	case RoundCeil:
+		return d.RoundCeil(r.MaxScale)
+	}
+	return d.Round(r.MaxScale)
+}
+
+// DecimalFormat determines how decimals are formatted when converted to strings.
+// Decimals are formatted in plain notation with the fixed number of digits after the decimal point,
+// e.g. "10000.00" with a scale of 2, after being rounded to it.
+type DecimalFormat struct {
+	// Scale is the number of digits after the decimal point.
+	Scale int32
+	// Mode is the mode the decimals are rounded with, e.g. [RoundHalfEven] for banker's rounding.
+	Mode RoundingMode
+}
+
+// format formats the decimal.
+func (f *DecimalFormat) format(d decimal.Decimal) string {
+	r := DecimalRounding{Mode: f.Mode, MaxScale: f.Scale}
+	return r.round(d).StringFixed(f.Scale)
+}
+
+// fieldDecimalFormat returns the format of the decimals copied from the source field to the destination field.
+// The format given by the options takes precedence over the `copy` tag of the destination field, e.g. `copy:",scale=2"`,
+// which rounds with the mode of the copier's decimal format.
+func fieldDecimalFormat(dstField, srcField reflect.StructField, opts *CopierOptions, top bool) (DecimalFormat, bool, error) {
+	if top && opts != nil {
+		if f, ok := opts.DecimalFormats[srcField.Name]; ok {
+			return f, true, nil
+		}
+	}
+	scale, ok := copyTagOption(dstField, "scale")
+	if !ok {
+		return DecimalFormat{}, false, nil
+	}
+	n, err := strconv.ParseInt(scale, 10, 32)
+	if err != nil {
+		return DecimalFormat{}, false, serr.Wrap("bad decimal scale", err, serr.String("dstField", dstField.Name))
+	}
+	var f DecimalFormat
+	if opts != nil && opts.DecimalFormat != nil {
+		f = *opts.DecimalFormat
+	}
+	f.Scale = int32(n)
+	return f, true, nil
+}
+
+// decimalFormatOptions returns the options with the decimal format for copying a single field.
+func decimalFormatOptions(opts *CopierOptions, format DecimalFormat) *CopierOptions {
+	o := &CopierOptions{}
+	if opts != nil {
+		*o = *opts
+	}
+	o.DecimalFormat = &format
+	o.NoCache = true
+	return o
+}
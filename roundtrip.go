@@ -0,0 +1,162 @@
+package keyvalue
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"unsafe"
+
+	"github.com/mailstepcz/serr"
+)
+
+var (
+	// ErrLossyConversion signifies that copying a value to another type and back doesn't yield the original value.
+	ErrLossyConversion = errors.New("lossy conversion")
+	// ErrNoInverse signifies that a conversion used for copying values to another type can't be reversed.
+	ErrNoInverse = errors.New("no inverse conversion")
+)
+
+// BidiCopier copies between a pair of structures in both directions.
+type BidiCopier[D, S any] struct {
+	there, back func(unsafe.Pointer, unsafe.Pointer) error
+}
+
+// BidiCopierForPair creates copiers for a pair of structs in both directions, e.g. for request and response mapping.
+// Since every field has to be copied back, it checks that the conversion of every field has an inverse and fails
+// with an error wrapping [ErrNoInverse] otherwise: named converters need a converter registered under the same name
+// for the reversed pair of types and the other conversions have to be possible in the reverse direction.
+// Nested structures are checked field by field. The reverse conversions compiled by the check are cached
+// and reused by the reverse copier.
+func BidiCopierForPair[D, S any]() (*BidiCopier[D, S], error) {
+	dstType, srcType := reflect.TypeFor[D](), reflect.TypeFor[S]()
+	there, err := CopierForPair(dstType, srcType)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkInverses(dstType, srcType, make(map[[2]reflect.Type]bool)); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNoInverse, err)
+	}
+	back, err := CopierForPair(srcType, dstType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNoInverse, err)
+	}
+	return &BidiCopier[D, S]{there: there, back: back}, nil
+}
+
+// checkInverses checks that the conversions of the fields copied from the source structure to the destination structure
+// have inverses. The fields are matched like by a copier without options.
+func checkInverses(dstType, srcType reflect.Type, seen map[[2]reflect.Type]bool) error {
+	pair := [2]reflect.Type{dstType, srcType}
+	if seen[pair] {
+		return nil
+	}
+	seen[pair] = true
+	// values of the same type are copied without the renames given by the `copy` tags
+	tagRenames := dstType != srcType
+	dstRenamed := describeType(dstType).renamed
+	if !tagRenames {
+		dstRenamed = nil
+	}
+	for _, sf := range describeType(srcType).fields {
+		srcField := sf.StructField
+		if srcField.PkgPath != "" || sf.skipped {
+			continue
+		}
+		var (
+			dstField reflect.StructField
+			ok       bool
+		)
+		if rename, renamed := renamedField(srcField, nil, true, tagRenames); renamed {
+			dstField, ok = fieldByPath(dstType, rename)
+		} else if f, renamed := dstRenamed[srcField.Name]; renamed {
+			dstField, ok = f, true
+		} else {
+			dstField, ok = dstType.FieldByName(srcField.Name)
+		}
+		if !ok {
+			// fields which aren't copied are reported by the reverse copier
+			continue
+		}
+		if err := checkInverse(dstField, srcField, seen); err != nil {
+			return fieldError(err, srcField.Name, dstField.Type, srcField.Type)
+		}
+	}
+	return nil
+}
+
+// checkInverse checks that the conversion of the source field to the destination field has an inverse.
+func checkInverse(dstField, srcField reflect.StructField, seen map[[2]reflect.Type]bool) error {
+	if name := cmp.Or(tagConverterName(dstField), tagConverterName(srcField)); name != "" {
+		// the reverse copier selects the converter by the tag of the source field first
+		back := cmp.Or(tagConverterName(srcField), tagConverterName(dstField))
+		if _, ok := LookupNamedConv(back, srcField.Type, dstField.Type); !ok {
+			return serr.Wrap("", ErrNotRegistered, serr.String("converter", back),
+				serr.String("dstType", srcField.Type.String()), serr.String("srcType", dstField.Type.String()))
+		}
+		return nil
+	}
+	dstType, srcType := dstField.Type, srcField.Type
+	for dstType != srcType && convertedStructurally(dstType, srcType, nil) &&
+		(dstType.Kind() == reflect.Pointer && srcType.Kind() == reflect.Pointer || dstType.Kind() == reflect.Slice && srcType.Kind() == reflect.Slice) {
+		dstType, srcType = dstType.Elem(), srcType.Elem()
+	}
+	if dstType == srcType {
+		return nil
+	}
+	if dstType.Kind() == reflect.Struct && srcType.Kind() == reflect.Struct && convertedStructurally(dstType, srcType, nil) {
+		return checkInverses(dstType, srcType, seen)
+	}
+	_, err := valConv(srcField.Type, dstField.Type)
+	return err
+}
+
+// Copy copies the source object to the destination object.
+func (c *BidiCopier[D, S]) Copy(dst *D, src *S) error {
+	return c.there(unsafe.Pointer(dst), unsafe.Pointer(src))
+}
+
+// CopyBack copies the destination object back to the source object.
+func (c *BidiCopier[D, S]) CopyBack(src *S, dst *D) error {
+	return c.back(unsafe.Pointer(src), unsafe.Pointer(dst))
+}
+
+// CheckRoundTrip copies each sample to the destination type and back and reports the fields whose values change.
+// Each lossy field is reported as a [CopyError] wrapping [ErrLossyConversion] with the index of the sample
+// prepended to its path. It fails if either of the copiers can't be created.
+func CheckRoundTrip[D, S any](samples []S) error {
+	dstType, srcType := reflect.TypeFor[D](), reflect.TypeFor[S]()
+	there, err := CopierForPair(dstType, srcType)
+	if err != nil {
+		return err
+	}
+	back, err := CopierForPair(srcType, dstType)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i := range samples {
+		var (
+			d D
+			s S
+		)
+		if err := there(unsafe.Pointer(&d), unsafe.Pointer(&samples[i])); err != nil {
+			errs = append(errs, fieldError(err, strconv.Itoa(i), dstType, srcType))
+			continue
+		}
+		if err := back(unsafe.Pointer(&s), unsafe.Pointer(&d)); err != nil {
+			errs = append(errs, fieldError(err, strconv.Itoa(i), srcType, dstType))
+			continue
+		}
+		for _, diff := range diffStructs(nil, nil, reflect.ValueOf(&samples[i]).Elem(), reflect.ValueOf(&s).Elem()) {
+			errs = append(errs, &CopyError{
+				DstType:   dstType,
+				SrcType:   srcType,
+				FieldPath: append([]string{strconv.Itoa(i)}, diff.FieldPath...),
+				Err:       serr.Wrap("", ErrLossyConversion, serr.Any("before", diff.Before), serr.Any("after", diff.After)),
+			})
+		}
+	}
+	return errors.Join(errs...)
+}
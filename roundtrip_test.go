@@ -0,0 +1,132 @@
+package keyvalue
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripDomain struct {
+	ID    uuid.UUID
+	Name  string
+	Ratio float64
+}
+
+type roundTripDTO struct {
+	ID    string
+	Name  string
+	Ratio float32
+}
+
+func TestCheckRoundTrip(t *testing.T) {
+	req := require.New(t)
+
+	req.NoError(CheckRoundTrip[roundTripDTO]([]roundTripDomain{
+		{ID: uuid.New(), Name: "a", Ratio: 0.5},
+		{Name: "b", Ratio: 2},
+	}))
+
+	err := CheckRoundTrip[roundTripDTO]([]roundTripDomain{
+		{ID: uuid.New(), Name: "a", Ratio: 0.5},
+		{ID: uuid.New(), Name: "b", Ratio: 0.1},
+	})
+	req.ErrorIs(err, ErrLossyConversion)
+	var cerr *CopyError
+	req.ErrorAs(err, &cerr)
+	req.Equal("1.Ratio", cerr.Path())
+	req.Len(err.(interface{ Unwrap() []error }).Unwrap(), 1)
+
+	err = CheckRoundTrip[roundTripDomain]([]roundTripDTO{{ID: "not a uuid"}})
+	req.Error(err)
+	req.False(errors.Is(err, ErrLossyConversion))
+}
+
+type bidiPrice struct {
+	Cents int64 `copy:",conv=bidiCents"`
+}
+
+type bidiPriceDTO struct {
+	Cents string
+}
+
+type bidiOneWay struct {
+	Cents int64 `copy:",conv=bidiOneWay"`
+}
+
+type bidiExtraDTO struct {
+	ID    string
+	Name  string
+	Ratio float32
+	Extra string
+}
+
+type bidiOrder struct {
+	Prices []*bidiOneWay
+}
+
+type bidiOrderDTO struct {
+	Prices []*bidiPriceDTO
+}
+
+type bidiCode struct {
+	Code int
+}
+
+type bidiCodeDTO struct {
+	Code string
+}
+
+func init() {
+	RegisterNamedConverter("bidiCents", func(x int64) (string, error) {
+		return strconv.FormatInt(x, 10), nil
+	})
+	RegisterNamedConverter("bidiCents", func(x string) (int64, error) {
+		return strconv.ParseInt(x, 10, 64)
+	})
+	RegisterNamedConverter("bidiOneWay", func(x int64) (string, error) {
+		return strconv.FormatInt(x, 10), nil
+	})
+}
+
+func TestBidiCopierForPair(t *testing.T) {
+	req := require.New(t)
+
+	c, err := BidiCopierForPair[roundTripDTO, roundTripDomain]()
+	req.NoError(err)
+	u := uuid.New()
+	var dto roundTripDTO
+	req.NoError(c.Copy(&dto, &roundTripDomain{ID: u, Name: "a", Ratio: 0.5}))
+	req.Equal(roundTripDTO{ID: u.String(), Name: "a", Ratio: 0.5}, dto)
+	var domain roundTripDomain
+	req.NoError(c.CopyBack(&domain, &dto))
+	req.Equal(roundTripDomain{ID: u, Name: "a", Ratio: 0.5}, domain)
+
+	p, err := BidiCopierForPair[bidiPriceDTO, bidiPrice]()
+	req.NoError(err)
+	var price bidiPrice
+	req.NoError(p.CopyBack(&price, &bidiPriceDTO{Cents: "123"}))
+	req.Equal(int64(123), price.Cents)
+
+	_, err = BidiCopierForPair[bidiPriceDTO, bidiOneWay]()
+	req.ErrorIs(err, ErrNoInverse)
+	req.ErrorIs(err, ErrNotRegistered)
+
+	_, err = BidiCopierForPair[bidiOrderDTO, bidiOrder]()
+	req.ErrorIs(err, ErrNoInverse)
+	req.ErrorIs(err, ErrNotRegistered)
+	var cerr *CopyError
+	req.ErrorAs(err, &cerr)
+	req.Equal("Prices.Cents", cerr.Path())
+
+	_, err = BidiCopierForPair[bidiCodeDTO, bidiCode]()
+	req.ErrorIs(err, ErrNoInverse)
+	req.ErrorAs(err, &cerr)
+	req.Equal("Code", cerr.Path())
+
+	_, err = BidiCopierForPair[bidiExtraDTO, roundTripDomain]()
+	req.ErrorIs(err, ErrNoInverse)
+	req.ErrorIs(err, ErrFieldNotFound)
+}
@@ -0,0 +1,248 @@
+package keyvalue
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/mailstepcz/serr"
+)
+
+// RowMapper maps spreadsheet rows, such as CSV records or the rows of Excel sheets, to structures by their column headers.
+// Fields are matched with the columns named by their `col` tag, or by their names if untagged, ignoring case and surrounding
+// spaces. Fields tagged with `col:"name,required"` have to have a column and fields tagged with `col:"-"` aren't mapped.
+// Cells are converted like by [TypedCopy], e.g. into UUIDs, decimals and dates, and parsed into numbers and booleans.
+// Empty cells leave the fields unchanged.
+type RowMapper[D any] struct {
+	columns []rowColumn
+}
+
+// rowColumn maps the cells of a column to a field.
+type rowColumn struct {
+	index  int
+	header string
+	field  reflect.StructField
+	conv   func(unsafe.Pointer, string) error
+}
+
+// RowOptions configures the mapping of rows by [NewRowMapperWithOptions] and [ImportRows].
+type RowOptions struct {
+	// Columns maps column headers to the names of the fields the cells are copied into. They take precedence over
+	// the `col` tags of the fields and the columns have to be present. Headers are matched ignoring case and surrounding spaces.
+	Columns map[string]string
+	// Converters maps column headers to functions converting the cells of the columns. The results are converted
+	// into the field types like by [TypedCopy] and nil results leave the fields unchanged.
+	Converters map[string]func(string) (interface{}, error)
+}
+
+// NewRowMapper creates a mapper of the rows of a sheet with the header. Columns without fields are ignored.
+func NewRowMapper[D any](header []string) (*RowMapper[D], error) {
+	return NewRowMapperWithOptions[D](header, nil)
+}
+
+// NewRowMapperWithOptions creates a mapper of the rows of a sheet with the header and the options.
+// Columns without fields are ignored.
+func NewRowMapperWithOptions[D any](header []string, opts *RowOptions) (*RowMapper[D], error) {
+	t := reflect.TypeFor[D]()
+	if t.Kind() != reflect.Struct {
+		return nil, serr.Wrap("", ErrTypeNotStruct, serr.String("dstType", t.String()))
+	}
+	indices := make(map[string]int, len(header))
+	for i, h := range header {
+		h = columnKey(h)
+		if _, ok := indices[h]; !ok {
+			indices[h] = i
+		}
+	}
+	var (
+		mapped     map[string]string
+		converters map[string]func(string) (interface{}, error)
+	)
+	if opts != nil {
+		mapped = make(map[string]string, len(opts.Columns))
+		for h, name := range opts.Columns {
+			if f, ok := t.FieldByName(name); !ok || !f.IsExported() {
+				return nil, serr.Wrap("", ErrFieldNotFound, serr.String("dstField", name), serr.String("dstType", t.String()))
+			}
+			mapped[name] = h
+		}
+		converters = make(map[string]func(string) (interface{}, error), len(opts.Converters))
+		for h, conv := range opts.Converters {
+			converters[columnKey(h)] = conv
+		}
+	}
+	var columns []rowColumn
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		name, tagOpts, _ := strings.Cut(f.Tag.Get("col"), ",")
+		if h, ok := mapped[f.Name]; ok {
+			name, tagOpts = h, "required"
+		}
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if _, ok := fieldOffset(t, f.Index); !ok {
+			continue
+		}
+		index, ok := indices[columnKey(name)]
+		if !ok {
+			if tagOpts == "required" {
+				return nil, serr.Wrap("", ErrFieldNotFound, serr.String("column", name), serr.String("dstType", t.String()))
+			}
+			continue
+		}
+		var (
+			conv func(unsafe.Pointer, string) error
+			err  error
+		)
+		if fn, ok := converters[columnKey(name)]; ok {
+			conv = customCellConv(f.Type, fn)
+		} else if conv, err = cellConv(f.Type); err != nil {
+			return nil, compileFieldError(err, f.Name, f.Type, reflect.TypeFor[string]())
+		}
+		columns = append(columns, rowColumn{
+			index:  index,
+			header: header[index],
+			field:  f,
+			conv:   conv,
+		})
+	}
+	return &RowMapper[D]{
+		columns: columns,
+	}, nil
+}
+
+// Map maps the cells of a row to the structure. Rows may be shorter than the header.
+func (m *RowMapper[D]) Map(dst *D, cells []string) error {
+	for _, c := range m.columns {
+		if c.index >= len(cells) || strings.TrimSpace(cells[c.index]) == "" {
+			continue
+		}
+		p := reflect.ValueOf(dst).Elem().FieldByIndex(c.field.Index).Addr().UnsafePointer()
+		if err := c.conv(p, strings.TrimSpace(cells[c.index])); err != nil {
+			return fieldError(serr.Wrap("", err, serr.String("column", c.header)), c.field.Name, c.field.Type, reflect.TypeFor[string]())
+		}
+	}
+	return nil
+}
+
+// MapRows maps rows whose first row is the header to structures. The indices in errors are those of the data rows.
+func MapRows[D any](rows [][]string) ([]*D, error) {
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	m, err := NewRowMapper[D](rows[0])
+	if err != nil {
+		return nil, err
+	}
+	r := make([]*D, len(rows)-1)
+	for i, cells := range rows[1:] {
+		r[i] = new(D)
+		if err := m.Map(r[i], cells); err != nil {
+			return nil, elementError(err, i, reflect.TypeFor[D](), nil)
+		}
+	}
+	return r, nil
+}
+
+// ImportRows maps rows whose first row is the header to structures like [MapRows] with the options.
+// Rows which fail to map are left out and reported by a [SkippedElementsError] once all the rows are mapped,
+// so the other rows are still imported. The indices in errors are those of the data rows.
+func ImportRows[D any](rows [][]string, opts *RowOptions) ([]*D, error) {
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	m, err := NewRowMapperWithOptions[D](rows[0], opts)
+	if err != nil {
+		return nil, err
+	}
+	var skipped *SkippedElementsError
+	r := make([]*D, 0, len(rows)-1)
+	for i, cells := range rows[1:] {
+		d := new(D)
+		if err := m.Map(d, cells); err != nil {
+			if skipped == nil {
+				skipped = &SkippedElementsError{}
+			}
+			skipped.Elements = append(skipped.Elements, elementError(err, i, reflect.TypeFor[D](), nil))
+			continue
+		}
+		r = append(r, d)
+	}
+	if skipped != nil {
+		return r, skipped
+	}
+	return r, nil
+}
+
+// columnKey returns the key matching column headers and field names ignoring case and surrounding spaces.
+func columnKey(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// customCellConv returns a converter of cells into values of the type using the function.
+func customCellConv(t reflect.Type, fn func(string) (interface{}, error)) func(unsafe.Pointer, string) error {
+	return func(dst unsafe.Pointer, s string) error {
+		x, err := fn(s)
+		if err != nil {
+			return err
+		}
+		if x == nil {
+			return nil
+		}
+		return assignValue(t, dst, x, nil)
+	}
+}
+
+// cellOptions are the options cells are converted with. Cells hold times and dates as strings.
+var cellOptions = &CopierOptions{TimeStrings: true}
+
+// cellConv returns a converter of cells into values of the type. Cells are converted like by [TypedCopy] if possible
+// and parsed as numbers and booleans otherwise.
+func cellConv(t reflect.Type) (func(unsafe.Pointer, string) error, error) {
+	if conv, err := valConvWithOptions(t, reflect.TypeFor[string](), cellOptions); err == nil {
+		return func(dst unsafe.Pointer, s string) error {
+			return conv(dst, unsafe.Pointer(&s))
+		}, nil
+	}
+	switch {
+	case isNumber(t):
+		return func(dst unsafe.Pointer, s string) error {
+			x, err := parseNumber(t, s)
+			if err != nil {
+				return err
+			}
+			reflect.NewAt(t, dst).Elem().Set(reflect.ValueOf(x))
+			return nil
+		}, nil
+	case t.Kind() == reflect.Bool:
+		return func(dst unsafe.Pointer, s string) error {
+			b, err := strconv.ParseBool(s)
+			if err != nil {
+				return err
+			}
+			reflect.NewAt(t, dst).Elem().SetBool(b)
+			return nil
+		}, nil
+	case t.Kind() == reflect.Pointer:
+		conv, err := cellConv(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return func(dst unsafe.Pointer, s string) error {
+			v := reflect.New(t.Elem())
+			if err := conv(v.UnsafePointer(), s); err != nil {
+				return err
+			}
+			*(*unsafe.Pointer)(dst) = v.UnsafePointer()
+			return nil
+		}, nil
+	}
+	return nil, serr.Wrap("", ErrUnsupportedTypePair, serr.String("srcType", "string"), serr.String("dstType", t.String()))
+}
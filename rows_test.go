@@ -0,0 +1,122 @@
+package keyvalue
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rickb777/date/v2"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+type importedItem struct {
+	ID          uuid.UUID       `col:"Item ID,required"`
+	Name        string          `col:"name"`
+	Price       decimal.Decimal `col:"Price"`
+	DeliveredOn date.Date       `col:"Delivered On"`
+	Quantity    *int            `col:"Qty"`
+	Active      bool            `col:"Active"`
+	Note        string
+	Ignored     string `col:"-"`
+}
+
+func TestMapRows(t *testing.T) {
+	req := require.New(t)
+
+	u1, u2 := uuid.New(), uuid.New()
+	items, err := MapRows[importedItem]([][]string{
+		{" item id ", "Name", "Price", "Delivered On", "Qty", "Active", "Note", "Ignored", "Extra"},
+		{u1.String(), "Widget", "12.50", "2024-05-06", "3", "true", "fragile", "x", "y"},
+		{u2.String(), "Gadget", "", "", "", "false"},
+	})
+	req.NoError(err)
+	req.Len(items, 2)
+
+	req.Equal(u1, items[0].ID)
+	req.Equal("Widget", items[0].Name)
+	req.Equal("12.5", items[0].Price.String())
+	req.Equal(date.New(2024, 5, 6), items[0].DeliveredOn)
+	req.NotNil(items[0].Quantity)
+	req.Equal(3, *items[0].Quantity)
+	req.True(items[0].Active)
+	req.Equal("fragile", items[0].Note)
+	req.Empty(items[0].Ignored)
+
+	req.Equal(u2, items[1].ID)
+	req.True(items[1].Price.IsZero())
+	req.Nil(items[1].Quantity)
+	req.Empty(items[1].Note)
+}
+
+func TestMapRowsErrors(t *testing.T) {
+	req := require.New(t)
+
+	_, err := MapRows[importedItem]([][]string{{"Name"}})
+	req.ErrorIs(err, ErrFieldNotFound)
+
+	_, err = MapRows[importedItem]([][]string{
+		{"Item ID", "Qty"},
+		{uuid.NewString(), "1"},
+		{uuid.NewString(), "many"},
+	})
+	req.Error(err)
+	var ce *CopyError
+	req.ErrorAs(err, &ce)
+	req.Equal("1.Quantity", ce.Path())
+	req.Contains(err.Error(), "column=Qty")
+
+	items, err := MapRows[importedItem](nil)
+	req.NoError(err)
+	req.Nil(items)
+}
+
+func TestImportRows(t *testing.T) {
+	req := require.New(t)
+
+	type customer struct {
+		ID      uuid.UUID
+		Name    string
+		Balance decimal.Decimal
+		Active  bool
+	}
+	opts := &RowOptions{
+		Columns: map[string]string{
+			"Customer No.": "ID",
+			"Full Name":    "Name",
+			"Balance":      "Balance",
+		},
+		Converters: map[string]func(string) (interface{}, error){
+			"balance": func(s string) (interface{}, error) {
+				return decimal.NewFromString(strings.ReplaceAll(s, ",", "."))
+			},
+			"Active": func(s string) (interface{}, error) {
+				return s == "yes", nil
+			},
+		},
+	}
+
+	u1, u2 := uuid.New(), uuid.New()
+	customers, err := ImportRows[customer]([][]string{
+		{"customer no.", "Full Name", "Balance", "Active"},
+		{u1.String(), "Alice", "12,50", "yes"},
+		{"bogus", "Bob", "1", "no"},
+		{u2.String(), "Carol", "x", "no"},
+		{u2.String(), "Dave", "", "no"},
+	}, opts)
+	var se *SkippedElementsError
+	req.ErrorAs(err, &se)
+	req.Len(se.Elements, 2)
+	req.Equal("1.ID", se.Elements[0].Path())
+	req.Equal("2.Balance", se.Elements[1].Path())
+
+	req.Len(customers, 2)
+	req.Equal(customer{ID: u1, Name: "Alice", Balance: decimal.RequireFromString("12.50"), Active: true}, *customers[0])
+	req.Equal(customer{ID: u2, Name: "Dave"}, *customers[1])
+
+	_, err = ImportRows[customer]([][]string{{"Full Name", "Balance"}}, opts)
+	req.ErrorIs(err, ErrFieldNotFound)
+
+	_, err = ImportRows[customer]([][]string{{"Name"}}, &RowOptions{Columns: map[string]string{"Name": "Missing"}})
+	req.ErrorIs(err, ErrFieldNotFound)
+}
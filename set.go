@@ -0,0 +1,76 @@
+package keyvalue
+
+import (
+	"reflect"
+	"slices"
+	"unsafe"
+)
+
+// isSet checks whether the type is a map used as a set such as map[T]struct{}.
+func isSet(t reflect.Type) bool {
+	return t.Kind() == reflect.Map && t.Elem().Kind() == reflect.Struct && t.Elem().NumField() == 0
+}
+
+// setToSliceConv returns a converter of sets into slices of their converted elements.
+// The elements are ordered if they're numbers, strings or have a Compare method.
+func setToSliceConv(dstType, srcType reflect.Type, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	dstElType, srcElType := dstType.Elem(), srcType.Key()
+	elConv, err := valConvWithOptions(dstElType, srcElType, opts)
+	if err != nil {
+		return nil, err
+	}
+	dstElSize := dstElType.Size()
+	compare, ordered := compareFunc(srcElType)
+	onNil := nilHandler(dstType, opts)
+	return func(dst, src unsafe.Pointer) error {
+		srcMap := reflect.NewAt(srcType, src).Elem()
+		if srcMap.IsNil() {
+			return onNil(dst)
+		}
+		keys := srcMap.MapKeys()
+		if ordered {
+			slices.SortFunc(keys, compare)
+		}
+		dstSlice := reflect.MakeSlice(dstType, len(keys), len(keys))
+		dstPtr := dstSlice.UnsafePointer()
+		el := reflect.New(srcElType).Elem()
+		for i, key := range keys {
+			el.Set(key)
+			if err := elConv(unsafe.Add(dstPtr, uintptr(i)*dstElSize), el.Addr().UnsafePointer()); err != nil {
+				return err
+			}
+		}
+		reflect.NewAt(dstType, dst).Elem().Set(dstSlice)
+		return nil
+	}, nil
+}
+
+// sliceToSetConv returns a converter of slices into sets of their converted elements.
+func sliceToSetConv(dstType, srcType reflect.Type, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	dstElType, srcElType := dstType.Key(), srcType.Elem()
+	elConv, err := valConvWithOptions(dstElType, srcElType, opts)
+	if err != nil {
+		return nil, err
+	}
+	srcElSize := srcElType.Size()
+	member := reflect.New(dstType.Elem()).Elem()
+	onNil := nilHandler(dstType, opts)
+	return func(dst, src unsafe.Pointer) error {
+		srcSlice := reflect.NewAt(srcType, src).Elem()
+		if srcSlice.IsNil() {
+			return onNil(dst)
+		}
+		len := srcSlice.Len()
+		dstMap := reflect.MakeMapWithSize(dstType, len)
+		srcPtr := srcSlice.UnsafePointer()
+		for i := uintptr(0); i < uintptr(len); i++ {
+			el := reflect.New(dstElType)
+			if err := elConv(el.UnsafePointer(), unsafe.Add(srcPtr, i*srcElSize)); err != nil {
+				return elementError(err, int(i), dstElType, srcElType)
+			}
+			dstMap.SetMapIndex(el.Elem(), member)
+		}
+		reflect.NewAt(dstType, dst).Elem().Set(dstMap)
+		return nil
+	}, nil
+}
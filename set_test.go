@@ -0,0 +1,45 @@
+package keyvalue
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type setGroup struct {
+	Members map[uuid.UUID]struct{}
+	Roles   map[string]struct{}
+}
+
+type setGroupDTO struct {
+	Members []string
+	Roles   []string
+}
+
+func TestCopierSets(t *testing.T) {
+	req := require.New(t)
+
+	u1, u2 := uuid.New(), uuid.New()
+	var dto setGroupDTO
+	req.NoError(TypedCopy(&dto, &setGroup{
+		Members: map[uuid.UUID]struct{}{u1: {}, u2: {}},
+		Roles:   map[string]struct{}{"b": {}, "a": {}},
+	}))
+	req.ElementsMatch([]string{u1.String(), u2.String()}, dto.Members)
+	req.Equal([]string{"a", "b"}, dto.Roles)
+
+	var g setGroup
+	req.NoError(TypedCopy(&g, &setGroupDTO{Members: []string{u1.String(), u1.String()}, Roles: []string{"a"}}))
+	req.Equal(map[uuid.UUID]struct{}{u1: {}}, g.Members)
+	req.Equal(map[string]struct{}{"a": {}}, g.Roles)
+
+	g = setGroup{}
+	req.NoError(TypedCopy(&g, &setGroupDTO{}))
+	req.Nil(g.Members)
+
+	err := TypedCopy(&g, &setGroupDTO{Members: []string{"bad"}})
+	var cerr *CopyError
+	req.ErrorAs(err, &cerr)
+	req.Equal("Members.0", cerr.Path())
+}
@@ -0,0 +1,344 @@
+package keyvalue
+
+import (
+	"cmp"
+	"context"
+	"reflect"
+	"sort"
+	"strconv"
+	"unsafe"
+
+	"github.com/mailstepcz/serr"
+)
+
+// SliceOptions determines how destination slices are post-processed once their elements have been converted.
+type SliceOptions struct {
+	// Dedup removes duplicate elements, keeping the first occurrence of each.
+	Dedup bool
+	// DedupKey is the name of the element field identifying duplicate elements.
+	// Whole elements, or the values pointer elements reference, are compared if it's empty.
+	DedupKey string
+	// Sort sorts the elements stably after removing duplicates.
+	Sort bool
+	// SortKey is the name of the element field the elements are sorted by. Whole elements, or the values pointer elements
+	// reference, are sorted if it's empty. The sorted values have to be numbers, strings or have a Compare method
+	// like [time.Time]. Nil pointer elements come first.
+	SortKey string
+	// Less orders the elements instead of comparing their values. It receives the elements and implies Sort.
+	Less func(a, b interface{}) bool
+	// SkipInvalid skips the elements which can't be converted instead of failing the copy.
+	// The skipped elements are reported by a [SkippedElementsError] once the copy is complete.
+	SkipInvalid bool
+	// Tracer traces each copy of a slice performed by the slice copier. The element copies aren't traced separately.
+	Tracer CopyTracer
+}
+
+// nilElement is the deduplication key of nil pointer elements.
+type nilElement struct{}
+
+// fieldSliceOptions returns the slice options for copying the source field to the destination field.
+// The options take precedence over the `copy` tag of the destination field, e.g. `copy:",dedup=ID,sort=Name,skipinvalid"`.
+func fieldSliceOptions(dstField, srcField reflect.StructField, opts *CopierOptions, top bool) (SliceOptions, bool) {
+	if top && opts != nil {
+		if so, ok := opts.Slices[srcField.Name]; ok {
+			return so, true
+		}
+	}
+	var so SliceOptions
+	so.DedupKey, so.Dedup = copyTagOption(dstField, "dedup")
+	so.SortKey, so.Sort = copyTagOption(dstField, "sort")
+	_, so.SkipInvalid = copyTagOption(dstField, "skipinvalid")
+	return so, so.Dedup || so.Sort || so.SkipInvalid
+}
+
+// sliceProcessor returns a function post-processing slices of the type according to the options.
+func sliceProcessor(t reflect.Type, so SliceOptions) (func(reflect.Value), error) {
+	if t.Kind() != reflect.Slice {
+		return nil, serr.Wrap("", ErrUnsupportedTypePair, serr.String("dstType", t.String()))
+	}
+	var steps []func(reflect.Value)
+	if so.Dedup {
+		key, err := dedupKey(t.Elem(), so.DedupKey)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, func(v reflect.Value) {
+			dedupSlice(v, key)
+		})
+	}
+	if so.Sort || so.Less != nil {
+		less, err := sortLess(t.Elem(), so)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, func(v reflect.Value) {
+			sortSlice(v, less)
+		})
+	}
+	return func(v reflect.Value) {
+		for _, step := range steps {
+			step(v)
+		}
+	}, nil
+}
+
+// unsharedOptions returns the options with slice sharing disabled so that post-processed slices don't alias the source.
+func unsharedOptions(opts *CopierOptions) *CopierOptions {
+	if opts == nil || !opts.ShareSlices {
+		return opts
+	}
+	o := *opts
+	o.ShareSlices = false
+	o.NoCache = true
+	return &o
+}
+
+// processedConv returns a converter to slices which post-processes the converted slices.
+func processedConv(t reflect.Type, conv func(unsafe.Pointer, unsafe.Pointer) error, process func(reflect.Value)) func(unsafe.Pointer, unsafe.Pointer) error {
+	return func(dst, src unsafe.Pointer) error {
+		err := conv(dst, src)
+		if _, skipped := err.(*SkippedElementsError); err != nil && !skipped {
+			return err
+		}
+		process(reflect.NewAt(t, dst).Elem())
+		return err
+	}
+}
+
+// processedContextConv is the context-aware counterpart of [processedConv].
+func processedContextConv(t reflect.Type, conv func(context.Context, unsafe.Pointer, unsafe.Pointer) error, process func(reflect.Value)) func(context.Context, unsafe.Pointer, unsafe.Pointer) error {
+	return func(ctx context.Context, dst, src unsafe.Pointer) error {
+		err := conv(ctx, dst, src)
+		if _, skipped := err.(*SkippedElementsError); err != nil && !skipped {
+			return err
+		}
+		process(reflect.NewAt(t, dst).Elem())
+		return err
+	}
+}
+
+// skippingSliceConv returns a converter of slices which skips the elements that can't be converted.
+func skippingSliceConv(dstType, srcType reflect.Type, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	if dstType.Kind() != reflect.Slice || srcType.Kind() != reflect.Slice {
+		return nil, serr.Wrap("", ErrUnsupportedTypePair, serr.String("srcType", srcType.String()), serr.String("dstType", dstType.String()))
+	}
+	dstElType, srcElType := dstType.Elem(), srcType.Elem()
+	elConv, err := valConvWithOptions(dstElType, srcElType, opts)
+	if err != nil {
+		return nil, err
+	}
+	onNil := nilHandler(dstType, opts)
+	return func(dst, src unsafe.Pointer) error {
+		srcSlice := reflect.NewAt(srcType, src).Elem()
+		if srcSlice.IsNil() {
+			return onNil(dst)
+		}
+		n := srcSlice.Len()
+		dstSlice := reflect.MakeSlice(dstType, n, n)
+		var skipped *SkippedElementsError
+		kept := 0
+		for i := 0; i < n; i++ {
+			el := dstSlice.Index(kept)
+			if skipElement(&skipped, elConv(el.Addr().UnsafePointer(), srcSlice.Index(i).Addr().UnsafePointer()), i, dstElType, srcElType) {
+				el.SetZero()
+				continue
+			}
+			kept++
+		}
+		reflect.NewAt(dstType, dst).Elem().Set(dstSlice.Slice(0, kept))
+		if skipped != nil {
+			return skipped
+		}
+		return nil
+	}, nil
+}
+
+// skippingSliceCopier creates a typed copier for a pair of slices which skips the elements that can't be converted.
+func skippingSliceCopier[D, S any]() (func([]*S) ([]*D, error), error) {
+	c, err := TypedCopierForPair[D, S]()
+	if err != nil {
+		return nil, err
+	}
+	dstType, srcType := reflect.TypeFor[D](), reflect.TypeFor[S]()
+	return func(src []*S) ([]*D, error) {
+		r := make([]*D, 0, len(src))
+		var skipped *SkippedElementsError
+		for i, x := range src {
+			var y D
+			if !skipElement(&skipped, c(&y, x), i, dstType, srcType) {
+				r = append(r, &y)
+			}
+		}
+		if skipped != nil {
+			return r, skipped
+		}
+		return r, nil
+	}, nil
+}
+
+// skipElement records the error of the converted slice element and reports whether the element is skipped.
+// Elements with skipped elements of their own are kept and their skipped elements are recorded.
+func skipElement(skipped **SkippedElementsError, err error, index int, dstType, srcType reflect.Type) bool {
+	if err == nil {
+		return false
+	}
+	if se, ok := err.(*SkippedElementsError); ok {
+		*skipped = addSkipped(*skipped, se, strconv.Itoa(index))
+		return false
+	}
+	if *skipped == nil {
+		*skipped = &SkippedElementsError{}
+	}
+	(*skipped).Elements = append((*skipped).Elements, elementError(err, index, dstType, srcType))
+	return true
+}
+
+// elementKey returns a function extracting the named field, or the whole value if the name is empty, from slice elements
+// of the type along with the type of the extracted values. Pointer elements are dereferenced and yield false if they're nil.
+func elementKey(elType reflect.Type, name string) (func(reflect.Value) (reflect.Value, bool), reflect.Type, error) {
+	t := elType
+	ptr := t.Kind() == reflect.Pointer
+	if ptr {
+		t = t.Elem()
+	}
+	var index []int
+	if name != "" {
+		if t.Kind() != reflect.Struct {
+			return nil, nil, serr.Wrap("", ErrTypeNotStruct, serr.String("elemType", elType.String()))
+		}
+		f, ok := t.FieldByName(name)
+		if ok {
+			_, ok = fieldOffset(t, f.Index)
+		}
+		if !ok || f.PkgPath != "" {
+			return nil, nil, serr.Wrap("", ErrFieldNotFound, serr.String("keyField", name), serr.String("elemType", elType.String()))
+		}
+		index = f.Index
+		t = f.Type
+	}
+	return func(v reflect.Value) (reflect.Value, bool) {
+		if ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		if index != nil {
+			v = v.FieldByIndex(index)
+		}
+		return v, true
+	}, t, nil
+}
+
+// dedupKey returns a function computing the deduplication key of slice elements of the type.
+func dedupKey(elType reflect.Type, name string) (func(reflect.Value) interface{}, error) {
+	key, t, err := elementKey(elType, name)
+	if err != nil {
+		return nil, err
+	}
+	if !t.Comparable() {
+		return nil, serr.Wrap("", ErrUnsupportedTypePair, serr.String("keyField", name), serr.String("keyType", t.String()))
+	}
+	return func(v reflect.Value) interface{} {
+		k, ok := key(v)
+		if !ok {
+			return nilElement{}
+		}
+		return k.Interface()
+	}, nil
+}
+
+// dedupSlice removes duplicate elements from the slice. The slice is replaced by a new one if there are any duplicates
+// so that slices shared with the source are never modified.
+func dedupSlice(v reflect.Value, key func(reflect.Value) interface{}) {
+	n := v.Len()
+	if n < 2 {
+		return
+	}
+	seen := make(map[interface{}]struct{}, n)
+	kept := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		k := key(v.Index(i))
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		kept = append(kept, i)
+	}
+	if len(kept) == n {
+		return
+	}
+	r := reflect.MakeSlice(v.Type(), len(kept), len(kept))
+	for i, j := range kept {
+		r.Index(i).Set(v.Index(j))
+	}
+	v.Set(r)
+}
+
+// sortLess returns a function ordering slice elements of the type according to the options.
+func sortLess(elType reflect.Type, so SliceOptions) (func(a, b reflect.Value) bool, error) {
+	if so.Less != nil {
+		return func(a, b reflect.Value) bool {
+			return so.Less(a.Interface(), b.Interface())
+		}, nil
+	}
+	key, t, err := elementKey(elType, so.SortKey)
+	if err != nil {
+		return nil, err
+	}
+	compare, ok := compareFunc(t)
+	if !ok {
+		return nil, serr.Wrap("", ErrUnsupportedTypePair, serr.String("keyField", so.SortKey), serr.String("keyType", t.String()))
+	}
+	return func(a, b reflect.Value) bool {
+		ka, okA := key(a)
+		kb, okB := key(b)
+		if !okA || !okB {
+			return !okA && okB
+		}
+		return compare(ka, kb) < 0
+	}, nil
+}
+
+// compareFunc returns a function comparing values of the type, which have to be numbers, strings
+// or have a method Compare(T) int.
+func compareFunc(t reflect.Type) (func(a, b reflect.Value) int, bool) {
+	if m, ok := t.MethodByName("Compare"); ok && m.Type.NumIn() == 2 && m.Type.In(1) == t && m.Type.NumOut() == 1 && m.Type.Out(0).Kind() == reflect.Int {
+		return func(a, b reflect.Value) int {
+			return int(m.Func.Call([]reflect.Value{a, b})[0].Int())
+		}, true
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(a, b reflect.Value) int {
+			return cmp.Compare(a.Int(), b.Int())
+		}, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return func(a, b reflect.Value) int {
+			return cmp.Compare(a.Uint(), b.Uint())
+		}, true
+	case reflect.Float32, reflect.Float64:
+		return func(a, b reflect.Value) int {
+			return cmp.Compare(a.Float(), b.Float())
+		}, true
+	case reflect.String:
+		return func(a, b reflect.Value) int {
+			return cmp.Compare(a.String(), b.String())
+		}, true
+	}
+	return nil, false
+}
+
+// sortSlice sorts the slice stably. The slice is replaced by a sorted copy so that slices shared with the source are never modified.
+func sortSlice(v reflect.Value, less func(a, b reflect.Value) bool) {
+	n := v.Len()
+	if n < 2 {
+		return
+	}
+	r := reflect.MakeSlice(v.Type(), n, n)
+	reflect.Copy(r, v)
+	sort.SliceStable(r.Interface(), func(i, j int) bool {
+		return less(r.Index(i), r.Index(j))
+	})
+	v.Set(r)
+}
@@ -0,0 +1,238 @@
+package keyvalue
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type dedupItem struct {
+	ID   string
+	Name string
+}
+
+type dedupItemDTO struct {
+	ID   uuid.UUID
+	Name string
+}
+
+type dedupOrder struct {
+	ItemIDs []string
+	Items   []*dedupItem
+	Tags    []string
+}
+
+type dedupOrderDTO struct {
+	ItemIDs []uuid.UUID     `copy:",dedup"`
+	Items   []*dedupItemDTO `copy:",dedup=ID"`
+	Tags    []string
+}
+
+func TestCopierDedup(t *testing.T) {
+	req := require.New(t)
+
+	u1, u2 := uuid.New(), uuid.New()
+	src := dedupOrder{
+		ItemIDs: []string{u1.String(), u2.String(), u1.String()},
+		Items:   []*dedupItem{{ID: u1.String(), Name: "a"}, nil, {ID: u1.String(), Name: "b"}, nil, {ID: u2.String(), Name: "c"}},
+		Tags:    []string{"x", "x"},
+	}
+	var dst dedupOrderDTO
+	req.NoError(TypedCopy(&dst, &src))
+	req.Equal([]uuid.UUID{u1, u2}, dst.ItemIDs)
+	req.Equal([]*dedupItemDTO{{ID: u1, Name: "a"}, nil, {ID: u2, Name: "c"}}, dst.Items)
+	req.Equal([]string{"x", "x"}, dst.Tags)
+
+	copier, err := CopierForPairWithOptions(reflect.TypeFor[dedupOrder](), reflect.TypeFor[dedupOrder](), &CopierOptions{
+		Slices: map[string]SliceOptions{"Tags": {Dedup: true}},
+	})
+	req.NoError(err)
+	var same dedupOrder
+	req.NoError(copier(unsafe.Pointer(&same), unsafe.Pointer(&src)))
+	req.Equal([]string{"x"}, same.Tags)
+	req.Equal([]string{"x", "x"}, src.Tags)
+
+	_, err = CopierForPairWithOptions(reflect.TypeFor[dedupOrder](), reflect.TypeFor[dedupOrder](), &CopierOptions{
+		Slices: map[string]SliceOptions{"Items": {Dedup: true, DedupKey: "Missing"}},
+	})
+	req.ErrorIs(err, ErrFieldNotFound)
+
+	_, err = CopierForPairWithOptions(reflect.TypeFor[dedupItem](), reflect.TypeFor[dedupItem](), &CopierOptions{
+		Slices: map[string]SliceOptions{"Name": {Dedup: true}},
+	})
+	req.ErrorIs(err, ErrUnsupportedTypePair)
+}
+
+func TestSliceCopierDedup(t *testing.T) {
+	req := require.New(t)
+
+	u := uuid.New()
+	c, err := SliceCopierForPairWithOptions[dedupItemDTO, dedupItem](SliceOptions{Dedup: true})
+	req.NoError(err)
+	r, err := c([]*dedupItem{{ID: u.String(), Name: "a"}, {ID: u.String(), Name: "a"}, {ID: u.String(), Name: "b"}})
+	req.NoError(err)
+	req.Equal([]*dedupItemDTO{{ID: u, Name: "a"}, {ID: u, Name: "b"}}, r)
+
+	_, err = SliceCopierForPairWithOptions[dedupItemDTO, dedupItem](SliceOptions{Dedup: true, DedupKey: "Name"})
+	req.NoError(err)
+}
+
+type sortEvent struct {
+	Name string
+	At   time.Time
+}
+
+type sortTimeline struct {
+	Events []*sortEvent `copy:",sort=At"`
+	Names  []string     `copy:",dedup,sort"`
+	Scores []float64
+}
+
+func TestCopierSort(t *testing.T) {
+	req := require.New(t)
+
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	e1, e2, e3 := &sortEvent{Name: "a", At: t0.Add(time.Hour)}, &sortEvent{Name: "b", At: t0}, &sortEvent{Name: "c", At: t0}
+	src := sortTimeline{
+		Events: []*sortEvent{e1, e2, nil, e3},
+		Names:  []string{"b", "a", "b"},
+		Scores: []float64{2, 3, 1},
+	}
+	var dst sortTimeline
+	req.NoError(TypedCopy(&dst, &src))
+	req.Equal([]*sortEvent{nil, e2, e3, e1}, dst.Events)
+	req.Equal([]string{"a", "b"}, dst.Names)
+	req.Equal([]*sortEvent{e1, e2, nil, e3}, src.Events)
+
+	copier, err := CopierForPairWithOptions(reflect.TypeFor[sortTimeline](), reflect.TypeFor[sortTimeline](), &CopierOptions{
+		Slices: map[string]SliceOptions{"Scores": {Less: func(a, b interface{}) bool {
+			return a.(float64) > b.(float64)
+		}}},
+	})
+	req.NoError(err)
+	dst = sortTimeline{}
+	req.NoError(copier(unsafe.Pointer(&dst), unsafe.Pointer(&src)))
+	req.Equal([]float64{3, 2, 1}, dst.Scores)
+	req.Equal([]float64{2, 3, 1}, src.Scores)
+
+	_, err = CopierForPairWithOptions(reflect.TypeFor[sortTimeline](), reflect.TypeFor[sortTimeline](), &CopierOptions{
+		Slices: map[string]SliceOptions{"Events": {Sort: true}},
+	})
+	req.ErrorIs(err, ErrUnsupportedTypePair)
+}
+
+func TestSliceCopierSort(t *testing.T) {
+	req := require.New(t)
+
+	u := uuid.New()
+	c, err := SliceCopierForPairWithOptions[dedupItemDTO, dedupItem](SliceOptions{Sort: true, SortKey: "Name"})
+	req.NoError(err)
+	r, err := c([]*dedupItem{{ID: u.String(), Name: "b"}, {ID: u.String(), Name: "a"}})
+	req.NoError(err)
+	req.Equal([]*dedupItemDTO{{ID: u, Name: "a"}, {ID: u, Name: "b"}}, r)
+}
+
+type skipLine struct {
+	SKU string
+	IDs []string
+}
+
+type skipLineDTO struct {
+	SKU uuid.UUID
+	IDs []uuid.UUID `copy:",skipinvalid"`
+}
+
+type skipImport struct {
+	Lines []skipLine
+	Name  string
+}
+
+type skipImportDTO struct {
+	Lines []skipLineDTO `copy:",skipinvalid"`
+	Name  string
+}
+
+func TestCopierSkipInvalid(t *testing.T) {
+	req := require.New(t)
+
+	u1, u2 := uuid.New(), uuid.New()
+	var dst skipImportDTO
+	err := TypedCopy(&dst, &skipImport{
+		Lines: []skipLine{
+			{SKU: u1.String(), IDs: []string{u1.String(), "bad", u2.String()}},
+			{SKU: "bad"},
+			{SKU: u2.String()},
+		},
+		Name: "import",
+	})
+	var skipped *SkippedElementsError
+	req.ErrorAs(err, &skipped)
+	req.Len(skipped.Elements, 2)
+	req.Equal("Lines.0.IDs.1", skipped.Elements[0].Path())
+	req.Equal("Lines.1.SKU", skipped.Elements[1].Path())
+	req.Equal(skipImportDTO{
+		Lines: []skipLineDTO{{SKU: u1, IDs: []uuid.UUID{u1, u2}}, {SKU: u2}},
+		Name:  "import",
+	}, dst)
+
+	dst = skipImportDTO{}
+	req.NoError(TypedCopy(&dst, &skipImport{Lines: []skipLine{{SKU: u1.String()}}}))
+	req.Len(dst.Lines, 1)
+}
+
+func TestSliceCopierSkipInvalid(t *testing.T) {
+	req := require.New(t)
+
+	u := uuid.New()
+	c, err := SliceCopierForPairWithOptions[dedupItemDTO, dedupItem](SliceOptions{SkipInvalid: true, Sort: true, SortKey: "Name"})
+	req.NoError(err)
+	r, err := c([]*dedupItem{{ID: u.String(), Name: "b"}, {ID: "bad", Name: "c"}, {ID: u.String(), Name: "a"}})
+	var skipped *SkippedElementsError
+	req.ErrorAs(err, &skipped)
+	req.Len(skipped.Elements, 1)
+	req.Equal("1.ID", skipped.Elements[0].Path())
+	req.Equal([]*dedupItemDTO{{ID: u, Name: "a"}, {ID: u, Name: "b"}}, r)
+}
+
+type skipNested struct {
+	Line  *skipLine
+	Lines []skipLine
+	Name  string
+}
+
+type skipNestedDTO struct {
+	Line  *skipLineDTO
+	Lines []skipLineDTO
+	Name  string
+}
+
+func TestCopierSkipInvalidNested(t *testing.T) {
+	req := require.New(t)
+
+	u1, u2 := uuid.New(), uuid.New()
+	var dst skipNestedDTO
+	err := TypedCopy(&dst, &skipNested{
+		Line:  &skipLine{SKU: u1.String(), IDs: []string{"bad", u2.String()}},
+		Lines: []skipLine{{SKU: u2.String(), IDs: []string{u1.String(), "bad"}}},
+		Name:  "nested",
+	})
+	var skipped *SkippedElementsError
+	req.ErrorAs(err, &skipped)
+	req.Len(skipped.Elements, 2)
+	req.Equal("Line.IDs.0", skipped.Elements[0].Path())
+	req.Equal("Lines.0.IDs.1", skipped.Elements[1].Path())
+	req.Equal(skipNestedDTO{
+		Line:  &skipLineDTO{SKU: u1, IDs: []uuid.UUID{u2}},
+		Lines: []skipLineDTO{{SKU: u2, IDs: []uuid.UUID{u1}}},
+		Name:  "nested",
+	}, dst)
+
+	err = TypedCopy(&dst, &skipNested{Lines: []skipLine{{SKU: "bad"}}})
+	req.Error(err)
+	req.False(errors.As(err, &skipped))
+}
@@ -0,0 +1,80 @@
+package keyvalue
+
+import (
+	"reflect"
+	"unsafe"
+
+	"github.com/mailstepcz/serr"
+)
+
+// Splitter splits the value of a source field into the values of several destination fields.
+// Func returns one value per destination field; nil values leave the destination fields untouched.
+type Splitter struct {
+	SrcType   reflect.Type
+	DstFields []string
+	Func      func(interface{}) ([]interface{}, error)
+}
+
+// NewSplitter creates a splitter of source values of type S into the destination fields.
+func NewSplitter[S any](f func(S) ([]interface{}, error), dstFields ...string) Splitter {
+	return Splitter{
+		SrcType:   reflect.TypeFor[S](),
+		DstFields: dstFields,
+		Func: func(x interface{}) ([]interface{}, error) {
+			return f(x.(S))
+		},
+	}
+}
+
+// fieldSplitter returns the splitter for the source field.
+func fieldSplitter(name string, opts *CopierOptions, top bool) (Splitter, bool) {
+	if !top || opts == nil {
+		return Splitter{}, false
+	}
+	s, ok := opts.Splitters[name]
+	return s, ok
+}
+
+// splitterConv turns a splitter into a converter from the source field into the whole destination structure.
+func splitterConv(dstType, srcType reflect.Type, s Splitter, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	if s.SrcType != srcType {
+		return nil, serr.Wrap("", ErrUnsupportedTypePair, serr.String("srcType", srcType.String()), serr.String("splitterSrcType", s.SrcType.String()))
+	}
+	fields := make([]reflect.StructField, len(s.DstFields))
+	for i, name := range s.DstFields {
+		f, ok := dstType.FieldByName(name)
+		if !ok || f.PkgPath != "" {
+			return nil, serr.Wrap("", ErrFieldNotFound, serr.String("dstField", name), serr.String("dstType", dstType.Name()))
+		}
+		fields[i] = f
+	}
+	return func(dst, src unsafe.Pointer) error {
+		values, err := s.Func(reflect.NewAt(srcType, src).Elem().Interface())
+		if err != nil {
+			return err
+		}
+		if len(values) != len(fields) {
+			return serr.New("splitter returned wrong number of values", serr.Any("expected", len(fields)), serr.Any("actual", len(values)))
+		}
+		d := reflect.NewAt(dstType, dst).Elem()
+		for i, f := range fields {
+			if values[i] == nil {
+				continue
+			}
+			x := reflect.New(reflect.TypeOf(values[i]))
+			x.Elem().Set(reflect.ValueOf(values[i]))
+			conv, err := valConvWithOptions(f.Type, x.Type().Elem(), opts)
+			if err != nil {
+				return fieldError(err, f.Name, f.Type, x.Type().Elem())
+			}
+			fv, err := d.FieldByIndexErr(f.Index)
+			if err != nil {
+				return fieldError(ErrNilPointer, f.Name, f.Type, x.Type().Elem())
+			}
+			if err := conv(fv.Addr().UnsafePointer(), x.UnsafePointer()); err != nil {
+				return fieldError(err, f.Name, f.Type, x.Type().Elem())
+			}
+		}
+		return nil
+	}, nil
+}
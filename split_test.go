@@ -0,0 +1,85 @@
+package keyvalue
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+type splitSrc struct {
+	Name     string
+	Location string
+}
+
+type splitDst struct {
+	Name    string
+	City    string
+	Country string
+	Zip     int
+}
+
+func splitLocation(s string) ([]interface{}, error) {
+	city, country, ok := strings.Cut(s, ", ")
+	if !ok {
+		return nil, errors.New("bad location")
+	}
+	return []interface{}{city, country}, nil
+}
+
+func TestCopierSplitters(t *testing.T) {
+	req := require.New(t)
+
+	copier, err := CopierForPairWithOptions(reflect.TypeFor[splitDst](), reflect.TypeFor[splitSrc](), &CopierOptions{
+		Splitters: map[string]Splitter{
+			"Location": NewSplitter(splitLocation, "City", "Country"),
+		},
+	})
+	req.NoError(err)
+
+	var dst splitDst
+	req.NoError(copier(unsafe.Pointer(&dst), unsafe.Pointer(&splitSrc{Name: "HQ", Location: "Prague, Czechia"})))
+	req.Equal(splitDst{Name: "HQ", City: "Prague", Country: "Czechia"}, dst)
+
+	err = copier(unsafe.Pointer(&dst), unsafe.Pointer(&splitSrc{Location: "nowhere"}))
+	var cerr *CopyError
+	req.ErrorAs(err, &cerr)
+	req.Equal("Location", cerr.Path())
+
+	_, err = CopierForPairWithOptions(reflect.TypeFor[splitDst](), reflect.TypeFor[splitSrc](), &CopierOptions{
+		Splitters: map[string]Splitter{
+			"Location": NewSplitter(splitLocation, "City", "State"),
+		},
+	})
+	req.ErrorIs(err, ErrFieldNotFound)
+
+	copier, err = CopierForPairWithOptions(reflect.TypeFor[splitDst](), reflect.TypeFor[splitSrc](), &CopierOptions{
+		Splitters: map[string]Splitter{
+			"Location": NewSplitter(func(s string) ([]interface{}, error) {
+				return []interface{}{s, "x"}, nil
+			}, "City", "Zip"),
+		},
+	})
+	req.NoError(err)
+	err = copier(unsafe.Pointer(&dst), unsafe.Pointer(&splitSrc{Location: "Brno"}))
+	req.ErrorAs(err, &cerr)
+	req.Equal("Location.Zip", cerr.Path())
+}
+
+func TestRegistrySplitter(t *testing.T) {
+	req := require.New(t)
+
+	r := NewRegistry()
+	RegisterType[splitSrc](r, "Src")
+	RegisterType[splitDst](r, "Dst")
+	RegisterSplitter(r, "location", NewSplitter(splitLocation, "City", "Country"))
+	req.NoError(r.Load([]byte(`{"pairs": [{"dst": "Dst", "src": "Src", "split": {"Location": "location"}}]}`)))
+
+	var dst splitDst
+	req.NoError(r.Copy(&dst, &splitSrc{Location: "Brno, Czechia"}))
+	req.Equal("Brno", dst.City)
+	req.Equal("Czechia", dst.Country)
+}
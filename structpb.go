@@ -0,0 +1,324 @@
+package keyvalue
+
+import (
+	"encoding"
+	"encoding/base64"
+	"reflect"
+	"sort"
+	"sync"
+	"unsafe"
+
+	"github.com/mailstepcz/serr"
+	"github.com/mailstepcz/types"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+var (
+	structpbType   = reflect.TypeFor[structpb.Struct]()
+	pbValuePtrType = reflect.TypeFor[*structpb.Value]()
+)
+
+// isPBRecord checks whether values of the type are represented by protobuf structures, i.e. it's a structure
+// which isn't represented by a single value like times, decimals or nullable values, or a pointer to one.
+func isPBRecord(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == structpbType {
+		return false
+	}
+	pt := reflect.PointerTo(t)
+	return !pt.Implements(textUnmarshalerType) && !pt.Implements(types.Maybe) && !isNullable(t)
+}
+
+// structpbToStructConv returns a converter of protobuf structures into structures or pointers to structures.
+// Structure fields are matched with the keys given by the `key` tag, or with their names, like for dynamic maps.
+// Missing keys and null values leave the fields unchanged. Values are converted into the field types like by [TypedCopy],
+// nested protobuf structures and lists are converted into nested structures, maps and slices and byte slices are decoded
+// from base64. Strings are converted into times like with TimeStrings since times are stored as strings.
+func structpbToStructConv(dstType reflect.Type, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	var o CopierOptions
+	if opts != nil {
+		o = *opts
+	}
+	o.TimeStrings = true
+	dec, err := pbDecoder(dstType, &o)
+	if err != nil {
+		return nil, err
+	}
+	onNil := nilHandler(dstType, opts)
+	return func(dst, src unsafe.Pointer) error {
+		s := *(**structpb.Struct)(src)
+		if s == nil {
+			return onNil(dst)
+		}
+		return dec(dst, structpb.NewStructValue(s))
+	}, nil
+}
+
+// structToStructpbConv returns a converter of structures or pointers to structures into protobuf structures.
+// Structure fields are stored under the keys given by the `key` tag, or under their names, like for dynamic maps.
+// Values which marshal themselves as text, such as UUIDs and times, are stored as strings, byte slices are encoded
+// in base64 and nested structures, maps and slices become nested protobuf structures and lists.
+func structToStructpbConv(srcType reflect.Type, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	onNil := nilHandler(types.StructpbPtr, opts)
+	return func(dst, src unsafe.Pointer) error {
+		v, err := pbValueOf(reflect.NewAt(srcType, src).Elem())
+		if err != nil {
+			return err
+		}
+		s := v.GetStructValue()
+		if s == nil {
+			return onNil(dst)
+		}
+		*(**structpb.Struct)(dst) = s
+		return nil
+	}, nil
+}
+
+// pbDecoder returns a function decoding protobuf values into values of the type.
+// Decoders of nested structures are compiled once they're first used so that recursive types are supported.
+func pbDecoder(t reflect.Type, opts *CopierOptions) (func(unsafe.Pointer, *structpb.Value) error, error) {
+	switch {
+	case t == types.StructpbPtr:
+		return func(dst unsafe.Pointer, v *structpb.Value) error {
+			if s := v.GetStructValue(); s != nil {
+				*(**structpb.Struct)(dst) = s
+			}
+			return nil
+		}, nil
+
+	case t == pbValuePtrType:
+		return func(dst unsafe.Pointer, v *structpb.Value) error {
+			*(**structpb.Value)(dst) = v
+			return nil
+		}, nil
+
+	case t.Kind() == reflect.Pointer && isPBRecord(t):
+		dec, err := pbDecoder(t.Elem(), opts)
+		if err != nil {
+			return nil, err
+		}
+		return func(dst unsafe.Pointer, v *structpb.Value) error {
+			if _, ok := v.GetKind().(*structpb.Value_NullValue); ok {
+				return nil
+			}
+			p := (*unsafe.Pointer)(dst)
+			if *p == nil {
+				*p = reflect.New(t.Elem()).UnsafePointer()
+			}
+			return dec(*p, v)
+		}, nil
+
+	case isPBRecord(t):
+		type pbField struct {
+			key     string
+			aliases []string
+			field   reflect.StructField
+			offset  uintptr
+			dec     func() (func(unsafe.Pointer, *structpb.Value) error, error)
+		}
+		desc := describeType(t)
+		fields := make([]pbField, 0, len(desc.keys))
+		for k, idx := range desc.keys {
+			offset, ok := fieldOffset(t, idx)
+			if !ok {
+				continue
+			}
+			f := t.FieldByIndex(idx)
+			fields = append(fields, pbField{
+				key:     k,
+				aliases: desc.aliases[k],
+				field:   f,
+				offset:  offset,
+				dec: sync.OnceValues(func() (func(unsafe.Pointer, *structpb.Value) error, error) {
+					return pbDecoder(f.Type, opts)
+				}),
+			})
+		}
+		sort.Slice(fields, func(i, j int) bool {
+			return fields[i].key < fields[j].key
+		})
+		return func(dst unsafe.Pointer, v *structpb.Value) error {
+			s := v.GetStructValue()
+			if s == nil {
+				return serr.Wrap("", ErrBadType, serr.String("dstType", t.String()))
+			}
+			for _, f := range fields {
+				fv, ok := lookupKey(s.GetFields(), f.key, f.aliases)
+				if !ok {
+					continue
+				}
+				dec, err := f.dec()
+				if err != nil {
+					return fieldError(err, f.field.Name, f.field.Type, types.StructpbPtr)
+				}
+				if err := dec(unsafe.Add(dst, f.offset), fv); err != nil {
+					return fieldError(err, f.field.Name, f.field.Type, types.StructpbPtr)
+				}
+			}
+			return nil
+		}, nil
+
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return func(dst unsafe.Pointer, v *structpb.Value) error {
+			if _, ok := v.GetKind().(*structpb.Value_NullValue); ok {
+				return nil
+			}
+			b, err := base64.StdEncoding.DecodeString(v.GetStringValue())
+			if err != nil {
+				return err
+			}
+			reflect.NewAt(t, dst).Elem().SetBytes(b)
+			return nil
+		}, nil
+
+	case t.Kind() == reflect.Slice:
+		dec, err := pbDecoder(t.Elem(), opts)
+		if err != nil {
+			return nil, err
+		}
+		return func(dst unsafe.Pointer, v *structpb.Value) error {
+			if _, ok := v.GetKind().(*structpb.Value_NullValue); ok {
+				return nil
+			}
+			l := v.GetListValue()
+			if l == nil {
+				return serr.Wrap("", ErrBadType, serr.String("dstType", t.String()))
+			}
+			s := reflect.MakeSlice(t, len(l.GetValues()), len(l.GetValues()))
+			for i, el := range l.GetValues() {
+				if err := dec(s.Index(i).Addr().UnsafePointer(), el); err != nil {
+					return elementError(err, i, t.Elem(), nil)
+				}
+			}
+			reflect.NewAt(t, dst).Elem().Set(s)
+			return nil
+		}, nil
+
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String && t != dynmapType:
+		dec, err := pbDecoder(t.Elem(), opts)
+		if err != nil {
+			return nil, err
+		}
+		return func(dst unsafe.Pointer, v *structpb.Value) error {
+			if _, ok := v.GetKind().(*structpb.Value_NullValue); ok {
+				return nil
+			}
+			s := v.GetStructValue()
+			if s == nil {
+				return serr.Wrap("", ErrBadType, serr.String("dstType", t.String()))
+			}
+			m := reflect.MakeMapWithSize(t, len(s.GetFields()))
+			for k, fv := range s.GetFields() {
+				el := reflect.New(t.Elem())
+				if err := dec(el.UnsafePointer(), fv); err != nil {
+					return fieldError(err, k, t.Elem(), nil)
+				}
+				m.SetMapIndex(reflect.ValueOf(k).Convert(t.Key()), el.Elem())
+			}
+			reflect.NewAt(t, dst).Elem().Set(m)
+			return nil
+		}, nil
+	}
+	return func(dst unsafe.Pointer, v *structpb.Value) error {
+		x := v.AsInterface()
+		if x == nil {
+			return nil
+		}
+		xv := reflect.New(reflect.TypeOf(x))
+		xv.Elem().Set(reflect.ValueOf(x))
+		conv, err := valConvWithOptions(t, xv.Type().Elem(), opts)
+		if err != nil {
+			return err
+		}
+		return conv(dst, xv.UnsafePointer())
+	}, nil
+}
+
+// pbValueOf encodes the value as a protobuf value.
+func pbValueOf(v reflect.Value) (*structpb.Value, error) {
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok && v.Kind() != reflect.Pointer {
+		b, err := m.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return structpb.NewStringValue(string(b)), nil
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return structpb.NewNullValue(), nil
+		}
+		switch x := v.Interface().(type) {
+		case *structpb.Struct:
+			return structpb.NewStructValue(x), nil
+		case *structpb.Value:
+			return x, nil
+		}
+		return pbValueOf(v.Elem())
+
+	case reflect.Struct:
+		fm := dynmapFields(v.Type())
+		s := &structpb.Struct{Fields: make(map[string]*structpb.Value, len(fm))}
+		for k, idx := range fm {
+			f, err := v.FieldByIndexErr(idx)
+			if err != nil {
+				continue
+			}
+			fv, err := pbValueOf(f)
+			if err != nil {
+				return nil, fieldError(err, v.Type().FieldByIndex(idx).Name, types.StructpbPtr, f.Type())
+			}
+			s.Fields[k] = fv
+		}
+		return structpb.NewStructValue(s), nil
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return structpb.NewNullValue(), nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 && v.Kind() == reflect.Slice {
+			return structpb.NewStringValue(base64.StdEncoding.EncodeToString(v.Bytes())), nil
+		}
+		l := &structpb.ListValue{Values: make([]*structpb.Value, v.Len())}
+		for i := range l.Values {
+			el, err := pbValueOf(v.Index(i))
+			if err != nil {
+				return nil, elementError(err, i, nil, v.Type().Elem())
+			}
+			l.Values[i] = el
+		}
+		return structpb.NewListValue(l), nil
+
+	case reflect.Map:
+		if v.IsNil() {
+			return structpb.NewNullValue(), nil
+		}
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, serr.Wrap("", ErrBadType, serr.String("srcType", v.Type().String()))
+		}
+		s := &structpb.Struct{Fields: make(map[string]*structpb.Value, v.Len())}
+		iter := v.MapRange()
+		for iter.Next() {
+			fv, err := pbValueOf(iter.Value())
+			if err != nil {
+				return nil, fieldError(err, iter.Key().String(), types.StructpbPtr, iter.Value().Type())
+			}
+			s.Fields[iter.Key().String()] = fv
+		}
+		return structpb.NewStructValue(s), nil
+
+	case reflect.Bool:
+		return structpb.NewBoolValue(v.Bool()), nil
+	case reflect.String:
+		return structpb.NewStringValue(v.String()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return structpb.NewNumberValue(float64(v.Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return structpb.NewNumberValue(float64(v.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		return structpb.NewNumberValue(v.Float()), nil
+	}
+	return nil, serr.Wrap("", ErrBadType, serr.String("srcType", v.Type().String()))
+}
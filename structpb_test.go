@@ -0,0 +1,123 @@
+package keyvalue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type pbAttributes struct {
+	ID       uuid.UUID      `key:"id"`
+	Name     string         `key:"name"`
+	Count    int            `key:"count"`
+	Created  time.Time      `key:"created"`
+	Tags     []string       `key:"tags"`
+	Limits   map[string]int `key:"limits"`
+	Payload  []byte         `key:"payload"`
+	Nested   *pbAttributes  `key:"nested"`
+	Optional *string        `key:"optional"`
+}
+
+type pbEnvelope struct {
+	Kind       string
+	Attributes *structpb.Struct
+}
+
+type domainEnvelope struct {
+	Kind       string
+	Attributes pbAttributes
+}
+
+type domainEnvelopePtr struct {
+	Kind       string
+	Attributes *pbAttributes
+}
+
+func TestStructpbToStruct(t *testing.T) {
+	req := require.New(t)
+
+	u := uuid.New()
+	attrs, err := structpb.NewStruct(map[string]interface{}{
+		"id":      u.String(),
+		"name":    "widget",
+		"count":   3.0,
+		"created": "2024-05-06T07:08:09Z",
+		"tags":    []interface{}{"a", "b"},
+		"limits":  map[string]interface{}{"daily": 10.0},
+		"payload": "AAE=",
+		"nested":  map[string]interface{}{"name": "child", "optional": nil},
+		"unknown": true,
+	})
+	req.NoError(err)
+
+	var dst domainEnvelope
+	req.NoError(NewerCopy(&dst, &pbEnvelope{Kind: "item", Attributes: attrs}))
+	req.Equal("item", dst.Kind)
+	req.Equal(u, dst.Attributes.ID)
+	req.Equal("widget", dst.Attributes.Name)
+	req.Equal(3, dst.Attributes.Count)
+	req.True(time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC).Equal(dst.Attributes.Created))
+	req.Equal([]string{"a", "b"}, dst.Attributes.Tags)
+	req.Equal(map[string]int{"daily": 10}, dst.Attributes.Limits)
+	req.Equal([]byte{0, 1}, dst.Attributes.Payload)
+	req.NotNil(dst.Attributes.Nested)
+	req.Equal("child", dst.Attributes.Nested.Name)
+	req.Nil(dst.Attributes.Nested.Optional)
+
+	var dstPtr domainEnvelopePtr
+	req.NoError(NewerCopy(&dstPtr, &pbEnvelope{Kind: "item", Attributes: attrs}))
+	req.NotNil(dstPtr.Attributes)
+	req.Equal(u, dstPtr.Attributes.ID)
+
+	dstPtr = domainEnvelopePtr{}
+	req.NoError(NewerCopy(&dstPtr, &pbEnvelope{Kind: "empty"}))
+	req.Nil(dstPtr.Attributes)
+
+	attrs.Fields["count"] = structpb.NewStringValue("many")
+	err = NewerCopy(&dst, &pbEnvelope{Attributes: attrs})
+	req.Error(err)
+	var ce *CopyError
+	req.ErrorAs(err, &ce)
+	req.Equal("Attributes.Count", ce.Path())
+}
+
+func TestStructToStructpb(t *testing.T) {
+	req := require.New(t)
+
+	u := uuid.New()
+	optional := "set"
+	src := domainEnvelope{
+		Kind: "item",
+		Attributes: pbAttributes{
+			ID:       u,
+			Name:     "widget",
+			Count:    3,
+			Created:  time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC),
+			Tags:     []string{"a"},
+			Payload:  []byte{0, 1},
+			Nested:   &pbAttributes{Name: "child"},
+			Optional: &optional,
+		},
+	}
+	var dst pbEnvelope
+	req.NoError(NewerCopy(&dst, &src))
+	m := dst.Attributes.AsMap()
+	req.Equal(u.String(), m["id"])
+	req.Equal("widget", m["name"])
+	req.Equal(3.0, m["count"])
+	req.Equal("2024-05-06T07:08:09Z", m["created"])
+	req.Equal([]interface{}{"a"}, m["tags"])
+	req.Nil(m["limits"])
+	req.Equal("AAE=", m["payload"])
+	req.Equal("set", m["optional"])
+	req.Equal("child", m["nested"].(map[string]interface{})["name"])
+
+	var roundTrip domainEnvelope
+	req.NoError(NewerCopy(&roundTrip, &dst))
+	req.Equal(src.Attributes.ID, roundTrip.Attributes.ID)
+	req.Equal(src.Attributes.Payload, roundTrip.Attributes.Payload)
+	req.Equal(*src.Attributes.Optional, *roundTrip.Attributes.Optional)
+}
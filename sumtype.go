@@ -0,0 +1,132 @@
+package keyvalue
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"unsafe"
+
+	"github.com/mailstepcz/serr"
+)
+
+var (
+	sumVariants   = make(map[reflect.Type]map[string]reflect.Type)
+	sumVariantsMu sync.RWMutex
+)
+
+// RegisterSumVariant registers the type T as the variant named name of the sum type I, i.e. of an interface
+// implemented by all its variants. Oneof fields of protobuf messages are copied to and from fields of sum types
+// of the same name by matching the names of the oneof variants, such as Card for a wrapper of a field named Card,
+// with the names of the registered variants. It panics if I isn't an interface implemented by T.
+func RegisterSumVariant[I, T any](name string) {
+	it, t := reflect.TypeFor[I](), reflect.TypeFor[T]()
+	if it.Kind() != reflect.Interface || !t.Implements(it) {
+		panic(fmt.Sprintf("keyvalue: %s isn't a variant of the sum type %s", t, it))
+	}
+	sumVariantsMu.Lock()
+	defer sumVariantsMu.Unlock()
+	if sumVariants[it] == nil {
+		sumVariants[it] = make(map[string]reflect.Type)
+	}
+	sumVariants[it][name] = t
+}
+
+// isSumType checks whether the type has registered variants.
+func isSumType(t reflect.Type) bool {
+	sumVariantsMu.RLock()
+	defer sumVariantsMu.RUnlock()
+	_, ok := sumVariants[t]
+	return ok
+}
+
+// sumVariant returns the type of the variant of the sum type with the name.
+func sumVariant(t reflect.Type, name string) (reflect.Type, bool) {
+	sumVariantsMu.RLock()
+	defer sumVariantsMu.RUnlock()
+	vt, ok := sumVariants[t][name]
+	return vt, ok
+}
+
+// oneofToSumConv returns a converter of the oneof field of the source structure into a sum type.
+// The populated oneof variant is converted into the registered variant of the same name.
+// Empty oneofs are handled by the nil policy and so are the variants missing in the sum type if the options omit them.
+func oneofToSumConv(dstType, srcType reflect.Type, f reflect.StructField, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	type target struct {
+		typ  reflect.Type
+		conv func(unsafe.Pointer, unsafe.Pointer) error
+	}
+	targets := make(map[reflect.Type]target)
+	for _, v := range oneofVariants(srcType, f) {
+		vt, ok := sumVariant(dstType, v.field.Name)
+		if !ok {
+			if opts != nil && opts.OmitNotFound {
+				continue
+			}
+			return nil, serr.Wrap("", ErrFieldNotFound, serr.String("variant", v.field.Name), serr.String("dstType", dstType.String()))
+		}
+		conv, err := valConvWithOptions(vt, v.field.Type, opts)
+		if err != nil {
+			return nil, compileFieldError(err, v.field.Name, vt, v.field.Type)
+		}
+		targets[v.wrapper] = target{typ: vt, conv: conv}
+	}
+	onNil := nilHandler(dstType, opts)
+	return func(dst, src unsafe.Pointer) error {
+		x := reflect.NewAt(f.Type, src).Elem()
+		if x.IsNil() {
+			return onNil(dst)
+		}
+		t, ok := targets[x.Elem().Type()]
+		if !ok {
+			return onNil(dst)
+		}
+		v := reflect.New(t.typ)
+		// the variant is the only field of the wrapper
+		if err := t.conv(v.UnsafePointer(), x.Elem().UnsafePointer()); err != nil {
+			return err
+		}
+		reflect.NewAt(dstType, dst).Elem().Set(v.Elem())
+		return nil
+	}, nil
+}
+
+// sumToOneofConv returns a converter of a sum type into the oneof field of the destination structure.
+// The variant of the sum type is converted into the oneof variant of the same name. Nil values are handled
+// by the nil policy and values of types which aren't variants of the oneof fail with [ErrNotRegistered].
+func sumToOneofConv(dstType reflect.Type, f reflect.StructField, srcType reflect.Type, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	type source struct {
+		wrapper reflect.Type
+		conv    func(unsafe.Pointer, unsafe.Pointer) error
+	}
+	sources := make(map[reflect.Type]source)
+	for _, v := range oneofVariants(dstType, f) {
+		vt, ok := sumVariant(srcType, v.field.Name)
+		if !ok {
+			continue
+		}
+		conv, err := valConvWithOptions(v.field.Type, vt, opts)
+		if err != nil {
+			return nil, compileFieldError(err, v.field.Name, v.field.Type, vt)
+		}
+		sources[vt] = source{wrapper: v.wrapper, conv: conv}
+	}
+	onNil := nilHandler(f.Type, opts)
+	return func(dst, src unsafe.Pointer) error {
+		x := reflect.NewAt(srcType, src).Elem()
+		if x.IsNil() {
+			return onNil(dst)
+		}
+		s, ok := sources[x.Elem().Type()]
+		if !ok {
+			return serr.Wrap("", ErrNotRegistered, serr.String("variant", x.Elem().Type().String()), serr.String("srcType", srcType.String()))
+		}
+		v := reflect.New(x.Elem().Type())
+		v.Elem().Set(x.Elem())
+		w := reflect.New(s.wrapper.Elem())
+		if err := s.conv(w.UnsafePointer(), v.UnsafePointer()); err != nil {
+			return err
+		}
+		reflect.NewAt(f.Type, dst).Elem().Set(w)
+		return nil
+	}, nil
+}
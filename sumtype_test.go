@@ -0,0 +1,80 @@
+package keyvalue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type sumValue interface {
+	isSumValue()
+}
+
+type sumNumber float64
+
+func (sumNumber) isSumValue() {}
+
+type sumText string
+
+func (sumText) isSumValue() {}
+
+type sumFlag bool
+
+func (sumFlag) isSumValue() {}
+
+type sumNull struct{}
+
+func (sumNull) isSumValue() {}
+
+type sumDocument struct {
+	Kind sumValue
+}
+
+func TestOneofToSumType(t *testing.T) {
+	req := require.New(t)
+
+	RegisterSumVariant[sumValue, sumNumber]("NumberValue")
+	RegisterSumVariant[sumValue, sumText]("StringValue")
+	RegisterSumVariant[sumValue, sumFlag]("BoolValue")
+
+	var dst sumDocument
+	req.NoError(TypedCopy(&dst, structpb.NewNumberValue(1.5), WithOmitNotFound()))
+	req.Equal(sumDocument{Kind: sumNumber(1.5)}, dst)
+
+	req.NoError(TypedCopy(&dst, structpb.NewStringValue("abc"), WithOmitNotFound()))
+	req.Equal(sumDocument{Kind: sumText("abc")}, dst)
+
+	req.NoError(TypedCopy(&dst, &structpb.Value{}, WithOmitNotFound(), WithNilPolicy(NilZero)))
+	req.Nil(dst.Kind)
+
+	req.ErrorIs(TypedCopy(&dst, structpb.NewNumberValue(1.5)), ErrFieldNotFound)
+
+	req.Panics(func() {
+		RegisterSumVariant[sumValue, string]("StringValue")
+	})
+}
+
+func TestSumTypeToOneof(t *testing.T) {
+	req := require.New(t)
+
+	RegisterSumVariant[sumValue, sumNumber]("NumberValue")
+	RegisterSumVariant[sumValue, sumText]("StringValue")
+	RegisterSumVariant[sumValue, sumFlag]("BoolValue")
+
+	var dst structpb.Value
+	req.NoError(TypedCopy(&dst, &sumDocument{Kind: sumText("abc")}))
+	req.Equal("abc", dst.GetStringValue())
+
+	req.NoError(TypedCopy(&dst, &sumDocument{Kind: sumNumber(2)}))
+	req.Equal(2.0, dst.GetNumberValue())
+
+	req.NoError(TypedCopy(&dst, &sumDocument{Kind: sumFlag(true)}))
+	req.True(dst.GetBoolValue())
+
+	dst = structpb.Value{}
+	req.NoError(TypedCopy(&dst, &sumDocument{}))
+	req.Nil(dst.Kind)
+
+	req.ErrorIs(TypedCopy(&dst, &sumDocument{Kind: sumNull{}}), ErrNotRegistered)
+}
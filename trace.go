@@ -0,0 +1,67 @@
+package keyvalue
+
+import (
+	"context"
+	"reflect"
+	"time"
+	"unsafe"
+)
+
+// CopyTracer traces the copies performed by copiers and slice copiers, e.g. by starting OpenTelemetry spans
+// and recording copy counts and latencies keyed by the type pairs. An OpenTelemetry tracer can be plugged in as
+//
+//	keyvalue.CopyTracerFunc(func(ctx context.Context, dstType, srcType reflect.Type) (context.Context, func(keyvalue.CopyStats)) {
+//		ctx, span := tracer.Start(ctx, "copy "+srcType.String()+" to "+dstType.String())
+//		return ctx, func(stats keyvalue.CopyStats) {
+//			if stats.Err != nil {
+//				span.RecordError(stats.Err)
+//			}
+//			span.End()
+//		}
+//	})
+type CopyTracer interface {
+	// StartCopy is invoked before a copy of a value of the source type into a value of the destination type
+	// with the context passed on to the copier. The returned context is passed on to the converters and hooks
+	// of the copy and the returned function receives the statistics of the copy once it's done.
+	StartCopy(ctx context.Context, dstType, srcType reflect.Type) (context.Context, func(CopyStats))
+}
+
+// CopyTracerFunc is a function implementing [CopyTracer].
+type CopyTracerFunc func(ctx context.Context, dstType, srcType reflect.Type) (context.Context, func(CopyStats))
+
+// StartCopy invokes the function.
+func (f CopyTracerFunc) StartCopy(ctx context.Context, dstType, srcType reflect.Type) (context.Context, func(CopyStats)) {
+	return f(ctx, dstType, srcType)
+}
+
+// traced returns a copier reporting its copies to the tracer.
+func traced(copier func(context.Context, unsafe.Pointer, unsafe.Pointer) error, tracer CopyTracer, stats CopyStats) func(context.Context, unsafe.Pointer, unsafe.Pointer) error {
+	return func(ctx context.Context, dst, src unsafe.Pointer) error {
+		ctx, end := tracer.StartCopy(ctx, stats.DstType, stats.SrcType)
+		start := time.Now()
+		err := copier(ctx, dst, src)
+		stats := stats
+		stats.Duration = time.Since(start)
+		stats.Err = err
+		end(stats)
+		return err
+	}
+}
+
+// tracedSlice returns a slice copier reporting its copies to the tracer with the background context.
+func tracedSlice[D, S any](c func([]*S) ([]*D, error), tracer CopyTracer) func([]*S) ([]*D, error) {
+	dstType, srcType := reflect.TypeFor[[]*D](), reflect.TypeFor[[]*S]()
+	return func(src []*S) ([]*D, error) {
+		_, end := tracer.StartCopy(context.Background(), dstType, srcType)
+		start := time.Now()
+		r, err := c(src)
+		end(CopyStats{
+			DstType:  dstType,
+			SrcType:  srcType,
+			Elements: len(src),
+			Duration: time.Since(start),
+			Err:      err,
+		})
+		return r, err
+	}
+}
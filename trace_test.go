@@ -0,0 +1,55 @@
+package keyvalue
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+type tracerKey struct{}
+
+func TestCopierTracer(t *testing.T) {
+	req := require.New(t)
+
+	type traced struct {
+		dstType, srcType reflect.Type
+		stats            CopyStats
+	}
+	var spans []traced
+	var seen []interface{}
+	tracer := CopyTracerFunc(func(ctx context.Context, dstType, srcType reflect.Type) (context.Context, func(CopyStats)) {
+		return context.WithValue(ctx, tracerKey{}, "span"), func(stats CopyStats) {
+			spans = append(spans, traced{dstType: dstType, srcType: srcType, stats: stats})
+		}
+	})
+
+	copier, err := ContextCopierForPairWithOptions(reflect.TypeFor[copierDst2](), reflect.TypeFor[copierSrc4](), &CopierOptions{
+		OmitNotFound: true,
+		Tracer:       tracer,
+		ValidateContext: func(ctx context.Context, _ interface{}) error {
+			seen = append(seen, ctx.Value(tracerKey{}))
+			return nil
+		},
+		NoCache: true,
+	})
+	req.NoError(err)
+	var dst copierDst2
+	req.NoError(copier(context.Background(), unsafe.Pointer(&dst), unsafe.Pointer(&copierSrc4{N: 1234, S: "text"})))
+	req.Len(spans, 1)
+	req.Equal(reflect.TypeFor[copierDst2](), spans[0].dstType)
+	req.Equal(reflect.TypeFor[copierSrc4](), spans[0].srcType)
+	req.Equal(2, spans[0].stats.Fields)
+	req.NoError(spans[0].stats.Err)
+	req.Equal([]interface{}{"span"}, seen)
+
+	c, err := SliceCopierForPairWithOptions[copierDst2, copierDst2](SliceOptions{Tracer: tracer})
+	req.NoError(err)
+	_, err = c([]*copierDst2{{N: 1}, {N: 2}})
+	req.NoError(err)
+	req.Len(spans, 2)
+	req.Equal(reflect.TypeFor[[]*copierDst2](), spans[1].dstType)
+	req.Equal(2, spans[1].stats.Elements)
+}
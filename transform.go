@@ -0,0 +1,73 @@
+package keyvalue
+
+import (
+	"reflect"
+	"strings"
+	"unsafe"
+
+	"github.com/mailstepcz/serr"
+	"golang.org/x/text/unicode/norm"
+)
+
+// StringTransform is a transformation of copied strings such as trimming or case folding.
+type StringTransform func(string) string
+
+// string transforms
+var (
+	// TrimSpace removes leading and trailing white space.
+	TrimSpace StringTransform = strings.TrimSpace
+	// ToLower maps letters to lower case.
+	ToLower StringTransform = strings.ToLower
+	// ToUpper maps letters to upper case.
+	ToUpper StringTransform = strings.ToUpper
+	// NFC converts strings to the Unicode normalization form C.
+	NFC StringTransform = norm.NFC.String
+)
+
+// namedTransforms are the string transforms which can be used in the `transform` tag.
+var namedTransforms = map[string]StringTransform{
+	"trim":  TrimSpace,
+	"lower": ToLower,
+	"upper": ToUpper,
+	"nfc":   NFC,
+}
+
+// fieldTransforms returns the string transforms applied when copying the source field to the destination field.
+// Transforms listed in the `transform` tag of the destination field are followed by those given by the options.
+func fieldTransforms(dstField, srcField reflect.StructField, opts *CopierOptions, top bool) ([]StringTransform, error) {
+	var transforms []StringTransform
+	if tag := dstField.Tag.Get("transform"); tag != "" {
+		for _, name := range strings.Split(tag, ",") {
+			t, ok := namedTransforms[strings.TrimSpace(name)]
+			if !ok {
+				return nil, serr.New("unknown string transform", serr.String("transform", name))
+			}
+			transforms = append(transforms, t)
+		}
+	}
+	if top && opts != nil {
+		transforms = append(transforms, opts.StringTransforms[srcField.Name]...)
+	}
+	if len(transforms) > 0 && dstField.Type.Kind() != reflect.String {
+		return nil, serr.Wrap("", ErrUnsupportedTypePair, serr.String("dstType", dstField.Type.String()))
+	}
+	return transforms, nil
+}
+
+// transformedConv returns a converter to strings which transforms the converted strings.
+func transformedConv(dstType, srcType reflect.Type, transforms []StringTransform, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	conv, err := valConvWithOptions(dstType, srcType, opts)
+	if err != nil {
+		return nil, err
+	}
+	return func(dst, src unsafe.Pointer) error {
+		if err := conv(dst, src); err != nil {
+			return err
+		}
+		s := (*string)(dst)
+		for _, t := range transforms {
+			*s = t(*s)
+		}
+		return nil
+	}, nil
+}
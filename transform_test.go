@@ -0,0 +1,61 @@
+package keyvalue
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+type transformDst struct {
+	Email   string `transform:"trim,lower"`
+	Code    string
+	Name    string `transform:"nfc"`
+	Comment string
+}
+
+type transformSrc struct {
+	Email   string
+	Code    *string
+	Name    string
+	Comment string
+}
+
+type transformBadDst struct {
+	Email int `transform:"trim"`
+}
+
+type transformUnknownDst struct {
+	Email string `transform:"reverse"`
+}
+
+func TestCopierStringTransforms(t *testing.T) {
+	req := require.New(t)
+
+	code := " ab-12 "
+	src := transformSrc{
+		Email:   "  John@Example.COM ",
+		Code:    &code,
+		Name:    "Cafe\u0301",
+		Comment: "  as is ",
+	}
+	copier, err := CopierForPairWithOptions(reflect.TypeFor[transformDst](), reflect.TypeFor[transformSrc](), &CopierOptions{
+		StringTransforms: map[string][]StringTransform{
+			"Code": {TrimSpace, ToUpper},
+		},
+	})
+	req.NoError(err)
+	var dst transformDst
+	req.NoError(copier(unsafe.Pointer(&dst), unsafe.Pointer(&src)))
+	req.Equal("john@example.com", dst.Email)
+	req.Equal("AB-12", dst.Code)
+	req.Equal("Caf\u00e9", dst.Name)
+	req.Equal("  as is ", dst.Comment)
+
+	_, err = CopierForPair(reflect.TypeFor[transformBadDst](), reflect.TypeFor[transformSrc]())
+	req.ErrorIs(err, ErrUnsupportedTypePair)
+
+	_, err = CopierForPairWithOptions(reflect.TypeFor[transformUnknownDst](), reflect.TypeFor[transformSrc](), &CopierOptions{OmitNotFound: true})
+	req.Error(err)
+}
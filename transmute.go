@@ -126,7 +126,15 @@ func Transmuter(typ reflect.Type) func(interface{}) interface{} {
 	}
 }
 
+// interfaceHeader mirrors the runtime representation of interfaces, i.e. a type word followed by a data word,
+// which is the same on all the supported architectures including 32-bit ones and wasm.
 type interfaceHeader struct {
 	typ uintptr
 	ptr unsafe.Pointer
 }
+
+// Compile-time checks that interfaceHeader agrees with the size of interfaces on the target architecture.
+var (
+	_ [unsafe.Sizeof(interfaceHeader{}) - unsafe.Sizeof(interface{}(nil))]struct{}
+	_ [unsafe.Sizeof(interface{}(nil)) - unsafe.Sizeof(interfaceHeader{})]struct{}
+)
@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"testing"
 	"time"
+	"unsafe"
 
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
@@ -157,3 +158,13 @@ func TestCircularStructureReturnsError(t *testing.T) {
 	_, err := JSONType(reflect.TypeOf((*employee)(nil)).Elem(), "jsonv2")
 	req.EqualError(err, "circular type reference not supported by transmuter")
 }
+
+func TestInterfaceHeaderLayout(t *testing.T) {
+	req := require.New(t)
+
+	x := new(int)
+	var i interface{} = x
+	h := (*interfaceHeader)(unsafe.Pointer(&i))
+	req.Equal(unsafe.Pointer(x), h.ptr)
+	req.Equal(unsafe.Sizeof(i), unsafe.Sizeof(*h))
+}
@@ -0,0 +1,114 @@
+package keyvalue
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// typeDescriptors caches the descriptors of structure types.
+var typeDescriptors sync.Map // reflect.Type -> *typeDescriptor
+
+// typeDescriptor describes the fields of a structure type.
+// Descriptors are shared by the copier compiler, the dynamic map conversions and the adapters so they mustn't be modified.
+type typeDescriptor struct {
+	// fields are the visible fields of the structure in the order given by [reflect.VisibleFields].
+	fields []fieldDescriptor
+	// keys are the indices of the exported fields keyed by their canonical dynamic map keys.
+	keys map[string][]int
+	// aliases are the alternative keys of the fields in the order of preference keyed by their canonical keys.
+	aliases map[string][]string
+	// canonical maps the alternative keys to the canonical ones.
+	canonical map[string]string
+	// ambiguous are the ambiguous field names along with the paths of the conflicting fields.
+	ambiguous map[string][]string
+	// renamed are the exported fields renamed by their `copy` tags keyed by the names given by the tags.
+	renamed map[string]reflect.StructField
+}
+
+// fieldDescriptor describes a visible field of a structure.
+type fieldDescriptor struct {
+	reflect.StructField
+	// offset is the offset of the field relative to the outermost structure, valid unless viaPointer is set.
+	offset uintptr
+	// viaPointer tells whether the field is promoted through an embedded pointer.
+	viaPointer bool
+	// skipped tells whether the field is excluded from copying by the `kv:"-"` tag.
+	skipped bool
+}
+
+// describeType returns the descriptor of the structure type, computing it on first use.
+func describeType(t reflect.Type) *typeDescriptor {
+	if d, ok := typeDescriptors.Load(t); ok {
+		return d.(*typeDescriptor)
+	}
+	visible := reflect.VisibleFields(t)
+	d := &typeDescriptor{
+		fields:    make([]fieldDescriptor, len(visible)),
+		keys:      make(map[string][]int),
+		ambiguous: ambiguousFields(t),
+	}
+	for i, f := range visible {
+		offset, ok := fieldOffset(t, f.Index)
+		d.fields[i] = fieldDescriptor{
+			StructField: f,
+			offset:      offset,
+			viaPointer:  !ok,
+			skipped:     f.Tag.Get("kv") == "-",
+		}
+		if f.PkgPath != "" || d.fields[i].skipped {
+			continue
+		}
+		if name := copyTagName(f); name != "" {
+			if d.renamed == nil {
+				d.renamed = make(map[string]reflect.StructField)
+			}
+			if g, ok := d.renamed[name]; !ok || len(f.Index) < len(g.Index) {
+				d.renamed[name] = f
+			}
+		}
+		key := f.Name
+		if tag := f.Tag.Get("key"); tag != "" {
+			keys := strings.Split(tag, ",")
+			key = keys[0]
+			for _, alias := range keys[1:] {
+				if alias == "" {
+					continue
+				}
+				if d.aliases == nil {
+					d.aliases = make(map[string][]string)
+					d.canonical = make(map[string]string)
+				}
+				d.aliases[key] = append(d.aliases[key], alias)
+				d.canonical[alias] = key
+			}
+		}
+		d.keys[key] = f.Index
+	}
+	actual, _ := typeDescriptors.LoadOrStore(t, d)
+	return actual.(*typeDescriptor)
+}
+
+// dynmapField returns the index of the field with the dynamic map key, which may be an alternative key.
+func (d *typeDescriptor) dynmapField(key string) ([]int, bool) {
+	if idx, ok := d.keys[key]; ok {
+		return idx, true
+	}
+	idx, ok := d.keys[d.canonical[key]]
+	return idx, ok
+}
+
+// lookupKey returns the value stored in the map under the canonical key or, if there's none,
+// under the first alternative key present in the map.
+func lookupKey[V any](m map[string]V, key string, aliases []string) (V, bool) {
+	if x, ok := m[key]; ok {
+		return x, true
+	}
+	for _, alias := range aliases {
+		if x, ok := m[alias]; ok {
+			return x, true
+		}
+	}
+	var zero V
+	return zero, false
+}
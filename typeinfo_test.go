@@ -0,0 +1,44 @@
+package keyvalue
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+type describedInner struct {
+	Inner string
+}
+
+type described struct {
+	*describedInner
+	ID      int    `key:"id"`
+	Name    string `kv:"-"`
+	private bool
+}
+
+func TestDescribeType(t *testing.T) {
+	req := require.New(t)
+
+	typ := reflect.TypeFor[described]()
+	d := describeType(typ)
+	req.Same(d, describeType(typ))
+
+	req.Equal(map[string][]int{
+		"Inner": {0, 0},
+		"id":    {1},
+	}, d.keys)
+
+	byName := make(map[string]fieldDescriptor, len(d.fields))
+	for _, f := range d.fields {
+		byName[f.Name] = f
+	}
+	req.Len(byName, 5)
+	req.True(byName["Inner"].viaPointer)
+	req.False(byName["ID"].viaPointer)
+	req.Equal(unsafe.Offsetof(described{}.ID), byName["ID"].offset)
+	req.True(byName["Name"].skipped)
+	req.False(byName["private"].skipped)
+}
@@ -0,0 +1,143 @@
+package keyvalue
+
+import (
+	"reflect"
+	"unsafe"
+
+	"github.com/mailstepcz/serr"
+)
+
+// TaggedUnion describes a tagged union in the source structure, i.e. a discriminator field along with the fields
+// holding the payloads of the variants, which is copied into a oneof field or a field of a sum type registered
+// with [RegisterSumVariant]. Only the payload of the variant selected by the discriminator is copied.
+type TaggedUnion struct {
+	// Discriminator is the name of the string field holding the tag of the active variant.
+	// Empty tags are handled by the nil policy.
+	Discriminator string
+	// Variants maps the tags to the names of the variants of the destination field. Unknown tags make the copy fail
+	// with [ErrFieldNotFound].
+	Variants map[string]string
+	// Payloads maps the names of the variants to the names of the source fields holding their payloads.
+	// The payloads are taken from the fields named after the variants by default.
+	Payloads map[string]string
+}
+
+// unionFields returns the names of the source fields consumed by the tagged unions in the options.
+func unionFields(opts *CopierOptions, top bool) []string {
+	if !top || opts == nil {
+		return nil
+	}
+	var names []string
+	for _, name := range sortedKeys(opts.Unions) {
+		u := opts.Unions[name]
+		names = append(names, u.Discriminator)
+		for _, tag := range sortedKeys(u.Variants) {
+			names = append(names, u.payloadField(u.Variants[tag]))
+		}
+	}
+	return names
+}
+
+// isUnionField checks whether the destination field is populated from a tagged union.
+func isUnionField(name string, opts *CopierOptions, top bool) bool {
+	if !top || opts == nil {
+		return false
+	}
+	_, ok := opts.Unions[name]
+	return ok
+}
+
+// payloadField returns the name of the source field holding the payload of the variant.
+func (u TaggedUnion) payloadField(variant string) string {
+	if name, ok := u.Payloads[variant]; ok {
+		return name
+	}
+	return variant
+}
+
+// taggedUnionConv returns a converter of the tagged union in the source structure into the destination field.
+func taggedUnionConv(dstType reflect.Type, f reflect.StructField, srcType reflect.Type, u TaggedUnion, opts *CopierOptions) (func(unsafe.Pointer, unsafe.Pointer) error, error) {
+	type variant struct {
+		name    string
+		offset  uintptr
+		srcType reflect.Type
+		dstType reflect.Type
+		conv    func(unsafe.Pointer, unsafe.Pointer) error
+		// wrapper is the oneof wrapper of the variant, if any
+		wrapper reflect.Type
+	}
+	disc, ok := srcType.FieldByName(u.Discriminator)
+	var discOffset uintptr
+	if ok {
+		discOffset, ok = fieldOffset(srcType, disc.Index)
+	}
+	if !ok {
+		return nil, serr.Wrap("", ErrFieldNotFound, serr.String("srcField", u.Discriminator), serr.String("srcType", srcType.Name()))
+	}
+	if disc.Type.Kind() != reflect.String {
+		return nil, serr.Wrap("", ErrUnsupportedTypePair, serr.String("srcField", u.Discriminator), serr.String("srcType", disc.Type.String()))
+	}
+	var wrappers map[string]oneofVariant
+	switch {
+	case isOneof(f):
+		wrappers = make(map[string]oneofVariant)
+		for _, v := range oneofVariants(dstType, f) {
+			wrappers[v.field.Name] = v
+		}
+	case isSumType(f.Type):
+	default:
+		return nil, serr.Wrap("", ErrUnsupportedTypePair, serr.String("dstField", f.Name), serr.String("dstType", f.Type.String()))
+	}
+	variants := make(map[string]*variant, len(u.Variants))
+	for tag, name := range u.Variants {
+		payload := u.payloadField(name)
+		sf, ok := srcType.FieldByName(payload)
+		var offset uintptr
+		if ok {
+			offset, ok = fieldOffset(srcType, sf.Index)
+		}
+		if !ok {
+			return nil, serr.Wrap("", ErrFieldNotFound, serr.String("srcField", payload), serr.String("srcType", srcType.Name()))
+		}
+		v := &variant{name: name, offset: offset, srcType: sf.Type}
+		if wrappers != nil {
+			w, ok := wrappers[name]
+			if !ok {
+				return nil, serr.Wrap("", ErrFieldNotFound, serr.String("variant", name), serr.String("oneof", f.Name))
+			}
+			v.wrapper, v.dstType = w.wrapper, w.field.Type
+		} else if v.dstType, ok = sumVariant(f.Type, name); !ok {
+			return nil, serr.Wrap("", ErrFieldNotFound, serr.String("variant", name), serr.String("dstType", f.Type.String()))
+		}
+		conv, err := valConvWithOptions(v.dstType, sf.Type, opts)
+		if err != nil {
+			return nil, compileFieldError(err, payload, v.dstType, sf.Type)
+		}
+		v.conv = conv
+		variants[tag] = v
+	}
+	onNil := nilHandler(f.Type, opts)
+	return func(dst, src unsafe.Pointer) error {
+		tag := reflect.NewAt(disc.Type, unsafe.Add(src, discOffset)).Elem().String()
+		if tag == "" {
+			return onNil(dst)
+		}
+		v, ok := variants[tag]
+		if !ok {
+			return serr.Wrap("", ErrFieldNotFound, serr.String("tag", tag), serr.String("discriminator", u.Discriminator))
+		}
+		// the variant is the only field of its oneof wrapper
+		x := reflect.New(v.dstType)
+		if v.wrapper != nil {
+			x = reflect.New(v.wrapper.Elem())
+		}
+		if err := v.conv(x.UnsafePointer(), unsafe.Add(src, v.offset)); err != nil {
+			return fieldError(err, v.name, v.dstType, v.srcType)
+		}
+		if v.wrapper == nil {
+			x = x.Elem()
+		}
+		reflect.NewAt(f.Type, dst).Elem().Set(x)
+		return nil
+	}, nil
+}
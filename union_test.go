@@ -0,0 +1,62 @@
+package keyvalue
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type taggedScalar struct {
+	Type   string
+	Amount float64
+	Label  string
+}
+
+var taggedScalarUnion = TaggedUnion{
+	Discriminator: "Type",
+	Variants:      map[string]string{"number": "NumberValue", "text": "StringValue"},
+	Payloads:      map[string]string{"NumberValue": "Amount", "StringValue": "Label"},
+}
+
+func TestTaggedUnionToOneof(t *testing.T) {
+	req := require.New(t)
+
+	opts := []CopierOption{WithTaggedUnion("Kind", taggedScalarUnion), WithNilPolicy(NilZero)}
+
+	var dst structpb.Value
+	req.NoError(TypedCopy(&dst, &taggedScalar{Type: "number", Amount: 1.5, Label: "ignored"}, opts...))
+	req.Equal(&structpb.Value_NumberValue{NumberValue: 1.5}, dst.Kind)
+
+	req.NoError(TypedCopy(&dst, &taggedScalar{Type: "text", Label: "abc"}, opts...))
+	req.Equal(&structpb.Value_StringValue{StringValue: "abc"}, dst.Kind)
+
+	req.NoError(TypedCopy(&dst, &taggedScalar{Label: "abc"}, opts...))
+	req.Nil(dst.Kind)
+
+	req.ErrorIs(TypedCopy(&dst, &taggedScalar{Type: "date"}, opts...), ErrFieldNotFound)
+
+	bad := TaggedUnion{Discriminator: "Type", Variants: map[string]string{"date": "DateValue"}}
+	req.ErrorIs(TypedCopy(&dst, &taggedScalar{}, WithTaggedUnion("Kind", bad)), ErrFieldNotFound)
+}
+
+func TestTaggedUnionToSumType(t *testing.T) {
+	req := require.New(t)
+
+	RegisterSumVariant[sumValue, sumNumber]("NumberValue")
+	RegisterSumVariant[sumValue, sumText]("StringValue")
+
+	var dst sumDocument
+	req.NoError(TypedCopy(&dst, &taggedScalar{Type: "number", Amount: 2}, WithTaggedUnion("Kind", taggedScalarUnion)))
+	req.Equal(sumDocument{Kind: sumNumber(2)}, dst)
+
+	req.NoError(TypedCopy(&dst, &taggedScalar{Type: "text", Label: "abc"}, WithTaggedUnion("Kind", taggedScalarUnion)))
+	req.Equal(sumDocument{Kind: sumText("abc")}, dst)
+
+	h, err := HandleForPair(reflect.TypeFor[sumDocument](), reflect.TypeFor[taggedScalar](), NewCopierOptions(WithTaggedUnion("Kind", taggedScalarUnion)))
+	req.NoError(err)
+	_, irreversible, err := h.ReverseCopier()
+	req.NoError(err)
+	req.Equal("Kind", irreversible[0].Field)
+}
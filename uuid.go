@@ -0,0 +1,76 @@
+package keyvalue
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/oklog/ulid/v2"
+)
+
+// errInvalidUUIDFormat mirrors the error returned by [uuid.Parse] without allocating it anew on every failure.
+var errInvalidUUIDFormat = errors.New("invalid UUID format")
+
+var hexValues = func() (t [256]byte) {
+	for i := range t {
+		t[i] = 0xff
+	}
+	for c := '0'; c <= '9'; c++ {
+		t[c] = byte(c - '0')
+	}
+	for c := 'a'; c <= 'f'; c++ {
+		t[c] = byte(c - 'a' + 10)
+	}
+	for c := 'A'; c <= 'F'; c++ {
+		t[c] = byte(c - 'A' + 10)
+	}
+	return
+}()
+
+// parseUUID parses a UUID. The canonical and the plain hexadecimal forms are handled without allocating,
+// the other forms are delegated to [uuid.Parse].
+func parseUUID(s string) (uuid.UUID, error) {
+	var u uuid.UUID
+	switch len(s) {
+	case 36:
+		if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			return u, errInvalidUUIDFormat
+		}
+		for i, x := range [16]int{0, 2, 4, 6, 9, 11, 14, 16, 19, 21, 24, 26, 28, 30, 32, 34} {
+			b1, b2 := hexValues[s[x]], hexValues[s[x+1]]
+			if b1 == 0xff || b2 == 0xff {
+				return u, errInvalidUUIDFormat
+			}
+			u[i] = b1<<4 | b2
+		}
+		return u, nil
+	case 32:
+		for i := range u {
+			b1, b2 := hexValues[s[2*i]], hexValues[s[2*i+1]]
+			if b1 == 0xff || b2 == 0xff {
+				return u, errInvalidUUIDFormat
+			}
+			u[i] = b1<<4 | b2
+		}
+		return u, nil
+	}
+	return uuid.Parse(s)
+}
+
+// parseAnyUUID parses a UUID given in any of the forms accepted by [parseUUID] or as a ULID,
+// which shares the 128-bit representation.
+func parseAnyUUID(s string) (uuid.UUID, error) {
+	if len(s) == ulid.EncodedSize {
+		id, err := ulid.ParseStrict(s)
+		return uuid.UUID(id), err
+	}
+	return parseUUID(s)
+}
+
+// parseAnyULID parses a ULID given in its canonical form or as a UUID, which shares the 128-bit representation.
+func parseAnyULID(s string) (ulid.ULID, error) {
+	if len(s) != ulid.EncodedSize {
+		u, err := parseUUID(s)
+		return ulid.ULID(u), err
+	}
+	return ulid.Parse(s)
+}
@@ -0,0 +1,68 @@
+package keyvalue
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/google/uuid"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUUID(t *testing.T) {
+	req := require.New(t)
+
+	u := uuid.New()
+	for _, s := range []string{
+		u.String(),
+		"urn:uuid:" + u.String(),
+		"{" + u.String() + "}",
+		hexString(u[:]),
+	} {
+		v, err := parseUUID(s)
+		req.NoError(err)
+		req.Equal(u, v)
+	}
+
+	_, err := parseUUID("faf5914d-0734-4d91-b486-e046ce19729g")
+	req.EqualError(err, "invalid UUID format")
+	_, err = parseUUID("uuid")
+	req.True(uuid.IsInvalidLengthError(err))
+}
+
+func hexString(b []byte) string {
+	const digits = "0123456789abcdef"
+	r := make([]byte, 0, 2*len(b))
+	for _, x := range b {
+		r = append(r, digits[x>>4], digits[x&0xf])
+	}
+	return string(r)
+}
+
+func benchmarkValConv[D, S any](b *testing.B, src S) {
+	f, err := valConv(reflect.TypeFor[D](), reflect.TypeFor[S]())
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	var dst D
+	for i := 0; i < b.N; i++ {
+		if err := f(unsafe.Pointer(&dst), unsafe.Pointer(&src)); err != nil {
+			b.Fatal(err)
+		}
+	}
+	gr = dst
+}
+
+func BenchmarkStringToUUID(b *testing.B) {
+	benchmarkValConv[uuid.UUID](b, uuid.NewString())
+}
+
+func BenchmarkStringToULID(b *testing.B) {
+	benchmarkValConv[ulid.ULID](b, ulid.Make().String())
+}
+
+func BenchmarkUUIDToString(b *testing.B) {
+	benchmarkValConv[string](b, uuid.New())
+}
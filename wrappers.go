@@ -0,0 +1,33 @@
+package keyvalue
+
+import (
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// registerWrapper registers a protobuf wrapper as a nullable type. Nil wrappers are treated as NULL.
+func registerWrapper[W any, T any](value func(*W) *T, wrap func(T) *W) {
+	RegisterNullable(func(x **W) (*T, error) {
+		if *x == nil {
+			return nil, nil
+		}
+		return value(*x), nil
+	}, func(x **W, y *T) {
+		if y == nil {
+			*x = nil
+			return
+		}
+		*x = wrap(*y)
+	})
+}
+
+func init() {
+	registerWrapper(func(x *wrapperspb.StringValue) *string { return &x.Value }, wrapperspb.String)
+	registerWrapper(func(x *wrapperspb.BoolValue) *bool { return &x.Value }, wrapperspb.Bool)
+	registerWrapper(func(x *wrapperspb.Int32Value) *int32 { return &x.Value }, wrapperspb.Int32)
+	registerWrapper(func(x *wrapperspb.Int64Value) *int64 { return &x.Value }, wrapperspb.Int64)
+	registerWrapper(func(x *wrapperspb.UInt32Value) *uint32 { return &x.Value }, wrapperspb.UInt32)
+	registerWrapper(func(x *wrapperspb.UInt64Value) *uint64 { return &x.Value }, wrapperspb.UInt64)
+	registerWrapper(func(x *wrapperspb.FloatValue) *float32 { return &x.Value }, wrapperspb.Float)
+	registerWrapper(func(x *wrapperspb.DoubleValue) *float64 { return &x.Value }, wrapperspb.Double)
+	registerWrapper(func(x *wrapperspb.BytesValue) *[]byte { return &x.Value }, wrapperspb.Bytes)
+}
@@ -0,0 +1,61 @@
+package keyvalue
+
+import (
+	"testing"
+
+	"github.com/mailstepcz/maybe"
+	"github.com/mailstepcz/pointer"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type wrappersDomain struct {
+	Name    maybe.Maybe[string]
+	Count   maybe.Maybe[int64]
+	Active  maybe.Maybe[bool]
+	Ratio   *float64
+	Limit   int32
+	Comment maybe.Maybe[string]
+}
+
+type wrappersProto struct {
+	Name    *wrapperspb.StringValue
+	Count   *wrapperspb.Int64Value
+	Active  *wrapperspb.BoolValue
+	Ratio   *wrapperspb.DoubleValue
+	Limit   *wrapperspb.Int64Value
+	Comment *wrapperspb.StringValue
+}
+
+func TestWrappers(t *testing.T) {
+	req := require.New(t)
+
+	var pb wrappersProto
+	err := TypedCopy(&pb, &wrappersDomain{
+		Name:   maybe.Unit("John"),
+		Count:  maybe.Unit[int64](3),
+		Active: maybe.Unit(false),
+		Ratio:  pointer.To(0.0),
+		Limit:  10,
+	})
+	req.NoError(err)
+	req.Equal("John", pb.Name.GetValue())
+	req.Equal(int64(3), pb.Count.GetValue())
+	req.NotNil(pb.Active)
+	req.False(pb.Active.GetValue())
+	req.NotNil(pb.Ratio)
+	req.Zero(pb.Ratio.GetValue())
+	req.Equal(int64(10), pb.Limit.GetValue())
+	req.Nil(pb.Comment)
+
+	var d wrappersDomain
+	err = TypedCopy(&d, &pb)
+	req.NoError(err)
+	req.Equal(wrappersDomain{
+		Name:   maybe.Unit("John"),
+		Count:  maybe.Unit[int64](3),
+		Active: maybe.Unit(false),
+		Ratio:  pointer.To(0.0),
+		Limit:  10,
+	}, d)
+}